@@ -0,0 +1,158 @@
+// Command migrate applies, reverts and creates database migrations.
+//
+//	migrate up            apply every pending migration
+//	migrate down N        revert the N most recent migrations
+//	migrate status        list migrations and when they were applied
+//	migrate create NAME   add an empty up/down pair to internal/db/migrations
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"budgex_backend/internal/config"
+	"budgex_backend/internal/db"
+)
+
+func usage() {
+	fmt.Fprintf(os.Stderr, `usage: migrate [-dir DIR] <command>
+
+commands:
+  up            apply every pending migration
+  down N        revert the N most recent migrations
+  status        list migrations and when they were applied
+  create NAME   add an empty up/down pair (written to -dir)
+
+DATABASE_URL selects the database.
+`)
+	flag.PrintDefaults()
+}
+
+func main() {
+	log.SetFlags(0)
+	dir := flag.String("dir", db.MigrationsDir, "migrations directory for create")
+	flag.Usage = usage
+	flag.Parse()
+	args := flag.Args()
+	if len(args) == 0 {
+		usage()
+		os.Exit(2)
+	}
+
+	switch args[0] {
+	case "up", "down", "status", "create":
+	default:
+		usage()
+		os.Exit(2)
+	}
+
+	if args[0] == "create" {
+		if len(args) != 2 {
+			usage()
+			os.Exit(2)
+		}
+		if err := create(*dir, args[1]); err != nil {
+			log.Fatalf("create: %v", err)
+		}
+		return
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("config: %v", err)
+	}
+	gdb := db.Must(db.Connect(cfg.PostgresDSN()))
+	m, err := db.NewMigrator(gdb)
+	if err != nil {
+		log.Fatalf("migrations: %v", err)
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	switch args[0] {
+	case "up":
+		ran, err := m.Up(ctx)
+		for _, mig := range ran {
+			log.Printf("applied  %04d_%s", mig.Version, mig.Name)
+		}
+		if err != nil {
+			log.Fatalf("up: %v", err)
+		}
+		if len(ran) == 0 {
+			log.Print("schema is up to date")
+		}
+	case "down":
+		if len(args) != 2 {
+			usage()
+			os.Exit(2)
+		}
+		n, err := strconv.Atoi(args[1])
+		if err != nil || n < 1 {
+			log.Fatalf("down: N must be a positive number, got %q", args[1])
+		}
+		ran, err := m.Down(ctx, n)
+		for _, mig := range ran {
+			log.Printf("reverted %04d_%s", mig.Version, mig.Name)
+		}
+		if err != nil {
+			log.Fatalf("down: %v", err)
+		}
+	case "status":
+		st, err := m.Status(ctx)
+		if err != nil {
+			log.Fatalf("status: %v", err)
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED")
+		for _, s := range st {
+			applied := "pending"
+			switch {
+			case s.Missing:
+				applied = s.AppliedAt.Format("2006-01-02 15:04:05") + " (no file)"
+			case s.AppliedAt != nil:
+				applied = s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Fprintf(w, "%04d\t%s\t%s\n", s.Version, s.Name, applied)
+		}
+		w.Flush()
+	}
+}
+
+var nonWord = regexp.MustCompile(`[^a-z0-9]+`)
+
+// create writes NNNN_name.up.sql and .down.sql with the next version after
+// the highest one in dir.
+func create(dir, name string) error {
+	name = strings.Trim(nonWord.ReplaceAllString(strings.ToLower(name), "_"), "_")
+	if name == "" {
+		return fmt.Errorf("name must contain letters or digits")
+	}
+	migs, err := db.LoadMigrations(os.DirFS(dir))
+	if err != nil {
+		return err
+	}
+	next := 1
+	if len(migs) > 0 {
+		next = migs[len(migs)-1].Version + 1
+	}
+	base := filepath.Join(dir, fmt.Sprintf("%04d_%s", next, name))
+	for _, f := range []struct{ path, body string }{
+		{base + ".up.sql", "-- " + name + "\n"},
+		{base + ".down.sql", "-- revert " + name + "\n"},
+	} {
+		if err := os.WriteFile(f.path, []byte(f.body), 0o644); err != nil {
+			return err
+		}
+		fmt.Println(f.path)
+	}
+	return nil
+}
@@ -20,12 +20,15 @@ import (
 	"time"
 
 	"budgex_backend/internal/api"
+	"budgex_backend/internal/auth"
 	"budgex_backend/internal/config"
 	"budgex_backend/internal/db"
 	_ "budgex_backend/internal/docs" // generated package
 	"budgex_backend/internal/observability"
+	"budgex_backend/internal/recurring"
+	"budgex_backend/internal/service"
+	"budgex_backend/internal/store/postgres"
 
-	"github.com/clerk/clerk-sdk-go/v2"
 	fiberSwagger "github.com/swaggo/fiber-swagger"
 )
 
@@ -34,6 +37,9 @@ func main() {
 	if err != nil {
 		log.Fatalf("config: %v", err)
 	}
+	if err := cfg.LoadAuth(); err != nil {
+		log.Fatalf("config: %v", err)
+	}
 
 	// Logger
 	_, err = observability.InitLogger(
@@ -53,20 +59,50 @@ func main() {
 	}
 	defer observability.ShutdownTracer(context.Background(), tp)
 
-	// Initialize Clerk SDK
-	clerkSecretKey := os.Getenv("CLERK_SECRET_KEY")
-	if clerkSecretKey == "" {
-		log.Fatal("CLERK_SECRET_KEY environment variable is not set")
+	// Authentication
+	authn, err := auth.New(context.Background(), cfg)
+	if err != nil {
+		log.Fatalf("auth: %v", err)
+	}
+	log.Printf("auth mode: %s", cfg.AuthMode)
+	if cfg.AuthMode == config.AuthStatic {
+		log.Printf("WARNING: AUTH_MODE=%s accepts fixed tokens; use it for development and tests only", config.AuthStatic)
 	}
-	log.Printf("Clerk SDK initialized with secret key: %s...", clerkSecretKey[:10])
-	clerk.SetKey(clerkSecretKey)
 
 	gdb := db.Must(db.Connect(cfg.PostgresDSN()))
-	if err := db.AutoMigrate(gdb); err != nil {
-		log.Fatalf("migrate: %v", err)
+	switch cfg.DBMigrate {
+	case config.MigrateUp:
+		ran, err := db.Migrate(context.Background(), gdb)
+		if err != nil {
+			log.Fatalf("migrate: %v", err)
+		}
+		for _, m := range ran {
+			log.Printf("migrated %d_%s", m.Version, m.Name)
+		}
+	case config.MigrateCheck:
+		if err := db.CheckSchema(context.Background(), gdb); err != nil {
+			log.Fatalf("migrate: %v", err)
+		}
 	}
 
-	app := api.Build(gdb)
+	// Row-level security only holds for an ordinary role and covered tables
+	if warnings, err := db.CheckRLS(context.Background(), gdb); err != nil {
+		log.Printf("rls check: %v", err)
+	} else {
+		for _, w := range warnings {
+			log.Printf("WARNING: %s", w)
+		}
+	}
+
+	// Recurring transactions: catches up on start, then runs periodically
+	workerCtx, stopWorker := context.WithCancel(context.Background())
+	defer stopWorker()
+	go recurring.Run(workerCtx, gdb, cfg.RecurringInterval)
+
+	app, err := api.Build(service.New(postgres.New(gdb)), authn)
+	if err != nil {
+		log.Fatalf("api: %v", err)
+	}
 
 	// Swagger UI (served at /swagger/index.html)
 	app.Get("/swagger/*", fiberSwagger.WrapHandler)
@@ -88,6 +124,7 @@ func main() {
 	// graceful shutdown
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
+	stopWorker()
 	if err := app.Shutdown(); err != nil {
 		log.Printf("shutdown error: %v", err)
 	}
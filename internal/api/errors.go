@@ -0,0 +1,83 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"budgex_backend/internal/apperr"
+	"budgex_backend/internal/fx"
+	"budgex_backend/internal/observability"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// ErrorHandler answers every error returned by a handler or middleware with
+// an application/problem+json body. Errors that are not *apperr.Error are
+// internal: they are logged with the request id and their text is never
+// sent to the client.
+func ErrorHandler(c *fiber.Ctx, err error) error {
+	p := classify(err).Problem()
+	var fe *fiber.Error
+	if errors.As(err, &fe) && apperr.As(err) == nil {
+		// routing and body-size errors from Fiber itself keep their status
+		p.Status, p.Title = fe.Code, http.StatusText(fe.Code)
+		if fe.Code < 500 {
+			p.Detail = fe.Message
+		}
+	}
+	p.Instance = c.Path()
+	p.RequestID, _ = c.Locals("requestid").(string)
+
+	if p.Status >= 500 {
+		uid, _ := c.Locals("user_id").(string)
+		observability.L().Error("request_failed",
+			zap.String("code", p.Code),
+			zap.String("method", c.Method()),
+			zap.String("path", c.OriginalURL()),
+			zap.String("request_id", p.RequestID),
+			zap.String("user_id", uid),
+			zap.Error(err),
+		)
+	}
+	return c.Status(p.Status).JSON(p, apperr.ProblemContentType)
+}
+
+// classify turns any error into an *apperr.Error.
+func classify(err error) *apperr.Error {
+	if e := apperr.As(err); e != nil {
+		return e
+	}
+	var missing *fx.MissingRateError
+	if errors.As(err, &missing) {
+		// the caller can fix this by uploading a rate
+		return apperr.Invalid("fx_rate_missing").
+			WithDetail(fmt.Sprintf("No %s to %s exchange rate covers %s; upload one.",
+				missing.From, missing.To, missing.Date.Format("2006-01-02"))).
+			With("from", missing.From).
+			With("to", missing.To).
+			With("date", missing.Date.Format("2006-01-02"))
+	}
+	if errors.Is(err, gorm.ErrForeignKeyViolated) {
+		// validation checks references first; this is a race with a delete
+		return apperr.Conflict("reference_conflict").
+			WithDetail("A referenced row no longer exists, or this row is still referenced.").
+			Wrap(err)
+	}
+	var fe *fiber.Error
+	if errors.As(err, &fe) {
+		code := strings.ToLower(strings.ReplaceAll(http.StatusText(fe.Code), " ", "_"))
+		if code == "" {
+			code = "http_error"
+		}
+		kind := apperr.KindBadRequest
+		if fe.Code >= 500 {
+			kind = apperr.KindInternal
+		}
+		return apperr.New(kind, code).Wrap(err)
+	}
+	return apperr.Internal(err)
+}
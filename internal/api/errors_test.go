@@ -0,0 +1,139 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"budgex_backend/internal/apperr"
+	"budgex_backend/internal/fx"
+	"budgex_backend/internal/validate"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+type errorsInput struct {
+	Name   string `json:"name" validate:"required"`
+	Amount int    `json:"amount" validate:"gte=1"`
+}
+
+func errorsApp() *fiber.App {
+	app := fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("requestid", "req-1")
+		return c.Next()
+	})
+	app.Get("/not-found", func(c *fiber.Ctx) error { return apperr.NotFound() })
+	app.Get("/invalid-field", func(c *fiber.Ctx) error { return apperr.Field("month", "invalid_month") })
+	app.Get("/invalid", func(c *fiber.Ctx) error { return validate.Struct(errorsInput{}) })
+	app.Get("/conflict", func(c *fiber.Ctx) error {
+		return apperr.Conflict("budget_exists").With("id", "b1")
+	})
+	app.Get("/fk", func(c *fiber.Ctx) error {
+		return fmt.Errorf("create transaction: %w", gorm.ErrForeignKeyViolated)
+	})
+	app.Get("/missing-rate", func(c *fiber.Ctx) error {
+		return fmt.Errorf("convert: %w", &fx.MissingRateError{
+			From: "USD", To: "LKR", Date: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+		})
+	})
+	app.Get("/boom", func(c *fiber.Ctx) error {
+		return errors.New(`pq: relation "secret_table" does not exist`)
+	})
+	return app
+}
+
+func TestErrorHandler(t *testing.T) {
+	app := errorsApp()
+	tests := []struct {
+		name   string
+		path   string
+		status int
+		code   string
+		fields []apperr.FieldError
+		extra  map[string]string
+	}{
+		{"not found", "/not-found", 404, "not_found", nil, nil},
+		{"unknown route", "/nowhere", 404, "not_found", nil, nil},
+		{
+			"invalid field", "/invalid-field", 422, "invalid_month",
+			[]apperr.FieldError{{Field: "month", Code: "invalid_month"}}, nil,
+		},
+		{
+			"validation", "/invalid", 422, "invalid_input",
+			[]apperr.FieldError{{Field: "name", Code: "name_required"}, {Field: "amount", Code: "amount_must_be_at_least_1"}}, nil,
+		},
+		{"conflict", "/conflict", 409, "budget_exists", nil, map[string]string{"id": "b1"}},
+		{"foreign key violation", "/fk", 409, "reference_conflict", nil, nil},
+		{
+			"missing rate", "/missing-rate", 422, "fx_rate_missing", nil,
+			map[string]string{"from": "USD", "to": "LKR", "date": "2024-03-01"},
+		},
+		{"unknown error", "/boom", 500, "internal", nil, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := app.Test(httptest.NewRequest("GET", tt.path, nil))
+			if err != nil {
+				t.Fatal(err)
+			}
+			body, _ := io.ReadAll(res.Body)
+			if res.StatusCode != tt.status {
+				t.Fatalf("status = %d, want %d: %s", res.StatusCode, tt.status, body)
+			}
+			if ct := res.Header.Get("Content-Type"); !strings.HasPrefix(ct, apperr.ProblemContentType) {
+				t.Errorf("Content-Type = %q", ct)
+			}
+			var p struct {
+				apperr.Problem
+				From string `json:"from"`
+				To   string `json:"to"`
+				Date string `json:"date"`
+				ID   string `json:"id"`
+			}
+			if err := json.Unmarshal(body, &p); err != nil {
+				t.Fatal(err)
+			}
+			if p.Type != "about:blank" || p.Status != tt.status || p.Code != tt.code {
+				t.Errorf("problem = %s, want status %d and code %s", body, tt.status, tt.code)
+			}
+			if p.Instance != tt.path || p.RequestID != "req-1" {
+				t.Errorf("instance %q, request id %q", p.Instance, p.RequestID)
+			}
+			if len(p.Errors) != len(tt.fields) {
+				t.Fatalf("errors = %+v, want %+v", p.Errors, tt.fields)
+			}
+			for i, f := range tt.fields {
+				if p.Errors[i].Field != f.Field || p.Errors[i].Code != f.Code {
+					t.Errorf("errors[%d] = %+v, want %s %s", i, p.Errors[i], f.Field, f.Code)
+				}
+			}
+			got := map[string]string{"from": p.From, "to": p.To, "date": p.Date, "id": p.ID}
+			for k, v := range tt.extra {
+				if got[k] != v {
+					t.Errorf("%s = %q, want %q", k, got[k], v)
+				}
+			}
+		})
+	}
+}
+
+func TestErrorHandlerHidesInternalErrors(t *testing.T) {
+	res, err := errorsApp().Test(httptest.NewRequest("GET", "/boom", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(res.Body)
+	if strings.Contains(string(body), "secret_table") {
+		t.Errorf("internal error leaked to the client: %s", body)
+	}
+	if !strings.Contains(string(body), "An unexpected error occurred.") {
+		t.Errorf("body = %s, want the generic detail", body)
+	}
+}
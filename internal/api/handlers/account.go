@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"budgex_backend/internal/apperr"
+	"budgex_backend/internal/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type AccountHandler struct{ Svc *service.Accounts }
+
+func (h AccountHandler) Register(r fiber.Router) {
+	grp := r.Group("/accounts")
+	grp.Get("/", h.List)
+	grp.Post("/", h.Create)
+	grp.Get("/:id", h.Get)
+	grp.Patch("/:id", h.Update)
+	grp.Delete("/:id", h.Delete)
+	grp.Get("/:id/balance", h.Balance)
+	grp.Get("/:id/transactions", h.Transactions)
+}
+
+// List godoc
+// @Summary      List accounts with current balances
+// @Tags         accounts
+// @Security     BearerAuth
+// @Produce      json
+// @Param        include_archived  query  bool  false  "Include archived accounts"
+// @Success      200  {array}  service.AccountWithBalance
+// @Router       /accounts/ [get]
+func (h AccountHandler) List(c *fiber.Ctx) error {
+	out, err := h.Svc.List(c.UserContext(), member(c), c.QueryBool("include_archived"))
+	if err != nil {
+		return err
+	}
+	return c.JSON(out)
+}
+
+// Create godoc
+// @Summary      Create account
+// @Tags         accounts
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        body  body  service.NewAccount  true  "Account"
+// @Success      201   {object} models.Account
+// @Failure      422   {object} apperr.Problem
+// @Router       /accounts/ [post]
+func (h AccountHandler) Create(c *fiber.Ctx) error {
+	var in service.NewAccount
+	if err := c.BodyParser(&in); err != nil {
+		return apperr.BadRequest("bad_json")
+	}
+	acc, err := h.Svc.Create(c.UserContext(), member(c), in)
+	if err != nil {
+		return err
+	}
+	return c.Status(201).JSON(acc)
+}
+
+// Get godoc
+// @Summary      Get account
+// @Tags         accounts
+// @Security     BearerAuth
+// @Produce      json
+// @Param        id   path      string  true  "Account ID"
+// @Success      200  {object}  models.Account
+// @Failure      404  {object}  apperr.Problem
+// @Router       /accounts/{id} [get]
+func (h AccountHandler) Get(c *fiber.Ctx) error {
+	acc, err := h.Svc.Get(c.UserContext(), member(c), c.Params("id"))
+	if err != nil {
+		return err
+	}
+	return c.JSON(acc)
+}
+
+// Update godoc
+// @Summary      Update account (partial); set archived to hide it
+// @Description  Changing the currency relabels the account's transactions; amounts are not converted.
+// @Tags         accounts
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        id    path  string                true  "Account ID"
+// @Param        body  body  service.AccountPatch  true  "Fields to change"
+// @Success      200   {object} models.Account
+// @Failure      404   {object} apperr.Problem
+// @Failure      422   {object} apperr.Problem
+// @Router       /accounts/{id} [patch]
+func (h AccountHandler) Update(c *fiber.Ctx) error {
+	var in service.AccountPatch
+	if err := c.BodyParser(&in); err != nil {
+		return apperr.BadRequest("bad_json")
+	}
+	acc, err := h.Svc.Update(c.UserContext(), member(c), c.Params("id"), in)
+	if err != nil {
+		return err
+	}
+	return c.JSON(acc)
+}
+
+// Delete godoc
+// @Summary      Delete an account without transactions (archive it otherwise)
+// @Tags         accounts
+// @Security     BearerAuth
+// @Param        id   path  string  true  "Account ID"
+// @Success      204
+// @Failure      404  {object}  apperr.Problem
+// @Failure      409  {object}  apperr.Problem
+// @Router       /accounts/{id} [delete]
+func (h AccountHandler) Delete(c *fiber.Ctx) error {
+	if err := h.Svc.Delete(c.UserContext(), member(c), c.Params("id")); err != nil {
+		return err
+	}
+	return c.SendStatus(204)
+}
+
+// Balance godoc
+// @Summary      Account balance at the end of a day
+// @Tags         accounts
+// @Security     BearerAuth
+// @Produce      json
+// @Param        id     path   string  true   "Account ID"
+// @Param        as_of  query  string  false  "YYYY-MM-DD, inclusive (defaults to today)"
+// @Success      200  {object}  service.AccountBalanceResp
+// @Failure      404  {object}  apperr.Problem
+// @Failure      422  {object}  apperr.Problem
+// @Router       /accounts/{id}/balance [get]
+func (h AccountHandler) Balance(c *fiber.Ctx) error {
+	out, err := h.Svc.Balance(c.UserContext(), member(c), c.Params("id"), c.Query("as_of"))
+	if err != nil {
+		return err
+	}
+	return c.JSON(out)
+}
+
+// Transactions godoc
+// @Summary      Account transactions with running balance (newest first)
+// @Tags         accounts
+// @Security     BearerAuth
+// @Produce      json
+// @Param        id     path   string  true   "Account ID"
+// @Param        from   query  string  false  "YYYY-MM-DD, inclusive"
+// @Param        to     query  string  false  "YYYY-MM-DD, inclusive"
+// @Param        limit  query  int     false  "Max items" default(100) maximum(500)
+// @Success      200  {array}   service.AccountTxRow
+// @Failure      404  {object}  apperr.Problem
+// @Failure      422  {object}  apperr.Problem
+// @Router       /accounts/{id}/transactions [get]
+func (h AccountHandler) Transactions(c *fiber.Ctx) error {
+	out, err := h.Svc.Ledger(c.UserContext(), member(c), c.Params("id"), service.LedgerQuery{
+		From: c.Query("from"), To: c.Query("to"), Limit: pageSize(c),
+	})
+	if err != nil {
+		return err
+	}
+	return c.JSON(out)
+}
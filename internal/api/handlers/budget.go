@@ -1,26 +1,21 @@
 package handlers
 
 import (
-	"time"
-
-	"budgex_backend/internal/models"
+	"budgex_backend/internal/apperr"
+	"budgex_backend/internal/service"
 
 	"github.com/gofiber/fiber/v2"
-	"gorm.io/gorm"
 )
 
-type BudgetHandler struct{ DB *gorm.DB }
+type BudgetHandler struct{ Svc *service.Budgets }
 
 func (h BudgetHandler) Register(r fiber.Router) {
 	grp := r.Group("/budgets")
-	grp.Get("/", h.List)    // ?month=YYYY-MM (optional; defaults to current)
-	grp.Post("/", h.Upsert) // set/replace a budget row
-}
-
-type upsertBudgetDTO struct {
-	Month      string  `json:"month"`       // "YYYY-MM"
-	CategoryID string  `json:"category_id"` // required
-	Amount     float64 `json:"amount"`      // required
+	grp.Get("/", h.List)                         // ?month=YYYY-MM (optional; defaults to current)
+	grp.Post("/", h.Upsert)                      // set/replace a budget row
+	grp.Get("/progress", h.Progress)             // ?month=YYYY-MM; budget vs actual
+	grp.Get("/ready_to_assign", h.ReadyToAssign) // ?month=YYYY-MM
+	grp.Put("/rollover", h.SetRollover)          // per-category rollover mode
 }
 
 // List godoc
@@ -32,15 +27,9 @@ type upsertBudgetDTO struct {
 // @Success      200    {array} models.Budget
 // @Router       /budgets/ [get]
 func (h BudgetHandler) List(c *fiber.Ctx) error {
-	month := c.Query("month")
-	if month == "" {
-		now := time.Now().UTC()
-		month = now.Format("2006-01")
-	}
-	var out []models.Budget
-	if err := h.DB.Where("user_id = ? AND month = ? AND deleted_at IS NULL",
-		userID(c), month).Order("category_id").Find(&out).Error; err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	out, err := h.Svc.List(c.UserContext(), member(c), c.Query("month"))
+	if err != nil {
+		return err
 	}
 	return c.JSON(out)
 }
@@ -51,36 +40,80 @@ func (h BudgetHandler) List(c *fiber.Ctx) error {
 // @Security     BearerAuth
 // @Accept       json
 // @Produce      json
-// @Param        body  body  upsertBudgetDTO  true  "Budget"
+// @Param        body  body  service.BudgetInput  true  "Budget"
 // @Success      201   {object} models.Budget
 // @Router       /budgets/ [post]
 func (h BudgetHandler) Upsert(c *fiber.Ctx) error {
-	var in upsertBudgetDTO
+	var in service.BudgetInput
 	if err := c.BodyParser(&in); err != nil {
-		return c.Status(400).JSON(fiber.Map{"error": "bad_json"})
+		return apperr.BadRequest("bad_json")
 	}
-	if in.Month == "" || len(in.Month) != 7 {
-		return c.Status(422).JSON(fiber.Map{"error": "month_format_YYYY-MM"})
+	row, err := h.Svc.Upsert(c.UserContext(), member(c), in)
+	if err != nil {
+		return err
+	}
+	return c.Status(201).JSON(row)
+}
+
+// SetRollover godoc
+// @Summary      Turn budget rollover on or off for a category
+// @Description  With rollover, a month's leftover (or overspend) carries into the next month's available amount.
+// @Tags         budgets
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        body  body  service.Rollover  true  "Rollover mode"
+// @Success      200   {object} models.Category
+// @Failure      404   {object} apperr.Problem
+// @Router       /budgets/rollover [put]
+func (h BudgetHandler) SetRollover(c *fiber.Ctx) error {
+	var in service.Rollover
+	if err := c.BodyParser(&in); err != nil {
+		return apperr.BadRequest("bad_json")
 	}
-	if in.CategoryID == "" || in.Amount < 0 {
-		return c.Status(422).JSON(fiber.Map{"error": "category_id_and_amount_required"})
+	cat, err := h.Svc.SetRollover(c.UserContext(), member(c), in)
+	if err != nil {
+		return err
 	}
+	return c.JSON(cat)
+}
 
-	row := models.Budget{
-		Base:       models.Base{UserID: userID(c)},
-		Month:      in.Month,
-		CategoryID: in.CategoryID,
-		Amount:     in.Amount,
+// Progress godoc
+// @Summary      Budget vs actual for a month
+// @Description  Spend in a subcategory counts towards the nearest budgeted category above it.
+// @Description  Spend with no budgeted category on its path is reported under its top-level category.
+// @Description  Rollover categories add what was left (or overspent) in earlier months to available.
+// @Description  Amounts are in the base currency; spend in other currencies is converted at its day's rate.
+// @Description  The month runs from the user's month_start_day in their timezone.
+// @Tags         budgets
+// @Security     BearerAuth
+// @Produce      json
+// @Param        month  query  string  false  "YYYY-MM (defaults current)"
+// @Success      200    {object} service.BudgetProgressResp
+// @Failure      422    {object} apperr.Problem  "invalid_month or fx_rate_missing"
+// @Router       /budgets/progress [get]
+func (h BudgetHandler) Progress(c *fiber.Ctx) error {
+	out, err := h.Svc.Progress(c.UserContext(), member(c), c.Query("month"))
+	if err != nil {
+		return err
 	}
-	// Unique on (user_id, month, category_id) effectively
-	// GORM upsert with ON CONFLICT
-	if err := h.DB.
-		Clauses(
-			// import clause: "gorm.io/gorm/clause"
-			clauseOnConflictSetAmount(),
-		).
-		Create(&row).Error; err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	return c.JSON(out)
+}
+
+// ReadyToAssign godoc
+// @Summary      Money not yet assigned to a budget
+// @Description  Income received up to the end of month minus all budget amounts up to and including month.
+// @Tags         budgets
+// @Security     BearerAuth
+// @Produce      json
+// @Param        month  query  string  false  "YYYY-MM (defaults current)"
+// @Success      200    {object} service.ReadyToAssignResp
+// @Failure      422    {object} apperr.Problem  "invalid_month or fx_rate_missing"
+// @Router       /budgets/ready_to_assign [get]
+func (h BudgetHandler) ReadyToAssign(c *fiber.Ctx) error {
+	out, err := h.Svc.ReadyToAssign(c.UserContext(), member(c), c.Query("month"))
+	if err != nil {
+		return err
 	}
-	return c.Status(201).JSON(row)
+	return c.JSON(out)
 }
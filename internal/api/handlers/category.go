@@ -1,23 +1,38 @@
 package handlers
 
 import (
-	"budgex_backend/internal/models"
+	"budgex_backend/internal/apperr"
+	"budgex_backend/internal/service"
+	"budgex_backend/internal/validate"
 
 	"github.com/gofiber/fiber/v2"
-	"gorm.io/gorm"
 )
 
-type CategoryHandler struct{ DB *gorm.DB }
+type CategoryHandler struct{ Svc *service.Categories }
 
 func (h CategoryHandler) Register(r fiber.Router) {
 	grp := r.Group("/categories")
 	grp.Get("/", h.List)
 	grp.Post("/", h.Create)
+	grp.Get("/tree", h.Tree)
+	grp.Post("/seed", h.Seed) // add missing default categories
+	grp.Patch("/:id", h.Update)
+	grp.Delete("/:id", h.Delete) // ?replacement_id=<uuid> moves transactions first
+	grp.Post("/:id/archive", h.Archive)
+	grp.Post("/:id/unarchive", h.Unarchive)
+	grp.Post("/:id/merge", h.Merge)
 }
 
-type createCategoryDTO struct {
-	Name     string  `json:"name"`
-	ParentID *string `json:"parent_id"`
+type seedCategoriesDTO struct {
+	Locale string `json:"locale"` // en | de | fr | es; defaults to Accept-Language
+}
+
+type mergeCategoryDTO struct {
+	TargetID string `json:"target_id" validate:"required,uuid"`
+}
+
+func init() {
+	validate.Register(mergeCategoryDTO{})
 }
 
 // List godoc
@@ -25,13 +40,13 @@ type createCategoryDTO struct {
 // @Tags         categories
 // @Security     BearerAuth
 // @Produce      json
+// @Param        include_archived  query  bool  false  "Include archived categories"
 // @Success      200  {array}  models.Category
 // @Router       /categories/ [get]
 func (h CategoryHandler) List(c *fiber.Ctx) error {
-	var out []models.Category
-	if err := h.DB.Where("user_id = ? AND deleted_at IS NULL", userID(c)).
-		Order("name ASC").Find(&out).Error; err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	out, err := h.Svc.List(c.UserContext(), member(c), c.QueryBool("include_archived"))
+	if err != nil {
+		return err
 	}
 	return c.JSON(out)
 }
@@ -42,21 +57,167 @@ func (h CategoryHandler) List(c *fiber.Ctx) error {
 // @Security     BearerAuth
 // @Accept       json
 // @Produce      json
-// @Param        body  body  createCategoryDTO  true  "Category"
+// @Param        body  body  service.NewCategory  true  "Category"
 // @Success      201   {object} models.Category
 // @Router       /categories/ [post]
 func (h CategoryHandler) Create(c *fiber.Ctx) error {
-	var in createCategoryDTO
-	if err := c.BodyParser(&in); err != nil || in.Name == "" {
-		return c.Status(422).JSON(fiber.Map{"error": "name_required"})
+	var in service.NewCategory
+	if err := c.BodyParser(&in); err != nil {
+		return apperr.BadRequest("bad_json")
 	}
-	cat := models.Category{
-		Base:     models.Base{UserID: userID(c)},
-		Name:     in.Name,
-		ParentID: in.ParentID,
-	}
-	if err := h.DB.Create(&cat).Error; err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	cat, err := h.Svc.Create(c.UserContext(), member(c), in)
+	if err != nil {
+		return err
 	}
 	return c.Status(201).JSON(cat)
 }
+
+// Tree godoc
+// @Summary      Category hierarchy as nested JSON
+// @Description  Categories whose parent is missing are listed at the top level.
+// @Tags         categories
+// @Security     BearerAuth
+// @Produce      json
+// @Param        include_archived  query  bool  false  "Include archived categories"
+// @Success      200  {array}  service.CategoryNode
+// @Router       /categories/tree [get]
+func (h CategoryHandler) Tree(c *fiber.Ctx) error {
+	out, err := h.Svc.Tree(c.UserContext(), member(c), c.QueryBool("include_archived"))
+	if err != nil {
+		return err
+	}
+	return c.JSON(out)
+}
+
+// Seed godoc
+// @Summary      Add the default categories
+// @Description  Creates the default category tree for the locale, skipping every name the household already has
+// @Description  (case-insensitive); existing categories with a default name are reused as parents.
+// @Tags         categories
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        body  body  seedCategoriesDTO  false  "Locale"
+// @Success      200   {object} seed.Result
+// @Router       /categories/seed [post]
+func (h CategoryHandler) Seed(c *fiber.Ctx) error {
+	var in seedCategoriesDTO
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&in); err != nil {
+			return apperr.BadRequest("bad_json")
+		}
+	}
+	locale := in.Locale
+	if locale == "" {
+		locale = c.Get(fiber.HeaderAcceptLanguage)
+	}
+	out, err := h.Svc.Seed(c.UserContext(), member(c), locale)
+	if err != nil {
+		return err
+	}
+	return c.JSON(out)
+}
+
+// Update godoc
+// @Summary      Rename or move a category
+// @Tags         categories
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        id    path  string                 true  "Category ID"
+// @Param        body  body  service.CategoryPatch  true  "Fields to change"
+// @Success      200   {object} models.Category
+// @Failure      404   {object} apperr.Problem
+// @Failure      422   {object} apperr.Problem
+// @Router       /categories/{id} [patch]
+func (h CategoryHandler) Update(c *fiber.Ctx) error {
+	var in service.CategoryPatch
+	if err := c.BodyParser(&in); err != nil {
+		return apperr.BadRequest("bad_json")
+	}
+	cat, err := h.Svc.Update(c.UserContext(), member(c), c.Params("id"), in)
+	if err != nil {
+		return err
+	}
+	return c.JSON(cat)
+}
+
+func (h CategoryHandler) setArchived(c *fiber.Ctx, archived bool) error {
+	cat, err := h.Svc.SetArchived(c.UserContext(), member(c), c.Params("id"), archived)
+	if err != nil {
+		return err
+	}
+	return c.JSON(cat)
+}
+
+// Archive godoc
+// @Summary      Archive category
+// @Description  Archived categories are hidden from lists; their transactions and budgets are kept.
+// @Tags         categories
+// @Security     BearerAuth
+// @Produce      json
+// @Param        id   path  string  true  "Category ID"
+// @Success      200  {object}  models.Category
+// @Failure      404  {object}  apperr.Problem
+// @Router       /categories/{id}/archive [post]
+func (h CategoryHandler) Archive(c *fiber.Ctx) error { return h.setArchived(c, true) }
+
+// Unarchive godoc
+// @Summary      Unarchive category
+// @Tags         categories
+// @Security     BearerAuth
+// @Produce      json
+// @Param        id   path  string  true  "Category ID"
+// @Success      200  {object}  models.Category
+// @Failure      404  {object}  apperr.Problem
+// @Router       /categories/{id}/unarchive [post]
+func (h CategoryHandler) Unarchive(c *fiber.Ctx) error { return h.setArchived(c, false) }
+
+// Delete godoc
+// @Summary      Delete category
+// @Description  Refused with 409 while transactions use the category, unless replacement_id is given:
+// @Description  then its transactions, budgets and rules move to the replacement first.
+// @Description  Subcategories move up to the deleted category's parent.
+// @Tags         categories
+// @Security     BearerAuth
+// @Param        id              path   string  true   "Category ID"
+// @Param        replacement_id  query  string  false  "Category receiving the transactions"
+// @Success      204
+// @Failure      404  {object}  apperr.Problem
+// @Failure      409  {object}  apperr.Problem
+// @Router       /categories/{id} [delete]
+func (h CategoryHandler) Delete(c *fiber.Ctx) error {
+	if err := h.Svc.Delete(c.UserContext(), member(c), c.Params("id"), c.Query("replacement_id")); err != nil {
+		return err
+	}
+	return c.SendStatus(204)
+}
+
+// Merge godoc
+// @Summary      Merge a category into another
+// @Description  Moves transactions, split lines, budgets (amounts of the same month are added up),
+// @Description  recurring rules and subcategories to the target, then deletes the category.
+// @Tags         categories
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        id    path  string            true  "Category to merge away"
+// @Param        body  body  mergeCategoryDTO  true  "Target category"
+// @Success      200   {object} models.Category
+// @Failure      404   {object} apperr.Problem
+// @Failure      422   {object} apperr.Problem
+// @Router       /categories/{id}/merge [post]
+func (h CategoryHandler) Merge(c *fiber.Ctx) error {
+	var in mergeCategoryDTO
+	if err := c.BodyParser(&in); err != nil {
+		return apperr.BadRequest("bad_json")
+	}
+	if err := validate.Struct(in); err != nil {
+		return err
+	}
+	target, err := h.Svc.Merge(c.UserContext(), member(c), c.Params("id"), in.TargetID)
+	if err != nil {
+		return err
+	}
+	return c.JSON(target)
+}
@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"io"
+
+	"budgex_backend/internal/apperr"
+	"budgex_backend/internal/fx"
+	"budgex_backend/internal/models"
+	"budgex_backend/internal/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type FxHandler struct{ Svc *service.Rates }
+
+func (h FxHandler) Register(r fiber.Router) {
+	grp := r.Group("/fx_rates")
+	grp.Get("/", h.List)
+	grp.Post("/csv", h.UploadCSV)
+	grp.Post("/ecb", h.UploadECB)
+}
+
+// List godoc
+// @Summary      List uploaded exchange rates (newest first)
+// @Tags         fx
+// @Security     BearerAuth
+// @Produce      json
+// @Param        base   query  string  false  "Base currency"
+// @Param        quote  query  string  false  "Quote currency"
+// @Param        from   query  string  false  "YYYY-MM-DD, inclusive"
+// @Param        to     query  string  false  "YYYY-MM-DD, inclusive"
+// @Param        limit  query  int     false  "Max items" default(100) maximum(500)
+// @Success      200  {array}   models.FxRate
+// @Failure      422  {object}  apperr.Problem
+// @Router       /fx_rates/ [get]
+func (h FxHandler) List(c *fiber.Ctx) error {
+	out, err := h.Svc.List(c.UserContext(), member(c), service.RateQuery{
+		Base: c.Query("base"), Quote: c.Query("quote"),
+		From: c.Query("from"), To: c.Query("to"),
+		Limit: pageSize(c),
+	})
+	if err != nil {
+		return err
+	}
+	return c.JSON(out)
+}
+
+// UploadCSV godoc
+// @Summary      Upload exchange rates from CSV
+// @Description  Header row with date (YYYY-MM-DD), base, quote and rate columns; 1 base = rate quote.
+// @Description  Rates for a day and pair that already exist are replaced.
+// @Tags         fx
+// @Security     BearerAuth
+// @Accept       multipart/form-data
+// @Produce      json
+// @Param        file  formData  file  true  "CSV file"
+// @Success      201  {object}  service.RateUploadResp
+// @Failure      400  {object}  apperr.Problem
+// @Failure      422  {object}  apperr.Problem
+// @Router       /fx_rates/csv [post]
+func (h FxHandler) UploadCSV(c *fiber.Ctx) error {
+	return h.upload(c, fx.SourceCSV, fx.ParseCSV)
+}
+
+// UploadECB godoc
+// @Summary      Upload ECB euro reference rates
+// @Description  Accepts the ECB eurofxref XML files (daily, last 90 days or full history); all rates are EUR based.
+// @Tags         fx
+// @Security     BearerAuth
+// @Accept       multipart/form-data
+// @Produce      json
+// @Param        file  formData  file  true  "eurofxref XML file"
+// @Success      201  {object}  service.RateUploadResp
+// @Failure      400  {object}  apperr.Problem
+// @Failure      422  {object}  apperr.Problem
+// @Router       /fx_rates/ecb [post]
+func (h FxHandler) UploadECB(c *fiber.Ctx) error {
+	return h.upload(c, fx.SourceECB, fx.ParseECB)
+}
+
+func (h FxHandler) upload(c *fiber.Ctx, source string, parse func(io.Reader) ([]models.FxRate, error)) error {
+	_, body, err := formFile(c, "file")
+	if err != nil {
+		return apperr.BadRequest("file_required")
+	}
+	defer body.Close()
+
+	rates, err := parse(body)
+	if err != nil {
+		return apperr.Field("file", "unreadable_file").WithDetail(err.Error())
+	}
+	out, err := h.Svc.Save(c.UserContext(), member(c), source, rates)
+	if err != nil {
+		return err
+	}
+	return c.Status(201).JSON(out)
+}
@@ -1,13 +1,12 @@
 package handlers
 
 import (
+	"budgex_backend/internal/service"
+
 	"github.com/gofiber/fiber/v2"
-	"gorm.io/gorm"
 )
 
-type HealthHandler struct {
-	DB *gorm.DB
-}
+type HealthHandler struct{ Svc *service.Health }
 
 func (h HealthHandler) Register(r fiber.Router) {
 	r.Get("/healthz", h.health)
@@ -17,17 +16,12 @@ func (h HealthHandler) Register(r fiber.Router) {
 // @Summary  Health check
 // @Tags     health
 // @Success  200  {object}  map[string]any
+// @Failure  503  {object}  apperr.Problem
 // @Router   /healthz [get]
 func (h HealthHandler) health(c *fiber.Ctx) error {
-	// Basic DB ping; if DB is down, still return 503 instead of panicking
-	if h.DB != nil {
-		if err := h.DB.Exec("SELECT 1").Error; err != nil {
-			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
-				"ok":     false,
-				"error":  "db_unavailable",
-				"detail": err.Error(),
-			})
-		}
+	// If the database is down, still answer: 503 instead of panicking.
+	if err := h.Svc.Check(c.UserContext()); err != nil {
+		return err
 	}
 	return c.JSON(fiber.Map{"ok": true, "service": "budgex", "version": "0.1.0"})
 }
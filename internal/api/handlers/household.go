@@ -0,0 +1,272 @@
+package handlers
+
+import (
+	"budgex_backend/internal/apperr"
+	"budgex_backend/internal/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type HouseholdHandler struct{ Svc *service.Households }
+
+func (h HouseholdHandler) Register(r fiber.Router) {
+	grp := r.Group("/households")
+	grp.Get("/", h.List)
+	grp.Post("/", h.Create)
+	grp.Post("/join", h.Join)
+	// "current" is the household named by X-Household-ID, else the first one
+	grp.Get("/current", h.Get)
+	grp.Patch("/current", h.Update)
+	grp.Delete("/current", h.Delete)
+	grp.Get("/current/members", h.Members)
+	grp.Patch("/current/members/:user_id", h.SetRole)
+	grp.Delete("/current/members/:user_id", h.RemoveMember)
+	grp.Get("/current/invites", h.Invites)
+	grp.Post("/current/invites", h.CreateInvite)
+	grp.Delete("/current/invites/:id", h.RevokeInvite)
+}
+
+// List godoc
+// @Summary      List the caller's households
+// @Description  Each household carries the caller's role in it, in the order they joined.
+// @Tags         households
+// @Security     BearerAuth
+// @Produce      json
+// @Success      200  {array}  models.Household
+// @Router       /households/ [get]
+func (h HouseholdHandler) List(c *fiber.Ctx) error {
+	out, err := h.Svc.List(c.UserContext(), userID(c))
+	if err != nil {
+		return err
+	}
+	return c.JSON(out)
+}
+
+// Create godoc
+// @Summary      Create household
+// @Description  The caller becomes its owner. It starts with the default categories of the Accept-Language locale.
+// @Tags         households
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        body  body  service.NewHousehold  true  "Household"
+// @Success      201   {object} models.Household
+// @Failure      422   {object} apperr.Problem
+// @Router       /households/ [post]
+func (h HouseholdHandler) Create(c *fiber.Ctx) error {
+	var in service.NewHousehold
+	if err := c.BodyParser(&in); err != nil {
+		return apperr.BadRequest("bad_json")
+	}
+	out, err := h.Svc.Create(c.UserContext(), userID(c), in, c.Get(fiber.HeaderAcceptLanguage))
+	if err != nil {
+		return err
+	}
+	return c.Status(201).JSON(out)
+}
+
+// Join godoc
+// @Summary      Join a household with an invite code
+// @Description  Codes are single-use and expire after seven days; case, spaces and dashes are ignored.
+// @Tags         households
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        body  body  service.JoinHousehold  true  "Invite code"
+// @Success      200   {object} models.Household
+// @Failure      404   {object} apperr.Problem  "invite_not_found"
+// @Failure      409   {object} apperr.Problem  "invite_used, invite_expired or already_member"
+// @Router       /households/join [post]
+func (h HouseholdHandler) Join(c *fiber.Ctx) error {
+	var in service.JoinHousehold
+	if err := c.BodyParser(&in); err != nil {
+		return apperr.BadRequest("bad_json")
+	}
+	out, err := h.Svc.Join(c.UserContext(), userID(c), in)
+	if err != nil {
+		return err
+	}
+	return c.JSON(out)
+}
+
+// Get godoc
+// @Summary      Current household
+// @Tags         households
+// @Security     BearerAuth
+// @Produce      json
+// @Param        X-Household-ID  header  string  false  "Household ID (defaults to the first one joined)"
+// @Success      200  {object}  models.Household
+// @Failure      404  {object}  apperr.Problem
+// @Router       /households/current [get]
+func (h HouseholdHandler) Get(c *fiber.Ctx) error {
+	out, err := h.Svc.Get(c.UserContext(), member(c))
+	if err != nil {
+		return err
+	}
+	return c.JSON(out)
+}
+
+// Update godoc
+// @Summary      Rename the current household
+// @Description  Owners only.
+// @Tags         households
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        X-Household-ID  header  string  false  "Household ID (defaults to the first one joined)"
+// @Param        body  body  service.HouseholdPatch  true  "Fields to change"
+// @Success      200   {object} models.Household
+// @Failure      403   {object} apperr.Problem
+// @Failure      422   {object} apperr.Problem
+// @Router       /households/current [patch]
+func (h HouseholdHandler) Update(c *fiber.Ctx) error {
+	var in service.HouseholdPatch
+	if err := c.BodyParser(&in); err != nil {
+		return apperr.BadRequest("bad_json")
+	}
+	out, err := h.Svc.Update(c.UserContext(), member(c), in)
+	if err != nil {
+		return err
+	}
+	return c.JSON(out)
+}
+
+// Delete godoc
+// @Summary      Delete the current household
+// @Description  Owners only. Every membership and open invite ends; members without another household
+// @Description  get a new personal one on their next request.
+// @Tags         households
+// @Security     BearerAuth
+// @Param        X-Household-ID  header  string  false  "Household ID (defaults to the first one joined)"
+// @Success      204
+// @Failure      403  {object}  apperr.Problem
+// @Router       /households/current [delete]
+func (h HouseholdHandler) Delete(c *fiber.Ctx) error {
+	if err := h.Svc.Delete(c.UserContext(), member(c)); err != nil {
+		return err
+	}
+	return c.SendStatus(204)
+}
+
+// Members godoc
+// @Summary      Members of the current household
+// @Tags         households
+// @Security     BearerAuth
+// @Produce      json
+// @Param        X-Household-ID  header  string  false  "Household ID (defaults to the first one joined)"
+// @Success      200  {array}  models.HouseholdMember
+// @Router       /households/current/members [get]
+func (h HouseholdHandler) Members(c *fiber.Ctx) error {
+	out, err := h.Svc.Members(c.UserContext(), member(c))
+	if err != nil {
+		return err
+	}
+	return c.JSON(out)
+}
+
+// SetRole godoc
+// @Summary      Change a member's role
+// @Description  Owners only. The last owner cannot be demoted.
+// @Tags         households
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        X-Household-ID  header  string  false  "Household ID (defaults to the first one joined)"
+// @Param        user_id  path  string              true  "Member's user ID"
+// @Param        body     body  service.MemberRole  true  "Role"
+// @Success      200   {object} models.HouseholdMember
+// @Failure      403   {object} apperr.Problem
+// @Failure      404   {object} apperr.Problem
+// @Failure      409   {object} apperr.Problem  "last_owner"
+// @Router       /households/current/members/{user_id} [patch]
+func (h HouseholdHandler) SetRole(c *fiber.Ctx) error {
+	var in service.MemberRole
+	if err := c.BodyParser(&in); err != nil {
+		return apperr.BadRequest("bad_json")
+	}
+	out, err := h.Svc.SetRole(c.UserContext(), member(c), c.Params("user_id"), in)
+	if err != nil {
+		return err
+	}
+	return c.JSON(out)
+}
+
+// RemoveMember godoc
+// @Summary      Remove a member, or leave the household
+// @Description  Owners remove anyone; every member may remove themselves. The last owner cannot leave.
+// @Tags         households
+// @Security     BearerAuth
+// @Param        X-Household-ID  header  string  false  "Household ID (defaults to the first one joined)"
+// @Param        user_id  path  string  true  "Member's user ID"
+// @Success      204
+// @Failure      403  {object}  apperr.Problem
+// @Failure      404  {object}  apperr.Problem
+// @Failure      409  {object}  apperr.Problem  "last_owner"
+// @Router       /households/current/members/{user_id} [delete]
+func (h HouseholdHandler) RemoveMember(c *fiber.Ctx) error {
+	if err := h.Svc.RemoveMember(c.UserContext(), member(c), c.Params("user_id")); err != nil {
+		return err
+	}
+	return c.SendStatus(204)
+}
+
+// Invites godoc
+// @Summary      Open invites of the current household
+// @Description  Owners only.
+// @Tags         households
+// @Security     BearerAuth
+// @Produce      json
+// @Param        X-Household-ID  header  string  false  "Household ID (defaults to the first one joined)"
+// @Success      200  {array}  models.HouseholdInvite
+// @Failure      403  {object}  apperr.Problem
+// @Router       /households/current/invites [get]
+func (h HouseholdHandler) Invites(c *fiber.Ctx) error {
+	out, err := h.Svc.Invites(c.UserContext(), member(c))
+	if err != nil {
+		return err
+	}
+	return c.JSON(out)
+}
+
+// CreateInvite godoc
+// @Summary      Invite someone to the current household
+// @Description  Owners only. Returns a single-use code, valid for seven days, that joins with the given role.
+// @Tags         households
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        X-Household-ID  header  string  false  "Household ID (defaults to the first one joined)"
+// @Param        body  body  service.NewInvite  true  "Role (editor or viewer)"
+// @Success      201   {object} models.HouseholdInvite
+// @Failure      403   {object} apperr.Problem
+// @Failure      422   {object} apperr.Problem
+// @Router       /households/current/invites [post]
+func (h HouseholdHandler) CreateInvite(c *fiber.Ctx) error {
+	var in service.NewInvite
+	if err := c.BodyParser(&in); err != nil {
+		return apperr.BadRequest("bad_json")
+	}
+	out, err := h.Svc.CreateInvite(c.UserContext(), member(c), in)
+	if err != nil {
+		return err
+	}
+	return c.Status(201).JSON(out)
+}
+
+// RevokeInvite godoc
+// @Summary      Revoke an open invite
+// @Description  Owners only.
+// @Tags         households
+// @Security     BearerAuth
+// @Param        X-Household-ID  header  string  false  "Household ID (defaults to the first one joined)"
+// @Param        id   path  string  true  "Invite ID"
+// @Success      204
+// @Failure      403  {object}  apperr.Problem
+// @Failure      404  {object}  apperr.Problem
+// @Router       /households/current/invites/{id} [delete]
+func (h HouseholdHandler) RevokeInvite(c *fiber.Ctx) error {
+	if err := h.Svc.RevokeInvite(c.UserContext(), member(c), c.Params("id")); err != nil {
+		return err
+	}
+	return c.SendStatus(204)
+}
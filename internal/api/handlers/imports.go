@@ -0,0 +1,207 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+
+	"budgex_backend/internal/apperr"
+	"budgex_backend/internal/imports"
+	"budgex_backend/internal/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type ImportHandler struct{ Svc *service.Imports }
+
+func (h ImportHandler) Register(r fiber.Router) {
+	grp := r.Group("/imports")
+	grp.Get("/", h.List)
+	grp.Post("/csv", h.PreviewCSV)
+	grp.Post("/ofx", h.PreviewOFX)
+	grp.Post("/qif", h.PreviewQIF)
+	grp.Get("/:id", h.Get)
+	grp.Post("/:id/commit", h.Commit)
+	grp.Post("/:id/rollback", h.Rollback)
+}
+
+// List godoc
+// @Summary      List import batches
+// @Tags         imports
+// @Security     BearerAuth
+// @Produce      json
+// @Success      200  {array}  models.ImportBatch
+// @Router       /imports/ [get]
+func (h ImportHandler) List(c *fiber.Ctx) error {
+	out, err := h.Svc.List(c.UserContext(), member(c))
+	if err != nil {
+		return err
+	}
+	return c.JSON(out)
+}
+
+// PreviewCSV godoc
+// @Summary      Parse a CSV statement into a preview batch (dry run)
+// @Description  Nothing is written to transactions until the batch is committed.
+// @Tags         imports
+// @Security     BearerAuth
+// @Accept       multipart/form-data
+// @Produce      json
+// @Param        file     formData  file    true  "CSV statement"
+// @Param        mapping  formData  string  true  "imports.CSVMapping as JSON"
+// @Param        account_id  formData  string  false  "Account the statement belongs to"
+// @Success      201  {object}  service.ImportPreview
+// @Failure      400  {object}  apperr.Problem
+// @Failure      422  {object}  apperr.Problem
+// @Router       /imports/csv [post]
+func (h ImportHandler) PreviewCSV(c *fiber.Ctx) error {
+	var m imports.CSVMapping
+	if err := json.Unmarshal([]byte(c.FormValue("mapping")), &m); err != nil {
+		return apperr.BadRequest("bad_mapping_json")
+	}
+	if err := m.Validate(); err != nil {
+		return apperr.Field("mapping", "bad_mapping").WithDetail(err.Error())
+	}
+	name, body, err := formFile(c, "file")
+	if err != nil {
+		return apperr.BadRequest("file_required")
+	}
+	defer body.Close()
+
+	rows, err := imports.ParseCSV(body, m)
+	if err != nil {
+		return apperr.Field("file", "unreadable_file").WithDetail(err.Error())
+	}
+	return h.savePreview(c, imports.SourceCSV, name, rows)
+}
+
+// PreviewOFX godoc
+// @Summary      Parse an OFX/QFX statement into a preview batch (dry run)
+// @Description  Rows whose FITID was already imported are flagged as duplicates and skipped on commit.
+// @Tags         imports
+// @Security     BearerAuth
+// @Accept       multipart/form-data
+// @Produce      json
+// @Param        file  formData  file  true  "OFX 1.x/2.x or QFX statement"
+// @Param        account_id  formData  string  false  "Account the statement belongs to"
+// @Success      201  {object}  service.ImportPreview
+// @Failure      400  {object}  apperr.Problem
+// @Failure      422  {object}  apperr.Problem
+// @Router       /imports/ofx [post]
+func (h ImportHandler) PreviewOFX(c *fiber.Ctx) error {
+	name, body, err := formFile(c, "file")
+	if err != nil {
+		return apperr.BadRequest("file_required")
+	}
+	defer body.Close()
+
+	rows, err := imports.ParseOFX(body)
+	if err != nil {
+		return apperr.Field("file", "unreadable_file").WithDetail(err.Error())
+	}
+	return h.savePreview(c, imports.SourceOFX, name, rows)
+}
+
+// PreviewQIF godoc
+// @Summary      Parse a QIF export into a preview batch (dry run)
+// @Tags         imports
+// @Security     BearerAuth
+// @Accept       multipart/form-data
+// @Produce      json
+// @Param        file         formData  file    true   "QIF file"
+// @Param        date_format  formData  string  false  "Date pattern used by the file (default MM/DD/YYYY)"
+// @Param        account_id   formData  string  false  "Account the statement belongs to"
+// @Success      201  {object}  service.ImportPreview
+// @Failure      400  {object}  apperr.Problem
+// @Failure      422  {object}  apperr.Problem
+// @Router       /imports/qif [post]
+func (h ImportHandler) PreviewQIF(c *fiber.Ctx) error {
+	name, body, err := formFile(c, "file")
+	if err != nil {
+		return apperr.BadRequest("file_required")
+	}
+	defer body.Close()
+
+	rows, err := imports.ParseQIF(body, c.FormValue("date_format"))
+	if err != nil {
+		return apperr.Field("file", "unreadable_file").WithDetail(err.Error())
+	}
+	return h.savePreview(c, imports.SourceQIF, name, rows)
+}
+
+// savePreview stores parsed rows as a new batch in preview state.
+func (h ImportHandler) savePreview(c *fiber.Ctx, source, filename string, rows []imports.Row) error {
+	out, err := h.Svc.Preview(c.UserContext(), member(c), source, filename, c.FormValue("account_id"), rows)
+	if err != nil {
+		return err
+	}
+	return c.Status(201).JSON(out)
+}
+
+// Get godoc
+// @Summary      Get an import batch with its preview rows
+// @Tags         imports
+// @Security     BearerAuth
+// @Produce      json
+// @Param        id   path      string  true  "Batch ID"
+// @Success      200  {object}  service.ImportPreview
+// @Failure      404  {object}  apperr.Problem
+// @Router       /imports/{id} [get]
+func (h ImportHandler) Get(c *fiber.Ctx) error {
+	out, err := h.Svc.Get(c.UserContext(), member(c), c.Params("id"))
+	if err != nil {
+		return err
+	}
+	return c.JSON(out)
+}
+
+// Commit godoc
+// @Summary      Commit a previewed batch
+// @Description  Creates one transaction per valid preview row; rows with errors and duplicates are skipped.
+// @Description  The current household must be the one the batch was previewed in.
+// @Tags         imports
+// @Security     BearerAuth
+// @Produce      json
+// @Param        id   path      string  true  "Batch ID"
+// @Success      200  {object}  models.ImportBatch
+// @Failure      404  {object}  apperr.Problem
+// @Failure      409  {object}  apperr.Problem
+// @Router       /imports/{id}/commit [post]
+func (h ImportHandler) Commit(c *fiber.Ctx) error {
+	batch, err := h.Svc.Commit(c.UserContext(), member(c), c.Params("id"))
+	if err != nil {
+		return err
+	}
+	return c.JSON(batch)
+}
+
+// Rollback godoc
+// @Summary      Roll back a committed batch
+// @Description  Soft-deletes every transaction created by the batch.
+// @Tags         imports
+// @Security     BearerAuth
+// @Produce      json
+// @Param        id   path      string  true  "Batch ID"
+// @Success      200  {object}  models.ImportBatch
+// @Failure      404  {object}  apperr.Problem
+// @Failure      409  {object}  apperr.Problem
+// @Router       /imports/{id}/rollback [post]
+func (h ImportHandler) Rollback(c *fiber.Ctx) error {
+	batch, err := h.Svc.Rollback(c.UserContext(), member(c), c.Params("id"))
+	if err != nil {
+		return err
+	}
+	return c.JSON(batch)
+}
+
+// formFile opens an uploaded multipart file.
+func formFile(c *fiber.Ctx, key string) (string, io.ReadCloser, error) {
+	fh, err := c.FormFile(key)
+	if err != nil {
+		return "", nil, err
+	}
+	f, err := fh.Open()
+	if err != nil {
+		return "", nil, err
+	}
+	return fh.Filename, f, nil
+}
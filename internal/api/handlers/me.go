@@ -1,15 +1,18 @@
 package handlers
 
 import (
-	"fmt"
+	"budgex_backend/internal/apperr"
+	"budgex_backend/internal/service"
 
 	"github.com/gofiber/fiber/v2"
 )
 
-type MeHandler struct{}
+type MeHandler struct{ Svc *service.Settings }
 
 func (h MeHandler) Register(r fiber.Router) {
 	r.Get("/me", h.Me)
+	r.Get("/me/settings", h.Settings)
+	r.Put("/me/settings", h.UpdateSettings)
 }
 
 // Me godoc
@@ -17,13 +20,53 @@ func (h MeHandler) Register(r fiber.Router) {
 // @Tags         auth
 // @Security     BearerAuth
 // @Success      200  {object}  map[string]string  "user_id"
-// @Failure      401  {object}  map[string]string  "unauthorized"
+// @Failure      401  {object}  apperr.Problem  "unauthorized"
 // @Router       /me [get]
 func (h MeHandler) Me(c *fiber.Ctx) error {
-	uid, _ := c.Locals("user_id").(string)
-	fmt.Printf("DEBUG: Me handler, user_id: %s\n", uid)
+	uid := userID(c)
 	if uid == "" {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+		return apperr.Unauthorized()
 	}
 	return c.JSON(fiber.Map{"user_id": uid})
 }
+
+// Settings godoc
+// @Summary      Current user's settings
+// @Tags         auth
+// @Security     BearerAuth
+// @Produce      json
+// @Success      200  {object}  models.UserSettings
+// @Router       /me/settings [get]
+func (h MeHandler) Settings(c *fiber.Ctx) error {
+	s, err := h.Svc.Get(c.UserContext(), member(c))
+	if err != nil {
+		return err
+	}
+	return c.JSON(s)
+}
+
+// UpdateSettings godoc
+// @Summary      Update the current user's settings
+// @Description  The base currency is what budgets and analytics are reported in, and the currency of
+// @Description  new transactions without an account. Existing amounts are not converted.
+// @Description  Timezone and month_start_day decide where days and budget months begin: with
+// @Description  month_start_day 25, the budget month "2024-03" runs from 25 March to 24 April.
+// @Tags         auth
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        body  body  service.SettingsPatch  true  "Settings to change"
+// @Success      200   {object}  models.UserSettings
+// @Failure      422   {object}  apperr.Problem
+// @Router       /me/settings [put]
+func (h MeHandler) UpdateSettings(c *fiber.Ctx) error {
+	var in service.SettingsPatch
+	if err := c.BodyParser(&in); err != nil {
+		return apperr.BadRequest("bad_json")
+	}
+	s, err := h.Svc.Update(c.UserContext(), member(c), in)
+	if err != nil {
+		return err
+	}
+	return c.JSON(s)
+}
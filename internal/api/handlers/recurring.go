@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"budgex_backend/internal/apperr"
+	"budgex_backend/internal/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type RecurringHandler struct{ Svc *service.Recurring }
+
+func (h RecurringHandler) Register(r fiber.Router) {
+	grp := r.Group("/recurring")
+	grp.Get("/", h.List)
+	grp.Post("/", h.Create)
+	grp.Get("/upcoming", h.Upcoming) // ?days=N
+	grp.Get("/:id", h.Get)
+	grp.Patch("/:id", h.Update)
+	grp.Delete("/:id", h.Delete)
+}
+
+// List godoc
+// @Summary      List the current household's recurring rules
+// @Tags         recurring
+// @Security     BearerAuth
+// @Produce      json
+// @Success      200  {array}  models.RecurringRule
+// @Router       /recurring/ [get]
+func (h RecurringHandler) List(c *fiber.Ctx) error {
+	out, err := h.Svc.List(c.UserContext(), member(c))
+	if err != nil {
+		return err
+	}
+	return c.JSON(out)
+}
+
+// Create godoc
+// @Summary      Create recurring rule
+// @Description  Occurrences from start_date up to today are created by the next worker run, in the
+// @Description  current household and on behalf of the rule's creator.
+// @Tags         recurring
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        body  body  service.RecurringInput  true  "Rule"
+// @Success      201   {object} models.RecurringRule
+// @Failure      422   {object} apperr.Problem
+// @Router       /recurring/ [post]
+func (h RecurringHandler) Create(c *fiber.Ctx) error {
+	var in service.RecurringInput
+	if err := c.BodyParser(&in); err != nil {
+		return apperr.BadRequest("bad_json")
+	}
+	r, err := h.Svc.Create(c.UserContext(), member(c), in)
+	if err != nil {
+		return err
+	}
+	return c.Status(201).JSON(r)
+}
+
+// Get godoc
+// @Summary      Get recurring rule
+// @Tags         recurring
+// @Security     BearerAuth
+// @Produce      json
+// @Param        id   path      string  true  "Rule ID"
+// @Success      200  {object}  models.RecurringRule
+// @Failure      404  {object}  apperr.Problem
+// @Router       /recurring/{id} [get]
+func (h RecurringHandler) Get(c *fiber.Ctx) error {
+	r, err := h.Svc.Get(c.UserContext(), member(c), c.Params("id"))
+	if err != nil {
+		return err
+	}
+	return c.JSON(r)
+}
+
+// Update godoc
+// @Summary      Update recurring rule (partial)
+// @Description  Already created transactions are left as they are. Only the rule's creator can change
+// @Description  its account, since occurrences are booked on their behalf.
+// @Tags         recurring
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        id    path  string                  true  "Rule ID"
+// @Param        body  body  service.RecurringInput  true  "Fields to change"
+// @Success      200   {object} models.RecurringRule
+// @Failure      404   {object} apperr.Problem
+// @Failure      422   {object} apperr.Problem
+// @Router       /recurring/{id} [patch]
+func (h RecurringHandler) Update(c *fiber.Ctx) error {
+	var in service.RecurringInput
+	if err := c.BodyParser(&in); err != nil {
+		return apperr.BadRequest("bad_json")
+	}
+	r, err := h.Svc.Update(c.UserContext(), member(c), c.Params("id"), in)
+	if err != nil {
+		return err
+	}
+	return c.JSON(r)
+}
+
+// Delete godoc
+// @Summary      Delete recurring rule
+// @Description  Stops future occurrences; created transactions are kept.
+// @Tags         recurring
+// @Security     BearerAuth
+// @Param        id   path  string  true  "Rule ID"
+// @Success      204
+// @Failure      404  {object}  apperr.Problem
+// @Router       /recurring/{id} [delete]
+func (h RecurringHandler) Delete(c *fiber.Ctx) error {
+	if err := h.Svc.Delete(c.UserContext(), member(c), c.Params("id")); err != nil {
+		return err
+	}
+	return c.SendStatus(204)
+}
+
+// Upcoming godoc
+// @Summary      Preview occurrences due in the next N days
+// @Description  Includes overdue occurrences the worker has not created yet. Days follow the user's timezone.
+// @Tags         recurring
+// @Security     BearerAuth
+// @Produce      json
+// @Param        days  query  int  false  "Horizon in days (default 30)" minimum(1) maximum(366)
+// @Success      200  {array}  service.UpcomingItem
+// @Router       /recurring/upcoming [get]
+func (h RecurringHandler) Upcoming(c *fiber.Ctx) error {
+	out, err := h.Svc.Upcoming(c.UserContext(), member(c), c.QueryInt("days", 30))
+	if err != nil {
+		return err
+	}
+	return c.JSON(out)
+}
@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"budgex_backend/internal/apperr"
+	"budgex_backend/internal/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type RuleHandler struct{ Svc *service.Rules }
+
+func (h RuleHandler) Register(r fiber.Router) {
+	grp := r.Group("/rules")
+	grp.Get("/", h.List)
+	grp.Post("/", h.Create)
+	grp.Get("/:id", h.Get)
+	grp.Patch("/:id", h.Update)
+	grp.Delete("/:id", h.Delete)
+	grp.Post("/:id/apply", h.Apply) // ?dry_run=true&overwrite=true
+}
+
+// List godoc
+// @Summary      List rules in the order they run
+// @Tags         rules
+// @Security     BearerAuth
+// @Produce      json
+// @Success      200  {array}  models.Rule
+// @Router       /rules/ [get]
+func (h RuleHandler) List(c *fiber.Ctx) error {
+	out, err := h.Svc.List(c.UserContext(), member(c))
+	if err != nil {
+		return err
+	}
+	return c.JSON(out)
+}
+
+// Create godoc
+// @Summary      Create rule
+// @Description  Conditions: payee/memo contains or regex (case-insensitive), amount range, type, account.
+// @Description  Actions: set category, add tags, rename payee, set memo.
+// @Tags         rules
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        body  body  service.RuleInput  true  "Rule"
+// @Success      201   {object} models.Rule
+// @Failure      422   {object} apperr.Problem
+// @Router       /rules/ [post]
+func (h RuleHandler) Create(c *fiber.Ctx) error {
+	var in service.RuleInput
+	if err := c.BodyParser(&in); err != nil {
+		return apperr.BadRequest("bad_json")
+	}
+	r, err := h.Svc.Create(c.UserContext(), member(c), in)
+	if err != nil {
+		return err
+	}
+	return c.Status(201).JSON(r)
+}
+
+// Get godoc
+// @Summary      Get rule
+// @Tags         rules
+// @Security     BearerAuth
+// @Produce      json
+// @Param        id   path      string  true  "Rule ID"
+// @Success      200  {object}  models.Rule
+// @Failure      404  {object}  apperr.Problem
+// @Router       /rules/{id} [get]
+func (h RuleHandler) Get(c *fiber.Ctx) error {
+	r, err := h.Svc.Get(c.UserContext(), member(c), c.Params("id"))
+	if err != nil {
+		return err
+	}
+	return c.JSON(r)
+}
+
+// Update godoc
+// @Summary      Update rule (partial)
+// @Tags         rules
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        id    path  string             true  "Rule ID"
+// @Param        body  body  service.RuleInput  true  "Fields to change; empty strings clear"
+// @Success      200   {object} models.Rule
+// @Failure      404   {object} apperr.Problem
+// @Failure      422   {object} apperr.Problem
+// @Router       /rules/{id} [patch]
+func (h RuleHandler) Update(c *fiber.Ctx) error {
+	var in service.RuleInput
+	if err := c.BodyParser(&in); err != nil {
+		return apperr.BadRequest("bad_json")
+	}
+	r, err := h.Svc.Update(c.UserContext(), member(c), c.Params("id"), in)
+	if err != nil {
+		return err
+	}
+	return c.JSON(r)
+}
+
+// Delete godoc
+// @Summary      Delete rule
+// @Tags         rules
+// @Security     BearerAuth
+// @Param        id   path  string  true  "Rule ID"
+// @Success      204
+// @Failure      404  {object}  apperr.Problem
+// @Router       /rules/{id} [delete]
+func (h RuleHandler) Delete(c *fiber.Ctx) error {
+	if err := h.Svc.Delete(c.UserContext(), member(c), c.Params("id")); err != nil {
+		return err
+	}
+	return c.SendStatus(204)
+}
+
+// Apply godoc
+// @Summary      Run a rule over existing transactions
+// @Description  Runs over the current household's transactions; the rule's category is only set if it belongs to that household.
+// @Description  With dry_run=true only counts what would change. By default only uncategorized
+// @Description  transactions get a category; overwrite=true replaces existing categories and memos.
+// @Tags         rules
+// @Security     BearerAuth
+// @Produce      json
+// @Param        id         path   string  true   "Rule ID"
+// @Param        dry_run    query  bool    false  "Count only"
+// @Param        overwrite  query  bool    false  "Replace existing category and memo"
+// @Success      200  {object}  service.RuleApplyResp
+// @Failure      404  {object}  apperr.Problem
+// @Router       /rules/{id}/apply [post]
+func (h RuleHandler) Apply(c *fiber.Ctx) error {
+	out, err := h.Svc.Apply(c.UserContext(), member(c), c.Params("id"),
+		c.QueryBool("dry_run"), c.QueryBool("overwrite"))
+	if err != nil {
+		return err
+	}
+	return c.JSON(out)
+}
@@ -1,29 +1,25 @@
 package handlers
 
 import (
+	"budgex_backend/internal/apperr"
 	"budgex_backend/internal/models"
-	"time"
+	"budgex_backend/internal/service"
 
 	"github.com/gofiber/fiber/v2"
-	"gorm.io/gorm"
 )
 
-type TxHandler struct{ DB *gorm.DB }
+type TxHandler struct{ Svc *service.Transactions }
 
 func (h TxHandler) Register(r fiber.Router) {
 	tx := r.Group("/transactions")
 	tx.Get("/", h.List)
 	tx.Post("/", h.Create)
-}
-
-type createTxDTO struct {
-	Type       string  `json:"type"`           // "income" | "expense"
-	Date       *string `json:"date,omitempty"` // ISO; defaults now
-	Amount     float64 `json:"amount"`
-	Payee      *string `json:"payee"`
-	Memo       *string `json:"memo"`
-	CategoryID *string `json:"category_id"`
-	Tags       *string `json:"tags"`
+	tx.Get("/duplicates", h.Duplicates) // ?days=3
+	tx.Post("/merge", h.Merge)
+	tx.Get("/:id", h.Get)
+	tx.Patch("/:id", h.Update)
+	tx.Delete("/:id", h.Delete)
+	tx.Post("/:id/restore", h.Restore)
 }
 
 func userID(c *fiber.Ctx) string {
@@ -35,60 +31,147 @@ func userID(c *fiber.Ctx) string {
 	return ""
 }
 
+// member is the caller in the household the request acts in, as resolved by
+// middleware.Household.
+func member(c *fiber.Ctx) service.Member {
+	m, _ := c.Locals("member").(service.Member)
+	return m
+}
+
 // List godoc
-// @Summary      List recent transactions
+// @Summary      List transactions (newest first, cursor-paginated)
 // @Tags         transactions
 // @Security     BearerAuth
 // @Produce      json
-// @Param        limit  query   int  false  "Max items" default(100)
-// @Success      200    {array} models.Transaction
-// @Failure      401    {object} map[string]string
+// @Param        limit                query   int     false  "Max items" default(100) maximum(500)
+// @Param        cursor               query   string  false  "next_cursor from the previous page"
+// @Param        from                 query   string  false  "Start date (YYYY-MM-DD or RFC3339, inclusive)"
+// @Param        to                   query   string  false  "End date (YYYY-MM-DD inclusive, or RFC3339 exclusive)"
+// @Param        type                 query   string  false  "income | expense"
+// @Param        category_id          query   string  false  "Category ID"
+// @Param        account_id           query   string  false  "Account ID"
+// @Param        include_descendants  query   bool    false  "Also match child categories of category_id"
+// @Param        payee                query   string  false  "Payee substring (case-insensitive)"
+// @Param        tag                  query   string  false  "Exact tag"
+// @Param        min_amount           query   number  false  "Minimum amount"
+// @Param        max_amount           query   number  false  "Maximum amount"
+// @Param        source               query   string  false  "Source (manual, import:csv, ...)"
+// @Success      200    {object} service.TxPage
+// @Failure      401    {object} apperr.Problem
+// @Failure      422    {object} apperr.Problem
 // @Router       /transactions/ [get]
 func (h TxHandler) List(c *fiber.Ctx) error {
-	var out []models.Transaction
-	err := h.DB.
-		Where("user_id = ? AND deleted_at IS NULL", userID(c)).
-		Order("date DESC, created_at DESC").
-		Limit(100).
-		Find(&out).Error
+	page, err := h.Svc.List(c.UserContext(), member(c), txQuery(c))
 	if err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+		return err
 	}
-	return c.JSON(out)
+	return c.JSON(page)
 }
 
 // Create godoc
 // @Summary      Create transaction
+// @Description  type "transfer" moves money between two accounts and returns both legs (service.Transfer).
 // @Tags         transactions
 // @Security     BearerAuth
 // @Accept       json
 // @Produce      json
-// @Param        body  body    createTxDTO  true  "Transaction"
+// @Param        body  body    service.NewTransaction  true  "Transaction"
 // @Success      201   {object} models.Transaction
-// @Failure      400   {object} map[string]string
-// @Failure      401   {object} map[string]string
+// @Failure      400   {object} apperr.Problem
+// @Failure      401   {object} apperr.Problem
 // @Router       /transactions/ [post]
 func (h TxHandler) Create(c *fiber.Ctx) error {
-	var in createTxDTO
+	var in service.NewTransaction
 	if err := c.BodyParser(&in); err != nil {
-		return c.Status(400).JSON(fiber.Map{"error": "bad_json"})
-	}
-	if in.Type != "income" && in.Type != "expense" {
-		return c.Status(422).JSON(fiber.Map{"error": "type_must_be_income_or_expense"})
+		return apperr.BadRequest("bad_json")
 	}
-	d := time.Now().UTC()
-	if in.Date != nil && *in.Date != "" {
-		if t, err := time.Parse(time.RFC3339, *in.Date); err == nil {
-			d = t
+	if in.Type == models.TxTransfer {
+		out, err := h.Svc.CreateTransfer(c.UserContext(), member(c), in)
+		if err != nil {
+			return err
 		}
+		return c.Status(201).JSON(out)
 	}
-	tx := models.Transaction{
-		Base: models.Base{UserID: userID(c)},
-		Type: in.Type, Date: d, Amount: in.Amount,
-		Payee: in.Payee, Memo: in.Memo, CategoryID: in.CategoryID, Tags: in.Tags,
-	}
-	if err := h.DB.Create(&tx).Error; err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	tx, err := h.Svc.Create(c.UserContext(), member(c), in)
+	if err != nil {
+		return err
 	}
 	return c.Status(201).JSON(tx)
 }
+
+// Get godoc
+// @Summary      Get transaction
+// @Tags         transactions
+// @Security     BearerAuth
+// @Produce      json
+// @Param        id   path      string  true  "Transaction ID"
+// @Success      200  {object}  models.Transaction
+// @Failure      404  {object}  apperr.Problem
+// @Router       /transactions/{id} [get]
+func (h TxHandler) Get(c *fiber.Ctx) error {
+	tx, err := h.Svc.Get(c.UserContext(), member(c), c.Params("id"))
+	if err != nil {
+		return err
+	}
+	return c.JSON(tx)
+}
+
+// Update godoc
+// @Summary      Update transaction (partial)
+// @Tags         transactions
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        id    path    string                    true  "Transaction ID"
+// @Param        body  body    service.TransactionPatch  true  "Fields to change"
+// @Success      200   {object} models.Transaction
+// @Failure      400   {object} apperr.Problem
+// @Failure      403   {object} apperr.Problem "read-only member, or account_id changed by someone other than the creator"
+// @Failure      404   {object} apperr.Problem
+// @Failure      422   {object} apperr.Problem
+// @Router       /transactions/{id} [patch]
+func (h TxHandler) Update(c *fiber.Ctx) error {
+	var in service.TransactionPatch
+	if err := c.BodyParser(&in); err != nil {
+		return apperr.BadRequest("bad_json")
+	}
+	tx, err := h.Svc.Update(c.UserContext(), member(c), c.Params("id"), in)
+	if err != nil {
+		return err
+	}
+	return c.JSON(tx)
+}
+
+// Delete godoc
+// @Summary      Soft-delete transaction
+// @Description  Deleting either leg of a transfer deletes both.
+// @Tags         transactions
+// @Security     BearerAuth
+// @Param        id   path  string  true  "Transaction ID"
+// @Success      204
+// @Failure      404  {object}  apperr.Problem
+// @Router       /transactions/{id} [delete]
+func (h TxHandler) Delete(c *fiber.Ctx) error {
+	if err := h.Svc.Delete(c.UserContext(), member(c), c.Params("id")); err != nil {
+		return err
+	}
+	return c.SendStatus(204)
+}
+
+// Restore godoc
+// @Summary      Restore a soft-deleted transaction
+// @Description  Restoring either leg of a transfer restores both.
+// @Tags         transactions
+// @Security     BearerAuth
+// @Produce      json
+// @Param        id   path      string  true  "Transaction ID"
+// @Success      200  {object}  models.Transaction
+// @Failure      404  {object}  apperr.Problem
+// @Router       /transactions/{id}/restore [post]
+func (h TxHandler) Restore(c *fiber.Ctx) error {
+	tx, err := h.Svc.Restore(c.UserContext(), member(c), c.Params("id"))
+	if err != nil {
+		return err
+	}
+	return c.JSON(tx)
+}
@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"budgex_backend/internal/apperr"
+	"budgex_backend/internal/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Duplicates godoc
+// @Summary      Likely duplicate transactions
+// @Description  Groups income/expense rows with the same type and amount, dates at most `days` apart
+// @Description  and similar payees (after dropping digits and bank noise such as "POS" or "CARD").
+// @Tags         transactions
+// @Security     BearerAuth
+// @Produce      json
+// @Param        days  query  int     false  "Max days between duplicates (default 3)" minimum(0) maximum(31)
+// @Param        from  query  string  false  "Only rows on or after (YYYY-MM-DD); defaults to one year ago"
+// @Param        to    query  string  false  "Only rows on or before (YYYY-MM-DD)"
+// @Success      200   {array}  service.DuplicateGroup
+// @Failure      422   {object} apperr.Problem
+// @Router       /transactions/duplicates [get]
+func (h TxHandler) Duplicates(c *fiber.Ctx) error {
+	out, err := h.Svc.Duplicates(c.UserContext(), member(c), service.DuplicateQuery{
+		Days: c.QueryInt("days", service.DefaultDuplicateDays),
+		From: c.Query("from"),
+		To:   c.Query("to"),
+	})
+	if err != nil {
+		return err
+	}
+	return c.JSON(out)
+}
+
+// Merge godoc
+// @Summary      Merge duplicate transactions
+// @Description  Keeps keep_id, soft-deletes the others and folds their tags and memos into the kept row.
+// @Description  A missing payee or category on the kept row is taken from the others.
+// @Tags         transactions
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        body  body  service.MergeTransactions  true  "Rows to merge"
+// @Success      200   {object} models.Transaction
+// @Failure      404   {object} apperr.Problem
+// @Failure      422   {object} apperr.Problem
+// @Router       /transactions/merge [post]
+func (h TxHandler) Merge(c *fiber.Ctx) error {
+	var in service.MergeTransactions
+	if err := c.BodyParser(&in); err != nil {
+		return apperr.BadRequest("bad_json")
+	}
+	tx, err := h.Svc.Merge(c.UserContext(), member(c), in)
+	if err != nil {
+		return err
+	}
+	return c.JSON(tx)
+}
@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"budgex_backend/internal/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	defaultTxPageSize = 100
+	maxTxPageSize     = 500
+)
+
+// pageSize reads the limit query parameter of a listing, clamped to
+// 1..maxTxPageSize.
+func pageSize(c *fiber.Ctx) int {
+	return min(max(c.QueryInt("limit", defaultTxPageSize), 1), maxTxPageSize)
+}
+
+// txQuery reads the listing filters and page size from the query string.
+func txQuery(c *fiber.Ctx) service.TxQuery {
+	return service.TxQuery{
+		Limit:              pageSize(c),
+		Cursor:             c.Query("cursor"),
+		From:               c.Query("from"),
+		To:                 c.Query("to"),
+		Type:               c.Query("type"),
+		CategoryID:         c.Query("category_id"),
+		IncludeDescendants: c.QueryBool("include_descendants"),
+		AccountID:          c.Query("account_id"),
+		Payee:              c.Query("payee"),
+		Tag:                c.Query("tag"),
+		MinAmount:          c.Query("min_amount"),
+		MaxAmount:          c.Query("max_amount"),
+		Source:             c.Query("source"),
+	}
+}
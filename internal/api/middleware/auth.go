@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"errors"
+	"strings"
+
+	"budgex_backend/internal/apperr"
+	"budgex_backend/internal/auth"
+	"budgex_backend/internal/observability"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+// FiberAuth verifies the request's bearer token with a and stores the id of
+// its user as c.Locals("user_id"). A missing or rejected token is 401; a
+// token that could not be checked, because the signing keys are out of
+// reach, is 503.
+func FiberAuth(a auth.Authenticator) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		token := bearerToken(c.Get(fiber.HeaderAuthorization))
+		if token == "" {
+			return apperr.Unauthorized()
+		}
+		uid, err := a.Authenticate(c.UserContext(), token)
+		if errors.Is(err, auth.ErrInvalidToken) {
+			observability.L().Debug("auth_rejected", zap.Error(err))
+			return apperr.Unauthorized()
+		}
+		if err != nil {
+			return apperr.Unavailable("auth_unavailable").Wrap(err)
+		}
+		c.Locals("user_id", uid)
+		return c.Next()
+	}
+}
+
+// bearerToken takes the token out of an "Authorization: Bearer <token>"
+// header.
+func bearerToken(header string) string {
+	scheme, token, ok := strings.Cut(strings.TrimSpace(header), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"budgex_backend/internal/apperr"
+	"budgex_backend/internal/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// HouseholdHeader names the household a request acts in. Without it the
+// request acts in the first household the caller joined.
+const HouseholdHeader = "X-Household-ID"
+
+// Household resolves the caller's membership of the household the request
+// acts in and stores it as c.Locals("member"). A user in no household gets a
+// personal one with the default categories of their Accept-Language. Must run
+// after Tenant, so that household is created in the request's transaction.
+func Household(svc *service.Households) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		uid, _ := c.Locals("user_id").(string)
+		if uid == "" {
+			return apperr.Unauthorized()
+		}
+		m, err := svc.Resolve(c.UserContext(), uid, c.Get(HouseholdHeader), c.Get(fiber.HeaderAcceptLanguage))
+		if err != nil {
+			return err
+		}
+		c.Locals("member", m)
+		c.Locals("household_id", m.HouseholdID)
+		return c.Next()
+	}
+}
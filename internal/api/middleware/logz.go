@@ -13,7 +13,12 @@ import (
 func Logz() fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		start := time.Now()
-		err := c.Next()
+		if err := c.Next(); err != nil {
+			// Render the error here so the logged status is the one sent.
+			if herr := c.App().ErrorHandler(c, err); herr != nil {
+				_ = c.SendStatus(fiber.StatusInternalServerError)
+			}
+		}
 		lat := time.Since(start)
 
 		// Correlate with OTel trace/span if present
@@ -47,6 +52,9 @@ func Logz() fiber.Handler {
 		if uid != "" {
 			fields = append(fields, zap.String("user_id", uid))
 		}
+		if hid, _ := c.Locals("household_id").(string); hid != "" {
+			fields = append(fields, zap.String("household_id", hid))
+		}
 		if rid, ok := reqID.(string); ok && rid != "" {
 			fields = append(fields, zap.String("request_id", rid))
 		}
@@ -60,6 +68,6 @@ func Logz() fiber.Handler {
 		default:
 			logger.Info("http_request", fields...)
 		}
-		return err
+		return nil
 	}
 }
@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+
+	"budgex_backend/internal/apperr"
+	"budgex_backend/internal/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// errFailedRequest rolls back the transaction of a request that did not
+// succeed.
+var errFailedRequest = errors.New("request failed")
+
+// Tenant runs the rest of the request in one database transaction that
+// row-level security scopes to the caller, so a query that forgets its
+// user_id filter still only sees the caller's rows. Must run after FiberAuth.
+// The transaction rides on c.UserContext(), where the services pick it up. It
+// commits when the handler returns no error and a status below 400, and rolls
+// back otherwise.
+func Tenant(svc *service.Services) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		uid, _ := c.Locals("user_id").(string)
+		if uid == "" {
+			return apperr.Unauthorized()
+		}
+		ctx := c.UserContext()
+		defer c.SetUserContext(ctx)
+		began := false
+		err := svc.AsUser(ctx, uid, func(tx context.Context) error {
+			began = true
+			c.SetUserContext(tx)
+			if err := c.Next(); err != nil {
+				return err
+			}
+			if c.Response().StatusCode() >= fiber.StatusBadRequest {
+				return errFailedRequest
+			}
+			return nil
+		})
+		switch {
+		case errors.Is(err, errFailedRequest):
+			return nil
+		case err != nil && !began:
+			return apperr.Unavailable("database_unavailable").Wrap(err)
+		}
+		return err
+	}
+}
@@ -0,0 +1,223 @@
+package middleware_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"budgex_backend/internal/api"
+	"budgex_backend/internal/api/middleware"
+	"budgex_backend/internal/db"
+	"budgex_backend/internal/models"
+	"budgex_backend/internal/money"
+	"budgex_backend/internal/service"
+	"budgex_backend/internal/store/postgres"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// testRole is an ordinary role the requests switch to, since the test
+// database is usually reached as a superuser, which row-level security
+// ignores.
+const testRole = "budgex_rls_test"
+
+// TestTenantHidesOtherUsersRows runs against a real database: set
+// TEST_DATABASE_URL to a scratch Postgres database, which is migrated up. The
+// connecting role must be able to create and switch to testRole.
+func TestTenantHidesOtherUsersRows(t *testing.T) {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+	ctx := context.Background()
+	gdb, err := db.Connect(dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Migrate(ctx, gdb); err != nil {
+		t.Fatal(err)
+	}
+	if err := gdb.Exec(`
+		DO $$ BEGIN
+		  IF NOT EXISTS (SELECT FROM pg_roles WHERE rolname = '` + testRole + `') THEN
+		    CREATE ROLE ` + testRole + ` NOLOGIN;
+		  END IF;
+		END $$;
+		GRANT USAGE ON SCHEMA public TO ` + testRole + `;
+		GRANT SELECT, INSERT, UPDATE, DELETE ON ALL TABLES IN SCHEMA public TO ` + testRole + `;`).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	alice, bob := "rls_alice_"+uuid.NewString(), "rls_bob_"+uuid.NewString()
+	seedRows(t, gdb, alice)
+	bobHousehold := seedRows(t, gdb, bob)
+	t.Cleanup(func() {
+		_ = db.AsSystem(ctx, gdb, func(tx *gorm.DB) error {
+			users := []string{alice, bob}
+			for _, m := range []any{&models.Budget{}, &models.Transaction{}, &models.Category{}} {
+				tx.Where("created_by IN ?", users).Delete(m)
+			}
+			tx.Where("user_id IN ?", users).Delete(&models.HouseholdMember{})
+			tx.Where("created_by IN ?", users).Delete(&models.Household{})
+			return nil
+		})
+	})
+
+	app := fiber.New(fiber.Config{ErrorHandler: api.ErrorHandler})
+	app.Use(func(c *fiber.Ctx) error { // stands in for FiberAuth
+		c.Locals("user_id", c.Get("X-User"))
+		return c.Next()
+	})
+	app.Use(middleware.Tenant(service.New(postgres.New(gdb))))
+	app.Use(func(c *fiber.Ctx) error {
+		if err := postgres.Conn(c.UserContext(), gdb).Exec("SET LOCAL ROLE " + testRole).Error; err != nil {
+			return err
+		}
+		return c.Next()
+	})
+	// A handler that forgot its household_id filter.
+	app.Get("/leak/:table", func(c *fiber.Ctx) error {
+		var owners []string
+		err := postgres.Conn(c.UserContext(), gdb).
+			Table(c.Params("table")).Distinct().Pluck("created_by", &owners).Error
+		if err != nil {
+			return err
+		}
+		return c.JSON(owners)
+	})
+	// A handler that lists members without naming the household.
+	app.Get("/members", func(c *fiber.Ctx) error {
+		var users []string
+		err := postgres.Conn(c.UserContext(), gdb).
+			Model(&models.HouseholdMember{}).Distinct().Pluck("user_id", &users).Error
+		if err != nil {
+			return err
+		}
+		return c.JSON(users)
+	})
+	// A handler that writes a row into someone else's household.
+	app.Post("/plant", func(c *fiber.Ctx) error {
+		return postgres.Conn(c.UserContext(), gdb).Create(&models.Category{
+			HouseholdBase: models.HouseholdBase{HouseholdID: bobHousehold, CreatedBy: alice}, Name: "planted",
+		}).Error
+	})
+
+	for _, table := range []string{"categories", "transactions", "budgets"} {
+		req := httptest.NewRequest("GET", "/leak/"+table, nil)
+		req.Header.Set("X-User", alice)
+		res, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(res.Body)
+		if res.StatusCode != 200 {
+			t.Fatalf("%s: status %d: %s", table, res.StatusCode, body)
+		}
+		var owners []string
+		if err := json.Unmarshal(body, &owners); err != nil {
+			t.Fatal(err)
+		}
+		for _, o := range owners {
+			if o == bob {
+				t.Errorf("%s: alice sees bob's rows", table)
+			}
+		}
+		if len(owners) != 1 || owners[0] != alice {
+			t.Errorf("%s: owners = %v, want only alice", table, owners)
+		}
+	}
+
+	req := httptest.NewRequest("GET", "/members", nil)
+	req.Header.Set("X-User", alice)
+	res, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(res.Body)
+	var users []string
+	if err := json.Unmarshal(body, &users); err != nil {
+		t.Fatalf("members: status %d: %s", res.StatusCode, body)
+	}
+	if len(users) != 1 || users[0] != alice {
+		t.Errorf("members = %v, want only alice", users)
+	}
+	// the policies hold for the table owner as well
+	var unforced []string
+	if err := gdb.Raw(`SELECT relname FROM pg_class
+		WHERE relname IN ('households', 'household_members', 'household_invites', 'categories', 'transactions')
+		  AND relnamespace = to_regnamespace(current_schema()) AND NOT relforcerowsecurity`).Scan(&unforced).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(unforced) > 0 {
+		t.Errorf("row-level security is not forced on %v", unforced)
+	}
+
+	req = httptest.NewRequest("POST", "/plant", nil)
+	req.Header.Set("X-User", alice)
+	res, err = app.Test(req, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.StatusCode != 500 {
+		t.Errorf("planting a row in bob's household: status %d, want 500", res.StatusCode)
+	}
+	var n int64
+	_ = db.AsSystem(ctx, gdb, func(tx *gorm.DB) error {
+		return tx.Model(&models.Category{}).Where("household_id = ? AND name = 'planted'", bobHousehold).Count(&n).Error
+	})
+	if n != 0 {
+		t.Errorf("bob has %d planted categories", n)
+	}
+
+	req = httptest.NewRequest("GET", "/leak/transactions", nil)
+	res, err = app.Test(req, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.StatusCode != 401 {
+		t.Errorf("no user: status %d, want 401", res.StatusCode)
+	}
+}
+
+// seedRows gives uid a household holding a category, a transaction and a
+// budget, bypassing row-level security the way jobs do, and returns the
+// household's id.
+func seedRows(t *testing.T, gdb *gorm.DB, uid string) string {
+	t.Helper()
+	h := models.Household{Name: "Home", CreatedBy: uid}
+	err := db.AsSystem(context.Background(), gdb, func(tx *gorm.DB) error {
+		if err := tx.Create(&h).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(&models.HouseholdMember{
+			HouseholdID: h.ID, UserID: uid, Role: models.RoleOwner,
+		}).Error; err != nil {
+			return err
+		}
+		own := models.HouseholdBase{HouseholdID: h.ID, CreatedBy: uid}
+		cat := models.Category{HouseholdBase: own, Name: "Food"}
+		if err := tx.Create(&cat).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(&models.Transaction{
+			HouseholdBase: own, Type: models.TxExpense, Date: time.Now(),
+			Amount: money.FromCents(1234), Currency: "USD", CategoryID: &cat.ID,
+		}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.Budget{
+			HouseholdBase: own, Month: time.Now().Format("2006-01"),
+			CategoryID: cat.ID, Amount: money.FromCents(50000),
+		}).Error
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return h.ID
+}
@@ -4,13 +4,22 @@ package api
 import (
 	"budgex_backend/internal/api/handlers"
 	"budgex_backend/internal/api/middleware"
+	"budgex_backend/internal/auth"
+	"budgex_backend/internal/service"
+	"budgex_backend/internal/validate"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/requestid"
-	"gorm.io/gorm"
 )
 
-func Build(db *gorm.DB) *fiber.App {
+// Build wires the routes to svc. It fails when the validate tags of a request
+// type are wrong, so the mistake shows on start rather than on the first
+// request.
+func Build(svc *service.Services, authn auth.Authenticator) (*fiber.App, error) {
+	if err := validate.CheckRegistered(); err != nil {
+		return nil, err
+	}
+
 	app := fiber.New(fiber.Config{
 		AppName:               "budgex-backend",
 		DisableStartupMessage: true,
@@ -19,27 +28,39 @@ func Build(db *gorm.DB) *fiber.App {
 		ReadBufferSize:  64 * 1024,       // 64KB header buffer (was ~4KB default)
 		WriteBufferSize: 8192,            // 8KB write buffer
 		BodyLimit:       4 * 1024 * 1024, // 4MB body limit
+		// Every error becomes an RFC 7807 problem (see errors.go)
+		ErrorHandler: ErrorHandler,
 	})
 
-	// Order matters: requestid -> otel -> auth -> logz
+	// Order matters: requestid -> otel -> auth -> logz -> tenant -> household
 	app.Use(requestid.New())   // adds c.Locals("requestid")
 	app.Use(middleware.OTel()) // starts OTel spans (trace/parent propagation)
 
 	// Public
 	api := app.Group("/api")
-	handlers.HealthHandler{DB: db}.Register(api)
+	handlers.HealthHandler{Svc: svc.Health}.Register(api)
 
-	// Auth (your working Clerk middleware)
-	protected := api.Group("", middleware.FiberAuth())
+	// Bearer tokens, verified by the authenticator AUTH_MODE selects
+	protected := api.Group("", middleware.FiberAuth(authn))
 
 	// Structured logging AFTER auth so user_id is set for logs
 	protected.Use(middleware.Logz())
+	// One transaction per request, scoped to the caller by row-level security
+	protected.Use(middleware.Tenant(svc))
+	// The household the request acts in (X-Household-ID, else the first one)
+	protected.Use(middleware.Household(svc.Households))
 	// Protected routes
-	handlers.MeHandler{}.Register(protected)
-	handlers.TxHandler{DB: db}.Register(protected)
-	handlers.CategoryHandler{DB: db}.Register(protected)
-	handlers.BudgetHandler{DB: db}.Register(protected)
-	handlers.AnalyticsHandler{DB: db}.Register(protected)
+	handlers.MeHandler{Svc: svc.Settings}.Register(protected)
+	handlers.HouseholdHandler{Svc: svc.Households}.Register(protected)
+	handlers.TxHandler{Svc: svc.Transactions}.Register(protected)
+	handlers.CategoryHandler{Svc: svc.Categories}.Register(protected)
+	handlers.BudgetHandler{Svc: svc.Budgets}.Register(protected)
+	handlers.AnalyticsHandler{Svc: svc.Analytics}.Register(protected)
+	handlers.AccountHandler{Svc: svc.Accounts}.Register(protected)
+	handlers.ImportHandler{Svc: svc.Imports}.Register(protected)
+	handlers.RecurringHandler{Svc: svc.Recurring}.Register(protected)
+	handlers.RuleHandler{Svc: svc.Rules}.Register(protected)
+	handlers.FxHandler{Svc: svc.Rates}.Register(protected)
 
-	return app
+	return app, nil
 }
@@ -0,0 +1,14 @@
+package api
+
+import (
+	"testing"
+
+	"budgex_backend/internal/validate"
+)
+
+// Build refuses to start on a broken validate tag; catch it before that.
+func TestRequestTags(t *testing.T) {
+	if err := validate.CheckRegistered(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,153 @@
+// Package apperr defines the errors the API reports to clients. An Error
+// carries a stable snake_case Code that clients can switch on, an optional
+// human-readable Detail, per-field problems for invalid input, and the
+// internal cause, which is logged but never sent.
+package apperr
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Kind says what went wrong and picks the HTTP status.
+type Kind int
+
+const (
+	KindInternal     Kind = iota // something failed on our side
+	KindBadRequest               // the request could not be read (malformed JSON, missing file)
+	KindInvalid                  // the input was read but breaks a rule
+	KindUnauthorized             // no valid credentials
+	KindForbidden                // authenticated, but not allowed
+	KindNotFound                 // the thing acted on does not exist
+	KindConflict                 // the current state does not allow it
+	KindUnavailable              // a dependency is down
+)
+
+var statuses = map[Kind]int{
+	KindInternal:     http.StatusInternalServerError,
+	KindBadRequest:   http.StatusBadRequest,
+	KindInvalid:      http.StatusUnprocessableEntity,
+	KindUnauthorized: http.StatusUnauthorized,
+	KindForbidden:    http.StatusForbidden,
+	KindNotFound:     http.StatusNotFound,
+	KindConflict:     http.StatusConflict,
+	KindUnavailable:  http.StatusServiceUnavailable,
+}
+
+// FieldError is one problem with one input field.
+type FieldError struct {
+	Field  string `json:"field"`            // JSON name, dotted for nested fields (splits.0.amount)
+	Code   string `json:"code"`             // snake_case reason
+	Detail string `json:"detail,omitempty"` // human-readable
+}
+
+// Error is a failure with a code meant for clients.
+type Error struct {
+	Kind   Kind
+	Code   string
+	Detail string
+	Fields []FieldError
+	// Extra holds additional members of the problem, such as the id that was
+	// not found.
+	Extra map[string]any
+	// Cause is the underlying error. It is logged, never shown to clients.
+	Cause error
+}
+
+func (e *Error) Error() string {
+	if e.Cause != nil {
+		return e.Code + ": " + e.Cause.Error()
+	}
+	return e.Code
+}
+
+func (e *Error) Unwrap() error { return e.Cause }
+
+// Status is the HTTP status for the error's kind.
+func (e *Error) Status() int { return statuses[e.Kind] }
+
+// New returns an error of the given kind and code.
+func New(kind Kind, code string) *Error { return &Error{Kind: kind, Code: code} }
+
+// WithDetail sets the human-readable explanation.
+func (e *Error) WithDetail(detail string) *Error {
+	e.Detail = detail
+	return e
+}
+
+// With adds a member to the problem body.
+func (e *Error) With(key string, v any) *Error {
+	if e.Extra == nil {
+		e.Extra = map[string]any{}
+	}
+	e.Extra[key] = v
+	return e
+}
+
+// Wrap records the cause.
+func (e *Error) Wrap(cause error) *Error {
+	e.Cause = cause
+	return e
+}
+
+// BadRequest is a request that could not be read.
+func BadRequest(code string) *Error { return New(KindBadRequest, code) }
+
+// Invalid is input that breaks a rule not tied to one field.
+func Invalid(code string) *Error { return New(KindInvalid, code) }
+
+// Field is invalid input in one field; code is both the error's code and the
+// field's.
+func Field(field, code string) *Error {
+	return &Error{Kind: KindInvalid, Code: code, Fields: []FieldError{{Field: field, Code: code}}}
+}
+
+// Fields is invalid input in several fields. A single field keeps its own
+// code; more than one are reported as invalid_input.
+func Fields(fields ...FieldError) *Error {
+	if len(fields) == 1 {
+		return &Error{Kind: KindInvalid, Code: fields[0].Code, Fields: fields}
+	}
+	return &Error{
+		Kind:   KindInvalid,
+		Code:   "invalid_input",
+		Detail: fmt.Sprintf("%d fields are invalid.", len(fields)),
+		Fields: fields,
+	}
+}
+
+// NotFound is a missing resource.
+func NotFound() *Error { return New(KindNotFound, "not_found") }
+
+// Conflict is a request the current state does not allow.
+func Conflict(code string) *Error { return New(KindConflict, code) }
+
+// Unauthorized is a request without valid credentials.
+func Unauthorized() *Error { return New(KindUnauthorized, "unauthorized") }
+
+// Forbidden is a request the caller may not make.
+func Forbidden(code string) *Error { return New(KindForbidden, code) }
+
+// Unavailable is a dependency that is down.
+func Unavailable(code string) *Error { return New(KindUnavailable, code) }
+
+// Internal wraps an unexpected failure.
+func Internal(cause error) *Error {
+	return &Error{Kind: KindInternal, Code: "internal", Cause: cause}
+}
+
+// As returns err as an *Error, or nil when it is not one.
+func As(err error) *Error {
+	var e *Error
+	if errors.As(err, &e) {
+		return e
+	}
+	return nil
+}
+
+// IsKind reports whether err is an *Error of kind k.
+func IsKind(err error, k Kind) bool {
+	e := As(err)
+	return e != nil && e.Kind == k
+}
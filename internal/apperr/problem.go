@@ -0,0 +1,64 @@
+package apperr
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// ProblemContentType is the media type of Problem bodies.
+const ProblemContentType = "application/problem+json"
+
+// Problem is the body of every error response, following RFC 7807. Members
+// from Error.Extra are added next to these.
+type Problem struct {
+	Type      string       `json:"type"`                 // always about:blank; code identifies the problem
+	Title     string       `json:"title"`                // HTTP status text
+	Status    int          `json:"status"`               // HTTP status code
+	Detail    string       `json:"detail,omitempty"`     // human-readable explanation
+	Instance  string       `json:"instance,omitempty"`   // request path
+	Code      string       `json:"code"`                 // stable snake_case reason
+	RequestID string       `json:"request_id,omitempty"` // X-Request-ID of the request, for support
+	Errors    []FieldError `json:"errors,omitempty"`     // per-field problems of invalid input
+
+	Extra map[string]any `json:"-" swaggerignore:"true"`
+}
+
+// MarshalJSON writes Extra as top-level members; the standard members win
+// on a clash.
+func (p Problem) MarshalJSON() ([]byte, error) {
+	type plain Problem
+	b, err := json.Marshal(plain(p))
+	if err != nil || len(p.Extra) == 0 {
+		return b, err
+	}
+	var std map[string]json.RawMessage
+	if err := json.Unmarshal(b, &std); err != nil {
+		return nil, err
+	}
+	m := make(map[string]any, len(std)+len(p.Extra))
+	for k, v := range p.Extra {
+		m[k] = v
+	}
+	for k, v := range std {
+		m[k] = v
+	}
+	return json.Marshal(m)
+}
+
+// Problem renders e for clients. The cause is left out.
+func (e *Error) Problem() Problem {
+	status := e.Status()
+	detail := e.Detail
+	if e.Kind == KindInternal && detail == "" {
+		detail = "An unexpected error occurred."
+	}
+	return Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+		Code:   e.Code,
+		Errors: e.Fields,
+		Extra:  e.Extra,
+	}
+}
@@ -0,0 +1,48 @@
+// Package auth verifies the bearer tokens of API requests and names the user
+// each was issued to. The Authenticator is chosen by config.Config.AuthMode:
+// Clerk session tokens, any OIDC/JWT issuer whose keys are published as a
+// JWKS, or fixed tokens for development and tests.
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"budgex_backend/internal/config"
+)
+
+// ErrInvalidToken is wrapped by every error about the token itself: bad
+// signature, expired, wrong issuer or audience, unknown. Other errors mean
+// the token could not be checked, e.g. because the keys were unreachable.
+var ErrInvalidToken = errors.New("auth: invalid token")
+
+var errNoSubject = errors.New("token has no sub claim")
+
+// Authenticator verifies bearer tokens.
+type Authenticator interface {
+	// Authenticate returns the id of the user token was issued to.
+	Authenticate(ctx context.Context, token string) (string, error)
+}
+
+// New builds the Authenticator cfg selects.
+func New(ctx context.Context, cfg config.Config) (Authenticator, error) {
+	switch cfg.AuthMode {
+	case config.AuthClerk:
+		return NewClerk(cfg.ClerkSecretKey, cfg.AuthClockSkew), nil
+	case config.AuthJWT:
+		return NewJWT(ctx, JWTConfig{
+			JWKS:      cfg.JWKS,
+			Issuer:    cfg.JWTIssuer,
+			Audience:  cfg.JWTAudience,
+			ClockSkew: cfg.AuthClockSkew,
+		})
+	case config.AuthStatic:
+		return Static(cfg.StaticTokens), nil
+	}
+	return nil, fmt.Errorf("auth: unknown mode %q", cfg.AuthMode)
+}
+
+func invalid(err error) error {
+	return fmt.Errorf("%w: %v", ErrInvalidToken, err)
+}
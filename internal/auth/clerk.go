@@ -0,0 +1,59 @@
+package auth
+
+import (
+	"context"
+	"time"
+
+	"github.com/clerk/clerk-sdk-go/v2"
+	"github.com/clerk/clerk-sdk-go/v2/jwks"
+	clerkjwt "github.com/clerk/clerk-sdk-go/v2/jwt"
+)
+
+// Clerk verifies Clerk session tokens. The instance's signing keys are
+// fetched from the Clerk API and cached; the tokens themselves are checked
+// locally.
+type Clerk struct {
+	keys   *keyCache[*clerk.JSONWebKey]
+	leeway time.Duration
+}
+
+// NewClerk verifies the session tokens of the Clerk instance secretKey
+// belongs to, allowing leeway of clock skew.
+func NewClerk(secretKey string, leeway time.Duration) *Clerk {
+	client := jwks.NewClient(&clerk.ClientConfig{BackendConfig: clerk.BackendConfig{Key: &secretKey}})
+	return &Clerk{
+		keys: newKeyCache(func(ctx context.Context) (map[string]*clerk.JSONWebKey, error) {
+			set, err := client.Get(ctx, &jwks.GetParams{})
+			if err != nil {
+				return nil, err
+			}
+			keys := make(map[string]*clerk.JSONWebKey, len(set.Keys))
+			for _, k := range set.Keys {
+				if k != nil {
+					keys[k.KeyID] = k
+				}
+			}
+			return keys, nil
+		}),
+		leeway: leeway,
+	}
+}
+
+func (a *Clerk) Authenticate(ctx context.Context, token string) (string, error) {
+	unverified, err := clerkjwt.Decode(ctx, &clerkjwt.DecodeParams{Token: token})
+	if err != nil {
+		return "", invalid(err)
+	}
+	key, err := a.keys.get(ctx, unverified.KeyID)
+	if err != nil {
+		return "", err
+	}
+	claims, err := clerkjwt.Verify(ctx, &clerkjwt.VerifyParams{Token: token, JWK: key, Leeway: a.leeway})
+	if err != nil {
+		return "", invalid(err)
+	}
+	if claims.Subject == "" {
+		return "", invalid(errNoSubject)
+	}
+	return claims.Subject, nil
+}
@@ -0,0 +1,151 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/go-jose/go-jose/v3"
+	"github.com/go-jose/go-jose/v3/jwt"
+)
+
+// JWTConfig says which tokens a JWT authenticator accepts.
+type JWTConfig struct {
+	// JWKS is a file path or an http(s) URL of the issuer's JSON Web Key
+	// Set, e.g. the jwks_uri of an OIDC provider.
+	JWKS string
+	// Issuer must equal the iss claim.
+	Issuer string
+	// Audience must be among the aud claim.
+	Audience string
+	// ClockSkew is how far exp, nbf and iat may be off.
+	ClockSkew time.Duration
+}
+
+// JWT verifies signed JWTs of any OIDC/JWT issuer against its published
+// keys. The user is the sub claim.
+type JWT struct {
+	cfg  JWTConfig
+	keys *keyCache[jose.JSONWebKey]
+}
+
+// Asymmetric algorithms only: the keys are public, so an HMAC "signed" with
+// one would prove nothing.
+var jwtAlgorithms = map[string]bool{
+	string(jose.RS256): true, string(jose.RS384): true, string(jose.RS512): true,
+	string(jose.PS256): true, string(jose.PS384): true, string(jose.PS512): true,
+	string(jose.ES256): true, string(jose.ES384): true, string(jose.ES512): true,
+	string(jose.EdDSA): true,
+}
+
+// NewJWT loads the keys of cfg.JWKS, so a wrong path or URL shows on start.
+func NewJWT(ctx context.Context, cfg JWTConfig) (*JWT, error) {
+	a := &JWT{cfg: cfg}
+	a.keys = newKeyCache(func(ctx context.Context) (map[string]jose.JSONWebKey, error) {
+		return loadJWKS(ctx, cfg.JWKS)
+	})
+	if err := a.keys.load(ctx); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+func (a *JWT) Authenticate(ctx context.Context, token string) (string, error) {
+	tok, err := jwt.ParseSigned(token)
+	if err != nil {
+		return "", invalid(err)
+	}
+	if len(tok.Headers) != 1 {
+		return "", invalid(errors.New("token must have exactly one signature"))
+	}
+	h := tok.Headers[0]
+	if !jwtAlgorithms[h.Algorithm] {
+		return "", invalid(fmt.Errorf("algorithm %q not accepted", h.Algorithm))
+	}
+	key, err := a.keys.get(ctx, h.KeyID)
+	if err != nil {
+		return "", err
+	}
+	if key.Algorithm != "" && key.Algorithm != h.Algorithm {
+		return "", invalid(fmt.Errorf("key %q is for %s, not %s", h.KeyID, key.Algorithm, h.Algorithm))
+	}
+	var claims jwt.Claims
+	if err := tok.Claims(key.Key, &claims); err != nil {
+		return "", invalid(err)
+	}
+	if claims.Expiry == nil {
+		return "", invalid(errors.New("token has no exp claim"))
+	}
+	err = claims.ValidateWithLeeway(jwt.Expected{
+		Issuer:   a.cfg.Issuer,
+		Audience: jwt.Audience{a.cfg.Audience},
+		Time:     time.Now(),
+	}, a.cfg.ClockSkew)
+	if err != nil {
+		return "", invalid(err)
+	}
+	if claims.Subject == "" {
+		return "", invalid(errNoSubject)
+	}
+	return claims.Subject, nil
+}
+
+// jwksClient fetches key sets from URLs.
+var jwksClient = &http.Client{Timeout: 10 * time.Second}
+
+// loadJWKS reads the signing keys of the set at src, a file path or an
+// http(s) URL, by key id. Symmetric and encryption keys are left out; a set
+// of one key also serves tokens without a kid.
+func loadJWKS(ctx context.Context, src string) (map[string]jose.JSONWebKey, error) {
+	var body []byte
+	if strings.HasPrefix(src, "https://") || strings.HasPrefix(src, "http://") {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, src, nil)
+		if err != nil {
+			return nil, err
+		}
+		res, err := jwksClient.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		defer res.Body.Close()
+		if res.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("auth: GET %s: %s", src, res.Status)
+		}
+		if body, err = io.ReadAll(io.LimitReader(res.Body, 1<<20)); err != nil {
+			return nil, err
+		}
+	} else {
+		var err error
+		if body, err = os.ReadFile(src); err != nil {
+			return nil, err
+		}
+	}
+	var set jose.JSONWebKeySet
+	if err := json.Unmarshal(body, &set); err != nil {
+		return nil, fmt.Errorf("auth: %s: %w", src, err)
+	}
+	keys := map[string]jose.JSONWebKey{}
+	for _, k := range set.Keys {
+		if k = k.Public(); !k.IsPublic() || (k.Use != "" && k.Use != "sig") {
+			continue
+		}
+		keys[k.KeyID] = k
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("auth: %s holds no public signing keys", src)
+	}
+	if len(keys) == 1 {
+		var only jose.JSONWebKey
+		for _, k := range keys {
+			only = k
+		}
+		keys[""] = only
+	}
+	return keys, nil
+}
@@ -0,0 +1,155 @@
+package auth_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v3"
+	"github.com/go-jose/go-jose/v3/jwt"
+
+	"budgex_backend/internal/auth"
+)
+
+const (
+	issuer   = "https://issuer.example"
+	audience = "budgex"
+	skew     = time.Minute
+)
+
+type signingKey struct {
+	alg jose.SignatureAlgorithm
+	key any
+	kid string
+}
+
+// newJWT writes a JWKS of an RSA and an EC key to a file and returns a
+// verifier of it with the keys to sign with.
+func newJWT(t *testing.T) (*auth.JWT, signingKey, signingKey) {
+	t.Helper()
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	set := jose.JSONWebKeySet{Keys: []jose.JSONWebKey{
+		{Key: rsaKey.Public(), KeyID: "rsa", Algorithm: string(jose.RS256), Use: "sig"},
+		{Key: ecKey.Public(), KeyID: "ec", Use: "sig"},
+	}}
+	body, err := json.Marshal(set)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, body, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	a, err := auth.NewJWT(context.Background(), auth.JWTConfig{JWKS: path, Issuer: issuer, Audience: audience, ClockSkew: skew})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return a, signingKey{jose.RS256, rsaKey, "rsa"}, signingKey{jose.ES256, ecKey, "ec"}
+}
+
+func sign(t *testing.T, k signingKey, c jwt.Claims) string {
+	t.Helper()
+	opts := (&jose.SignerOptions{}).WithType("JWT")
+	if k.kid != "" {
+		opts = opts.WithHeader(jose.HeaderKey("kid"), k.kid)
+	}
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: k.alg, Key: k.key}, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := jwt.Signed(signer).Claims(c).CompactSerialize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func TestJWT(t *testing.T) {
+	a, rs, es := newJWT(t)
+	now := time.Now()
+	at := func(d time.Duration) *jwt.NumericDate { return jwt.NewNumericDate(now.Add(d)) }
+	claims := func(edit func(*jwt.Claims)) jwt.Claims {
+		c := jwt.Claims{
+			Issuer: issuer, Audience: jwt.Audience{"other", audience}, Subject: "user_1",
+			IssuedAt: at(0), Expiry: at(time.Hour),
+		}
+		edit(&c)
+		return c
+	}
+	keep := func(*jwt.Claims) {}
+
+	noneHeader := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none","typ":"JWT"}`))
+	payload, _ := json.Marshal(claims(keep))
+	none := noneHeader + "." + base64.RawURLEncoding.EncodeToString(payload) + "."
+
+	tests := []struct {
+		name  string
+		token string
+		ok    bool
+	}{
+		{"rsa", sign(t, rs, claims(keep)), true},
+		{"ec", sign(t, es, claims(keep)), true},
+		{"wrong issuer", sign(t, rs, claims(func(c *jwt.Claims) { c.Issuer = "https://evil.example" })), false},
+		{"no issuer", sign(t, rs, claims(func(c *jwt.Claims) { c.Issuer = "" })), false},
+		{"wrong audience", sign(t, rs, claims(func(c *jwt.Claims) { c.Audience = jwt.Audience{"other"} })), false},
+		{"no sub", sign(t, rs, claims(func(c *jwt.Claims) { c.Subject = "" })), false},
+		{"no exp", sign(t, rs, claims(func(c *jwt.Claims) { c.Expiry = nil })), false},
+
+		{"expired within skew", sign(t, rs, claims(func(c *jwt.Claims) { c.Expiry = at(-skew / 2) })), true},
+		{"expired beyond skew", sign(t, rs, claims(func(c *jwt.Claims) { c.Expiry = at(-2 * skew) })), false},
+		{"not yet valid within skew", sign(t, rs, claims(func(c *jwt.Claims) { c.NotBefore = at(skew / 2) })), true},
+		{"not yet valid beyond skew", sign(t, rs, claims(func(c *jwt.Claims) { c.NotBefore = at(2 * skew) })), false},
+		{"issued in the future", sign(t, rs, claims(func(c *jwt.Claims) { c.IssuedAt = at(2 * skew) })), false},
+
+		{"alg none", none, false},
+		{"hs256", sign(t, signingKey{jose.HS256, []byte("0123456789abcdef0123456789abcdef"), "rsa"}, claims(keep)), false},
+		{"alg other than the key's", sign(t, signingKey{jose.PS256, rs.key, "rsa"}, claims(keep)), false},
+		{"key of another kid", sign(t, signingKey{jose.RS256, rs.key, "ec"}, claims(keep)), false},
+		{"unknown kid", sign(t, signingKey{jose.RS256, rs.key, "gone"}, claims(keep)), false},
+		{"no kid with two keys", sign(t, signingKey{jose.RS256, rs.key, ""}, claims(keep)), false},
+		{"not a jwt", "abc", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uid, err := a.Authenticate(context.Background(), tt.token)
+			if tt.ok {
+				if err != nil || uid != "user_1" {
+					t.Fatalf("Authenticate = %q, %v; want user_1", uid, err)
+				}
+				return
+			}
+			if !errors.Is(err, auth.ErrInvalidToken) {
+				t.Fatalf("Authenticate = %q, %v; want ErrInvalidToken", uid, err)
+			}
+		})
+	}
+}
+
+func TestNewJWTBadSource(t *testing.T) {
+	dir := t.TempDir()
+	empty := filepath.Join(dir, "empty.json")
+	if err := os.WriteFile(empty, []byte(`{"keys":[]}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	for _, src := range []string{filepath.Join(dir, "missing.json"), empty} {
+		if _, err := auth.NewJWT(context.Background(), auth.JWTConfig{JWKS: src}); err == nil {
+			t.Errorf("NewJWT(%s) = nil error", src)
+		}
+	}
+}
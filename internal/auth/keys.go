@@ -0,0 +1,83 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// keyCache holds signing keys by key id. It fetches the whole set again once
+// maxAge has passed or a token names an unknown key, but never more than once
+// per minRefresh, so tokens with made-up key ids cannot flood the source.
+type keyCache[K any] struct {
+	fetch      func(ctx context.Context) (map[string]K, error)
+	maxAge     time.Duration
+	minRefresh time.Duration
+
+	mu      sync.Mutex
+	keys    map[string]K
+	fetched time.Time     // last successful fetch
+	tried   time.Time     // last attempt
+	err     error         // of the last attempt
+	refresh chan struct{} // closed when the running fetch ends; nil if none
+}
+
+func newKeyCache[K any](fetch func(ctx context.Context) (map[string]K, error)) *keyCache[K] {
+	return &keyCache[K]{fetch: fetch, maxAge: time.Hour, minRefresh: time.Minute}
+}
+
+// load fetches the keys now.
+func (c *keyCache[K]) load(ctx context.Context) error {
+	keys, err := c.fetch(ctx)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.keys, c.fetched, c.tried = keys, time.Now(), time.Now()
+	return nil
+}
+
+// get returns the key kid. A key already known is still served while the
+// source cannot be reached. The fetch runs without holding mu; callers that
+// arrive meanwhile wait for it instead of fetching again.
+func (c *keyCache[K]) get(ctx context.Context, kid string) (K, error) {
+	c.mu.Lock()
+	k, ok := c.keys[kid]
+	if ok && time.Since(c.fetched) < c.maxAge {
+		c.mu.Unlock()
+		return k, nil
+	}
+	switch done := c.refresh; {
+	case done != nil:
+		c.mu.Unlock()
+		select {
+		case <-done:
+		case <-ctx.Done():
+			return k, ctx.Err()
+		}
+		c.mu.Lock()
+	case c.tried.IsZero() || time.Since(c.tried) >= c.minRefresh:
+		done = make(chan struct{})
+		c.refresh, c.tried = done, time.Now()
+		c.mu.Unlock()
+		keys, err := c.fetch(ctx)
+		c.mu.Lock()
+		if c.err = err; err == nil {
+			c.keys, c.fetched = keys, time.Now()
+		}
+		c.refresh = nil
+		close(done)
+	}
+	k, ok = c.keys[kid]
+	err := c.err
+	c.mu.Unlock()
+	switch {
+	case ok:
+		return k, nil
+	case err != nil:
+		return k, fmt.Errorf("auth: signing keys unavailable: %w", err)
+	}
+	return k, invalid(fmt.Errorf("unknown key id %q", kid))
+}
@@ -0,0 +1,102 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestKeyCacheRefresh(t *testing.T) {
+	var calls atomic.Int32
+	source := map[string]int{"a": 1}
+	var fail bool
+	c := newKeyCache(func(context.Context) (map[string]int, error) {
+		calls.Add(1)
+		if fail {
+			return nil, errors.New("down")
+		}
+		out := map[string]int{}
+		for k, v := range source {
+			out[k] = v
+		}
+		return out, nil
+	})
+	ctx := context.Background()
+	if err := c.load(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// an unknown kid refetches only after minRefresh
+	source["b"] = 2
+	if _, err := c.get(ctx, "b"); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("get(b) right after load = %v, want ErrInvalidToken", err)
+	}
+	c.tried = time.Now().Add(-c.minRefresh)
+	if k, err := c.get(ctx, "b"); err != nil || k != 2 {
+		t.Fatalf("get(b) = %d, %v", k, err)
+	}
+
+	// known keys are served while the source is down
+	fail = true
+	c.fetched = time.Now().Add(-c.maxAge)
+	c.tried = time.Time{}
+	if k, err := c.get(ctx, "a"); err != nil || k != 1 {
+		t.Errorf("get(a) with the source down = %d, %v", k, err)
+	}
+	c.tried = time.Time{}
+	if _, err := c.get(ctx, "c"); err == nil || errors.Is(err, ErrInvalidToken) {
+		t.Errorf("get(c) with the source down = %v, want keys unavailable", err)
+	}
+	if n := calls.Load(); n != 4 {
+		t.Errorf("fetched %d times, want 4", n)
+	}
+}
+
+func TestKeyCacheConcurrentMiss(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	c := newKeyCache(func(context.Context) (map[string]int, error) {
+		calls.Add(1)
+		<-release
+		return map[string]int{"a": 1}, nil
+	})
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 10)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := c.get(context.Background(), "a"); err != nil {
+				errs <- err
+			}
+		}()
+	}
+	// the cache stays usable while the fetch runs
+	for {
+		c.mu.Lock()
+		running := c.refresh != nil
+		c.mu.Unlock()
+		if running {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := c.get(ctx, "a"); !errors.Is(err, context.Canceled) {
+		t.Errorf("get with a cancelled context = %v", err)
+	}
+	close(release)
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+	if n := calls.Load(); n != 1 {
+		t.Errorf("fetched %d times, want 1", n)
+	}
+}
@@ -0,0 +1,21 @@
+package auth
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+)
+
+// Static accepts a fixed set of tokens, each standing for one user id. It is
+// for development and tests, where no identity provider is at hand; never
+// use it in production.
+type Static map[string]string
+
+func (s Static) Authenticate(_ context.Context, token string) (string, error) {
+	for t, uid := range s {
+		if subtle.ConstantTimeCompare([]byte(t), []byte(token)) == 1 {
+			return uid, nil
+		}
+	}
+	return "", invalid(errors.New("unknown static token"))
+}
@@ -0,0 +1,34 @@
+package auth_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"budgex_backend/internal/auth"
+)
+
+func TestStatic(t *testing.T) {
+	a := auth.Static{"dev-token": "user_1", "other-token": "user_2"}
+	tests := []struct {
+		token, want string
+	}{
+		{"dev-token", "user_1"},
+		{"other-token", "user_2"},
+		{"dev-token ", ""},
+		{"dev", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		uid, err := a.Authenticate(context.Background(), tt.token)
+		if tt.want == "" {
+			if !errors.Is(err, auth.ErrInvalidToken) {
+				t.Errorf("Authenticate(%q) = %q, %v; want ErrInvalidToken", tt.token, uid, err)
+			}
+			continue
+		}
+		if err != nil || uid != tt.want {
+			t.Errorf("Authenticate(%q) = %q, %v; want %q", tt.token, uid, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package config_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"budgex_backend/internal/config"
+)
+
+func TestLoadIntegers(t *testing.T) {
+	tests := []struct {
+		name, key, value string
+		skew             time.Duration
+		err              string // "" when Load succeeds
+	}{
+		{"default", "AUTH_CLOCK_SKEW_SECONDS", "", time.Minute, ""},
+		{"zero skew", "AUTH_CLOCK_SKEW_SECONDS", "0", 0, ""},
+		{"skew", "AUTH_CLOCK_SKEW_SECONDS", " 5 ", 5 * time.Second, ""},
+		{"negative skew", "AUTH_CLOCK_SKEW_SECONDS", "-1", 0, "AUTH_CLOCK_SKEW_SECONDS must be an integer of at least 0"},
+		{"garbage", "AUTH_CLOCK_SKEW_SECONDS", "abc", 0, `got "abc"`},
+		{"trailing garbage", "AUTH_CLOCK_SKEW_SECONDS", "30s", 0, `got "30s"`},
+		{"zero port", "PORT", "0", time.Minute, "PORT must be an integer of at least 1"},
+		{"zero interval", "RECURRING_INTERVAL_SECONDS", "0", time.Minute, "RECURRING_INTERVAL_SECONDS must be an integer of at least 1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(tt.key, tt.value)
+			cfg, err := config.Load()
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("Load = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cfg.AuthClockSkew != tt.skew {
+				t.Errorf("AuthClockSkew = %v, want %v", cfg.AuthClockSkew, tt.skew)
+			}
+		})
+	}
+}
+
+func TestLoadWithoutAuthKeys(t *testing.T) {
+	// cmd/migrate loads the config too and has no use for auth keys
+	t.Setenv("AUTH_MODE", "")
+	t.Setenv("CLERK_SECRET_KEY", "")
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.AuthMode != config.AuthClerk {
+		t.Errorf("AuthMode = %q, want %q", cfg.AuthMode, config.AuthClerk)
+	}
+	if err := cfg.LoadAuth(); err == nil || !strings.Contains(err.Error(), "needs CLERK_SECRET_KEY") {
+		t.Errorf("LoadAuth = %v, want the missing key", err)
+	}
+}
+
+func TestLoadAuth(t *testing.T) {
+	tests := []struct {
+		name   string
+		env    map[string]string
+		mode   string
+		tokens map[string]string
+		err    string // "" when LoadAuth succeeds
+	}{
+		{
+			name: "clerk by default",
+			env:  map[string]string{"CLERK_SECRET_KEY": "sk_test"},
+			mode: config.AuthClerk,
+		},
+		{
+			name: "clerk without key",
+			env:  map[string]string{"AUTH_MODE": config.AuthClerk},
+			err:  "AUTH_MODE=clerk needs CLERK_SECRET_KEY",
+		},
+		{
+			name: "jwt",
+			env: map[string]string{
+				"AUTH_MODE": config.AuthJWT, "JWT_JWKS": "https://issuer.test/jwks.json",
+				"JWT_ISSUER": "https://issuer.test", "JWT_AUDIENCE": "budgex",
+			},
+			mode: config.AuthJWT,
+		},
+		{
+			name: "jwt without audience",
+			env: map[string]string{
+				"AUTH_MODE": config.AuthJWT, "JWT_JWKS": "jwks.json", "JWT_ISSUER": "https://issuer.test",
+			},
+			err: "AUTH_MODE=jwt needs JWT_JWKS, JWT_ISSUER and JWT_AUDIENCE",
+		},
+		{
+			name: "jwt without keys",
+			env:  map[string]string{"AUTH_MODE": config.AuthJWT, "JWT_ISSUER": "i", "JWT_AUDIENCE": "a"},
+			err:  "AUTH_MODE=jwt needs",
+		},
+		{
+			name:   "static",
+			env:    map[string]string{"AUTH_MODE": config.AuthStatic, "AUTH_STATIC_TOKENS": " dev = user_1 ,, ci=user_2,"},
+			mode:   config.AuthStatic,
+			tokens: map[string]string{"dev": "user_1", "ci": "user_2"},
+		},
+		{
+			name: "static without tokens",
+			env:  map[string]string{"AUTH_MODE": config.AuthStatic},
+			err:  "AUTH_MODE=static needs AUTH_STATIC_TOKENS",
+		},
+		{
+			name: "static with only separators",
+			env:  map[string]string{"AUTH_MODE": config.AuthStatic, "AUTH_STATIC_TOKENS": " , ,"},
+			err:  "AUTH_MODE=static needs AUTH_STATIC_TOKENS",
+		},
+		{
+			name: "static entry without user",
+			env:  map[string]string{"AUTH_MODE": config.AuthStatic, "AUTH_STATIC_TOKENS": "dev=user_1,ci="},
+			err:  "AUTH_STATIC_TOKENS: entry 2 is not token=user_id",
+		},
+		{
+			name: "static entry without token",
+			env:  map[string]string{"AUTH_MODE": config.AuthStatic, "AUTH_STATIC_TOKENS": "=user_1"},
+			err:  "AUTH_STATIC_TOKENS: entry 1 is not token=user_id",
+		},
+		{
+			name: "static entry without equals",
+			env:  map[string]string{"AUTH_MODE": config.AuthStatic, "AUTH_STATIC_TOKENS": "dev"},
+			err:  "AUTH_STATIC_TOKENS: entry 1 is not token=user_id",
+		},
+		{
+			name: "unknown mode",
+			env:  map[string]string{"AUTH_MODE": "basic"},
+			err:  `AUTH_MODE must be clerk, jwt or static, got "basic"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, k := range []string{"AUTH_MODE", "CLERK_SECRET_KEY", "JWT_JWKS", "JWT_ISSUER", "JWT_AUDIENCE", "AUTH_STATIC_TOKENS"} {
+				t.Setenv(k, tt.env[k])
+			}
+			cfg, err := config.Load()
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = cfg.LoadAuth()
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("LoadAuth = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cfg.AuthMode != tt.mode {
+				t.Errorf("AuthMode = %q, want %q", cfg.AuthMode, tt.mode)
+			}
+			if !reflect.DeepEqual(cfg.StaticTokens, tt.tokens) {
+				t.Errorf("StaticTokens = %v, want %v", cfg.StaticTokens, tt.tokens)
+			}
+		})
+	}
+}
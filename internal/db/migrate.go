@@ -0,0 +1,349 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Schema changes are plain SQL files in migrations/, embedded in the binary:
+// NNNN_name.up.sql applies a change and NNNN_name.down.sql reverts it. Applied
+// versions are recorded in schema_migrations. Up and Down hold a Postgres
+// advisory lock, so replicas starting together apply each migration once.
+//
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// MigrationsDir is where migration files live, relative to the repo root.
+const MigrationsDir = "internal/db/migrations"
+
+// migrationLock is the advisory lock key held while migrating.
+const migrationLock int64 = 0x62756467657801
+
+// minServerVersion is the oldest Postgres the migrations run on, as in
+// server_version_num: 0002 and 0004 use ON DELETE SET NULL (column), new in
+// Postgres 15.
+const minServerVersion = 150000
+
+var migrationFile = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// Migration is one versioned schema change.
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// MigrationStatus is a migration and whether it has been applied. Missing
+// marks a version recorded in the database that this binary has no file for.
+type MigrationStatus struct {
+	Version   int
+	Name      string
+	AppliedAt *time.Time
+	Missing   bool
+}
+
+// Migrations returns the embedded migrations in version order.
+func Migrations() ([]Migration, error) {
+	sub, err := fs.Sub(migrationFiles, "migrations")
+	if err != nil {
+		return nil, err
+	}
+	return LoadMigrations(sub)
+}
+
+// LoadMigrations reads NNNN_name.up.sql / .down.sql pairs from fsys. Every
+// version needs both files.
+func LoadMigrations(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+	byVersion := map[int]*Migration{}
+	for _, e := range entries {
+		m := migrationFile.FindStringSubmatch(e.Name())
+		if e.IsDir() || m == nil {
+			continue
+		}
+		v, _ := strconv.Atoi(m[1])
+		body, err := fs.ReadFile(fsys, e.Name())
+		if err != nil {
+			return nil, err
+		}
+		mig, ok := byVersion[v]
+		if !ok {
+			mig = &Migration{Version: v, Name: m[2]}
+			byVersion[v] = mig
+		} else if mig.Name != m[2] {
+			return nil, fmt.Errorf("migration %d has two names: %s and %s", v, mig.Name, m[2])
+		}
+		if m[3] == "up" {
+			mig.Up = string(body)
+		} else {
+			mig.Down = string(body)
+		}
+	}
+	out := make([]Migration, 0, len(byVersion))
+	for _, mig := range byVersion {
+		if mig.Up == "" || mig.Down == "" {
+			return nil, fmt.Errorf("migration %d_%s needs both an up and a down file", mig.Version, mig.Name)
+		}
+		out = append(out, *mig)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Version < out[j].Version })
+	return out, nil
+}
+
+// Migrator applies and reverts migrations.
+type Migrator struct {
+	db   *sql.DB
+	migs []Migration
+}
+
+// NewMigrator returns a Migrator over the embedded migrations.
+func NewMigrator(gdb *gorm.DB) (*Migrator, error) {
+	sqlDB, err := gdb.DB()
+	if err != nil {
+		return nil, err
+	}
+	migs, err := Migrations()
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: sqlDB, migs: migs}, nil
+}
+
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// applied reads schema_migrations; a database that was never migrated has
+// no table yet and nothing applied.
+func applied(ctx context.Context, q queryer) (map[int]MigrationStatus, error) {
+	var exists bool
+	if err := q.QueryRowContext(ctx, `SELECT to_regclass('schema_migrations') IS NOT NULL`).Scan(&exists); err != nil {
+		return nil, err
+	}
+	out := map[int]MigrationStatus{}
+	if !exists {
+		return out, nil
+	}
+	rows, err := q.QueryContext(ctx, `SELECT version, name, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var s MigrationStatus
+		var at time.Time
+		if err := rows.Scan(&s.Version, &s.Name, &at); err != nil {
+			return nil, err
+		}
+		s.AppliedAt = &at
+		out[s.Version] = s
+	}
+	return out, rows.Err()
+}
+
+// Status lists every known migration, oldest first, plus applied versions
+// that have no file.
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	done, err := applied(ctx, m.db)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]MigrationStatus, 0, len(m.migs))
+	for _, mig := range m.migs {
+		s := MigrationStatus{Version: mig.Version, Name: mig.Name}
+		if a, ok := done[mig.Version]; ok {
+			s.AppliedAt = a.AppliedAt
+			delete(done, mig.Version)
+		}
+		out = append(out, s)
+	}
+	for _, a := range done {
+		a.Missing = true
+		out = append(out, a)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Version < out[j].Version })
+	return out, nil
+}
+
+// Pending returns the migrations not applied yet, in the order Up applies
+// them.
+func (m *Migrator) Pending(ctx context.Context) ([]Migration, error) {
+	done, err := applied(ctx, m.db)
+	if err != nil {
+		return nil, err
+	}
+	return m.pending(done), nil
+}
+
+func (m *Migrator) pending(done map[int]MigrationStatus) []Migration {
+	var out []Migration
+	for _, mig := range m.migs {
+		if _, ok := done[mig.Version]; !ok {
+			out = append(out, mig)
+		}
+	}
+	return out
+}
+
+// Up applies every pending migration, each in its own transaction, and
+// returns the ones it applied. It stops at the first failure.
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	var ran []Migration
+	err := m.locked(ctx, func(conn *sql.Conn) error {
+		done, err := applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for _, mig := range m.pending(done) {
+			err := inTx(ctx, conn, func(tx *sql.Tx) error {
+				if _, err := tx.ExecContext(ctx, mig.Up); err != nil {
+					return err
+				}
+				_, err := tx.ExecContext(ctx,
+					`INSERT INTO schema_migrations (version, name) VALUES ($1, $2)`, mig.Version, mig.Name)
+				return err
+			})
+			if err != nil {
+				return fmt.Errorf("migration %d_%s: %w", mig.Version, mig.Name, err)
+			}
+			ran = append(ran, mig)
+		}
+		return nil
+	})
+	return ran, err
+}
+
+// Down reverts the n most recently applied migrations, newest first, and
+// returns the ones it reverted.
+func (m *Migrator) Down(ctx context.Context, n int) ([]Migration, error) {
+	byVersion := map[int]Migration{}
+	for _, mig := range m.migs {
+		byVersion[mig.Version] = mig
+	}
+	var ran []Migration
+	err := m.locked(ctx, func(conn *sql.Conn) error {
+		done, err := applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+		versions := make([]int, 0, len(done))
+		for v := range done {
+			versions = append(versions, v)
+		}
+		sort.Sort(sort.Reverse(sort.IntSlice(versions)))
+		if n > len(versions) {
+			n = len(versions)
+		}
+		for _, v := range versions[:n] {
+			mig, ok := byVersion[v]
+			if !ok {
+				return fmt.Errorf("migration %d_%s: no down file in this binary", v, done[v].Name)
+			}
+			err := inTx(ctx, conn, func(tx *sql.Tx) error {
+				if _, err := tx.ExecContext(ctx, mig.Down); err != nil {
+					return err
+				}
+				_, err := tx.ExecContext(ctx, `DELETE FROM schema_migrations WHERE version = $1`, v)
+				return err
+			})
+			if err != nil {
+				return fmt.Errorf("migration %d_%s: %w", mig.Version, mig.Name, err)
+			}
+			ran = append(ran, mig)
+		}
+		return nil
+	})
+	return ran, err
+}
+
+// locked runs fn on one connection while holding the migration lock. Other
+// callers wait for it, then see what it applied.
+func (m *Migrator) locked(ctx context.Context, fn func(*sql.Conn) error) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	var version int
+	if err := conn.QueryRowContext(ctx, `SELECT current_setting('server_version_num')::int`).Scan(&version); err != nil {
+		return err
+	}
+	if version < minServerVersion {
+		return fmt.Errorf("migrations need PostgreSQL 15 or later; the server is version %d", version)
+	}
+	if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, migrationLock); err != nil {
+		return err
+	}
+	// unlock even when ctx is cancelled, or the pooled session keeps the lock
+	defer conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1)`, migrationLock)
+
+	if _, err := conn.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+		  version    bigint PRIMARY KEY,
+		  name       text NOT NULL,
+		  applied_at timestamptz NOT NULL DEFAULT now()
+		)`); err != nil {
+		return err
+	}
+	return fn(conn)
+}
+
+// inTx runs fn in a transaction that row-level security does not restrict,
+// so data fixes in migrations see every user's rows.
+func inTx(ctx context.Context, conn *sql.Conn, fn func(*sql.Tx) error) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `SELECT set_config('app.rls_bypass', 'on', true)`); err != nil {
+		return errors.Join(err, tx.Rollback())
+	}
+	if err := fn(tx); err != nil {
+		return errors.Join(err, tx.Rollback())
+	}
+	return tx.Commit()
+}
+
+// ErrSchemaBehind is returned by CheckSchema when migrations are pending.
+var ErrSchemaBehind = errors.New("database schema is behind; run `go run ./cmd/migrate up`")
+
+// CheckSchema fails with ErrSchemaBehind when any embedded migration has not
+// been applied.
+func CheckSchema(ctx context.Context, gdb *gorm.DB) error {
+	m, err := NewMigrator(gdb)
+	if err != nil {
+		return err
+	}
+	pending, err := m.Pending(ctx)
+	if err != nil {
+		return err
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("%w (%d pending, first %d_%s)", ErrSchemaBehind, len(pending), pending[0].Version, pending[0].Name)
+	}
+	return nil
+}
+
+// Migrate applies pending migrations, for servers that migrate on start.
+func Migrate(ctx context.Context, gdb *gorm.DB) ([]Migration, error) {
+	m, err := NewMigrator(gdb)
+	if err != nil {
+		return nil, err
+	}
+	return m.Up(ctx)
+}
@@ -0,0 +1,314 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"budgex_backend/internal/models"
+	"budgex_backend/internal/money"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+func TestLoadMigrations(t *testing.T) {
+	fsys := fstest.MapFS{
+		"0002_add_note.up.sql":     {Data: []byte("ALTER TABLE t ADD COLUMN note text;")},
+		"0002_add_note.down.sql":   {Data: []byte("ALTER TABLE t DROP COLUMN note;")},
+		"0001_create_t.up.sql":     {Data: []byte("CREATE TABLE t (id int);")},
+		"0001_create_t.down.sql":   {Data: []byte("DROP TABLE t;")},
+		"README.md":                {Data: []byte("not a migration")},
+		"0003_Bad-Name.up.sql":     {Data: []byte("ignored")},
+		"0010_later.up.sql":        {Data: []byte("SELECT 10;")},
+		"0010_later.down.sql":      {Data: []byte("SELECT -10;")},
+		"nested/0004_x.up.sql":     {Data: []byte("ignored")},
+		"nested/0004_x.down.sql":   {Data: []byte("ignored")},
+		"0005_no_down.up.sql.orig": {Data: []byte("ignored")},
+	}
+	migs, err := LoadMigrations(fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, m := range migs {
+		got = append(got, fmt.Sprintf("%d_%s", m.Version, m.Name))
+	}
+	if strings.Join(got, " ") != "1_create_t 2_add_note 10_later" {
+		t.Errorf("migrations = %v", got)
+	}
+	if migs[1].Up != "ALTER TABLE t ADD COLUMN note text;" || migs[1].Down != "ALTER TABLE t DROP COLUMN note;" {
+		t.Errorf("2_add_note = %+v", migs[1])
+	}
+}
+
+func TestLoadMigrationsErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		fsys fstest.MapFS
+		want string
+	}{
+		{"no down", fstest.MapFS{"0001_a.up.sql": {Data: []byte("SELECT 1;")}}, "needs both an up and a down file"},
+		{"no up", fstest.MapFS{"0001_a.down.sql": {Data: []byte("SELECT 1;")}}, "needs both an up and a down file"},
+		{"two names", fstest.MapFS{
+			"0001_a.up.sql":   {Data: []byte("SELECT 1;")},
+			"0001_b.down.sql": {Data: []byte("SELECT 1;")},
+		}, "migration 1 has two names"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadMigrations(tt.fsys)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("LoadMigrations = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestEmbeddedMigrations(t *testing.T) {
+	migs, err := Migrations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, m := range migs {
+		if m.Version != i+1 {
+			t.Errorf("migration %d_%s out of sequence, want version %d", m.Version, m.Name, i+1)
+		}
+	}
+}
+
+// testMigrator returns a Migrator over migs in a scratch schema of the
+// database named by TEST_DATABASE_URL, so its schema_migrations is its own.
+func testMigrator(t *testing.T, migs ...Migration) *Migrator {
+	t.Helper()
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+	schema := "migrate_test_" + uuid.NewString()[:8]
+	admin, err := Connect(dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := admin.Exec("CREATE SCHEMA " + schema).Error; err != nil {
+		t.Fatal(err)
+	}
+	sep := "?"
+	if strings.Contains(dsn, "?") {
+		sep = "&"
+	}
+	gdb, err := Connect(dsn + sep + "search_path=" + schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, err := gdb.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		sqlDB.Close()
+		admin.Exec("DROP SCHEMA " + schema + " CASCADE")
+		if s, err := admin.DB(); err == nil {
+			s.Close()
+		}
+	})
+	return &Migrator{db: sqlDB, migs: migs}
+}
+
+func versions(migs []Migration) string {
+	out := make([]string, len(migs))
+	for i, m := range migs {
+		out[i] = fmt.Sprint(m.Version)
+	}
+	return strings.Join(out, ",")
+}
+
+func TestMigratorUpAndDown(t *testing.T) {
+	ctx := context.Background()
+	m := testMigrator(t,
+		Migration{Version: 1, Name: "create_t", Up: "CREATE TABLE t (id int);", Down: "DROP TABLE t;"},
+		Migration{Version: 2, Name: "add_note", Up: "ALTER TABLE t ADD COLUMN note text;", Down: "ALTER TABLE t DROP COLUMN note;"},
+	)
+	ran, err := m.Up(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if versions(ran) != "1,2" {
+		t.Errorf("Up ran %s, want 1,2", versions(ran))
+	}
+	// applied versions are skipped
+	if ran, err = m.Up(ctx); err != nil || len(ran) != 0 {
+		t.Errorf("second Up ran %s, %v, want nothing", versions(ran), err)
+	}
+	st, err := m.Status(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(st) != 2 || st[0].AppliedAt == nil || st[1].AppliedAt == nil {
+		t.Errorf("Status = %+v, want both applied", st)
+	}
+
+	if ran, err = m.Down(ctx, 1); err != nil || versions(ran) != "2" {
+		t.Fatalf("Down(1) = %s, %v, want 2", versions(ran), err)
+	}
+	pending, err := m.Pending(ctx)
+	if err != nil || versions(pending) != "2" {
+		t.Errorf("Pending = %s, %v, want 2", versions(pending), err)
+	}
+	if ran, err = m.Down(ctx, 5); err != nil || versions(ran) != "1" {
+		t.Errorf("Down(5) = %s, %v, want 1", versions(ran), err)
+	}
+}
+
+func TestMigratorUpRollsBackFailure(t *testing.T) {
+	ctx := context.Background()
+	m := testMigrator(t,
+		Migration{Version: 1, Name: "create_t", Up: "CREATE TABLE t (id int);", Down: "DROP TABLE t;"},
+		Migration{Version: 2, Name: "broken", Up: "CREATE TABLE u (id int); INSERT INTO missing VALUES (1);", Down: "DROP TABLE u;"},
+		Migration{Version: 3, Name: "after", Up: "CREATE TABLE v (id int);", Down: "DROP TABLE v;"},
+	)
+	ran, err := m.Up(ctx)
+	if err == nil || !strings.Contains(err.Error(), "migration 2_broken") {
+		t.Fatalf("Up = %v, want the error of 2_broken", err)
+	}
+	if versions(ran) != "1" {
+		t.Errorf("Up ran %s, want 1", versions(ran))
+	}
+	// nothing of the failed migration is left, and the next one did not run
+	var tables string
+	if err := m.db.QueryRowContext(ctx, `SELECT string_agg(table_name, ',' ORDER BY table_name)
+		FROM information_schema.tables WHERE table_schema = current_schema()`).Scan(&tables); err != nil {
+		t.Fatal(err)
+	}
+	if tables != "schema_migrations,t" {
+		t.Errorf("tables = %v, want schema_migrations,t", tables)
+	}
+	pending, err := m.Pending(ctx)
+	if err != nil || versions(pending) != "2,3" {
+		t.Errorf("Pending = %s, %v, want 2,3", versions(pending), err)
+	}
+}
+
+func TestMigratorLock(t *testing.T) {
+	ctx := context.Background()
+	// without the lock the second CREATE TABLE fails, or both record version 1
+	m := testMigrator(t, Migration{
+		Version: 1, Name: "slow",
+		Up:   "SELECT pg_sleep(0.2); CREATE TABLE t (id int);",
+		Down: "DROP TABLE t;",
+	})
+	var (
+		wg    sync.WaitGroup
+		mu    sync.Mutex
+		total int
+		errs  []error
+	)
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ran, err := m.Up(ctx)
+			mu.Lock()
+			defer mu.Unlock()
+			total += len(ran)
+			if err != nil {
+				errs = append(errs, err)
+			}
+		}()
+	}
+	wg.Wait()
+	if len(errs) > 0 || total != 1 {
+		t.Errorf("concurrent Up applied %d migrations, errors %v; want 1 and none", total, errs)
+	}
+}
+
+// TestCategoryKeys checks, against the database named by TEST_DATABASE_URL,
+// that the migrated schema refuses rows pointing into another household.
+func TestCategoryKeys(t *testing.T) {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+	ctx := context.Background()
+	gdb, err := Connect(dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Migrate(ctx, gdb); err != nil {
+		t.Fatal(err)
+	}
+	uid := "keys_" + uuid.NewString()
+	create := func(v any) error {
+		return AsSystem(ctx, gdb, func(tx *gorm.DB) error { return tx.Create(v).Error })
+	}
+	var own [2]models.HouseholdBase
+	var cats [2]models.Category
+	var txs [2]models.Transaction
+	for i := range own {
+		h := models.Household{Name: "Home", CreatedBy: uid}
+		if err := create(&h); err != nil {
+			t.Fatal(err)
+		}
+		own[i] = models.HouseholdBase{HouseholdID: h.ID, CreatedBy: uid}
+		cats[i] = models.Category{HouseholdBase: own[i], Name: "Food"}
+		if err := create(&cats[i]); err != nil {
+			t.Fatal(err)
+		}
+		txs[i] = models.Transaction{
+			HouseholdBase: own[i], Type: models.TxExpense, Date: time.Now(),
+			Amount: money.FromCents(100), Currency: "USD",
+		}
+		if err := create(&txs[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Cleanup(func() {
+		_ = AsSystem(ctx, gdb, func(tx *gorm.DB) error {
+			for _, m := range []any{&models.Budget{}, &models.TransactionSplit{}, &models.Transaction{}, &models.Category{}} {
+				tx.Unscoped().Where("created_by = ?", uid).Delete(m)
+			}
+			tx.Unscoped().Where("created_by = ?", uid).Delete(&models.Household{})
+			return nil
+		})
+	})
+
+	// every row lives in the first household and points into the second
+	a, b := own[0], own[1]
+	tests := []struct {
+		name string
+		row  any
+	}{
+		{"subcategory", &models.Category{HouseholdBase: a, Name: "Snacks", ParentID: &cats[1].ID}},
+		{"transaction", &models.Transaction{
+			HouseholdBase: a, Type: models.TxExpense, Date: time.Now(),
+			Amount: money.FromCents(100), Currency: "USD", CategoryID: &cats[1].ID,
+		}},
+		{"split category", &models.TransactionSplit{HouseholdBase: a, TransactionID: txs[0].ID, CategoryID: &cats[1].ID, Amount: money.FromCents(100)}},
+		{"split transaction", &models.TransactionSplit{HouseholdBase: a, TransactionID: txs[1].ID, Amount: money.FromCents(100)}},
+		{"budget", &models.Budget{HouseholdBase: a, Month: "2026-01", CategoryID: cats[1].ID, Amount: money.FromCents(100)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := create(tt.row); !errors.Is(err, gorm.ErrForeignKeyViolated) {
+				t.Errorf("insert = %v, want a foreign key violation", err)
+			}
+		})
+	}
+	// the same references within one household are fine
+	ok := []any{
+		&models.Category{HouseholdBase: b, Name: "Snacks", ParentID: &cats[1].ID},
+		&models.TransactionSplit{HouseholdBase: b, TransactionID: txs[1].ID, CategoryID: &cats[1].ID, Amount: money.FromCents(100)},
+		&models.Budget{HouseholdBase: b, Month: "2026-01", CategoryID: cats[1].ID, Amount: money.FromCents(100)},
+	}
+	for _, row := range ok {
+		if err := create(row); err != nil {
+			t.Errorf("insert %T = %v", row, err)
+		}
+	}
+}
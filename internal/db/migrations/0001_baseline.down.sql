@@ -0,0 +1,9 @@
+-- The baseline is the whole schema, data included; reverting it would drop
+-- every table. Refuse, so `migrate down` past 0002 cannot wipe a database.
+-- Drop the schema by hand if that is really meant.
+
+DO $$
+BEGIN
+  RAISE EXCEPTION 'migration 0001_baseline cannot be reverted: it would drop every table';
+END
+$$;
@@ -0,0 +1,269 @@
+-- Schema as it stood when migrations replaced AutoMigrate. Every statement is
+-- IF NOT EXISTS so databases created by AutoMigrate adopt it. Those may date
+-- from any earlier release, so the columns added to a table after it was
+-- first created are added again here before their indexes.
+
+CREATE EXTENSION IF NOT EXISTS pgcrypto;
+
+CREATE TABLE IF NOT EXISTS categories (
+  id              uuid DEFAULT gen_random_uuid(),
+  created_at      timestamptz,
+  updated_at      timestamptz,
+  deleted_at      timestamptz,
+  user_id         text NOT NULL,
+  name            text NOT NULL,
+  parent_id       text,
+  budget_rollover boolean NOT NULL DEFAULT false,
+  archived        boolean NOT NULL DEFAULT false,
+  PRIMARY KEY (id)
+);
+ALTER TABLE categories
+  ADD COLUMN IF NOT EXISTS budget_rollover boolean NOT NULL DEFAULT false,
+  ADD COLUMN IF NOT EXISTS archived        boolean NOT NULL DEFAULT false;
+CREATE INDEX IF NOT EXISTS idx_categories_parent_id ON categories (parent_id);
+CREATE INDEX IF NOT EXISTS idx_categories_user_id ON categories (user_id);
+CREATE INDEX IF NOT EXISTS idx_categories_deleted_at ON categories (deleted_at);
+
+CREATE TABLE IF NOT EXISTS transactions (
+  id                uuid DEFAULT gen_random_uuid(),
+  created_at        timestamptz,
+  updated_at        timestamptz,
+  deleted_at        timestamptz,
+  user_id           text NOT NULL,
+  type              text NOT NULL,
+  date              timestamptz,
+  amount            numeric(14,2) NOT NULL,
+  currency          char(3) NOT NULL DEFAULT 'USD',
+  payee             text,
+  memo              text,
+  category_id       uuid,
+  source            text DEFAULT 'manual',
+  tags              text,
+  account_id        uuid,
+  transfer_id       uuid,
+  import_batch_id   uuid,
+  recurring_rule_id uuid,
+  external_id       text,
+  PRIMARY KEY (id)
+);
+ALTER TABLE transactions
+  ADD COLUMN IF NOT EXISTS currency          char(3) NOT NULL DEFAULT 'USD',
+  ADD COLUMN IF NOT EXISTS account_id        uuid,
+  ADD COLUMN IF NOT EXISTS transfer_id       uuid,
+  ADD COLUMN IF NOT EXISTS import_batch_id   uuid,
+  ADD COLUMN IF NOT EXISTS recurring_rule_id uuid,
+  ADD COLUMN IF NOT EXISTS external_id       text;
+CREATE INDEX IF NOT EXISTS idx_transactions_recurring_rule_id ON transactions (recurring_rule_id);
+CREATE INDEX IF NOT EXISTS idx_transactions_import_batch_id ON transactions (import_batch_id);
+CREATE INDEX IF NOT EXISTS idx_transactions_transfer_id ON transactions (transfer_id);
+CREATE INDEX IF NOT EXISTS idx_transactions_account_id ON transactions (account_id);
+CREATE INDEX IF NOT EXISTS idx_transactions_category_id ON transactions (category_id);
+CREATE INDEX IF NOT EXISTS idx_transactions_date ON transactions (date);
+CREATE INDEX IF NOT EXISTS idx_transactions_user_id ON transactions (user_id);
+CREATE INDEX IF NOT EXISTS idx_transactions_deleted_at ON transactions (deleted_at);
+-- analytics
+CREATE INDEX IF NOT EXISTS idx_tx_user_date ON transactions (user_id, date);
+CREATE INDEX IF NOT EXISTS idx_tx_user_type_date ON transactions (user_id, type, date);
+CREATE INDEX IF NOT EXISTS idx_tx_user_cat_date ON transactions (user_id, category_id, date);
+CREATE INDEX IF NOT EXISTS idx_tx_account_date ON transactions (account_id, date, created_at, id) WHERE deleted_at IS NULL;
+-- re-importing an overlapping statement must not duplicate rows
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tx_user_external_id
+  ON transactions (user_id, external_id)
+  WHERE external_id IS NOT NULL AND deleted_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS budgets (
+  id          uuid DEFAULT gen_random_uuid(),
+  created_at  timestamptz,
+  updated_at  timestamptz,
+  deleted_at  timestamptz,
+  user_id     text NOT NULL,
+  month       char(7),
+  category_id uuid,
+  amount      numeric(14,2) NOT NULL,
+  PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_budgets_category_id ON budgets (category_id);
+CREATE INDEX IF NOT EXISTS idx_budgets_month ON budgets (month);
+CREATE INDEX IF NOT EXISTS idx_budgets_user_id ON budgets (user_id);
+CREATE INDEX IF NOT EXISTS idx_budgets_deleted_at ON budgets (deleted_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_budgets_user_month_category ON budgets (user_id, month, category_id);
+
+CREATE TABLE IF NOT EXISTS import_batches (
+  id             uuid DEFAULT gen_random_uuid(),
+  created_at     timestamptz,
+  updated_at     timestamptz,
+  deleted_at     timestamptz,
+  user_id        text NOT NULL,
+  source         text NOT NULL,
+  filename       text,
+  account_id     uuid,
+  status         text NOT NULL DEFAULT 'preview',
+  row_count      bigint,
+  error_count    bigint,
+  imported       bigint,
+  preview        jsonb,
+  committed_at   timestamptz,
+  rolled_back_at timestamptz,
+  PRIMARY KEY (id)
+);
+ALTER TABLE import_batches
+  ADD COLUMN IF NOT EXISTS account_id uuid;
+CREATE INDEX IF NOT EXISTS idx_import_batches_user_id ON import_batches (user_id);
+CREATE INDEX IF NOT EXISTS idx_import_batches_deleted_at ON import_batches (deleted_at);
+
+CREATE TABLE IF NOT EXISTS accounts (
+  id              uuid DEFAULT gen_random_uuid(),
+  created_at      timestamptz,
+  updated_at      timestamptz,
+  deleted_at      timestamptz,
+  user_id         text NOT NULL,
+  name            text NOT NULL,
+  kind            text NOT NULL DEFAULT 'checking',
+  currency        char(3) NOT NULL DEFAULT 'USD',
+  opening_balance numeric(14,2) NOT NULL DEFAULT 0,
+  archived        boolean NOT NULL DEFAULT false,
+  PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_accounts_user_id ON accounts (user_id);
+CREATE INDEX IF NOT EXISTS idx_accounts_deleted_at ON accounts (deleted_at);
+
+CREATE TABLE IF NOT EXISTS transaction_splits (
+  id             uuid DEFAULT gen_random_uuid(),
+  created_at     timestamptz,
+  updated_at     timestamptz,
+  deleted_at     timestamptz,
+  user_id        text NOT NULL,
+  transaction_id uuid NOT NULL,
+  category_id    uuid,
+  amount         numeric(14,2) NOT NULL,
+  memo           text,
+  PRIMARY KEY (id),
+  CONSTRAINT fk_transactions_splits FOREIGN KEY (transaction_id) REFERENCES transactions (id)
+);
+CREATE INDEX IF NOT EXISTS idx_transaction_splits_category_id ON transaction_splits (category_id);
+CREATE INDEX IF NOT EXISTS idx_transaction_splits_transaction_id ON transaction_splits (transaction_id);
+CREATE INDEX IF NOT EXISTS idx_transaction_splits_user_id ON transaction_splits (user_id);
+CREATE INDEX IF NOT EXISTS idx_transaction_splits_deleted_at ON transaction_splits (deleted_at);
+
+CREATE TABLE IF NOT EXISTS recurring_rules (
+  id                   uuid DEFAULT gen_random_uuid(),
+  created_at           timestamptz,
+  updated_at           timestamptz,
+  deleted_at           timestamptz,
+  user_id              text NOT NULL,
+  name                 text NOT NULL,
+  type                 text NOT NULL,
+  amount               numeric(14,2) NOT NULL,
+  payee                text,
+  memo                 text,
+  category_id          uuid,
+  account_id           uuid,
+  tags                 text,
+  freq                 text NOT NULL,
+  "interval"           bigint NOT NULL DEFAULT 1,
+  month_day            bigint NOT NULL DEFAULT 0,
+  start_date           date NOT NULL,
+  end_date             date,
+  count                bigint,
+  active               boolean NOT NULL DEFAULT true,
+  materialized_through date,
+  PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_recurring_rules_user_id ON recurring_rules (user_id);
+CREATE INDEX IF NOT EXISTS idx_recurring_rules_deleted_at ON recurring_rules (deleted_at);
+
+CREATE TABLE IF NOT EXISTS category_seeds (
+  user_id    text,
+  version    bigint NOT NULL,
+  locale     text NOT NULL,
+  created_at timestamptz,
+  updated_at timestamptz,
+  PRIMARY KEY (user_id)
+);
+
+CREATE TABLE IF NOT EXISTS rules (
+  id              uuid DEFAULT gen_random_uuid(),
+  created_at      timestamptz,
+  updated_at      timestamptz,
+  deleted_at      timestamptz,
+  user_id         text NOT NULL,
+  name            text NOT NULL,
+  priority        bigint NOT NULL DEFAULT 100,
+  active          boolean NOT NULL DEFAULT true,
+  stop            boolean NOT NULL DEFAULT false,
+  payee_contains  text,
+  payee_regex     text,
+  memo_contains   text,
+  memo_regex      text,
+  min_amount      numeric(14,2),
+  max_amount      numeric(14,2),
+  tx_type         text,
+  account_id      uuid,
+  set_category_id uuid,
+  add_tags        text,
+  set_payee       text,
+  set_memo        text,
+  PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_rules_user_id ON rules (user_id);
+CREATE INDEX IF NOT EXISTS idx_rules_deleted_at ON rules (deleted_at);
+
+CREATE TABLE IF NOT EXISTS user_settings (
+  user_id         text,
+  base_currency   char(3) NOT NULL DEFAULT 'USD',
+  timezone        text NOT NULL DEFAULT 'UTC',
+  week_start      bigint NOT NULL DEFAULT 1,
+  month_start_day bigint NOT NULL DEFAULT 1,
+  created_at      timestamptz,
+  updated_at      timestamptz,
+  PRIMARY KEY (user_id)
+);
+ALTER TABLE user_settings
+  ADD COLUMN IF NOT EXISTS timezone        text NOT NULL DEFAULT 'UTC',
+  ADD COLUMN IF NOT EXISTS week_start      bigint NOT NULL DEFAULT 1,
+  ADD COLUMN IF NOT EXISTS month_start_day bigint NOT NULL DEFAULT 1;
+
+CREATE TABLE IF NOT EXISTS fx_rates (
+  user_id    text,
+  date       date,
+  base       char(3),
+  quote      char(3),
+  rate       numeric(20,10) NOT NULL,
+  source     text NOT NULL,
+  created_at timestamptz,
+  updated_at timestamptz,
+  PRIMARY KEY (user_id, date, base, quote)
+);
+
+-- Amounts were double precision before money became numeric(14,2). Round to
+-- the cent, which is what the API always showed; columns already numeric are
+-- left alone.
+DO $$
+DECLARE
+  c       record;
+  inexact bigint;
+BEGIN
+  FOR c IN
+    SELECT table_name, column_name FROM information_schema.columns
+    WHERE table_schema = current_schema()
+      AND data_type = 'double precision'
+      AND (table_name, column_name) IN (
+        ('transactions', 'amount'),
+        ('transaction_splits', 'amount'),
+        ('budgets', 'amount'),
+        ('accounts', 'opening_balance'),
+        ('recurring_rules', 'amount'),
+        ('rules', 'min_amount'),
+        ('rules', 'max_amount'))
+  LOOP
+    EXECUTE format('SELECT count(*) FROM %I WHERE %I::numeric <> round(%I::numeric, 2)',
+      c.table_name, c.column_name, c.column_name) INTO inexact;
+    IF inexact > 0 THEN
+      RAISE NOTICE '%.% has % values with float noise beyond the cent; rounding',
+        c.table_name, c.column_name, inexact;
+    END IF;
+    EXECUTE format('ALTER TABLE %I ALTER COLUMN %I TYPE numeric(14,2) USING round(%I::numeric, 2)',
+      c.table_name, c.column_name, c.column_name);
+  END LOOP;
+END
+$$;
@@ -0,0 +1,13 @@
+-- Drops the keys. Repaired rows stay repaired; integrity_repairs is kept so
+-- the old values can still be looked up.
+
+ALTER TABLE budgets DROP CONSTRAINT IF EXISTS fk_budgets_category_user;
+ALTER TABLE transaction_splits DROP CONSTRAINT IF EXISTS fk_transaction_splits_category_user;
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS fk_transactions_category_user;
+ALTER TABLE categories DROP CONSTRAINT IF EXISTS fk_categories_parent_user;
+
+ALTER TABLE budgets ALTER COLUMN category_id DROP NOT NULL;
+
+ALTER TABLE categories DROP CONSTRAINT IF EXISTS chk_categories_parent_not_self;
+ALTER TABLE categories DROP CONSTRAINT IF EXISTS uq_categories_user_id_id;
+ALTER TABLE categories ALTER COLUMN parent_id TYPE text USING parent_id::text;
@@ -0,0 +1,106 @@
+-- Category references become foreign keys on (user_id, id), so a row can
+-- only point at a category of the same user. A key that sets the reference
+-- to NULL names just that column, leaving user_id alone; that form needs
+-- Postgres 15 or later, which the runner checks before migrating.
+-- Rows that would break the new keys are repaired first and each repair is
+-- recorded in integrity_repairs.
+
+CREATE TABLE IF NOT EXISTS integrity_repairs (
+  id          bigserial PRIMARY KEY,
+  repaired_at timestamptz NOT NULL DEFAULT now(),
+  migration   integer NOT NULL,
+  table_name  text NOT NULL,
+  row_id      uuid NOT NULL,
+  user_id     text NOT NULL,
+  column_name text NOT NULL,
+  old_value   text,
+  action      text NOT NULL -- set_null | deleted
+);
+
+-- categories.parent_id: text that is not a uuid, the category itself, or a
+-- category that is missing or belongs to someone else.
+WITH bad AS (
+  SELECT c.id, c.user_id, c.parent_id
+  FROM categories c
+  WHERE c.parent_id IS NOT NULL
+    AND (c.parent_id !~* '^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$'
+         OR lower(c.parent_id) = c.id::text
+         OR NOT EXISTS (SELECT 1 FROM categories p
+                        WHERE p.id::text = lower(c.parent_id) AND p.user_id = c.user_id))
+), logged AS (
+  INSERT INTO integrity_repairs (migration, table_name, row_id, user_id, column_name, old_value, action)
+  SELECT 2, 'categories', id, user_id, 'parent_id', parent_id, 'set_null' FROM bad
+  RETURNING row_id
+)
+UPDATE categories SET parent_id = NULL WHERE id IN (SELECT row_id FROM logged);
+
+ALTER TABLE categories ALTER COLUMN parent_id TYPE uuid USING parent_id::uuid;
+ALTER TABLE categories ADD CONSTRAINT uq_categories_user_id_id UNIQUE (user_id, id);
+ALTER TABLE categories ADD CONSTRAINT chk_categories_parent_not_self CHECK (parent_id <> id);
+
+-- transactions and split lines lose a category they cannot have.
+WITH bad AS (
+  SELECT t.id, t.user_id, t.category_id
+  FROM transactions t
+  WHERE t.category_id IS NOT NULL
+    AND NOT EXISTS (SELECT 1 FROM categories c WHERE c.id = t.category_id AND c.user_id = t.user_id)
+), logged AS (
+  INSERT INTO integrity_repairs (migration, table_name, row_id, user_id, column_name, old_value, action)
+  SELECT 2, 'transactions', id, user_id, 'category_id', category_id::text, 'set_null' FROM bad
+  RETURNING row_id
+)
+UPDATE transactions SET category_id = NULL WHERE id IN (SELECT row_id FROM logged);
+
+WITH bad AS (
+  SELECT s.id, s.user_id, s.category_id
+  FROM transaction_splits s
+  WHERE s.category_id IS NOT NULL
+    AND NOT EXISTS (SELECT 1 FROM categories c WHERE c.id = s.category_id AND c.user_id = s.user_id)
+), logged AS (
+  INSERT INTO integrity_repairs (migration, table_name, row_id, user_id, column_name, old_value, action)
+  SELECT 2, 'transaction_splits', id, user_id, 'category_id', category_id::text, 'set_null' FROM bad
+  RETURNING row_id
+)
+UPDATE transaction_splits SET category_id = NULL WHERE id IN (SELECT row_id FROM logged);
+
+-- a budget means nothing without its category, so orphans are deleted.
+WITH bad AS (
+  SELECT b.id, b.user_id, b.category_id
+  FROM budgets b
+  WHERE b.category_id IS NULL
+     OR NOT EXISTS (SELECT 1 FROM categories c WHERE c.id = b.category_id AND c.user_id = b.user_id)
+), logged AS (
+  INSERT INTO integrity_repairs (migration, table_name, row_id, user_id, column_name, old_value, action)
+  SELECT 2, 'budgets', id, user_id, 'category_id', category_id::text, 'deleted' FROM bad
+  RETURNING row_id
+)
+DELETE FROM budgets WHERE id IN (SELECT row_id FROM logged);
+
+ALTER TABLE budgets ALTER COLUMN category_id SET NOT NULL;
+
+-- Deleting a category moves its subcategories to the top level, leaves its
+-- transactions and split lines uncategorized, and deletes its budgets.
+ALTER TABLE categories
+  ADD CONSTRAINT fk_categories_parent_user
+    FOREIGN KEY (user_id, parent_id) REFERENCES categories (user_id, id) ON DELETE SET NULL (parent_id);
+
+ALTER TABLE transactions
+  ADD CONSTRAINT fk_transactions_category_user
+    FOREIGN KEY (user_id, category_id) REFERENCES categories (user_id, id) ON DELETE SET NULL (category_id);
+
+ALTER TABLE transaction_splits
+  ADD CONSTRAINT fk_transaction_splits_category_user
+    FOREIGN KEY (user_id, category_id) REFERENCES categories (user_id, id) ON DELETE SET NULL (category_id);
+
+ALTER TABLE budgets
+  ADD CONSTRAINT fk_budgets_category_user
+    FOREIGN KEY (user_id, category_id) REFERENCES categories (user_id, id) ON DELETE CASCADE;
+
+DO $$
+DECLARE r record;
+BEGIN
+  FOR r IN SELECT table_name, action, count(*) AS n FROM integrity_repairs
+           WHERE migration = 2 GROUP BY 1, 2 ORDER BY 1, 2 LOOP
+    RAISE NOTICE 'integrity repair: % % rows %', r.n, r.table_name, r.action;
+  END LOOP;
+END $$;
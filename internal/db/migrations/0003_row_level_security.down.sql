@@ -0,0 +1,17 @@
+DO $$
+DECLARE t regclass;
+BEGIN
+  FOREACH t IN ARRAY ARRAY[
+    'categories', 'transactions', 'transaction_splits', 'budgets',
+    'accounts', 'import_batches', 'recurring_rules', 'rules',
+    'category_seeds', 'user_settings', 'fx_rates'
+  ]::regclass[] LOOP
+    EXECUTE format('DROP POLICY IF EXISTS tenant_isolation ON %s', t);
+    EXECUTE format('ALTER TABLE %s NO FORCE ROW LEVEL SECURITY', t);
+    EXECUTE format('ALTER TABLE %s DISABLE ROW LEVEL SECURITY', t);
+  END LOOP;
+END $$;
+
+DROP FUNCTION IF EXISTS enable_tenant_rls(regclass);
+DROP FUNCTION IF EXISTS app_rls_bypass();
+DROP FUNCTION IF EXISTS app_user_id();
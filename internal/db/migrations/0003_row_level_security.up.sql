@@ -0,0 +1,39 @@
+-- Row-level security as a second tenant boundary. Every user-scoped table only
+-- shows, and only accepts, rows whose user_id matches the app.user_id setting
+-- of the current transaction. Requests set it (middleware.Tenant); jobs that
+-- work across users, such as the recurring worker and these migrations, set
+-- app.rls_bypass instead. With neither set the tables look empty.
+--
+-- FORCE makes the policies apply to the tables' owner as well. Superusers and
+-- roles with BYPASSRLS are never subject to them, so the server has to
+-- connect as an ordinary role for any of this to hold.
+
+CREATE OR REPLACE FUNCTION app_user_id() RETURNS text
+  LANGUAGE sql STABLE
+  AS $$ SELECT NULLIF(current_setting('app.user_id', true), '') $$;
+
+CREATE OR REPLACE FUNCTION app_rls_bypass() RETURNS boolean
+  LANGUAGE sql STABLE
+  AS $$ SELECT COALESCE(current_setting('app.rls_bypass', true), '') = 'on' $$;
+
+-- enable_tenant_rls puts a table with a user_id column behind the policy.
+-- Migrations that add such a table call it:
+--   SELECT enable_tenant_rls('things');
+CREATE OR REPLACE FUNCTION enable_tenant_rls(tbl regclass) RETURNS void
+  LANGUAGE plpgsql
+  AS $$
+BEGIN
+  EXECUTE format('ALTER TABLE %s ENABLE ROW LEVEL SECURITY', tbl);
+  EXECUTE format('ALTER TABLE %s FORCE ROW LEVEL SECURITY', tbl);
+  EXECUTE format('DROP POLICY IF EXISTS tenant_isolation ON %s', tbl);
+  EXECUTE format(
+    'CREATE POLICY tenant_isolation ON %s'
+    ' USING (app_rls_bypass() OR user_id = app_user_id())'
+    ' WITH CHECK (app_rls_bypass() OR user_id = app_user_id())', tbl);
+END $$;
+
+SELECT enable_tenant_rls(t) FROM unnest(ARRAY[
+  'categories', 'transactions', 'transaction_splits', 'budgets',
+  'accounts', 'import_batches', 'recurring_rules', 'rules',
+  'category_seeds', 'user_settings', 'fx_rates'
+]::regclass[]) AS t;
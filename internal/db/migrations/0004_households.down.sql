@@ -0,0 +1,79 @@
+-- Hands every row back to the user who created it. This fails once a
+-- household is shared and one member's rows point at another member's
+-- categories; households themselves are dropped.
+
+DROP TABLE household_invites;
+
+DO $$
+DECLARE t text;
+BEGIN
+  FOREACH t IN ARRAY ARRAY['categories', 'transactions', 'transaction_splits', 'budgets', 'recurring_rules', 'category_seeds'] LOOP
+    EXECUTE format('DROP POLICY IF EXISTS household_read ON %I', t);
+    EXECUTE format('DROP POLICY IF EXISTS household_write ON %I', t);
+  END LOOP;
+END $$;
+
+ALTER TABLE budgets DROP CONSTRAINT fk_budgets_category_household;
+ALTER TABLE transaction_splits
+  DROP CONSTRAINT fk_transaction_splits_transaction_household,
+  DROP CONSTRAINT fk_transaction_splits_category_household;
+ALTER TABLE transactions DROP CONSTRAINT fk_transactions_category_household;
+ALTER TABLE categories DROP CONSTRAINT fk_categories_parent_household;
+ALTER TABLE transactions DROP CONSTRAINT uq_transactions_household_id_id;
+ALTER TABLE categories DROP CONSTRAINT uq_categories_household_id_id;
+
+DROP INDEX idx_tx_household_cat_date;
+DROP INDEX idx_tx_household_type_date;
+DROP INDEX idx_tx_household_date;
+DROP INDEX idx_tx_household_external_id;
+DROP INDEX idx_budgets_household_month_category;
+
+ALTER TABLE category_seeds ADD COLUMN user_id text;
+UPDATE category_seeds s SET user_id = h.created_by FROM households h WHERE h.id = s.household_id;
+ALTER TABLE category_seeds DROP CONSTRAINT category_seeds_pkey;
+ALTER TABLE category_seeds DROP COLUMN household_id;
+ALTER TABLE category_seeds ADD PRIMARY KEY (user_id);
+
+DO $$
+DECLARE t text;
+BEGIN
+  FOREACH t IN ARRAY ARRAY['categories', 'transactions', 'transaction_splits', 'budgets', 'recurring_rules'] LOOP
+    EXECUTE format('ALTER TABLE %I RENAME COLUMN created_by TO user_id', t);
+    EXECUTE format('ALTER INDEX IF EXISTS %I RENAME TO %I', 'idx_' || t || '_created_by', 'idx_' || t || '_user_id');
+    EXECUTE format('ALTER TABLE %I DROP COLUMN household_id', t);
+  END LOOP;
+END $$;
+
+CREATE UNIQUE INDEX idx_budgets_user_month_category ON budgets (user_id, month, category_id);
+CREATE UNIQUE INDEX idx_tx_user_external_id
+  ON transactions (user_id, external_id)
+  WHERE external_id IS NOT NULL AND deleted_at IS NULL;
+CREATE INDEX idx_tx_user_date ON transactions (user_id, date);
+CREATE INDEX idx_tx_user_type_date ON transactions (user_id, type, date);
+CREATE INDEX idx_tx_user_cat_date ON transactions (user_id, category_id, date);
+
+ALTER TABLE categories ADD CONSTRAINT uq_categories_user_id_id UNIQUE (user_id, id);
+ALTER TABLE categories
+  ADD CONSTRAINT fk_categories_parent_user
+    FOREIGN KEY (user_id, parent_id) REFERENCES categories (user_id, id) ON DELETE SET NULL (parent_id);
+ALTER TABLE transactions
+  ADD CONSTRAINT fk_transactions_category_user
+    FOREIGN KEY (user_id, category_id) REFERENCES categories (user_id, id) ON DELETE SET NULL (category_id);
+ALTER TABLE transaction_splits
+  ADD CONSTRAINT fk_transaction_splits_category_user
+    FOREIGN KEY (user_id, category_id) REFERENCES categories (user_id, id) ON DELETE SET NULL (category_id);
+ALTER TABLE budgets
+  ADD CONSTRAINT fk_budgets_category_user
+    FOREIGN KEY (user_id, category_id) REFERENCES categories (user_id, id) ON DELETE CASCADE;
+
+SELECT enable_tenant_rls(t) FROM unnest(ARRAY[
+  'categories', 'transactions', 'transaction_splits', 'budgets',
+  'recurring_rules', 'category_seeds'
+]::regclass[]) AS t;
+
+DROP FUNCTION enable_household_rls(regclass);
+DROP TABLE household_members;
+DROP TABLE households;
+DROP FUNCTION app_owned_household_ids();
+DROP FUNCTION app_editable_household_ids();
+DROP FUNCTION app_household_ids();
@@ -0,0 +1,205 @@
+-- Households: budgets shared by several users. Categories, transactions and
+-- their split lines, budgets, recurring rules and the category seed record
+-- move from one user to one household; the user_id they carried becomes
+-- created_by and only says who added the row. Every existing user gets a
+-- household of their own that holds what they had.
+--
+-- Row-level security on those tables now follows membership: members read,
+-- owners and editors write. Membership rows are read through the SECURITY
+-- DEFINER functions below, which is why household_members is not FORCEd: its
+-- owner has to be able to read it from inside the policies of other tables.
+
+CREATE TABLE households (
+  id         uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+  created_at timestamptz,
+  updated_at timestamptz,
+  deleted_at timestamptz,
+  name       text NOT NULL,
+  created_by text NOT NULL
+);
+
+CREATE TABLE household_members (
+  household_id uuid NOT NULL REFERENCES households (id) ON DELETE CASCADE,
+  user_id      text NOT NULL,
+  role         text NOT NULL CHECK (role IN ('owner', 'editor', 'viewer')),
+  created_at   timestamptz,
+  updated_at   timestamptz,
+  PRIMARY KEY (household_id, user_id)
+);
+CREATE INDEX idx_household_members_user_id ON household_members (user_id);
+
+CREATE TABLE household_invites (
+  id           uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+  created_at   timestamptz,
+  household_id uuid NOT NULL REFERENCES households (id) ON DELETE CASCADE,
+  code         text NOT NULL UNIQUE,
+  role         text NOT NULL CHECK (role IN ('editor', 'viewer')),
+  created_by   text NOT NULL,
+  expires_at   timestamptz NOT NULL,
+  accepted_by  text,
+  accepted_at  timestamptz
+);
+CREATE INDEX idx_household_invites_household_id ON household_invites (household_id);
+
+-- one household per user who has anything stored
+INSERT INTO households (name, created_by, created_at, updated_at)
+SELECT 'Personal', u.user_id, now(), now()
+FROM (
+  SELECT user_id FROM categories
+  UNION SELECT user_id FROM transactions
+  UNION SELECT user_id FROM transaction_splits
+  UNION SELECT user_id FROM budgets
+  UNION SELECT user_id FROM recurring_rules
+  UNION SELECT user_id FROM category_seeds
+  UNION SELECT user_id FROM accounts
+  UNION SELECT user_id FROM rules
+  UNION SELECT user_id FROM import_batches
+  UNION SELECT user_id FROM user_settings
+  UNION SELECT user_id FROM fx_rates
+) u
+WHERE u.user_id IS NOT NULL;
+
+INSERT INTO household_members (household_id, user_id, role, created_at, updated_at)
+SELECT id, created_by, 'owner', now(), now() FROM households;
+
+-- The user-keyed category references of 0002 give way to household-keyed
+-- ones below.
+ALTER TABLE budgets DROP CONSTRAINT fk_budgets_category_user;
+ALTER TABLE transaction_splits DROP CONSTRAINT fk_transaction_splits_category_user;
+ALTER TABLE transactions DROP CONSTRAINT fk_transactions_category_user;
+ALTER TABLE categories DROP CONSTRAINT fk_categories_parent_user;
+ALTER TABLE categories DROP CONSTRAINT uq_categories_user_id_id;
+
+DROP INDEX IF EXISTS idx_budgets_user_month_category;
+DROP INDEX IF EXISTS idx_tx_user_external_id;
+DROP INDEX IF EXISTS idx_tx_user_date;
+DROP INDEX IF EXISTS idx_tx_user_type_date;
+DROP INDEX IF EXISTS idx_tx_user_cat_date;
+
+DO $$
+DECLARE t text;
+BEGIN
+  FOREACH t IN ARRAY ARRAY['categories', 'transactions', 'transaction_splits', 'budgets', 'recurring_rules'] LOOP
+    EXECUTE format('DROP POLICY IF EXISTS tenant_isolation ON %I', t);
+    EXECUTE format('ALTER TABLE %I ADD COLUMN household_id uuid', t);
+    EXECUTE format('UPDATE %I r SET household_id = h.id FROM households h WHERE h.created_by = r.user_id', t);
+    EXECUTE format('ALTER TABLE %I ALTER COLUMN household_id SET NOT NULL', t);
+    EXECUTE format('ALTER TABLE %I ADD CONSTRAINT %I FOREIGN KEY (household_id) REFERENCES households (id)',
+                   t, 'fk_' || t || '_household');
+    EXECUTE format('CREATE INDEX %I ON %I (household_id)', 'idx_' || t || '_household_id', t);
+    EXECUTE format('ALTER TABLE %I RENAME COLUMN user_id TO created_by', t);
+    EXECUTE format('ALTER INDEX IF EXISTS %I RENAME TO %I', 'idx_' || t || '_user_id', 'idx_' || t || '_created_by');
+  END LOOP;
+END $$;
+
+DROP POLICY IF EXISTS tenant_isolation ON category_seeds;
+ALTER TABLE category_seeds ADD COLUMN household_id uuid;
+UPDATE category_seeds s SET household_id = h.id FROM households h WHERE h.created_by = s.user_id;
+DELETE FROM category_seeds WHERE household_id IS NULL;
+ALTER TABLE category_seeds DROP CONSTRAINT category_seeds_pkey;
+ALTER TABLE category_seeds DROP COLUMN user_id;
+ALTER TABLE category_seeds
+  ADD PRIMARY KEY (household_id),
+  ADD CONSTRAINT fk_category_seeds_household
+    FOREIGN KEY (household_id) REFERENCES households (id) ON DELETE CASCADE;
+
+CREATE UNIQUE INDEX idx_budgets_household_month_category ON budgets (household_id, month, category_id);
+CREATE UNIQUE INDEX idx_tx_household_external_id
+  ON transactions (household_id, external_id)
+  WHERE external_id IS NOT NULL AND deleted_at IS NULL;
+-- analytics
+CREATE INDEX idx_tx_household_date ON transactions (household_id, date);
+CREATE INDEX idx_tx_household_type_date ON transactions (household_id, type, date);
+CREATE INDEX idx_tx_household_cat_date ON transactions (household_id, category_id, date);
+
+-- A row can only point at a category, or a split line at a transaction, of
+-- its own household. The category keys take over the actions of 0002.
+ALTER TABLE categories ADD CONSTRAINT uq_categories_household_id_id UNIQUE (household_id, id);
+ALTER TABLE transactions ADD CONSTRAINT uq_transactions_household_id_id UNIQUE (household_id, id);
+
+ALTER TABLE categories
+  ADD CONSTRAINT fk_categories_parent_household
+    FOREIGN KEY (household_id, parent_id) REFERENCES categories (household_id, id) ON DELETE SET NULL (parent_id);
+ALTER TABLE transactions
+  ADD CONSTRAINT fk_transactions_category_household
+    FOREIGN KEY (household_id, category_id) REFERENCES categories (household_id, id) ON DELETE SET NULL (category_id);
+ALTER TABLE transaction_splits
+  ADD CONSTRAINT fk_transaction_splits_category_household
+    FOREIGN KEY (household_id, category_id) REFERENCES categories (household_id, id) ON DELETE SET NULL (category_id),
+  ADD CONSTRAINT fk_transaction_splits_transaction_household
+    FOREIGN KEY (household_id, transaction_id) REFERENCES transactions (household_id, id);
+ALTER TABLE budgets
+  ADD CONSTRAINT fk_budgets_category_household
+    FOREIGN KEY (household_id, category_id) REFERENCES categories (household_id, id) ON DELETE CASCADE;
+
+-- Households the current user belongs to, may write to, and owns.
+CREATE FUNCTION app_household_ids() RETURNS SETOF uuid
+  LANGUAGE sql STABLE SECURITY DEFINER SET search_path FROM CURRENT
+  AS $$ SELECT household_id FROM household_members WHERE user_id = app_user_id() $$;
+
+CREATE FUNCTION app_editable_household_ids() RETURNS SETOF uuid
+  LANGUAGE sql STABLE SECURITY DEFINER SET search_path FROM CURRENT
+  AS $$ SELECT household_id FROM household_members
+        WHERE user_id = app_user_id() AND role IN ('owner', 'editor') $$;
+
+CREATE FUNCTION app_owned_household_ids() RETURNS SETOF uuid
+  LANGUAGE sql STABLE SECURITY DEFINER SET search_path FROM CURRENT
+  AS $$ SELECT household_id FROM household_members
+        WHERE user_id = app_user_id() AND role = 'owner' $$;
+
+-- enable_household_rls puts a table with a household_id column behind the
+-- membership policies. Migrations that add such a table call it:
+--   SELECT enable_household_rls('things');
+CREATE FUNCTION enable_household_rls(tbl regclass) RETURNS void
+  LANGUAGE plpgsql
+  AS $$
+BEGIN
+  EXECUTE format('ALTER TABLE %s ENABLE ROW LEVEL SECURITY', tbl);
+  EXECUTE format('ALTER TABLE %s FORCE ROW LEVEL SECURITY', tbl);
+  EXECUTE format('DROP POLICY IF EXISTS household_read ON %s', tbl);
+  EXECUTE format('DROP POLICY IF EXISTS household_write ON %s', tbl);
+  EXECUTE format(
+    'CREATE POLICY household_read ON %s FOR SELECT'
+    ' USING (app_rls_bypass() OR household_id IN (SELECT app_household_ids()))', tbl);
+  EXECUTE format(
+    'CREATE POLICY household_write ON %s'
+    ' USING (app_rls_bypass() OR household_id IN (SELECT app_editable_household_ids()))'
+    ' WITH CHECK (app_rls_bypass() OR household_id IN (SELECT app_editable_household_ids()))', tbl);
+END $$;
+
+SELECT enable_household_rls(t) FROM unnest(ARRAY[
+  'categories', 'transactions', 'transaction_splits', 'budgets',
+  'recurring_rules', 'category_seeds'
+]::regclass[]) AS t;
+
+-- Members see their households; owners change them. New households and
+-- redeemed invites are written with app.rls_bypass set.
+ALTER TABLE households ENABLE ROW LEVEL SECURITY;
+ALTER TABLE households FORCE ROW LEVEL SECURITY;
+CREATE POLICY household_read ON households FOR SELECT
+  USING (app_rls_bypass() OR id IN (SELECT app_household_ids()));
+CREATE POLICY household_write ON households
+  USING (app_rls_bypass() OR id IN (SELECT app_owned_household_ids()))
+  WITH CHECK (app_rls_bypass() OR id IN (SELECT app_owned_household_ids()));
+
+ALTER TABLE household_members ENABLE ROW LEVEL SECURITY;
+CREATE POLICY household_read ON household_members FOR SELECT
+  USING (app_rls_bypass() OR household_id IN (SELECT app_household_ids()));
+CREATE POLICY household_write ON household_members
+  USING (app_rls_bypass() OR household_id IN (SELECT app_owned_household_ids()))
+  WITH CHECK (app_rls_bypass() OR household_id IN (SELECT app_owned_household_ids()));
+CREATE POLICY household_leave ON household_members FOR DELETE
+  USING (user_id = app_user_id());
+
+ALTER TABLE household_invites ENABLE ROW LEVEL SECURITY;
+ALTER TABLE household_invites FORCE ROW LEVEL SECURITY;
+CREATE POLICY household_write ON household_invites
+  USING (app_rls_bypass() OR household_id IN (SELECT app_owned_household_ids()))
+  WITH CHECK (app_rls_bypass() OR household_id IN (SELECT app_owned_household_ids()));
+
+DO $$
+DECLARE n bigint;
+BEGIN
+  SELECT count(*) INTO n FROM households;
+  RAISE NOTICE 'households: created % personal households', n;
+END $$;
@@ -0,0 +1 @@
+ALTER TABLE import_batches DROP COLUMN household_id;
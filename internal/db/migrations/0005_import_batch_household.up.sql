@@ -0,0 +1,11 @@
+-- An import batch remembers the household its preview checked for
+-- duplicates, and its commit goes into that household only. Committed
+-- batches take the household of their transactions, others the first
+-- household their user joined.
+
+ALTER TABLE import_batches ADD COLUMN household_id uuid REFERENCES households (id);
+UPDATE import_batches b SET household_id = COALESCE(
+  (SELECT t.household_id FROM transactions t WHERE t.import_batch_id = b.id LIMIT 1),
+  (SELECT m.household_id FROM household_members m WHERE m.user_id = b.user_id
+   ORDER BY m.created_at LIMIT 1)
+);
@@ -0,0 +1,15 @@
+ALTER TABLE household_members NO FORCE ROW LEVEL SECURITY;
+
+CREATE OR REPLACE FUNCTION app_household_ids() RETURNS SETOF uuid
+  LANGUAGE sql STABLE SECURITY DEFINER SET search_path FROM CURRENT
+  AS $$ SELECT household_id FROM household_members WHERE user_id = app_user_id() $$;
+
+CREATE OR REPLACE FUNCTION app_editable_household_ids() RETURNS SETOF uuid
+  LANGUAGE sql STABLE SECURITY DEFINER SET search_path FROM CURRENT
+  AS $$ SELECT household_id FROM household_members
+        WHERE user_id = app_user_id() AND role IN ('owner', 'editor') $$;
+
+CREATE OR REPLACE FUNCTION app_owned_household_ids() RETURNS SETOF uuid
+  LANGUAGE sql STABLE SECURITY DEFINER SET search_path FROM CURRENT
+  AS $$ SELECT household_id FROM household_members
+        WHERE user_id = app_user_id() AND role = 'owner' $$;
@@ -0,0 +1,22 @@
+-- household_members is FORCEd like every other table, so the role that owns
+-- it is held to its policies too. The membership functions read it from
+-- inside those policies, and from inside other tables' policies; they set
+-- app.rls_bypass for their own query only, which also keeps the policies of
+-- household_members from calling back into them. Each still reads nothing
+-- but the current user's memberships.
+
+CREATE OR REPLACE FUNCTION app_household_ids() RETURNS SETOF uuid
+  LANGUAGE sql STABLE SECURITY DEFINER SET search_path FROM CURRENT SET app.rls_bypass = 'on'
+  AS $$ SELECT household_id FROM household_members WHERE user_id = app_user_id() $$;
+
+CREATE OR REPLACE FUNCTION app_editable_household_ids() RETURNS SETOF uuid
+  LANGUAGE sql STABLE SECURITY DEFINER SET search_path FROM CURRENT SET app.rls_bypass = 'on'
+  AS $$ SELECT household_id FROM household_members
+        WHERE user_id = app_user_id() AND role IN ('owner', 'editor') $$;
+
+CREATE OR REPLACE FUNCTION app_owned_household_ids() RETURNS SETOF uuid
+  LANGUAGE sql STABLE SECURITY DEFINER SET search_path FROM CURRENT SET app.rls_bypass = 'on'
+  AS $$ SELECT household_id FROM household_members
+        WHERE user_id = app_user_id() AND role = 'owner' $$;
+
+ALTER TABLE household_members FORCE ROW LEVEL SECURITY;
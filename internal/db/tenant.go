@@ -0,0 +1,95 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// Row-level security (migrations 0003 and 0004) limits every table to the
+// rows of the user named by the app.user_id setting, or of the households
+// that user belongs to. Both settings below are transaction-local, the
+// parameterised form of SET LOCAL, so they end with the transaction and never
+// reach the next user of a pooled connection.
+
+// SetUser scopes the transaction tx to uid's rows.
+func SetUser(tx *gorm.DB, uid string) error {
+	return tx.Exec(`SELECT set_config('app.user_id', ?, true)`, uid).Error
+}
+
+// SetSystem lets the transaction tx see and write every user's rows. It is
+// for jobs that work across users; requests use System.
+func SetSystem(tx *gorm.DB) error {
+	return tx.Exec(`SELECT set_config('app.rls_bypass', 'on', true)`).Error
+}
+
+// System runs fn in a savepoint of tx that row-level security does not
+// restrict. It is for the few steps of a request that reach past the caller's
+// households: creating one, redeeming an invite, and reading the transactions
+// booked on the caller's accounts in households they have left.
+func System(tx *gorm.DB, fn func(tx *gorm.DB) error) error {
+	return tx.Transaction(func(sp *gorm.DB) error {
+		if err := SetSystem(sp); err != nil {
+			return err
+		}
+		if err := fn(sp); err != nil {
+			return err // rolling back the savepoint also undoes the setting
+		}
+		return sp.Exec(`SELECT set_config('app.rls_bypass', 'off', true)`).Error
+	})
+}
+
+// AsUser runs fn in a transaction scoped to uid.
+func AsUser(ctx context.Context, gdb *gorm.DB, uid string, fn func(tx *gorm.DB) error) error {
+	return gdb.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := SetUser(tx, uid); err != nil {
+			return err
+		}
+		return fn(tx)
+	})
+}
+
+// AsSystem runs fn in a transaction that sees every user's rows.
+func AsSystem(ctx context.Context, gdb *gorm.DB, fn func(tx *gorm.DB) error) error {
+	return gdb.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := SetSystem(tx); err != nil {
+			return err
+		}
+		return fn(tx)
+	})
+}
+
+// CheckRLS lists what keeps row-level security from protecting anything: a
+// connection role that bypasses it, and tables with a user_id or household_id
+// column that have no policies.
+func CheckRLS(ctx context.Context, gdb *gorm.DB) ([]string, error) {
+	var warnings []string
+	var bypass bool
+	if err := gdb.WithContext(ctx).Raw(
+		`SELECT rolsuper OR rolbypassrls FROM pg_roles WHERE rolname = current_user`,
+	).Scan(&bypass).Error; err != nil {
+		return nil, err
+	}
+	if bypass {
+		warnings = append(warnings, "the database role is a superuser or has BYPASSRLS, so row-level security is not enforced")
+	}
+	var tables []string
+	if err := gdb.WithContext(ctx).Raw(`
+		SELECT c.relname FROM pg_class c
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		WHERE c.relkind = 'r' AND n.nspname = current_schema()
+		  AND c.relname <> 'integrity_repairs'
+		  AND NOT c.relrowsecurity
+		  AND EXISTS (SELECT 1 FROM pg_attribute a
+		              WHERE a.attrelid = c.oid AND NOT a.attisdropped
+		                AND a.attname IN ('user_id', 'household_id'))
+		ORDER BY 1`).Scan(&tables).Error; err != nil {
+		return nil, err
+	}
+	for _, t := range tables {
+		warnings = append(warnings, fmt.Sprintf("table %s is scoped to users or households but has no row-level security; "+
+			"call enable_tenant_rls('%s') or enable_household_rls('%s') in a migration", t, t, t))
+	}
+	return warnings, nil
+}
@@ -0,0 +1,138 @@
+// Package dedupe decides whether two transactions are likely the same real
+// payment entered twice, e.g. once by hand and once from a bank import.
+package dedupe
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Threshold is the payee similarity from which two payees count as the same.
+const Threshold = 0.6
+
+// minContained is the length, in letters, from which a payee found whole
+// inside another counts as the same merchant, so "he" does not match "shell".
+const minContained = 4
+
+// noise words banks put around the merchant name.
+var noise = map[string]bool{
+	"pos": true, "card": true, "purchase": true, "payment": true, "debit": true,
+	"credit": true, "visa": true, "mastercard": true, "mc": true, "ach": true,
+	"sepa": true, "online": true, "www": true, "com": true, "inc": true,
+	"ltd": true, "llc": true, "gmbh": true, "the": true, "ref": true,
+}
+
+// NormalizePayee lowercases a payee and keeps only its meaningful words:
+// digits, punctuation and bank noise such as "POS" or "CARD 1234" go away.
+func NormalizePayee(s string) string {
+	words := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+	out := words[:0]
+	for _, w := range words {
+		if len([]rune(w)) < 2 || noise[w] {
+			continue
+		}
+		out = append(out, w)
+	}
+	return strings.Join(out, " ")
+}
+
+// Similarity scores two payees from 0 to 1 using the Dice coefficient of the
+// character bigrams of their normalized forms. Payees without a meaningful
+// word score 0, even against each other: there is nothing to compare.
+func Similarity(a, b string) float64 {
+	a, b = NormalizePayee(a), NormalizePayee(b)
+	if a == "" || b == "" {
+		return 0
+	}
+	if a == b {
+		return 1
+	}
+	// "amazon" vs "amazon marketplace": one name containing the other is a match
+	if contains(a, b) || contains(b, a) {
+		return 1
+	}
+	ba, bb := bigrams(a), bigrams(b)
+	if len(ba) == 0 || len(bb) == 0 {
+		return 0
+	}
+	common := 0
+	for g, n := range ba {
+		if m, ok := bb[g]; ok {
+			common += min(n, m)
+		}
+	}
+	return 2 * float64(common) / float64(total(ba)+total(bb))
+}
+
+// SimilarPayees reports whether two payees likely name the same merchant.
+func SimilarPayees(a, b string) bool {
+	return Similarity(a, b) >= Threshold
+}
+
+// contains reports whether the words of sub appear together in s and are
+// long enough to mean something on their own.
+func contains(s, sub string) bool {
+	return len([]rune(sub)) >= minContained && strings.Contains(" "+s+" ", " "+sub+" ")
+}
+
+func bigrams(s string) map[string]int {
+	out := map[string]int{}
+	for _, w := range strings.Fields(s) {
+		r := []rune(w)
+		for i := 0; i+1 < len(r); i++ {
+			out[string(r[i:i+2])]++
+		}
+	}
+	return out
+}
+
+func total(m map[string]int) int {
+	n := 0
+	for _, v := range m {
+		n += v
+	}
+	return n
+}
+
+// Groups joins pairs of ids into connected groups, so that a, b and c end up
+// together when a~b and b~c. Groups come out in order of first appearance.
+func Groups(pairs [][2]string) [][]string {
+	parent := map[string]string{}
+	var order []string
+	var find func(string) string
+	find = func(x string) string {
+		p, ok := parent[x]
+		if !ok {
+			parent[x] = x
+			order = append(order, x)
+			return x
+		}
+		if p == x {
+			return x
+		}
+		root := find(p)
+		parent[x] = root
+		return root
+	}
+	for _, p := range pairs {
+		ra, rb := find(p[0]), find(p[1])
+		if ra != rb {
+			parent[rb] = ra
+		}
+	}
+	idx := map[string]int{}
+	var out [][]string
+	for _, id := range order {
+		root := find(id)
+		i, ok := idx[root]
+		if !ok {
+			i = len(out)
+			idx[root] = i
+			out = append(out, nil)
+		}
+		out[i] = append(out[i], id)
+	}
+	return out
+}
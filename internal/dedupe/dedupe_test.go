@@ -0,0 +1,84 @@
+package dedupe_test
+
+import (
+	"reflect"
+	"testing"
+
+	"budgex_backend/internal/dedupe"
+)
+
+func TestNormalizePayee(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"", ""},
+		{"Amazon", "amazon"},
+		{"POS PURCHASE AMAZON.COM 1234", "amazon"},
+		{"CARD 5521 Starbucks #0042", "starbucks"},
+		{"Müller GmbH", "müller"},
+		{"The Coffee-House Ltd.", "coffee house"},
+		{"A & B Ltd", ""},
+		{"VISA 4455 00012", ""},
+	}
+	for _, tt := range tests {
+		if got := dedupe.NormalizePayee(tt.in); got != tt.want {
+			t.Errorf("NormalizePayee(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestSimilarPayees(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"Amazon", "AMAZON", true},
+		{"POS AMAZON 1234", "amazon.com", true},
+		{"Amazon", "Amazon Marketplace", true},
+		{"Starbucks", "Starbucks Coffee #42", true},
+		{"Starbuck", "Starbucks", true}, // close enough by bigrams
+		{"Netflix", "Spotify", false},
+		{"Shell", "Esso", false},
+		// short names only match whole
+		{"he", "shell", false},
+		{"Bp", "Bp", true},
+		{"Bp", "Bp Station", false},
+		// a payee without a meaningful word says nothing
+		{"", "", false},
+		{"", "Amazon", false},
+		{"CARD 1234", "POS 5678", false},
+		{"CARD 1234", "CARD 1234", false},
+	}
+	for _, tt := range tests {
+		if got := dedupe.SimilarPayees(tt.a, tt.b); got != tt.want {
+			t.Errorf("SimilarPayees(%q, %q) = %v (%.2f), want %v", tt.a, tt.b, got, dedupe.Similarity(tt.a, tt.b), tt.want)
+		}
+		if got := dedupe.SimilarPayees(tt.b, tt.a); got != tt.want {
+			t.Errorf("SimilarPayees(%q, %q) = %v, want %v", tt.b, tt.a, got, tt.want)
+		}
+	}
+}
+
+func TestGroups(t *testing.T) {
+	tests := []struct {
+		name  string
+		pairs [][2]string
+		want  [][]string
+	}{
+		{"none", nil, nil},
+		{"one pair", [][2]string{{"a", "b"}}, [][]string{{"a", "b"}}},
+		{"chain", [][2]string{{"a", "b"}, {"b", "c"}}, [][]string{{"a", "b", "c"}}},
+		{"separate", [][2]string{{"a", "b"}, {"c", "d"}}, [][]string{{"a", "b"}, {"c", "d"}}},
+		{
+			"joined later",
+			[][2]string{{"a", "b"}, {"c", "d"}, {"d", "b"}, {"e", "f"}},
+			[][]string{{"a", "b", "c", "d"}, {"e", "f"}},
+		},
+		{"repeated pair", [][2]string{{"a", "b"}, {"b", "a"}, {"a", "b"}}, [][]string{{"a", "b"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := dedupe.Groups(tt.pairs); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Groups = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,270 @@
+// Package fx converts amounts between currencies with the exchange rates a
+// user has uploaded.
+//
+// A rate for a day is the latest one on or before it, at most MaxRateAge old,
+// so weekends and bank holidays use the last business day. A pair without a
+// rate of its own is derived from its inverse or through a third currency,
+// which is how EUR-based ECB rates cover USD/LKR.
+package fx
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"budgex_backend/internal/models"
+	"budgex_backend/internal/money"
+	"budgex_backend/internal/settings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// MaxRateAge is how far back a conversion looks for a rate.
+const MaxRateAge = 7 * 24 * time.Hour
+
+// Sources of uploaded rates.
+const (
+	SourceCSV = "csv"
+	SourceECB = "ecb"
+)
+
+// NormalizeCode upper-cases an ISO 4217 code and reports whether it looks like
+// one.
+func NormalizeCode(s string) (string, bool) {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	if len(s) != 3 {
+		return "", false
+	}
+	for _, r := range s {
+		if r < 'A' || r > 'Z' {
+			return "", false
+		}
+	}
+	return s, true
+}
+
+// BaseCurrency returns the user's base currency.
+func BaseCurrency(ctx context.Context, db *gorm.DB, uid string) (string, error) {
+	s, err := settings.Load(ctx, db, uid)
+	if err != nil {
+		return "", err
+	}
+	return s.BaseCurrency, nil
+}
+
+// TxCurrency is the currency a new transaction is recorded in: its account's,
+// or the user's base currency when it has no account.
+func TxCurrency(ctx context.Context, db *gorm.DB, uid string, accountID *string) (string, error) {
+	if accountID == nil {
+		return BaseCurrency(ctx, db, uid)
+	}
+	var cur string
+	err := db.WithContext(ctx).Model(&models.Account{}).
+		Where("id = ? AND user_id = ?", *accountID, uid).
+		Pluck("currency", &cur).Error
+	if err != nil {
+		return "", err
+	}
+	if cur == "" {
+		return BaseCurrency(ctx, db, uid)
+	}
+	return cur, nil
+}
+
+// Save upserts rates for a user; a later upload of the same day and pair
+// replaces the earlier rate. It returns how many distinct rates were written.
+func Save(ctx context.Context, db *gorm.DB, uid, source string, rates []models.FxRate) (int, error) {
+	// one INSERT cannot upsert the same key twice, so the last row of a
+	// repeated day and pair wins
+	idx := map[string]int{}
+	var rows []models.FxRate
+	for _, r := range rates {
+		r.UserID, r.Source = uid, source
+		k := r.Date.Format("2006-01-02") + r.Base + r.Quote
+		if i, ok := idx[k]; ok {
+			rows[i] = r
+			continue
+		}
+		idx[k] = len(rows)
+		rows = append(rows, r)
+	}
+	if len(rows) == 0 {
+		return 0, nil
+	}
+	err := db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "date"}, {Name: "base"}, {Name: "quote"}},
+		DoUpdates: clause.AssignmentColumns([]string{"rate", "source", "updated_at"}),
+	}).CreateInBatches(&rows, 500).Error
+	return len(rows), err
+}
+
+// MissingRateError is returned when no rate converts From to To on Date.
+type MissingRateError struct {
+	From, To string
+	Date     time.Time
+}
+
+func (e *MissingRateError) Error() string {
+	return fmt.Sprintf("fx: no %s/%s rate for %s", e.From, e.To, e.Date.Format("2006-01-02"))
+}
+
+// Used describes a rate a Converter applied.
+type Used struct {
+	From string  `json:"from"`
+	To   string  `json:"to"`
+	Date string  `json:"date"` // day the rate was published (YYYY-MM-DD)
+	Rate float64 `json:"rate"` // 1 From = Rate To
+	Via  string  `json:"via,omitempty"`
+}
+
+type pair struct{ base, quote string }
+
+type quote struct {
+	date time.Time
+	rate float64
+}
+
+// Converter converts amounts into one currency and remembers the rates it
+// used. It is not safe for concurrent use.
+type Converter struct {
+	to     string
+	rates  map[pair][]quote // ascending by date
+	pivots []string
+	memo   map[string]found
+	used   map[Used]bool
+}
+
+type found struct {
+	rate float64
+	use  Used
+}
+
+// Load reads the user's rates that can apply to days in [from, to] and returns
+// a Converter into cur.
+func Load(ctx context.Context, db *gorm.DB, uid, cur string, from, to time.Time) (*Converter, error) {
+	var rows []models.FxRate
+	if err := db.WithContext(ctx).
+		Where("user_id = ? AND date >= ? AND date <= ?", uid, from.Add(-MaxRateAge), to).
+		Order("date").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	return New(cur, rows), nil
+}
+
+// New returns a Converter into cur over the given rates.
+func New(cur string, rates []models.FxRate) *Converter {
+	cv := &Converter{to: cur, rates: map[pair][]quote{}, memo: map[string]found{}, used: map[Used]bool{}}
+	seen := map[string]bool{}
+	for _, r := range rates {
+		if r.Rate <= 0 {
+			continue
+		}
+		p := pair{r.Base, r.Quote}
+		cv.rates[p] = append(cv.rates[p], quote{day(r.Date), r.Rate})
+		for _, c := range []string{r.Base, r.Quote} {
+			if !seen[c] {
+				seen[c] = true
+				cv.pivots = append(cv.pivots, c)
+			}
+		}
+	}
+	for _, qs := range cv.rates {
+		sort.Slice(qs, func(i, j int) bool { return qs[i].date.Before(qs[j].date) })
+	}
+	sort.Strings(cv.pivots)
+	return cv
+}
+
+// Currency is the currency the Converter converts into.
+func (cv *Converter) Currency() string { return cv.to }
+
+// Convert returns a, held in cur on day d, in the Converter's currency,
+// rounded to the cent.
+func (cv *Converter) Convert(a money.Amount, cur string, d time.Time) (money.Amount, error) {
+	if cur == cv.to || a == 0 {
+		return a, nil
+	}
+	d = day(d)
+	key := cur + d.Format("2006-01-02")
+	f, ok := cv.memo[key]
+	if !ok {
+		if f, ok = cv.find(cur, d); !ok {
+			return 0, &MissingRateError{From: cur, To: cv.to, Date: d}
+		}
+		cv.memo[key] = f
+	}
+	cv.used[f.use] = true
+	return money.FromFloat(a.Float64() * f.rate), nil
+}
+
+// Used lists the rates applied so far, oldest first.
+func (cv *Converter) Used() []Used {
+	out := make([]Used, 0, len(cv.used))
+	for u := range cv.used {
+		out = append(out, u)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Date != out[j].Date {
+			return out[i].Date < out[j].Date
+		}
+		return out[i].From < out[j].From
+	})
+	return out
+}
+
+// find picks the rate from cur into the target on d: a direct or inverse
+// quote if there is one, else a cross rate through a pivot currency. The
+// cross rate is dated by the older of its two legs.
+func (cv *Converter) find(cur string, d time.Time) (found, bool) {
+	if r, on, ok := cv.direct(cur, cv.to, d); ok {
+		return found{r, Used{From: cur, To: cv.to, Date: on.Format("2006-01-02"), Rate: r}}, true
+	}
+	for _, p := range cv.pivots {
+		if p == cur || p == cv.to {
+			continue
+		}
+		r1, on1, ok := cv.direct(cur, p, d)
+		if !ok {
+			continue
+		}
+		r2, on2, ok := cv.direct(p, cv.to, d)
+		if !ok {
+			continue
+		}
+		on := on1
+		if on2.Before(on) {
+			on = on2
+		}
+		r := r1 * r2
+		return found{r, Used{From: cur, To: cv.to, Date: on.Format("2006-01-02"), Rate: r, Via: p}}, true
+	}
+	return found{}, false
+}
+
+// direct finds a quote for from/to or to/from valid on d.
+func (cv *Converter) direct(from, to string, d time.Time) (float64, time.Time, bool) {
+	if q, ok := latest(cv.rates[pair{from, to}], d); ok {
+		return q.rate, q.date, true
+	}
+	if q, ok := latest(cv.rates[pair{to, from}], d); ok {
+		return 1 / q.rate, q.date, true
+	}
+	return 0, time.Time{}, false
+}
+
+// latest returns the last quote on or before d that is at most MaxRateAge old.
+func latest(qs []quote, d time.Time) (quote, bool) {
+	i := sort.Search(len(qs), func(i int) bool { return qs[i].date.After(d) })
+	if i == 0 || d.Sub(qs[i-1].date) > MaxRateAge {
+		return quote{}, false
+	}
+	return qs[i-1], true
+}
+
+func day(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,173 @@
+package fx_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"budgex_backend/internal/fx"
+	"budgex_backend/internal/models"
+	"budgex_backend/internal/money"
+)
+
+func day(s string) time.Time {
+	t, err := time.Parse(time.DateOnly, s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// rates are ECB-style: everything against EUR, on business days only.
+var rates = []models.FxRate{
+	{Date: day("2024-03-01"), Base: "EUR", Quote: "USD", Rate: 1.10}, // Friday
+	{Date: day("2024-03-01"), Base: "EUR", Quote: "LKR", Rate: 330},
+	{Date: day("2024-03-04"), Base: "EUR", Quote: "USD", Rate: 1.25}, // Monday
+	{Date: day("2024-03-04"), Base: "EUR", Quote: "GBP", Rate: 0},    // ignored
+}
+
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		name   string
+		to     string
+		amount money.Amount
+		cur    string
+		on     string
+		want   money.Amount
+		used   fx.Used
+	}{
+		{
+			"direct", "LKR", 1000, "EUR", "2024-03-01", 330000,
+			fx.Used{From: "EUR", To: "LKR", Date: "2024-03-01", Rate: 330},
+		},
+		{
+			"inverse", "EUR", 330000, "LKR", "2024-03-01", 1000,
+			fx.Used{From: "LKR", To: "EUR", Date: "2024-03-01", Rate: 1.0 / 330},
+		},
+		{
+			"cross rate through the pivot", "LKR", 1100, "USD", "2024-03-01", 330000,
+			fx.Used{From: "USD", To: "LKR", Date: "2024-03-01", Rate: 330 / 1.10, Via: "EUR"},
+		},
+		{
+			"weekend uses Friday's rate", "USD", 1000, "EUR", "2024-03-03", 1100,
+			fx.Used{From: "EUR", To: "USD", Date: "2024-03-01", Rate: 1.10},
+		},
+		{
+			"latest rate on or before the day", "USD", 1000, "EUR", "2024-03-05", 1250,
+			fx.Used{From: "EUR", To: "USD", Date: "2024-03-04", Rate: 1.25},
+		},
+		{
+			"cross rate is dated by its older leg", "LKR", 1250, "USD", "2024-03-05", 330000,
+			fx.Used{From: "USD", To: "LKR", Date: "2024-03-01", Rate: 330 / 1.25, Via: "EUR"},
+		},
+		{
+			"rate exactly MaxRateAge old", "LKR", 1000, "EUR", "2024-03-08", 330000,
+			fx.Used{From: "EUR", To: "LKR", Date: "2024-03-01", Rate: 330},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cv := fx.New(tt.to, rates)
+			got, err := cv.Convert(tt.amount, tt.cur, day(tt.on).Add(15*time.Hour))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("Convert = %s, want %s", got, tt.want)
+			}
+			used := cv.Used()
+			if len(used) != 1 {
+				t.Fatalf("Used = %+v, want one rate", used)
+			}
+			u := used[0]
+			if diff := u.Rate - tt.used.Rate; diff > 1e-9 || diff < -1e-9 {
+				t.Errorf("rate = %v, want %v", u.Rate, tt.used.Rate)
+			}
+			u.Rate = tt.used.Rate
+			if u != tt.used {
+				t.Errorf("Used = %+v, want %+v", u, tt.used)
+			}
+		})
+	}
+}
+
+func TestConvertMissingRate(t *testing.T) {
+	tests := []struct {
+		name string
+		to   string
+		cur  string
+		on   string
+	}{
+		{"older than MaxRateAge", "LKR", "EUR", "2024-03-09"},
+		{"before the first rate", "USD", "EUR", "2024-02-29"},
+		{"unknown currency", "EUR", "JPY", "2024-03-01"},
+		{"zero rates are ignored", "GBP", "EUR", "2024-03-04"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := fx.New(tt.to, rates).Convert(1000, tt.cur, day(tt.on))
+			var missing *fx.MissingRateError
+			if !errors.As(err, &missing) {
+				t.Fatalf("Convert = %v, want a MissingRateError", err)
+			}
+			if missing.From != tt.cur || missing.To != tt.to || !missing.Date.Equal(day(tt.on)) {
+				t.Errorf("error = %+v", missing)
+			}
+		})
+	}
+}
+
+func TestConvertNeedsNoRate(t *testing.T) {
+	cv := fx.New("EUR", nil)
+	if got, err := cv.Convert(1234, "EUR", day("2024-03-01")); err != nil || got != 1234 {
+		t.Errorf("same currency = %s, %v", got, err)
+	}
+	if got, err := cv.Convert(0, "USD", day("2024-03-01")); err != nil || got != 0 {
+		t.Errorf("zero = %s, %v", got, err)
+	}
+	if used := cv.Used(); len(used) != 0 {
+		t.Errorf("Used = %+v, want none", used)
+	}
+}
+
+func TestUsedOrder(t *testing.T) {
+	cv := fx.New("LKR", rates)
+	for _, c := range []struct{ cur, on string }{
+		{"USD", "2024-03-05"}, {"EUR", "2024-03-05"}, {"EUR", "2024-03-02"}, {"EUR", "2024-03-01"},
+	} {
+		if _, err := cv.Convert(100, c.cur, day(c.on)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var got [][2]string
+	for _, u := range cv.Used() {
+		got = append(got, [2]string{u.Date, u.From})
+	}
+	// the same rate on several days is listed once
+	want := [][2]string{{"2024-03-01", "EUR"}, {"2024-03-01", "USD"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Used = %v, want %v", got, want)
+	}
+}
+
+func TestNormalizeCode(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+		ok   bool
+	}{
+		{"usd", "USD", true},
+		{" LKR ", "LKR", true},
+		{"US", "", false},
+		{"EURO", "", false},
+		{"U$D", "", false},
+		{"", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := fx.NormalizeCode(tt.in)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("NormalizeCode(%q) = %q, %v, want %q, %v", tt.in, got, ok, tt.want, tt.ok)
+		}
+	}
+}
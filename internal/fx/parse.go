@@ -0,0 +1,124 @@
+package fx
+
+import (
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"budgex_backend/internal/models"
+)
+
+// ParseCSV reads rates from a CSV file with a header row naming the columns
+// date (YYYY-MM-DD), base, quote and rate, in any order; other columns are
+// ignored. Each row means 1 base = rate quote on date.
+func ParseCSV(r io.Reader) ([]models.FxRate, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("read header: %w", err)
+	}
+	col := map[string]int{}
+	for i, h := range header {
+		col[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(h, "\ufeff")))] = i
+	}
+	for _, name := range []string{"date", "base", "quote", "rate"} {
+		if _, ok := col[name]; !ok {
+			return nil, fmt.Errorf("missing %q column", name)
+		}
+	}
+
+	var out []models.FxRate
+	for line := 2; ; line++ {
+		rec, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		field := func(name string) string {
+			if i := col[name]; i < len(rec) {
+				return strings.TrimSpace(rec[i])
+			}
+			return ""
+		}
+		if strings.Join(rec, "") == "" {
+			continue
+		}
+		d, err := time.Parse("2006-01-02", field("date"))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: date must be YYYY-MM-DD", line)
+		}
+		rate, err := newRate(d, field("base"), field("quote"), field("rate"))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		out = append(out, rate)
+	}
+	return out, nil
+}
+
+// ecbEnvelope is the shape of the ECB euro reference rate files
+// (eurofxref-daily.xml, eurofxref-hist-90d.xml, eurofxref-hist.xml).
+type ecbEnvelope struct {
+	Days []struct {
+		Time  string `xml:"time,attr"`
+		Rates []struct {
+			Currency string `xml:"currency,attr"`
+			Rate     string `xml:"rate,attr"`
+		} `xml:"Cube"`
+	} `xml:"Cube>Cube"`
+}
+
+// ParseECB reads an ECB euro foreign exchange reference rate file. All of its
+// rates have EUR as base.
+func ParseECB(r io.Reader) ([]models.FxRate, error) {
+	var env ecbEnvelope
+	if err := xml.NewDecoder(r).Decode(&env); err != nil {
+		return nil, err
+	}
+	if len(env.Days) == 0 {
+		return nil, errors.New("no rates found; expected an ECB eurofxref file")
+	}
+	var out []models.FxRate
+	for _, day := range env.Days {
+		d, err := time.Parse("2006-01-02", day.Time)
+		if err != nil {
+			return nil, fmt.Errorf("bad date %q", day.Time)
+		}
+		for _, c := range day.Rates {
+			rate, err := newRate(d, "EUR", c.Currency, c.Rate)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", day.Time, err)
+			}
+			out = append(out, rate)
+		}
+	}
+	return out, nil
+}
+
+func newRate(d time.Time, base, quote, rate string) (models.FxRate, error) {
+	b, ok := NormalizeCode(base)
+	if !ok {
+		return models.FxRate{}, fmt.Errorf("bad base currency %q", base)
+	}
+	q, ok := NormalizeCode(quote)
+	if !ok {
+		return models.FxRate{}, fmt.Errorf("bad quote currency %q", quote)
+	}
+	if b == q {
+		return models.FxRate{}, fmt.Errorf("base and quote are both %s", b)
+	}
+	v, err := strconv.ParseFloat(rate, 64)
+	if err != nil || v <= 0 {
+		return models.FxRate{}, fmt.Errorf("rate must be a positive number, got %q", rate)
+	}
+	return models.FxRate{Date: d, Base: b, Quote: q, Rate: v}, nil
+}
@@ -0,0 +1,108 @@
+package fx_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"budgex_backend/internal/fx"
+	"budgex_backend/internal/models"
+)
+
+func TestParseCSV(t *testing.T) {
+	in := "\ufeffRate,Quote,Date,Base,Note\n" +
+		"330.5,lkr,2024-03-01,eur,from the bank\n" +
+		"\n" +
+		"1.1, USD , 2024-03-04,EUR\n"
+	got, err := fx.ParseCSV(strings.NewReader(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []models.FxRate{
+		{Date: day("2024-03-01"), Base: "EUR", Quote: "LKR", Rate: 330.5},
+		{Date: day("2024-03-04"), Base: "EUR", Quote: "USD", Rate: 1.1},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseCSV = %+v, want %+v", got, want)
+	}
+}
+
+func TestParseCSVErrors(t *testing.T) {
+	const header = "date,base,quote,rate\n"
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"empty", "", "read header"},
+		{"missing column", "date,base,rate\n2024-03-01,EUR,1.1\n", `missing "quote" column`},
+		{"bad date", header + "01/03/2024,EUR,USD,1.1\n", "line 2: date must be YYYY-MM-DD"},
+		{"bad base", header + "2024-03-01,EURO,USD,1.1\n", `line 2: bad base currency "EURO"`},
+		{"bad quote", header + "2024-03-01,EUR,US,1.1\n", `line 2: bad quote currency "US"`},
+		{"same currency", header + "2024-03-01,EUR,eur,1\n", "line 2: base and quote are both EUR"},
+		{"zero rate", header + "2024-03-01,EUR,USD,1.1\n2024-03-02,EUR,USD,0\n", "line 3: rate must be a positive number"},
+		{"not a number", header + "2024-03-01,EUR,USD,one\n", "line 2: rate must be a positive number"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := fx.ParseCSV(strings.NewReader(tt.in))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("ParseCSV = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+const ecbHist = `<?xml version="1.0" encoding="UTF-8"?>
+<gesmes:Envelope xmlns:gesmes="http://www.gesmes.org/xml/2002-08-01" xmlns="http://www.ecb.int/vocabulary/2002-08-01/eurofxref">
+	<gesmes:subject>Reference rates</gesmes:subject>
+	<gesmes:Sender>
+		<gesmes:name>European Central Bank</gesmes:name>
+	</gesmes:Sender>
+	<Cube>
+		<Cube time="2024-03-04">
+			<Cube currency="USD" rate="1.0842"/>
+			<Cube currency="JPY" rate="162.83"/>
+		</Cube>
+		<Cube time="2024-03-01">
+			<Cube currency="USD" rate="1.0830"/>
+		</Cube>
+	</Cube>
+</gesmes:Envelope>`
+
+func TestParseECB(t *testing.T) {
+	got, err := fx.ParseECB(strings.NewReader(ecbHist))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []models.FxRate{
+		{Date: day("2024-03-04"), Base: "EUR", Quote: "USD", Rate: 1.0842},
+		{Date: day("2024-03-04"), Base: "EUR", Quote: "JPY", Rate: 162.83},
+		{Date: day("2024-03-01"), Base: "EUR", Quote: "USD", Rate: 1.0830},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseECB = %+v, want %+v", got, want)
+	}
+}
+
+func TestParseECBErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"not xml", "date,base,quote,rate\n", "EOF"},
+		{"no rates", `<Envelope><Cube></Cube></Envelope>`, "no rates found"},
+		{"bad date", `<Envelope><Cube><Cube time="4 March"><Cube currency="USD" rate="1.08"/></Cube></Cube></Envelope>`, `bad date "4 March"`},
+		{"bad rate", `<Envelope><Cube><Cube time="2024-03-04"><Cube currency="USD" rate="-1"/></Cube></Cube></Envelope>`, "2024-03-04: rate must be a positive number"},
+		{"euro quoted", `<Envelope><Cube><Cube time="2024-03-04"><Cube currency="EUR" rate="1"/></Cube></Cube></Envelope>`, "base and quote are both EUR"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := fx.ParseECB(strings.NewReader(tt.in))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("ParseECB = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,194 @@
+package imports
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"budgex_backend/internal/models"
+	"budgex_backend/internal/money"
+)
+
+// CSVMapping tells the CSV parser where each field lives. Columns are header
+// names, or zero-based indexes when the file has no header row.
+type CSVMapping struct {
+	DateColumn       string `json:"date_column"`       // required
+	DateFormat       string `json:"date_format"`       // e.g. DD/MM/YYYY; defaults to YYYY-MM-DD
+	AmountColumn     string `json:"amount_column"`     // signed amount; negative = expense
+	DebitColumn      string `json:"debit_column"`      // alternative to amount_column: money out
+	CreditColumn     string `json:"credit_column"`     // alternative to amount_column: money in
+	PayeeColumn      string `json:"payee_column"`      // optional
+	MemoColumn       string `json:"memo_column"`       // optional
+	DecimalSeparator string `json:"decimal_separator"` // "." (default) or ","
+	Delimiter        string `json:"delimiter"`         // defaults to ","
+	NoHeader         bool   `json:"no_header"`         // first line is data
+}
+
+// Validate reports mapping mistakes before any row is parsed.
+func (m CSVMapping) Validate() error {
+	if m.DateColumn == "" {
+		return errors.New("date_column is required")
+	}
+	if m.AmountColumn == "" && m.DebitColumn == "" && m.CreditColumn == "" {
+		return errors.New("amount_column or debit_column/credit_column is required")
+	}
+	if m.AmountColumn != "" && (m.DebitColumn != "" || m.CreditColumn != "") {
+		return errors.New("use either amount_column or debit_column/credit_column, not both")
+	}
+	if m.DecimalSeparator != "" && m.DecimalSeparator != "." && m.DecimalSeparator != "," {
+		return errors.New(`decimal_separator must be "." or ","`)
+	}
+	if len([]rune(m.Delimiter)) > 1 {
+		return errors.New("delimiter must be a single character")
+	}
+	return nil
+}
+
+// ParseCSV reads a statement and returns one Row per data line. A malformed
+// file is an error; a malformed line only marks that Row.
+func ParseCSV(r io.Reader, m CSVMapping) ([]Row, error) {
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+	layout := GoLayout("YYYY-MM-DD")
+	if m.DateFormat != "" {
+		layout = GoLayout(m.DateFormat)
+	}
+
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+	if m.Delimiter != "" {
+		cr.Comma = []rune(m.Delimiter)[0]
+	}
+
+	var header []string
+	if !m.NoHeader {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			return nil, errors.New("file is empty")
+		}
+		if err != nil {
+			return nil, err
+		}
+		header = rec
+		if len(header) > 0 {
+			header[0] = strings.TrimPrefix(header[0], "\ufeff")
+		}
+	}
+
+	cols := map[string]int{}
+	for _, name := range []string{m.DateColumn, m.AmountColumn, m.DebitColumn, m.CreditColumn, m.PayeeColumn, m.MemoColumn} {
+		if name == "" {
+			continue
+		}
+		idx, err := columnIndex(header, name)
+		if err != nil {
+			return nil, err
+		}
+		cols[name] = idx
+	}
+	field := func(rec []string, name string) string {
+		if name == "" {
+			return ""
+		}
+		if i := cols[name]; i < len(rec) {
+			return strings.TrimSpace(rec[i])
+		}
+		return ""
+	}
+
+	var rows []Row
+	for {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			var pe *csv.ParseError
+			if !errors.As(err, &pe) {
+				return nil, err
+			}
+			rows = append(rows, Row{Line: pe.StartLine, Error: err.Error()})
+			continue
+		}
+		// the file line, which blank lines skipped by the reader still count
+		line, _ := cr.FieldPos(0)
+		if isBlank(rec) {
+			continue
+		}
+
+		d, err := time.Parse(layout, field(rec, m.DateColumn))
+		if err != nil {
+			rows = append(rows, Row{Line: line, Error: fmt.Sprintf("invalid date %q", field(rec, m.DateColumn))})
+			continue
+		}
+		amount, err := csvAmount(rec, m, field)
+		if err != nil {
+			rows = append(rows, Row{Line: line, Error: err.Error()})
+			continue
+		}
+		if amount == 0 {
+			rows = append(rows, Row{Line: line, Error: "amount is zero"})
+			continue
+		}
+		typ, amt := signed(amount)
+		rows = append(rows, Row{Line: line, Transaction: &models.Transaction{
+			Type:   typ,
+			Date:   d,
+			Amount: amt,
+			Payee:  optional(field(rec, m.PayeeColumn)),
+			Memo:   optional(field(rec, m.MemoColumn)),
+			Source: SourceCSV,
+		}})
+	}
+	return rows, nil
+}
+
+// csvAmount returns the signed amount of a record.
+func csvAmount(rec []string, m CSVMapping, field func([]string, string) string) (money.Amount, error) {
+	if m.AmountColumn != "" {
+		return ParseAmount(field(rec, m.AmountColumn), m.DecimalSeparator)
+	}
+	var total money.Amount
+	if v := field(rec, m.CreditColumn); v != "" {
+		credit, err := ParseAmount(v, m.DecimalSeparator)
+		if err != nil {
+			return 0, err
+		}
+		total += credit.Abs()
+	}
+	if v := field(rec, m.DebitColumn); v != "" {
+		debit, err := ParseAmount(v, m.DecimalSeparator)
+		if err != nil {
+			return 0, err
+		}
+		total -= debit.Abs()
+	}
+	return total, nil
+}
+
+func columnIndex(header []string, name string) (int, error) {
+	for i, h := range header {
+		if strings.EqualFold(strings.TrimSpace(h), strings.TrimSpace(name)) {
+			return i, nil
+		}
+	}
+	if i, err := strconv.Atoi(name); err == nil && i >= 0 {
+		return i, nil
+	}
+	return 0, fmt.Errorf("column %q not found", name)
+}
+
+func isBlank(rec []string) bool {
+	for _, f := range rec {
+		if strings.TrimSpace(f) != "" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,148 @@
+package imports_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"budgex_backend/internal/imports"
+	"budgex_backend/internal/models"
+	"budgex_backend/internal/money"
+)
+
+func tx(ext string) *models.Transaction {
+	return &models.Transaction{ExternalID: &ext}
+}
+
+// line is a Row reduced to what the tests compare.
+type line struct {
+	n      int
+	typ    string
+	date   string
+	amount money.Amount
+	payee  string
+	err    string
+}
+
+func lines(rows []imports.Row) []line {
+	out := make([]line, 0, len(rows))
+	for _, r := range rows {
+		l := line{n: r.Line, err: r.Error}
+		if tx := r.Transaction; tx != nil {
+			l.typ, l.date, l.amount = tx.Type, tx.Date.Format(time.DateOnly), tx.Amount
+			if tx.Payee != nil {
+				l.payee = *tx.Payee
+			}
+		}
+		out = append(out, l)
+	}
+	return out
+}
+
+func sameLines(t *testing.T, got []imports.Row, want []line) {
+	t.Helper()
+	g := lines(got)
+	if len(g) != len(want) {
+		t.Fatalf("got %d rows %+v, want %d", len(g), g, len(want))
+	}
+	for i := range want {
+		if g[i] != want[i] {
+			t.Errorf("row %d = %+v, want %+v", i, g[i], want[i])
+		}
+	}
+}
+
+func TestParseCSV(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		m    imports.CSVMapping
+		want []line
+	}{
+		{
+			"signed amount",
+			"\ufeffDate,Amount,Payee\n2026-03-04,-12.50,Bakery\n\n2026-03-05,1000,Employer\n",
+			imports.CSVMapping{DateColumn: "date", AmountColumn: "Amount", PayeeColumn: "payee"},
+			[]line{
+				{n: 2, typ: "expense", date: "2026-03-04", amount: 1250, payee: "Bakery"},
+				{n: 4, typ: "income", date: "2026-03-05", amount: 100000, payee: "Employer"},
+			},
+		},
+		{
+			"debit and credit columns",
+			"Booked;Out;In;Text\n04.03.2026;12,50;;Bäckerei\n05.03.2026;;1.000,00;Lohn\n",
+			imports.CSVMapping{DateColumn: "Booked", DateFormat: "DD.MM.YYYY", DebitColumn: "Out", CreditColumn: "In",
+				PayeeColumn: "Text", DecimalSeparator: ",", Delimiter: ";"},
+			[]line{
+				{n: 2, typ: "expense", date: "2026-03-04", amount: 1250, payee: "Bäckerei"},
+				{n: 3, typ: "income", date: "2026-03-05", amount: 100000, payee: "Lohn"},
+			},
+		},
+		{
+			"no header",
+			"3/4/26,(9.99),Shop\n",
+			imports.CSVMapping{DateColumn: "0", DateFormat: "M/D/YY", AmountColumn: "1", PayeeColumn: "2", NoHeader: true},
+			[]line{{n: 1, typ: "expense", date: "2026-03-04", amount: 999, payee: "Shop"}},
+		},
+		{
+			"bad lines",
+			"date,amount\nyesterday,1\n2026-03-04,abc\n2026-03-04,0\n2026-03-04,1.005\n2026-03-04\n",
+			imports.CSVMapping{DateColumn: "date", AmountColumn: "amount"},
+			[]line{
+				{n: 2, err: `invalid date "yesterday"`},
+				{n: 3, err: `invalid amount "abc"`},
+				{n: 4, err: "amount is zero"},
+				{n: 5, err: `amount has more than two decimals "1.005"`},
+				{n: 6, err: "empty amount"},
+			},
+		},
+		{
+			"bad quote",
+			"date,amount\n\n2026-03-04,1\"2\n2026-03-05,3\n",
+			imports.CSVMapping{DateColumn: "date", AmountColumn: "amount"},
+			[]line{
+				{n: 3, err: `parse error on line 3, column 13: bare " in non-quoted-field`},
+				{n: 4, typ: "income", date: "2026-03-05", amount: 300},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, err := imports.ParseCSV(strings.NewReader(tt.file), tt.m)
+			if err != nil {
+				t.Fatal(err)
+			}
+			sameLines(t, rows, tt.want)
+			for _, r := range rows {
+				if r.Transaction != nil && r.Transaction.Source != imports.SourceCSV {
+					t.Errorf("line %d source = %q", r.Line, r.Transaction.Source)
+				}
+			}
+		})
+	}
+}
+
+func TestParseCSVErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		m    imports.CSVMapping
+		want string
+	}{
+		{"no date column", "a\n", imports.CSVMapping{AmountColumn: "a"}, "date_column is required"},
+		{"no amount column", "a\n", imports.CSVMapping{DateColumn: "a"}, "amount_column or debit_column/credit_column is required"},
+		{"both amount kinds", "a\n", imports.CSVMapping{DateColumn: "a", AmountColumn: "b", DebitColumn: "c"}, "not both"},
+		{"bad separator", "a\n", imports.CSVMapping{DateColumn: "a", AmountColumn: "b", DecimalSeparator: ";"}, "decimal_separator"},
+		{"long delimiter", "a\n", imports.CSVMapping{DateColumn: "a", AmountColumn: "b", Delimiter: ";;"}, "single character"},
+		{"empty file", "", imports.CSVMapping{DateColumn: "a", AmountColumn: "b"}, "file is empty"},
+		{"unknown column", "date,value\n", imports.CSVMapping{DateColumn: "date", AmountColumn: "amount"}, `column "amount" not found`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := imports.ParseCSV(strings.NewReader(tt.file), tt.m)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("ParseCSV = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package imports
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+
+	"budgex_backend/internal/models"
+)
+
+var (
+	ofxTxBlock = regexp.MustCompile(`(?is)<STMTTRN>(.*?)</STMTTRN>`)
+	// A leaf element: the value runs to the next tag (OFX 2.x XML) or to the
+	// end of the line (OFX 1.x SGML, where leaves are never closed).
+	ofxLeaf   = regexp.MustCompile(`(?i)<([A-Z0-9.]+)>([^<\r\n]*)`)
+	ofxAcctID = regexp.MustCompile(`(?i)<ACCTID>([^<\r\n]*)`)
+)
+
+// ParseOFX reads an OFX 1.x (SGML) or 2.x (XML) statement; QFX files are OFX.
+// Each STMTTRN becomes one Row whose ExternalID is derived from the account
+// id and the bank's FITID.
+func ParseOFX(r io.Reader) ([]Row, error) {
+	raw, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	doc := string(raw)
+	if !strings.Contains(strings.ToUpper(doc), "<OFX>") {
+		return nil, errors.New("not an OFX document")
+	}
+
+	acct := ""
+	if m := ofxAcctID.FindStringSubmatch(doc); m != nil {
+		acct = strings.TrimSpace(m[1])
+	}
+
+	var rows []Row
+	seen := map[string]int{}
+	for _, loc := range ofxTxBlock.FindAllStringSubmatchIndex(doc, -1) {
+		line := strings.Count(doc[:loc[0]], "\n") + 1
+		fields := map[string]string{}
+		for _, m := range ofxLeaf.FindAllStringSubmatch(doc[loc[2]:loc[3]], -1) {
+			tag := strings.ToUpper(m[1])
+			if _, dup := fields[tag]; !dup {
+				fields[tag] = html.UnescapeString(strings.TrimSpace(m[2]))
+			}
+		}
+
+		d, err := parseOFXDate(fields["DTPOSTED"])
+		if err != nil {
+			rows = append(rows, Row{Line: line, Error: err.Error()})
+			continue
+		}
+		amt := fields["TRNAMT"]
+		sep := "."
+		if strings.Contains(amt, ",") && !strings.Contains(amt, ".") {
+			sep = ","
+		}
+		amount, err := ParseAmount(amt, sep)
+		if err != nil {
+			rows = append(rows, Row{Line: line, Error: err.Error()})
+			continue
+		}
+		if amount == 0 {
+			rows = append(rows, Row{Line: line, Error: "amount is zero"})
+			continue
+		}
+
+		var ext string
+		if fitid := fields["FITID"]; fitid != "" {
+			ext = "ofx:" + acct + ":" + fitid
+		} else {
+			key := fields["DTPOSTED"] + "|" + amt + "|" + fields["NAME"] + "|" + fields["MEMO"]
+			ext = fingerprint("ofx:"+acct+":", seen[key], key)
+			seen[key]++
+		}
+
+		typ, amt2 := signed(amount)
+		rows = append(rows, Row{Line: line, Transaction: &models.Transaction{
+			Type:       typ,
+			Date:       d,
+			Amount:     amt2,
+			Payee:      optional(fields["NAME"]),
+			Memo:       optional(fields["MEMO"]),
+			Source:     SourceOFX,
+			ExternalID: &ext,
+		}})
+	}
+	if len(rows) == 0 {
+		return nil, errors.New("no transactions found")
+	}
+	return rows, nil
+}
+
+// parseOFXDate reads YYYYMMDD[HHMMSS[.XXX]][[gmt offset:tz name]]. Only the
+// calendar date is kept, matching how statement dates are shown to the user.
+func parseOFXDate(s string) (time.Time, error) {
+	if len(s) < 8 {
+		return time.Time{}, fmt.Errorf("invalid date %q", s)
+	}
+	d, err := time.Parse("20060102", s[:8])
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q", s)
+	}
+	return d, nil
+}
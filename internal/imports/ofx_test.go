@@ -0,0 +1,144 @@
+package imports_test
+
+import (
+	"strings"
+	"testing"
+
+	"budgex_backend/internal/imports"
+)
+
+const ofxSGML = `OFXHEADER:100
+DATA:OFXSGML
+VERSION:102
+
+<OFX>
+<BANKMSGSRSV1><STMTTRNRS><STMTRS>
+<BANKACCTFROM>
+<BANKID>123
+<ACCTID>987654
+</BANKACCTFROM>
+<BANKTRANLIST>
+<STMTTRN>
+<TRNTYPE>DEBIT
+<DTPOSTED>20260304120000.000[-5:EST]
+<TRNAMT>-12.50
+<FITID>A1
+<NAME>Bakery &amp; Café
+<MEMO>card 1234
+</STMTTRN>
+<STMTTRN>
+<TRNTYPE>CREDIT
+<DTPOSTED>20260305
+<TRNAMT>1000.00
+<FITID>A2
+<NAME>Employer
+</STMTTRN>
+</BANKTRANLIST>
+</STMTRS></STMTTRNRS></BANKMSGSRSV1>
+</OFX>
+`
+
+const ofxXML = `<?xml version="1.0" encoding="UTF-8"?>
+<?OFX OFXHEADER="200" VERSION="220"?>
+<OFX><BANKMSGSRSV1><STMTTRNRS><STMTRS>
+<BANKACCTFROM><BANKID>123</BANKID><ACCTID>987654</ACCTID></BANKACCTFROM>
+<BANKTRANLIST>
+<STMTTRN><TRNTYPE>DEBIT</TRNTYPE><DTPOSTED>20260304120000.000[-5:EST]</DTPOSTED><TRNAMT>-12.50</TRNAMT><FITID>A1</FITID><NAME>Bakery &amp; Café</NAME><MEMO>card 1234</MEMO></STMTTRN>
+<STMTTRN><TRNTYPE>CREDIT</TRNTYPE><DTPOSTED>20260305</DTPOSTED><TRNAMT>1000.00</TRNAMT><FITID>A2</FITID><NAME>Employer</NAME></STMTTRN>
+</BANKTRANLIST>
+</STMTRS></STMTTRNRS></BANKMSGSRSV1></OFX>
+`
+
+func TestParseOFX(t *testing.T) {
+	for name, doc := range map[string]string{"1.x SGML": ofxSGML, "2.x XML": ofxXML} {
+		t.Run(name, func(t *testing.T) {
+			rows, err := imports.ParseOFX(strings.NewReader(doc))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(rows) != 2 {
+				t.Fatalf("got %d rows, want 2", len(rows))
+			}
+			g := lines(rows)
+			for i, want := range []line{
+				{typ: "expense", date: "2026-03-04", amount: 1250, payee: "Bakery & Café"},
+				{typ: "income", date: "2026-03-05", amount: 100000, payee: "Employer"},
+			} {
+				want.n = g[i].n
+				if g[i] != want {
+					t.Errorf("row %d = %+v, want %+v", i, g[i], want)
+				}
+			}
+			if ids := imports.ExternalIDs(rows); len(ids) != 2 || ids[0] != "ofx:987654:A1" || ids[1] != "ofx:987654:A2" {
+				t.Errorf("external ids = %v", ids)
+			}
+			if m := rows[0].Transaction.Memo; m == nil || *m != "card 1234" {
+				t.Errorf("memo = %v, want card 1234", m)
+			}
+			if rows[1].Transaction.Memo != nil || rows[0].Transaction.Source != imports.SourceOFX {
+				t.Errorf("memo %v, source %q", rows[1].Transaction.Memo, rows[0].Transaction.Source)
+			}
+		})
+	}
+}
+
+func TestParseOFXWithoutFITID(t *testing.T) {
+	doc := `<OFX><ACCTID>42
+<STMTTRN><DTPOSTED>20260304<TRNAMT>-5,00<NAME>Coffee</STMTTRN>
+<STMTTRN><DTPOSTED>20260304<TRNAMT>-5,00<NAME>Coffee</STMTTRN>
+<STMTTRN><DTPOSTED>20260304<TRNAMT>-5,00<NAME>Tea</STMTTRN>
+</OFX>`
+	parse := func() []string {
+		rows, err := imports.ParseOFX(strings.NewReader(doc))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if rows[0].Transaction.Amount != 500 {
+			t.Errorf("amount = %v, want 5.00", rows[0].Transaction.Amount)
+		}
+		return imports.ExternalIDs(rows)
+	}
+	first, again := parse(), parse()
+	if len(first) != 3 {
+		t.Fatalf("external ids = %v, want 3", first)
+	}
+	seen := map[string]bool{}
+	for i, id := range first {
+		if !strings.HasPrefix(id, "ofx:42:") {
+			t.Errorf("id %q does not name the account", id)
+		}
+		if seen[id] {
+			t.Errorf("id %q repeats within the file", id)
+		}
+		seen[id] = true
+		if again[i] != id {
+			t.Errorf("row %d id changed on re-import: %q, then %q", i, id, again[i])
+		}
+	}
+}
+
+func TestParseOFXErrors(t *testing.T) {
+	rows, err := imports.ParseOFX(strings.NewReader(`<OFX>
+<STMTTRN><DTPOSTED>2026<TRNAMT>1<FITID>1</STMTTRN>
+<STMTTRN><DTPOSTED>20260304<TRNAMT>abc<FITID>2</STMTTRN>
+<STMTTRN><DTPOSTED>20260304<TRNAMT>0.00<FITID>3</STMTTRN>
+</OFX>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sameLines(t, rows, []line{
+		{n: 2, err: `invalid date "2026"`},
+		{n: 3, err: `invalid amount "abc"`},
+		{n: 4, err: "amount is zero"},
+	})
+
+	for doc, want := range map[string]string{
+		"date,amount\n":   "not an OFX document",
+		"<OFX></OFX>":     "no transactions found",
+		"<ofx>\n</ofx>\n": "no transactions found",
+	} {
+		if _, err := imports.ParseOFX(strings.NewReader(doc)); err == nil || err.Error() != want {
+			t.Errorf("ParseOFX(%q) = %v, want %q", doc, err, want)
+		}
+	}
+}
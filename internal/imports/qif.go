@@ -0,0 +1,173 @@
+package imports
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"budgex_backend/internal/models"
+)
+
+// ParseQIF reads a QIF bank, cash or credit card export. dateFormat is the
+// user-facing pattern used by the file (defaults to MM/DD/YYYY); both two and
+// four digit years and the QIF apostrophe separator (1/5'24) are accepted.
+// QIF has no transaction ids, so each Row gets a content fingerprint as its
+// ExternalID.
+func ParseQIF(r io.Reader, dateFormat string) ([]Row, error) {
+	if dateFormat == "" {
+		dateFormat = "MM/DD/YYYY"
+	}
+	layouts := qifLayouts(dateFormat)
+
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	var (
+		rows     []Row
+		fields   = map[byte]string{}
+		start    = 0
+		line     = 0
+		skipping = false // inside a !Account block
+		accounts = false // inside an !Option:AutoSwitch account list
+		seen     = map[string]int{}
+	)
+	flush := func() {
+		defer func() { fields = map[byte]string{} }()
+		if len(fields) == 0 {
+			return
+		}
+		d, err := parseQIFDate(fields['D'], layouts)
+		if err != nil {
+			rows = append(rows, Row{Line: start, Error: err.Error()})
+			return
+		}
+		amt := fields['T']
+		if amt == "" {
+			amt = fields['U']
+		}
+		amount, err := ParseAmount(amt, ".")
+		if err != nil {
+			rows = append(rows, Row{Line: start, Error: err.Error()})
+			return
+		}
+		if amount == 0 {
+			rows = append(rows, Row{Line: start, Error: "amount is zero"})
+			return
+		}
+		key := fields['D'] + "|" + amt + "|" + fields['P'] + "|" + fields['M'] + "|" + fields['N']
+		ext := fingerprint("qif:", seen[key], key)
+		seen[key]++
+
+		typ, amt2 := signed(amount)
+		rows = append(rows, Row{Line: start, Transaction: &models.Transaction{
+			Type:       typ,
+			Date:       d,
+			Amount:     amt2,
+			Payee:      optional(fields['P']),
+			Memo:       optional(fields['M']),
+			Source:     SourceQIF,
+			ExternalID: &ext,
+		}})
+	}
+
+	for sc.Scan() {
+		line++
+		text := strings.TrimRight(sc.Text(), "\r")
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+		if line == 1 {
+			text = strings.TrimPrefix(text, "\ufeff")
+		}
+		switch {
+		case strings.HasPrefix(text, "!"):
+			hdr := strings.ToLower(text)
+			switch {
+			case strings.HasPrefix(hdr, "!option:autoswitch"):
+				accounts = true
+			case strings.HasPrefix(hdr, "!clear:autoswitch"):
+				accounts = false
+			default:
+				skipping = strings.HasPrefix(hdr, "!account")
+			}
+			if strings.HasPrefix(hdr, "!type:") && !qifCashType(hdr) {
+				return nil, fmt.Errorf("unsupported QIF section %q", text)
+			}
+			continue
+		case text[0] == '^':
+			if skipping {
+				skipping = accounts // a list holds many accounts, a block one
+				fields = map[byte]string{}
+				continue
+			}
+			flush()
+			continue
+		}
+		if skipping {
+			continue
+		}
+		if len(fields) == 0 {
+			start = line
+		}
+		code := text[0]
+		// split lines (S, E, $) and other codes are ignored; the first value wins
+		if _, ok := fields[code]; !ok && strings.IndexByte("DTUPMN", code) >= 0 {
+			fields[code] = strings.TrimSpace(text[1:])
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	flush() // tolerate a missing final ^
+	if len(rows) == 0 {
+		return nil, errors.New("no transactions found")
+	}
+	return rows, nil
+}
+
+func qifCashType(hdr string) bool {
+	t := strings.TrimSpace(strings.TrimPrefix(hdr, "!type:"))
+	switch t {
+	case "bank", "cash", "ccard", "oth a", "oth l":
+		return true
+	}
+	return false
+}
+
+// qifLayouts expands a pattern into the variants QIF writers actually emit:
+// with or without zero padding, and with two or four digit years.
+func qifLayouts(pattern string) []string {
+	p := strings.ToUpper(pattern)
+	loose := strings.NewReplacer("MM", "M", "DD", "D").Replace(p)
+	var out []string
+	for _, v := range []string{p, loose} {
+		out = append(out, GoLayout(v))
+		if strings.Contains(v, "YYYY") {
+			out = append(out, GoLayout(strings.Replace(v, "YYYY", "YY", 1)))
+		} else {
+			out = append(out, GoLayout(strings.Replace(v, "YY", "YYYY", 1)))
+		}
+	}
+	return out
+}
+
+func parseQIFDate(s string, layouts []string) (time.Time, error) {
+	norm := strings.ReplaceAll(s, " ", "")
+	// Quicken writes years after 2000 as 1/5'24, or 1/5' 5 for 2005
+	if i := strings.LastIndexByte(norm, '\''); i >= 0 {
+		year := norm[i+1:]
+		if len(year) == 1 {
+			year = "0" + year
+		}
+		norm = norm[:i] + "/" + year
+	}
+	for _, l := range layouts {
+		if t, err := time.Parse(l, norm); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q", s)
+}
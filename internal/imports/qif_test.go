@@ -0,0 +1,119 @@
+package imports_test
+
+import (
+	"strings"
+	"testing"
+
+	"budgex_backend/internal/imports"
+)
+
+func TestParseQIF(t *testing.T) {
+	tests := []struct {
+		name   string
+		file   string
+		format string
+		want   []line
+	}{
+		{
+			"bank",
+			"!Type:Bank\nD03/04/2026\nT-12.50\nPBakery\nMcard\n^\nD03/05/2026\nU1,000.00\nPEmployer\n^\n",
+			"",
+			[]line{
+				{n: 2, typ: "expense", date: "2026-03-04", amount: 1250, payee: "Bakery"},
+				{n: 7, typ: "income", date: "2026-03-05", amount: 100000, payee: "Employer"},
+			},
+		},
+		{
+			"apostrophe dates",
+			"!Type:CCard\nD3/4'26\nT-1.00\n^\nD12/25' 5\nT-2.00\n^\nD 1/ 5'2026\nT-3.00\n",
+			"",
+			[]line{
+				{n: 2, typ: "expense", date: "2026-03-04", amount: 100},
+				{n: 5, typ: "expense", date: "2005-12-25", amount: 200},
+				{n: 8, typ: "expense", date: "2026-01-05", amount: 300},
+			},
+		},
+		{
+			"day first",
+			"!Type:Cash\nD04/03/26\nT-9.99\n^\n",
+			"DD/MM/YYYY",
+			[]line{{n: 2, typ: "expense", date: "2026-03-04", amount: 999}},
+		},
+		{
+			"account block skipped",
+			"!Account\nNChecking\nTBank\n^\n!Type:Bank\nD03/04/2026\nT-5.00\n^\n",
+			"",
+			[]line{{n: 6, typ: "expense", date: "2026-03-04", amount: 500}},
+		},
+		{
+			"account list skipped",
+			"!Option:AutoSwitch\n!Account\nNChecking\nTBank\nD01/01/2026\n^\nNSavings\nTBank\n^\n!Clear:AutoSwitch\n" +
+				"!Account\nNChecking\n^\n!Type:Bank\nD03/04/2026\nT-5.00\nPShop\n^\n",
+			"",
+			[]line{{n: 15, typ: "expense", date: "2026-03-04", amount: 500, payee: "Shop"}},
+		},
+		{
+			"account list without a clear",
+			"!Option:AutoSwitch\n!Account\nNChecking\nTBank\nD01/01/2026\n^\nNSavings\n^\n!Type:Bank\nD03/04/2026\nT-5.00\nPShop\n^\n",
+			"",
+			[]line{{n: 10, typ: "expense", date: "2026-03-04", amount: 500, payee: "Shop"}},
+		},
+		{
+			"splits ignored",
+			"!Type:Bank\nD03/04/2026\nT-10.00\nSFood\n$-6.00\nSHome\n$-4.00\n^\n",
+			"",
+			[]line{{n: 2, typ: "expense", date: "2026-03-04", amount: 1000}},
+		},
+		{
+			"bad records",
+			"!Type:Bank\nDyesterday\nT1\n^\nD03/04/2026\nTabc\n^\nD03/04/2026\nT0\n^\n",
+			"",
+			[]line{
+				{n: 2, err: `invalid date "yesterday"`},
+				{n: 5, err: `invalid amount "abc"`},
+				{n: 8, err: "amount is zero"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, err := imports.ParseQIF(strings.NewReader(tt.file), tt.format)
+			if err != nil {
+				t.Fatal(err)
+			}
+			sameLines(t, rows, tt.want)
+		})
+	}
+}
+
+func TestParseQIFExternalIDs(t *testing.T) {
+	file := "!Type:Bank\nD03/04/2026\nT-5.00\nPCoffee\n^\nD03/04/2026\nT-5.00\nPCoffee\n^\nD03/04/2026\nT-5.00\nPTea\n^\n"
+	parse := func() []string {
+		rows, err := imports.ParseQIF(strings.NewReader(file), "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return imports.ExternalIDs(rows)
+	}
+	first, again := parse(), parse()
+	if len(first) != 3 || first[0] == first[1] || first[1] == first[2] || first[0] == first[2] {
+		t.Fatalf("external ids = %v, want 3 distinct", first)
+	}
+	for i := range first {
+		if !strings.HasPrefix(first[i], "qif:") || again[i] != first[i] {
+			t.Errorf("row %d id %q, then %q on re-import", i, first[i], again[i])
+		}
+	}
+}
+
+func TestParseQIFErrors(t *testing.T) {
+	for file, want := range map[string]string{
+		"!Type:Invst\nD03/04/2026\n^\n":   `unsupported QIF section "!Type:Invst"`,
+		"!Account\nNChecking\nTBank\n^\n": "no transactions found",
+		"":                                "no transactions found",
+	} {
+		if _, err := imports.ParseQIF(strings.NewReader(file), ""); err == nil || err.Error() != want {
+			t.Errorf("ParseQIF(%q) = %v, want %q", file, err, want)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+// Package imports parses bank statement files into transactions.
+// Parsers never touch the database; the API layer stores the parsed rows as a
+// preview and commits them later.
+package imports
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"budgex_backend/internal/models"
+	"budgex_backend/internal/money"
+)
+
+// Source values stamped on imported transactions.
+const (
+	SourceCSV = "import:csv"
+	SourceOFX = "import:ofx"
+	SourceQIF = "import:qif"
+)
+
+// Row is one parsed statement line. Exactly one of Transaction and Error is set.
+// Duplicate marks a row whose external id was already imported; it is skipped
+// on commit.
+type Row struct {
+	Line        int                 `json:"line"`
+	Transaction *models.Transaction `json:"transaction,omitempty"`
+	Error       string              `json:"error,omitempty"`
+	Duplicate   bool                `json:"duplicate,omitempty"`
+}
+
+// ExternalIDs returns the external ids of the parsed rows.
+func ExternalIDs(rows []Row) []string {
+	var out []string
+	for _, r := range rows {
+		if r.Transaction != nil && r.Transaction.ExternalID != nil {
+			out = append(out, *r.Transaction.ExternalID)
+		}
+	}
+	return out
+}
+
+// MarkDuplicates flags rows whose external id is in seen, and repeats of an
+// external id within the file itself.
+func MarkDuplicates(rows []Row, seen map[string]bool) {
+	for i := range rows {
+		tx := rows[i].Transaction
+		if tx == nil || tx.ExternalID == nil {
+			continue
+		}
+		if seen[*tx.ExternalID] {
+			rows[i].Duplicate = true
+			continue
+		}
+		seen[*tx.ExternalID] = true
+	}
+}
+
+// CountErrors returns how many rows failed to parse.
+func CountErrors(rows []Row) int {
+	n := 0
+	for _, r := range rows {
+		if r.Error != "" {
+			n++
+		}
+	}
+	return n
+}
+
+// ParseAmount reads a human formatted number such as "1,234.50", "1.234,50",
+// "-12.00", "(12.00)", "12.00-" or "$ 12.00". decimalSep is "." or ",".
+func ParseAmount(s, decimalSep string) (money.Amount, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, errors.New("empty amount")
+	}
+	neg := false
+	if strings.HasPrefix(s, "(") && strings.HasSuffix(s, ")") {
+		neg = true
+		s = s[1 : len(s)-1]
+	}
+	if strings.HasSuffix(s, "-") {
+		neg = true
+		s = strings.TrimSuffix(s, "-")
+	}
+
+	thousands := ","
+	if decimalSep == "," {
+		thousands = "."
+	} else {
+		decimalSep = "."
+	}
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case unicode.IsDigit(r):
+			b.WriteRune(r)
+		case string(r) == decimalSep:
+			b.WriteByte('.')
+		case r == '-':
+			neg = !neg
+		case string(r) == thousands, r == '+', r == '\'', unicode.IsSpace(r), unicode.IsLetter(r), unicode.Is(unicode.Sc, r):
+			// grouping, explicit sign, currency symbols and codes
+		default:
+			return 0, errors.New("invalid amount " + strconv.Quote(s))
+		}
+	}
+	if b.Len() == 0 {
+		return 0, errors.New("invalid amount " + strconv.Quote(s))
+	}
+	v, err := money.Parse(b.String())
+	if errors.Is(err, money.ErrPrecision) {
+		return 0, errors.New("amount has more than two decimals " + strconv.Quote(s))
+	}
+	if err != nil {
+		return 0, errors.New("invalid amount " + strconv.Quote(s))
+	}
+	if neg {
+		v = -v
+	}
+	return v, nil
+}
+
+// GoLayout turns a user-facing date pattern (YYYY-MM-DD, DD/MM/YYYY, M/D/YY, ...)
+// into a Go time layout.
+func GoLayout(pattern string) string {
+	r := strings.NewReplacer(
+		"YYYY", "2006", "YY", "06",
+		"MM", "01", "M", "1",
+		"DD", "02", "D", "2",
+	)
+	return r.Replace(strings.ToUpper(pattern))
+}
+
+// signed turns a signed statement amount into a transaction type and a
+// positive amount, the way transactions are stored.
+func signed(v money.Amount) (string, money.Amount) {
+	if v < 0 {
+		return "expense", -v
+	}
+	return "income", v
+}
+
+// fingerprint builds a stable id for formats without one. n tells apart
+// identical lines within the same file.
+func fingerprint(prefix string, n int, parts ...string) string {
+	h := sha1.New()
+	for _, p := range parts {
+		h.Write([]byte(p))
+		h.Write([]byte{0})
+	}
+	h.Write([]byte(strconv.Itoa(n)))
+	return prefix + hex.EncodeToString(h.Sum(nil))
+}
+
+func optional(s string) *string {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil
+	}
+	return &s
+}
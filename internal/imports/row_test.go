@@ -0,0 +1,96 @@
+package imports_test
+
+import (
+	"testing"
+	"time"
+
+	"budgex_backend/internal/imports"
+	"budgex_backend/internal/money"
+)
+
+func TestParseAmount(t *testing.T) {
+	tests := []struct {
+		in, sep string
+		want    money.Amount
+		err     bool
+	}{
+		{"12.00", ".", 1200, false},
+		{"12", "", 1200, false},
+		{"-12.5", ".", -1250, false},
+		{"+7.01", ".", 701, false},
+		{"1,234.50", ".", 123450, false},
+		{"1.234,50", ",", 123450, false},
+		{"1 234,50", ",", 123450, false},
+		{"1'234.50", ".", 123450, false},
+		{"(12.00)", ".", -1200, false},
+		{"12.00-", ".", -1200, false},
+		{"$ 12", ".", 1200, false},
+		{"-$12.00", ".", -1200, false},
+		{"12,00 €", ",", 1200, false},
+		{"EUR -3,10", ",", -310, false},
+		{"  0.01  ", ".", 1, false},
+
+		{"", ".", 0, true},
+		{"   ", ".", 0, true},
+		{"USD", ".", 0, true},
+		{"12.345", ".", 0, true},
+		{"1.2.3", ".", 0, true},
+		{"12#00", ".", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := imports.ParseAmount(tt.in, tt.sep)
+		if tt.err {
+			if err == nil {
+				t.Errorf("ParseAmount(%q, %q) = %v, want an error", tt.in, tt.sep, got)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("ParseAmount(%q, %q) = %v, %v; want %v", tt.in, tt.sep, got, err, tt.want)
+		}
+	}
+}
+
+func TestGoLayout(t *testing.T) {
+	tests := []struct {
+		pattern, layout, date string
+		want                  time.Time
+	}{
+		{"YYYY-MM-DD", "2006-01-02", "2026-03-04", time.Date(2026, 3, 4, 0, 0, 0, 0, time.UTC)},
+		{"DD/MM/YYYY", "02/01/2006", "04/03/2026", time.Date(2026, 3, 4, 0, 0, 0, 0, time.UTC)},
+		{"dd.mm.yyyy", "02.01.2006", "04.03.2026", time.Date(2026, 3, 4, 0, 0, 0, 0, time.UTC)},
+		{"MM/DD/YY", "01/02/06", "03/04/26", time.Date(2026, 3, 4, 0, 0, 0, 0, time.UTC)},
+		{"M/D/YY", "1/2/06", "3/4/26", time.Date(2026, 3, 4, 0, 0, 0, 0, time.UTC)},
+		{"M/D/YYYY", "1/2/2006", "12/25/2025", time.Date(2025, 12, 25, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		layout := imports.GoLayout(tt.pattern)
+		if layout != tt.layout {
+			t.Errorf("GoLayout(%q) = %q, want %q", tt.pattern, layout, tt.layout)
+			continue
+		}
+		if got, err := time.Parse(layout, tt.date); err != nil || !got.Equal(tt.want) {
+			t.Errorf("%s: parsing %q = %v, %v; want %v", tt.pattern, tt.date, got, err, tt.want)
+		}
+	}
+}
+
+func TestMarkDuplicates(t *testing.T) {
+	rows := []imports.Row{
+		{Line: 1, Transaction: tx("a")},
+		{Line: 2, Transaction: tx("b")},
+		{Line: 3, Transaction: tx("a")},
+		{Line: 4, Transaction: tx("")},
+		{Line: 5, Error: "invalid date"},
+	}
+	rows[3].Transaction.ExternalID = nil
+	imports.MarkDuplicates(rows, map[string]bool{"b": true})
+	for i, want := range []bool{false, true, true, false, false} {
+		if rows[i].Duplicate != want {
+			t.Errorf("line %d duplicate = %v, want %v", rows[i].Line, rows[i].Duplicate, want)
+		}
+	}
+	if n := imports.CountErrors(rows); n != 1 {
+		t.Errorf("CountErrors = %d, want 1", n)
+	}
+}
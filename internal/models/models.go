@@ -1,6 +1,10 @@
 package models
 
-import "time"
+import (
+	"time"
+
+	"budgex_backend/internal/money"
+)
 
 // internal/models/models.go
 type Base struct {
@@ -11,28 +15,257 @@ type Base struct {
 	UserID    string     `gorm:"type:text;index;not null" json:"user_id"` // ⬅ ensure TEXT
 }
 
+// HouseholdBase is Base for rows that belong to a household rather than to
+// one user. Members reach them through HouseholdID; CreatedBy only records
+// who added the row.
+type HouseholdBase struct {
+	ID          string     `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	DeletedAt   *time.Time `gorm:"index" json:"deleted_at,omitempty"`
+	HouseholdID string     `gorm:"type:uuid;index;not null" json:"household_id"`
+	CreatedBy   string     `gorm:"type:text;not null" json:"created_by"`
+}
+
+// Household roles. Viewers read, editors also write, owners also manage the
+// household, its members and invites.
+const (
+	RoleOwner  = "owner"
+	RoleEditor = "editor"
+	RoleViewer = "viewer"
+)
+
+// Household is a set of users sharing one budget: categories, transactions,
+// budgets and recurring rules.
+type Household struct {
+	ID        string     `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+	Name      string     `gorm:"not null" json:"name"`
+	CreatedBy string     `gorm:"type:text;not null" json:"created_by"`
+	// Role is the caller's role, filled in when households are listed for a
+	// member.
+	Role string `gorm:"->;-:migration" json:"role,omitempty"`
+}
+
+// HouseholdMember is one user's membership of a household.
+type HouseholdMember struct {
+	HouseholdID string    `gorm:"type:uuid;primaryKey" json:"household_id"`
+	UserID      string    `gorm:"type:text;primaryKey" json:"user_id"`
+	Role        string    `gorm:"type:text;not null" json:"role"`
+	CreatedAt   time.Time `json:"created_at"` // when they joined
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// HouseholdInvite lets whoever has its code join a household once, with the
+// invite's role, until it expires.
+type HouseholdInvite struct {
+	ID          string     `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	CreatedAt   time.Time  `json:"created_at"`
+	HouseholdID string     `gorm:"type:uuid;not null" json:"household_id"`
+	Code        string     `gorm:"type:text;not null;uniqueIndex" json:"code"`
+	Role        string     `gorm:"type:text;not null" json:"role"` // editor | viewer
+	CreatedBy   string     `gorm:"type:text;not null" json:"created_by"`
+	ExpiresAt   time.Time  `gorm:"not null" json:"expires_at"`
+	AcceptedBy  *string    `gorm:"type:text" json:"accepted_by,omitempty"`
+	AcceptedAt  *time.Time `json:"accepted_at,omitempty"`
+}
+
 type Category struct {
-	Base
+	HouseholdBase
 	Name     string  `gorm:"not null" json:"name"`
-	ParentID *string `gorm:"index" json:"parent_id,omitempty"`
+	ParentID *string `gorm:"type:uuid;index" json:"parent_id,omitempty"`
+	// BudgetRollover carries a month's unspent (or overspent) budget into the
+	// next month, envelope style.
+	BudgetRollover bool `gorm:"not null;default:false" json:"budget_rollover"`
+	Archived       bool `gorm:"not null;default:false" json:"archived"` // hidden from pickers, history kept
+}
+
+// Transaction types. A transfer is stored as two "transfer" rows sharing a
+// TransferID: the leg on the source account has a negative amount and the leg
+// on the destination account a positive one. Income and expense amounts are
+// always positive.
+const (
+	TxIncome   = "income"
+	TxExpense  = "expense"
+	TxTransfer = "transfer"
+)
+
+// IsIncomeOrExpense reports whether t is a type other than a transfer.
+func IsIncomeOrExpense(t string) bool {
+	return t == TxIncome || t == TxExpense
+}
+
+// NilIfEmpty maps "" to NULL for optional text columns.
+func NilIfEmpty(s *string) *string {
+	if s == nil || *s == "" {
+		return nil
+	}
+	return s
 }
 
 // internal/models/models.go
 type Transaction struct {
-	Base
-	Type       string    `gorm:"type:text;not null" json:"type"`
-	Date       time.Time `gorm:"index" json:"date"`
-	Amount     float64   `gorm:"not null" json:"amount"`
-	Payee      *string   `json:"payee,omitempty"`
-	Memo       *string   `json:"memo,omitempty"`
-	CategoryID *string   `gorm:"type:uuid;index" json:"category_id,omitempty"` // <- uuid
-	Source     string    `gorm:"default:'manual'" json:"source"`
-	Tags       *string   `json:"tags,omitempty"`
+	HouseholdBase
+	Type       string       `gorm:"type:text;not null" json:"type"`
+	Date       time.Time    `gorm:"index" json:"date"`
+	Amount     money.Amount `gorm:"not null" json:"amount"`
+	Currency   string       `gorm:"type:char(3);not null;default:'USD'" json:"currency"` // the account's currency, else the user's base currency
+	Payee      *string      `json:"payee,omitempty"`
+	Memo       *string      `json:"memo,omitempty"`
+	CategoryID *string      `gorm:"type:uuid;index" json:"category_id,omitempty"` // <- uuid
+	Source     string       `gorm:"default:'manual'" json:"source"`
+	Tags       *string      `json:"tags,omitempty"`
+	AccountID  *string      `gorm:"type:uuid;index" json:"account_id,omitempty"`
+	TransferID *string      `gorm:"type:uuid;index" json:"transfer_id,omitempty"` // shared by both legs of a transfer
+
+	ImportBatchID   *string `gorm:"type:uuid;index" json:"import_batch_id,omitempty"`
+	RecurringRuleID *string `gorm:"type:uuid;index" json:"recurring_rule_id,omitempty"`
+	ExternalID      *string `gorm:"type:text" json:"external_id,omitempty"` // stable id from the source (OFX FITID, ...)
+
+	Splits []TransactionSplit `gorm:"foreignKey:TransactionID" json:"splits,omitempty"`
+}
+
+// TransactionSplit is one category line of a split transaction. When a
+// transaction has splits they carry the categories, add up to its amount and
+// the transaction's own CategoryID is empty.
+type TransactionSplit struct {
+	HouseholdBase
+	TransactionID string       `gorm:"type:uuid;index;not null" json:"transaction_id"`
+	CategoryID    *string      `gorm:"type:uuid;index" json:"category_id,omitempty"`
+	Amount        money.Amount `gorm:"not null" json:"amount"`
+	Memo          *string      `json:"memo,omitempty"`
 }
 
 type Budget struct {
+	HouseholdBase
+	Month      string       `gorm:"type:char(7);index" json:"month"`
+	CategoryID string       `gorm:"type:uuid;index;not null" json:"category_id"` // <- uuid
+	Amount     money.Amount `gorm:"not null" json:"amount"`
+}
+
+// Account is a wallet, bank account or card that transactions move money in
+// and out of. Its balance is the opening balance plus income minus expenses.
+type Account struct {
+	Base
+	Name           string       `gorm:"not null" json:"name"`
+	Kind           string       `gorm:"type:text;not null;default:'checking'" json:"kind"` // see AccountKinds
+	Currency       string       `gorm:"type:char(3);not null;default:'USD'" json:"currency"`
+	OpeningBalance money.Amount `gorm:"not null;default:0" json:"opening_balance"`
+	Archived       bool         `gorm:"not null;default:false" json:"archived"`
+}
+
+var AccountKinds = []string{"cash", "checking", "savings", "credit_card", "investment", "loan", "other"}
+
+// RecurringRule is a template transaction plus a schedule. A background
+// worker creates one transaction per due occurrence and advances
+// MaterializedThrough so nothing is created twice.
+type RecurringRule struct {
+	HouseholdBase
+	Name string `gorm:"not null" json:"name"`
+
+	// template
+	Type       string       `gorm:"type:text;not null" json:"type"` // "income" | "expense"
+	Amount     money.Amount `gorm:"not null" json:"amount"`
+	Payee      *string      `json:"payee,omitempty"`
+	Memo       *string      `json:"memo,omitempty"`
+	CategoryID *string      `gorm:"type:uuid" json:"category_id,omitempty"`
+	AccountID  *string      `gorm:"type:uuid" json:"account_id,omitempty"`
+	Tags       *string      `json:"tags,omitempty"`
+
+	// schedule
+	Freq      string     `gorm:"type:text;not null" json:"freq"` // daily | weekly | monthly | yearly
+	Interval  int        `gorm:"not null;default:1" json:"interval"`
+	MonthDay  int        `gorm:"not null;default:0" json:"month_day"` // monthly: 1..31, -1 = last day, 0 = start day
+	StartDate time.Time  `gorm:"type:date;not null" json:"start_date"`
+	EndDate   *time.Time `gorm:"type:date" json:"end_date,omitempty"` // inclusive
+	Count     *int       `json:"count,omitempty"`                     // total occurrences
+
+	Active              bool       `gorm:"not null;default:true" json:"active"`
+	MaterializedThrough *time.Time `gorm:"type:date" json:"materialized_through,omitempty"`
+}
+
+// Rule categorizes and cleans up transactions as they are created or
+// imported. All set conditions must match; rules run by ascending Priority.
+type Rule struct {
 	Base
-	Month      string  `gorm:"type:char(7);index" json:"month"`
-	CategoryID string  `gorm:"type:uuid;index" json:"category_id"` // <- uuid
-	Amount     float64 `gorm:"not null" json:"amount"`
+	Name     string `gorm:"not null" json:"name"`
+	Priority int    `gorm:"not null;default:100" json:"priority"`
+	Active   bool   `gorm:"not null;default:true" json:"active"`
+	Stop     bool   `gorm:"not null;default:false" json:"stop"` // skip lower-priority rules after a match
+
+	// conditions (case-insensitive)
+	PayeeContains *string       `json:"payee_contains,omitempty"`
+	PayeeRegex    *string       `json:"payee_regex,omitempty"`
+	MemoContains  *string       `json:"memo_contains,omitempty"`
+	MemoRegex     *string       `json:"memo_regex,omitempty"`
+	MinAmount     *money.Amount `json:"min_amount,omitempty"`
+	MaxAmount     *money.Amount `json:"max_amount,omitempty"`
+	TxType        *string       `gorm:"type:text" json:"tx_type,omitempty"` // "income" | "expense"
+	AccountID     *string       `gorm:"type:uuid" json:"account_id,omitempty"`
+
+	// actions
+	SetCategoryID *string `gorm:"type:uuid" json:"set_category_id,omitempty"`
+	AddTags       *string `json:"add_tags,omitempty"` // comma separated
+	SetPayee      *string `json:"set_payee,omitempty"`
+	SetMemo       *string `json:"set_memo,omitempty"`
+}
+
+// ImportBatch groups the transactions created by one statement import so the
+// whole file can be previewed, committed and rolled back together.
+type ImportBatch struct {
+	Base
+	Source       string     `gorm:"type:text;not null" json:"source"` // import:csv, ...
+	Filename     string     `json:"filename"`
+	AccountID    *string    `gorm:"type:uuid" json:"account_id,omitempty"`              // stamped on every imported row
+	HouseholdID  *string    `gorm:"type:uuid" json:"household_id,omitempty"`            // checked for duplicates at preview, committed into
+	Status       string     `gorm:"type:text;not null;default:'preview'" json:"status"` // preview | committed | rolled_back
+	RowCount     int        `json:"row_count"`
+	ErrorCount   int        `json:"error_count"`
+	Imported     int        `json:"imported"`
+	Preview      []byte     `gorm:"type:jsonb" json:"-"` // parsed rows, kept until commit
+	CommittedAt  *time.Time `json:"committed_at,omitempty"`
+	RolledBackAt *time.Time `json:"rolled_back_at,omitempty"`
+}
+
+const (
+	ImportPreview    = "preview"
+	ImportCommitted  = "committed"
+	ImportRolledBack = "rolled_back"
+)
+
+// CategorySeed records which version of the default category tree a
+// household has received, so seeding happens once per version.
+type CategorySeed struct {
+	HouseholdID string    `gorm:"type:uuid;primaryKey" json:"household_id"`
+	Version     int       `gorm:"not null" json:"version"`
+	Locale      string    `gorm:"type:text;not null" json:"locale"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// UserSettings holds per-user preferences. A user without a row gets the
+// defaults.
+type UserSettings struct {
+	UserID        string    `gorm:"type:text;primaryKey" json:"user_id"`
+	BaseCurrency  string    `gorm:"type:char(3);not null;default:'USD'" json:"base_currency"` // budgets and analytics are reported in it
+	Timezone      string    `gorm:"type:text;not null;default:'UTC'" json:"timezone"`         // IANA name, e.g. Asia/Colombo
+	WeekStart     int       `gorm:"not null;default:1" json:"week_start"`                     // 0 = Sunday … 6 = Saturday
+	MonthStartDay int       `gorm:"not null;default:1" json:"month_start_day"`                // 1..28; budget months begin on this day
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// FxRate is one exchange rate: on Date, 1 Base buys Rate Quote. Rates belong
+// to the user who uploaded them.
+type FxRate struct {
+	UserID    string    `gorm:"type:text;primaryKey" json:"-"`
+	Date      time.Time `gorm:"type:date;primaryKey" json:"date"`
+	Base      string    `gorm:"type:char(3);primaryKey" json:"base"`
+	Quote     string    `gorm:"type:char(3);primaryKey" json:"quote"`
+	Rate      float64   `gorm:"type:numeric(20,10);not null" json:"rate"`
+	Source    string    `gorm:"type:text;not null" json:"source"` // csv | ecb
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
 }
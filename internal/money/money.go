@@ -0,0 +1,192 @@
+// Package money holds Amount, an exact amount of money.
+package money
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Amount is an amount of money in minor units (cents). It is stored as
+// numeric(14,2) and written to JSON as an exact decimal number such as 12.34.
+// JSON input may be a number or a string with at most two decimals.
+type Amount int64
+
+// Scale is the number of minor units in one major unit.
+const Scale = 100
+
+// Largest magnitude that fits numeric(14,2).
+const maxAbs = 999_999_999_999_99
+
+var (
+	ErrSyntax    = errors.New("money: invalid amount")
+	ErrPrecision = errors.New("money: more than two decimals")
+	ErrRange     = errors.New("money: amount out of range")
+)
+
+// FromCents returns the amount of c minor units.
+func FromCents(c int64) Amount { return Amount(c) }
+
+// FromFloat rounds f to the nearest cent, halves away from zero. Use it only
+// at the edges where a float is unavoidable (averages, ratios).
+func FromFloat(f float64) Amount { return Amount(math.Round(f * Scale)) }
+
+// Parse reads a decimal like "12.34", "-0.5", "+3" or ".75".
+func Parse(s string) (Amount, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, ErrSyntax
+	}
+	neg := false
+	switch s[0] {
+	case '-':
+		neg, s = true, s[1:]
+	case '+':
+		s = s[1:]
+	}
+	whole, frac, _ := strings.Cut(s, ".")
+	if whole == "" && frac == "" {
+		return 0, ErrSyntax
+	}
+	for _, part := range []string{whole, frac} {
+		for _, r := range part {
+			if r < '0' || r > '9' {
+				return 0, ErrSyntax
+			}
+		}
+	}
+	frac = strings.TrimRight(frac, "0")
+	if len(frac) > 2 {
+		return 0, ErrPrecision
+	}
+	if whole == "" {
+		whole = "0"
+	}
+	w, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil || w > maxAbs/Scale {
+		return 0, ErrRange
+	}
+	f := int64(0)
+	if frac != "" {
+		f, _ = strconv.ParseInt((frac + "0")[:2], 10, 64)
+	}
+	a := Amount(w*Scale + f)
+	if a > maxAbs {
+		return 0, ErrRange
+	}
+	if neg {
+		a = -a
+	}
+	return a, nil
+}
+
+// Cents returns the amount in minor units.
+func (a Amount) Cents() int64 { return int64(a) }
+
+// Float64 is the amount in major units, for ratios and display only.
+func (a Amount) Float64() float64 { return float64(a) / Scale }
+
+// Abs returns the magnitude of a.
+func (a Amount) Abs() Amount {
+	if a < 0 {
+		return -a
+	}
+	return a
+}
+
+// String formats the amount with exactly two decimals, e.g. "-12.30".
+func (a Amount) String() string {
+	sign := ""
+	c := int64(a)
+	if c < 0 {
+		sign, c = "-", -c
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, c/Scale, c%Scale)
+}
+
+// MarshalJSON writes the amount as an exact JSON number.
+func (a Amount) MarshalJSON() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+// UnmarshalJSON accepts 12.34 or "12.34".
+func (a *Amount) UnmarshalJSON(b []byte) error {
+	s := strings.TrimSpace(string(b))
+	if s == "null" {
+		return nil
+	}
+	s = strings.Trim(s, `"`)
+	// plain JSON numbers may use an exponent (1e2); normalize those first
+	if strings.ContainsAny(s, "eE") {
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return ErrSyntax
+		}
+		s = strconv.FormatFloat(f, 'f', -1, 64)
+	}
+	v, err := Parse(s)
+	if err != nil {
+		return err
+	}
+	*a = v
+	return nil
+}
+
+// UnmarshalText lets form and query parsers read amounts.
+func (a *Amount) UnmarshalText(b []byte) error {
+	v, err := Parse(string(b))
+	if err != nil {
+		return err
+	}
+	*a = v
+	return nil
+}
+
+// Value stores the amount as a numeric literal.
+func (a Amount) Value() (driver.Value, error) {
+	return a.String(), nil
+}
+
+// Scan reads numeric columns and the results of SUM and friends. Integers are
+// whole units. Values with more than two decimals (averages) are rounded to
+// the cent.
+func (a *Amount) Scan(src any) error {
+	switch v := src.(type) {
+	case nil:
+		*a = 0
+		return nil
+	case int64:
+		*a = Amount(v * Scale)
+		return nil
+	case float64:
+		*a = FromFloat(v)
+		return nil
+	case []byte:
+		return a.scanString(string(v))
+	case string:
+		return a.scanString(v)
+	}
+	return fmt.Errorf("money: cannot scan %T", src)
+}
+
+func (a *Amount) scanString(s string) error {
+	v, err := Parse(s)
+	if errors.Is(err, ErrPrecision) {
+		f, ferr := strconv.ParseFloat(strings.TrimSpace(s), 64)
+		if ferr != nil {
+			return ferr
+		}
+		v, err = FromFloat(f), nil
+	}
+	if err != nil {
+		return fmt.Errorf("%w: %q", err, s)
+	}
+	*a = v
+	return nil
+}
+
+// GormDataType is the column type used by migrations.
+func (Amount) GormDataType() string { return "numeric(14,2)" }
@@ -0,0 +1,154 @@
+package money
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Amount
+		err  error
+	}{
+		{"12.34", 1234, nil},
+		{"12", 1200, nil},
+		{"12.3", 1230, nil},
+		{"12.300", 1230, nil},
+		{".75", 75, nil},
+		{"0.05", 5, nil},
+		{"-0.5", -50, nil},
+		{"+3", 300, nil},
+		{" 7.10 ", 710, nil},
+		{"-999999999999.99", -maxAbs, nil},
+		{"999999999999.99", maxAbs, nil},
+
+		{"12.345", 0, ErrPrecision},
+		{"0.001", 0, ErrPrecision},
+		{"1000000000000", 0, ErrRange},
+		{"99999999999999999999", 0, ErrRange},
+		{"", 0, ErrSyntax},
+		{".", 0, ErrSyntax},
+		{"-", 0, ErrSyntax},
+		{"--1", 0, ErrSyntax},
+		{"1,000.00", 0, ErrSyntax},
+		{"1.2.3", 0, ErrSyntax},
+		{"$12", 0, ErrSyntax},
+		{"1e2", 0, ErrSyntax},
+	}
+	for _, tt := range tests {
+		got, err := Parse(tt.in)
+		if !errors.Is(err, tt.err) || got != tt.want {
+			t.Errorf("Parse(%q) = %d, %v; want %d, %v", tt.in, got, err, tt.want, tt.err)
+		}
+	}
+}
+
+func TestString(t *testing.T) {
+	tests := []struct {
+		in   Amount
+		want string
+	}{
+		{0, "0.00"},
+		{5, "0.05"},
+		{-5, "-0.05"},
+		{1230, "12.30"},
+		{-123456, "-1234.56"},
+	}
+	for _, tt := range tests {
+		if got := tt.in.String(); got != tt.want {
+			t.Errorf("Amount(%d).String() = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Amount
+		err  error
+	}{
+		{`12.34`, 1234, nil},
+		{`"12.34"`, 1234, nil},
+		{`"-0.5"`, -50, nil},
+		{`0.1`, 10, nil},
+		{`1e2`, 10000, nil},
+		{`1.5E1`, 1500, nil},
+		{`1.234e1`, 1234, nil},
+		{`null`, 42, nil}, // left as it was
+
+		{`12.345`, 0, ErrPrecision},
+		{`1e-3`, 0, ErrPrecision},
+		{`"abc"`, 0, ErrSyntax},
+		{`"1eX"`, 0, ErrSyntax},
+		{`true`, 0, ErrSyntax},
+	}
+	for _, tt := range tests {
+		got := Amount(42)
+		err := json.Unmarshal([]byte(tt.in), &got)
+		if tt.err != nil {
+			if !errors.Is(err, tt.err) {
+				t.Errorf("Unmarshal(%s) error = %v, want %v", tt.in, err, tt.err)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("Unmarshal(%s) = %d, %v; want %d", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	for _, a := range []Amount{0, 1, -1, 1099, -250000, maxAbs, -maxAbs} {
+		b, err := json.Marshal(a)
+		if err != nil {
+			t.Fatalf("Marshal(%d): %v", a, err)
+		}
+		var got Amount
+		if err := json.Unmarshal(b, &got); err != nil || got != a {
+			t.Errorf("round trip of %d via %s = %d, %v", a, b, got, err)
+		}
+	}
+}
+
+func TestScan(t *testing.T) {
+	tests := []struct {
+		src  any
+		want Amount
+	}{
+		{nil, 0},
+		{int64(12), 1200},
+		{float64(12.5), 1250},
+		{float64(2.999), 300},
+		{[]byte("12.34"), 1234},
+		{"-0.10", -10},
+		{"3.333333", 333}, // AVG results are rounded to the cent
+	}
+	for _, tt := range tests {
+		got := Amount(99)
+		if err := got.Scan(tt.src); err != nil || got != tt.want {
+			t.Errorf("Scan(%#v) = %d, %v; want %d", tt.src, got, err, tt.want)
+		}
+	}
+
+	var a Amount
+	for _, src := range []any{"abc", []byte(""), true} {
+		if err := a.Scan(src); err == nil {
+			t.Errorf("Scan(%#v) succeeded, want an error", src)
+		}
+	}
+}
+
+func TestValueScanRoundTrip(t *testing.T) {
+	for _, a := range []Amount{0, 7, -7, 1234, -99999, maxAbs, -maxAbs} {
+		v, err := a.Value()
+		if err != nil {
+			t.Fatalf("Value(%d): %v", a, err)
+		}
+		var got Amount
+		if err := got.Scan([]byte(v.(string))); err != nil || got != a {
+			t.Errorf("Scan(Value(%d) = %v) = %d, %v", a, v, got, err)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+// Package recurring expands recurring rules into dated occurrences and turns
+// due occurrences into transactions.
+package recurring
+
+import (
+	"errors"
+	"time"
+)
+
+// Frequencies understood by Schedule.
+const (
+	Daily   = "daily"
+	Weekly  = "weekly"
+	Monthly = "monthly"
+	Yearly  = "yearly"
+)
+
+// LastDay as MonthDay schedules a monthly rule on the last day of each month.
+const LastDay = -1
+
+// Schedule is a small subset of RFC 5545 RRULE. All dates are calendar days
+// at midnight UTC.
+type Schedule struct {
+	Freq     string
+	Interval int        // every N periods; 0 is treated as 1
+	MonthDay int        // monthly only: 1..31 or LastDay; 0 uses Start's day
+	Start    time.Time  // first possible occurrence
+	Until    *time.Time // inclusive
+	Count    int        // total occurrences; 0 is unlimited
+}
+
+// Validate reports schedules that can never produce a sensible date.
+func (s Schedule) Validate() error {
+	switch s.Freq {
+	case Daily, Weekly, Monthly, Yearly:
+	default:
+		return errors.New("freq must be daily, weekly, monthly or yearly")
+	}
+	if s.Interval < 0 {
+		return errors.New("interval must be positive")
+	}
+	if s.MonthDay != 0 && s.MonthDay != LastDay && (s.MonthDay < 1 || s.MonthDay > 31) {
+		return errors.New("month_day must be 1..31 or -1 for the last day")
+	}
+	if s.MonthDay != 0 && s.Freq != Monthly {
+		return errors.New("month_day only applies to monthly rules")
+	}
+	if s.Start.IsZero() {
+		return errors.New("start_date is required")
+	}
+	if s.Until != nil && s.Until.Before(Day(s.Start)) {
+		return errors.New("end_date is before start_date")
+	}
+	if s.Count < 0 {
+		return errors.New("count must be positive")
+	}
+	return nil
+}
+
+// Between returns the occurrences strictly after `after` (nil for none) and on
+// or before `through`, in order.
+func (s Schedule) Between(after *time.Time, through time.Time) []time.Time {
+	through = Day(through)
+	interval := s.Interval
+	if interval < 1 {
+		interval = 1
+	}
+	start := Day(s.Start)
+
+	var out []time.Time
+	n := 0 // occurrences on or after start, for Count
+	for k := 0; ; k++ {
+		d := s.nth(start, k*interval)
+		if d.After(through) || (s.Until != nil && d.After(Day(*s.Until))) {
+			break
+		}
+		if d.Before(start) {
+			continue
+		}
+		n++
+		if s.Count > 0 && n > s.Count {
+			break
+		}
+		if after == nil || d.After(Day(*after)) {
+			out = append(out, d)
+		}
+	}
+	return out
+}
+
+// nth is the date k periods after start, before the Start/Count checks.
+func (s Schedule) nth(start time.Time, k int) time.Time {
+	switch s.Freq {
+	case Daily:
+		return start.AddDate(0, 0, k)
+	case Weekly:
+		return start.AddDate(0, 0, 7*k)
+	case Monthly:
+		first := time.Date(start.Year(), start.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, k, 0)
+		day := s.MonthDay
+		if day == 0 {
+			day = start.Day()
+		}
+		return onDay(first, day)
+	default: // Yearly
+		first := time.Date(start.Year()+k, start.Month(), 1, 0, 0, 0, 0, time.UTC)
+		return onDay(first, start.Day())
+	}
+}
+
+// onDay moves the first of a month to the given day, clamped to the month's
+// last day (so the 31st becomes the 30th, 29 February the 28th, ...).
+func onDay(first time.Time, day int) time.Time {
+	last := first.AddDate(0, 1, -1).Day()
+	if day == LastDay || day > last {
+		day = last
+	}
+	return first.AddDate(0, 0, day-1)
+}
+
+// Day truncates t to its calendar day in UTC.
+func Day(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,155 @@
+package recurring_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"budgex_backend/internal/models"
+	"budgex_backend/internal/recurring"
+)
+
+func day(s string) time.Time {
+	t, err := time.Parse(time.DateOnly, s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func dates(ts []time.Time) string {
+	out := make([]string, len(ts))
+	for i, t := range ts {
+		out[i] = t.Format(time.DateOnly)
+	}
+	return strings.Join(out, " ")
+}
+
+func TestDue(t *testing.T) {
+	ptr := func(v time.Time) *time.Time { return &v }
+	count := func(n int) *int { return &n }
+	tests := []struct {
+		name    string
+		rule    models.RecurringRule
+		through string
+		want    string
+	}{
+		{
+			"monthly on the 31st clamps to each month's end",
+			models.RecurringRule{Freq: recurring.Monthly, MonthDay: 31, StartDate: day("2026-01-31")},
+			"2026-05-31",
+			"2026-01-31 2026-02-28 2026-03-31 2026-04-30 2026-05-31",
+		},
+		{
+			"start day 31 in a leap year",
+			models.RecurringRule{Freq: recurring.Monthly, StartDate: day("2028-01-31")},
+			"2028-03-31",
+			"2028-01-31 2028-02-29 2028-03-31",
+		},
+		{
+			"last day of the month",
+			models.RecurringRule{Freq: recurring.Monthly, MonthDay: recurring.LastDay, StartDate: day("2026-01-15")},
+			"2026-04-29",
+			"2026-01-31 2026-02-28 2026-03-31",
+		},
+		{
+			"month day before the start day skips the start month",
+			models.RecurringRule{Freq: recurring.Monthly, MonthDay: 5, StartDate: day("2026-01-20")},
+			"2026-03-05",
+			"2026-02-05 2026-03-05",
+		},
+		{
+			"every second week",
+			models.RecurringRule{Freq: recurring.Weekly, Interval: 2, StartDate: day("2026-03-02")},
+			"2026-04-01",
+			"2026-03-02 2026-03-16 2026-03-30",
+		},
+		{
+			"every third month",
+			models.RecurringRule{Freq: recurring.Monthly, Interval: 3, StartDate: day("2026-01-10")},
+			"2026-12-31",
+			"2026-01-10 2026-04-10 2026-07-10 2026-10-10",
+		},
+		{
+			"yearly on 29 February",
+			models.RecurringRule{Freq: recurring.Yearly, StartDate: day("2028-02-29")},
+			"2030-03-01",
+			"2028-02-29 2029-02-28 2030-02-28",
+		},
+		{
+			"count limits the occurrences",
+			models.RecurringRule{Freq: recurring.Daily, StartDate: day("2026-03-01"), Count: count(3)},
+			"2026-03-31",
+			"2026-03-01 2026-03-02 2026-03-03",
+		},
+		{
+			"count includes the ones already materialized",
+			models.RecurringRule{Freq: recurring.Daily, StartDate: day("2026-03-01"), Count: count(3),
+				MaterializedThrough: ptr(day("2026-03-02"))},
+			"2026-03-31",
+			"2026-03-03",
+		},
+		{
+			"until is inclusive",
+			models.RecurringRule{Freq: recurring.Weekly, StartDate: day("2026-03-02"), EndDate: ptr(day("2026-03-16"))},
+			"2026-04-30",
+			"2026-03-02 2026-03-09 2026-03-16",
+		},
+		{
+			"only what is not materialized yet",
+			models.RecurringRule{Freq: recurring.Monthly, StartDate: day("2026-01-15"), MaterializedThrough: ptr(day("2026-02-15"))},
+			"2026-04-14",
+			"2026-03-15",
+		},
+		{
+			"start in the future",
+			models.RecurringRule{Freq: recurring.Daily, StartDate: day("2026-06-01")},
+			"2026-05-31",
+			"",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			through := day(tt.through).Add(23 * time.Hour) // any time of that day
+			if got := dates(recurring.Due(tt.rule, through)); got != tt.want {
+				t.Errorf("Due = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	start := day("2026-03-01")
+	before := day("2026-02-28")
+	tests := []struct {
+		name string
+		s    recurring.Schedule
+		want string
+	}{
+		{"monthly", recurring.Schedule{Freq: recurring.Monthly, MonthDay: 31, Start: start}, ""},
+		{"last day", recurring.Schedule{Freq: recurring.Monthly, MonthDay: recurring.LastDay, Start: start}, ""},
+		{"until on the start day", recurring.Schedule{Freq: recurring.Daily, Start: start, Until: &start, Count: 1}, ""},
+		{"bad freq", recurring.Schedule{Freq: "hourly", Start: start}, "freq must be"},
+		{"negative interval", recurring.Schedule{Freq: recurring.Daily, Interval: -1, Start: start}, "interval must be positive"},
+		{"month day 32", recurring.Schedule{Freq: recurring.Monthly, MonthDay: 32, Start: start}, "month_day must be"},
+		{"month day -2", recurring.Schedule{Freq: recurring.Monthly, MonthDay: -2, Start: start}, "month_day must be"},
+		{"month day on weekly", recurring.Schedule{Freq: recurring.Weekly, MonthDay: 3, Start: start}, "only applies to monthly"},
+		{"no start", recurring.Schedule{Freq: recurring.Daily}, "start_date is required"},
+		{"until before start", recurring.Schedule{Freq: recurring.Daily, Start: start, Until: &before}, "end_date is before"},
+		{"negative count", recurring.Schedule{Freq: recurring.Daily, Start: start, Count: -1}, "count must be positive"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.s.Validate()
+			if tt.want == "" {
+				if err != nil {
+					t.Errorf("Validate = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Validate = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
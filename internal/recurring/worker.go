@@ -0,0 +1,159 @@
+package recurring
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"budgex_backend/internal/db"
+	"budgex_backend/internal/fx"
+	"budgex_backend/internal/models"
+	"budgex_backend/internal/observability"
+	"budgex_backend/internal/settings"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Source stamped on materialized transactions.
+const Source = "recurring"
+
+// ScheduleOf reads the schedule half of a rule.
+func ScheduleOf(r models.RecurringRule) Schedule {
+	s := Schedule{
+		Freq:     r.Freq,
+		Interval: r.Interval,
+		MonthDay: r.MonthDay,
+		Start:    r.StartDate,
+		Until:    r.EndDate,
+	}
+	if r.Count != nil {
+		s.Count = *r.Count
+	}
+	return s
+}
+
+// Due returns the rule's occurrences that have not been materialized yet, up
+// to and including through.
+func Due(r models.RecurringRule, through time.Time) []time.Time {
+	return ScheduleOf(r).Between(r.MaterializedThrough, through)
+}
+
+// Run materializes due occurrences every interval until ctx is cancelled.
+func Run(ctx context.Context, gdb *gorm.DB, every time.Duration) {
+	log := observability.L()
+	tick := time.NewTicker(every)
+	defer tick.Stop()
+	for {
+		n, err := Materialize(ctx, gdb, time.Now())
+		if n > 0 {
+			log.Info("recurring_materialized", zap.Int("transactions", n))
+		}
+		if err != nil && ctx.Err() == nil {
+			log.Error("recurring_materialize_failed", zap.Error(err))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-tick.C:
+		}
+	}
+}
+
+// Materialize creates the transactions of every active rule that are due on
+// or before its creator's current day, and returns how many it created. It is
+// safe to run from several replicas at once: each rule is locked while it is
+// processed, and occurrences carry a unique external id. Finding due rules
+// looks across households; each rule is then processed as its creator, so
+// row-level security applies to everything it writes. Rules whose creator may
+// no longer write to the household wait until someone who can takes them
+// over. A rule that fails is logged and skipped until the next run, so it
+// cannot hold up the others; the failures come back joined.
+func Materialize(ctx context.Context, gdb *gorm.DB, now time.Time) (int, error) {
+	const today = `(?::timestamptz AT TIME ZONE COALESCE(s.timezone, 'UTC'))::date`
+	var due []struct{ ID, CreatedBy string }
+	err := db.AsSystem(ctx, gdb, func(tx *gorm.DB) error {
+		return tx.Model(&models.RecurringRule{}).
+			Select("recurring_rules.id, recurring_rules.created_by").
+			Joins("JOIN household_members m ON m.household_id = recurring_rules.household_id"+
+				" AND m.user_id = recurring_rules.created_by AND m.role IN ?",
+				[]string{models.RoleOwner, models.RoleEditor}).
+			Joins("LEFT JOIN user_settings s ON s.user_id = recurring_rules.created_by").
+			Where("active AND deleted_at IS NULL AND start_date <= "+today, now).
+			Where("materialized_through IS NULL OR materialized_through < "+today, now).
+			Scan(&due).Error
+	})
+	if err != nil {
+		return 0, err
+	}
+	total := 0
+	var errs []error
+	for _, r := range due {
+		if ctx.Err() != nil {
+			return total, errors.Join(append(errs, ctx.Err())...)
+		}
+		n, err := materializeRule(ctx, gdb, r.CreatedBy, r.ID, now)
+		if err != nil {
+			observability.L().Error("recurring_rule_failed", zap.String("rule_id", r.ID), zap.Error(err))
+			errs = append(errs, fmt.Errorf("rule %s: %w", r.ID, err))
+			continue
+		}
+		total += n
+	}
+	return total, errors.Join(errs...)
+}
+
+// materializeRule creates a rule's occurrences up to its creator's current
+// day. Occurrences are calendar dates; each transaction is dated at the start
+// of that day in the creator's timezone.
+func materializeRule(ctx context.Context, gdb *gorm.DB, uid, id string, now time.Time) (int, error) {
+	created := 0
+	err := db.AsUser(ctx, gdb, uid, func(tx *gorm.DB) error {
+		var r models.RecurringRule
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("id = ? AND active AND deleted_at IS NULL", id).
+			First(&r).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil // another replica has it, or it was just disabled
+		}
+		if err != nil {
+			return err
+		}
+
+		cal, err := settings.CalendarFor(ctx, tx, r.CreatedBy)
+		if err != nil {
+			return err
+		}
+		today := cal.Day(now)
+		due := Due(r, today)
+		if len(due) > 0 {
+			cur, err := fx.TxCurrency(ctx, tx, r.CreatedBy, r.AccountID)
+			if err != nil {
+				return err
+			}
+			txs := make([]models.Transaction, 0, len(due))
+			for _, d := range due {
+				ext := "recurring:" + r.ID + ":" + d.Format("2006-01-02")
+				txs = append(txs, models.Transaction{
+					HouseholdBase: models.HouseholdBase{HouseholdID: r.HouseholdID, CreatedBy: r.CreatedBy},
+					Type:          r.Type, Date: cal.Midnight(d), Amount: r.Amount, Currency: cur,
+					Payee: r.Payee, Memo: r.Memo, CategoryID: r.CategoryID,
+					AccountID: r.AccountID, Tags: r.Tags,
+					Source: Source, RecurringRuleID: &r.ID, ExternalID: &ext,
+				})
+			}
+			res := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&txs)
+			if res.Error != nil {
+				return res.Error
+			}
+			created = int(res.RowsAffected)
+		}
+		return tx.Model(&r).Update("materialized_through", today).Error
+	})
+	if err != nil {
+		return 0, err // rolled back
+	}
+	return created, nil
+}
@@ -0,0 +1,188 @@
+// Package rules applies a user's auto-categorization rules to transactions.
+//
+// Rules run in priority order and every matching rule applies its actions,
+// unless an earlier match has Stop set. By default a rule only fills in what
+// is missing: the category is set on uncategorized transactions and the memo
+// on transactions without one. Payee renames and added tags always apply.
+// Transfers are never touched, and split transactions keep their lines.
+package rules
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"budgex_backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type compiled struct {
+	rule    models.Rule
+	payeeRe *regexp.Regexp
+	memoRe  *regexp.Regexp
+}
+
+// Engine holds a user's compiled rules.
+type Engine struct {
+	rules []compiled
+	// Overwrite lets rules replace an existing category and memo.
+	Overwrite bool
+	// Categories, when set, holds the categories rules may assign: those of
+	// the household the transactions belong to. Rules are the user's own and
+	// can name a category of another household.
+	Categories map[string]bool
+}
+
+// Compile checks a rule's regular expressions.
+func Compile(r models.Rule) error {
+	_, err := compile(r)
+	return err
+}
+
+func compile(r models.Rule) (compiled, error) {
+	out := compiled{rule: r}
+	var err error
+	if r.PayeeRegex != nil && *r.PayeeRegex != "" {
+		if out.payeeRe, err = regexp.Compile("(?i)" + *r.PayeeRegex); err != nil {
+			return out, fmt.Errorf("payee_regex: %w", err)
+		}
+	}
+	if r.MemoRegex != nil && *r.MemoRegex != "" {
+		if out.memoRe, err = regexp.Compile("(?i)" + *r.MemoRegex); err != nil {
+			return out, fmt.Errorf("memo_regex: %w", err)
+		}
+	}
+	return out, nil
+}
+
+// New builds an engine from rules already sorted by priority. Inactive rules
+// are left out.
+func New(rs []models.Rule) (*Engine, error) {
+	e := &Engine{}
+	for _, r := range rs {
+		if !r.Active {
+			continue
+		}
+		c, err := compile(r)
+		if err != nil {
+			return nil, fmt.Errorf("rule %s: %w", r.ID, err)
+		}
+		e.rules = append(e.rules, c)
+	}
+	return e, nil
+}
+
+// Load builds an engine from the user's active rules.
+func Load(ctx context.Context, db *gorm.DB, uid string) (*Engine, error) {
+	var rs []models.Rule
+	if err := db.WithContext(ctx).
+		Where("user_id = ? AND active AND deleted_at IS NULL", uid).
+		Order("priority ASC, created_at ASC").Find(&rs).Error; err != nil {
+		return nil, err
+	}
+	return New(rs)
+}
+
+// Apply runs the rules against tx, changing it in place, and returns the ids
+// of the rules that matched.
+func (e *Engine) Apply(tx *models.Transaction) []string {
+	if e == nil || tx.Type == models.TxTransfer {
+		return nil
+	}
+	var matched []string
+	for _, c := range e.rules {
+		if !c.matches(tx) {
+			continue
+		}
+		matched = append(matched, c.rule.ID)
+		e.act(c.rule, tx)
+		if c.rule.Stop {
+			break
+		}
+	}
+	return matched
+}
+
+func (c compiled) matches(tx *models.Transaction) bool {
+	r := c.rule
+	if tx.Type == models.TxTransfer {
+		return false
+	}
+	if r.TxType != nil && *r.TxType != "" && *r.TxType != tx.Type {
+		return false
+	}
+	if r.AccountID != nil && (tx.AccountID == nil || *tx.AccountID != *r.AccountID) {
+		return false
+	}
+	if r.MinAmount != nil && tx.Amount < *r.MinAmount {
+		return false
+	}
+	if r.MaxAmount != nil && tx.Amount > *r.MaxAmount {
+		return false
+	}
+	payee, memo := deref(tx.Payee), deref(tx.Memo)
+	if r.PayeeContains != nil && *r.PayeeContains != "" &&
+		!strings.Contains(strings.ToLower(payee), strings.ToLower(*r.PayeeContains)) {
+		return false
+	}
+	if c.payeeRe != nil && !c.payeeRe.MatchString(payee) {
+		return false
+	}
+	if r.MemoContains != nil && *r.MemoContains != "" &&
+		!strings.Contains(strings.ToLower(memo), strings.ToLower(*r.MemoContains)) {
+		return false
+	}
+	if c.memoRe != nil && !c.memoRe.MatchString(memo) {
+		return false
+	}
+	return true
+}
+
+func (e *Engine) act(r models.Rule, tx *models.Transaction) {
+	if r.SetCategoryID != nil && len(tx.Splits) == 0 && (tx.CategoryID == nil || e.Overwrite) &&
+		(e.Categories == nil || e.Categories[*r.SetCategoryID]) {
+		id := *r.SetCategoryID
+		tx.CategoryID = &id
+	}
+	if r.SetPayee != nil && *r.SetPayee != "" {
+		p := *r.SetPayee
+		tx.Payee = &p
+	}
+	if r.SetMemo != nil && *r.SetMemo != "" && (deref(tx.Memo) == "" || e.Overwrite) {
+		m := *r.SetMemo
+		tx.Memo = &m
+	}
+	if r.AddTags != nil {
+		// leave the stored formatting alone when every tag is already there
+		if tags := AddTags(deref(tx.Tags), *r.AddTags); tags != AddTags(deref(tx.Tags), "") {
+			tx.Tags = &tags
+		}
+	}
+}
+
+// AddTags merges comma-separated tag lists, keeping the order of first
+// appearance and dropping duplicates and blanks.
+func AddTags(existing, add string) string {
+	seen := map[string]bool{}
+	var out []string
+	for _, list := range []string{existing, add} {
+		for _, t := range strings.Split(list, ",") {
+			t = strings.TrimSpace(t)
+			if t == "" || seen[strings.ToLower(t)] {
+				continue
+			}
+			seen[strings.ToLower(t)] = true
+			out = append(out, t)
+		}
+	}
+	return strings.Join(out, ",")
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -0,0 +1,246 @@
+package rules_test
+
+import (
+	"reflect"
+	"testing"
+
+	"budgex_backend/internal/models"
+	"budgex_backend/internal/money"
+	"budgex_backend/internal/rules"
+)
+
+func ptr[T any](v T) *T { return &v }
+
+func rule(id string, r models.Rule) models.Rule {
+	r.ID, r.Name, r.Active = id, id, true
+	return r
+}
+
+func TestMatches(t *testing.T) {
+	acct, other := "acct-1", "acct-2"
+	tx := models.Transaction{
+		Type: models.TxExpense, Amount: 2599, AccountID: &acct,
+		Payee: ptr("SHELL Station 42"), Memo: ptr("Fuel, card 1234"),
+	}
+	tests := []struct {
+		name string
+		rule models.Rule
+		want bool
+	}{
+		{"payee contains ignores case", models.Rule{PayeeContains: ptr("shell")}, true},
+		{"payee does not contain", models.Rule{PayeeContains: ptr("esso")}, false},
+		{"payee regex ignores case", models.Rule{PayeeRegex: ptr(`^shell\b`)}, true},
+		{"payee regex misses", models.Rule{PayeeRegex: ptr(`^station`)}, false},
+		{"memo contains", models.Rule{MemoContains: ptr("FUEL")}, true},
+		{"memo regex", models.Rule{MemoRegex: ptr(`card \d{4}$`)}, true},
+		{"memo regex misses", models.Rule{MemoRegex: ptr(`^card`)}, false},
+		{"type", models.Rule{TxType: ptr(models.TxExpense)}, true},
+		{"other type", models.Rule{TxType: ptr(models.TxIncome)}, false},
+		{"account", models.Rule{AccountID: &acct}, true},
+		{"other account", models.Rule{AccountID: &other}, false},
+		{"amount in range", models.Rule{MinAmount: ptr(money.Amount(2000)), MaxAmount: ptr(money.Amount(3000))}, true},
+		{"min amount is inclusive", models.Rule{MinAmount: ptr(money.Amount(2599))}, true},
+		{"max amount is inclusive", models.Rule{MaxAmount: ptr(money.Amount(2599))}, true},
+		{"below min amount", models.Rule{MinAmount: ptr(money.Amount(2600))}, false},
+		{"above max amount", models.Rule{MaxAmount: ptr(money.Amount(2598))}, false},
+		{"every condition must hold", models.Rule{PayeeContains: ptr("shell"), MemoContains: ptr("toll")}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := rule("r", tt.rule)
+			r.AddTags = ptr("matched")
+			e, err := rules.New([]models.Rule{r})
+			if err != nil {
+				t.Fatal(err)
+			}
+			in := tx
+			if got := len(e.Apply(&in)) == 1; got != tt.want {
+				t.Errorf("matched = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApply(t *testing.T) {
+	food, fuel := "cat-food", "cat-fuel"
+	tests := []struct {
+		name      string
+		rules     []models.Rule
+		overwrite bool
+		tx        models.Transaction
+		matched   []string
+		category  *string
+		payee     string
+		memo      string
+		tags      string
+	}{
+		{
+			name: "sets the category of an uncategorized transaction",
+			rules: []models.Rule{
+				rule("fuel", models.Rule{PayeeContains: ptr("shell"), SetCategoryID: &fuel}),
+			},
+			tx:       models.Transaction{Type: models.TxExpense, Payee: ptr("Shell")},
+			matched:  []string{"fuel"},
+			category: &fuel, payee: "Shell",
+		},
+		{
+			name: "keeps an existing category",
+			rules: []models.Rule{
+				rule("fuel", models.Rule{PayeeContains: ptr("shell"), SetCategoryID: &fuel}),
+			},
+			tx:       models.Transaction{Type: models.TxExpense, Payee: ptr("Shell"), CategoryID: &food},
+			matched:  []string{"fuel"},
+			category: &food, payee: "Shell",
+		},
+		{
+			name: "overwrite replaces the category and memo",
+			rules: []models.Rule{
+				rule("fuel", models.Rule{PayeeContains: ptr("shell"), SetCategoryID: &fuel, SetMemo: ptr("petrol")}),
+			},
+			overwrite: true,
+			tx:        models.Transaction{Type: models.TxExpense, Payee: ptr("Shell"), CategoryID: &food, Memo: ptr("snacks")},
+			matched:   []string{"fuel"},
+			category:  &fuel, payee: "Shell", memo: "petrol",
+		},
+		{
+			name: "lower priority runs first and later rules fill in the rest",
+			rules: []models.Rule{
+				rule("first", models.Rule{PayeeContains: ptr("shell"), SetCategoryID: &fuel, SetPayee: ptr("Shell")}),
+				rule("second", models.Rule{PayeeContains: ptr("shell"), SetCategoryID: &food, SetMemo: ptr("petrol")}),
+			},
+			tx:       models.Transaction{Type: models.TxExpense, Payee: ptr("SHELL 42")},
+			matched:  []string{"first", "second"},
+			category: &fuel, payee: "Shell", memo: "petrol",
+		},
+		{
+			name: "stop skips the rules after a match",
+			rules: []models.Rule{
+				rule("first", models.Rule{PayeeContains: ptr("shell"), AddTags: ptr("car"), Stop: true}),
+				rule("second", models.Rule{PayeeContains: ptr("shell"), AddTags: ptr("travel")}),
+			},
+			tx:      models.Transaction{Type: models.TxExpense, Payee: ptr("Shell")},
+			matched: []string{"first"},
+			payee:   "Shell", tags: "car",
+		},
+		{
+			name: "stop on a rule that does not match",
+			rules: []models.Rule{
+				rule("first", models.Rule{PayeeContains: ptr("esso"), AddTags: ptr("car"), Stop: true}),
+				rule("second", models.Rule{PayeeContains: ptr("shell"), AddTags: ptr("travel")}),
+			},
+			tx:      models.Transaction{Type: models.TxExpense, Payee: ptr("Shell")},
+			matched: []string{"second"},
+			payee:   "Shell", tags: "travel",
+		},
+		{
+			name: "inactive rules are left out",
+			rules: []models.Rule{
+				{Base: models.Base{ID: "off"}, PayeeContains: ptr("shell"), AddTags: ptr("car")},
+			},
+			tx:    models.Transaction{Type: models.TxExpense, Payee: ptr("Shell")},
+			payee: "Shell",
+		},
+		{
+			name: "transfers are never touched",
+			rules: []models.Rule{
+				rule("fuel", models.Rule{PayeeContains: ptr("shell"), SetCategoryID: &fuel}),
+			},
+			tx:    models.Transaction{Type: models.TxTransfer, Payee: ptr("Shell")},
+			payee: "Shell",
+		},
+		{
+			name: "split transactions keep their lines",
+			rules: []models.Rule{
+				rule("fuel", models.Rule{PayeeContains: ptr("shell"), SetCategoryID: &fuel, AddTags: ptr("car")}),
+			},
+			tx: models.Transaction{Type: models.TxExpense, Payee: ptr("Shell"),
+				Splits: []models.TransactionSplit{{CategoryID: &food, Amount: 100}}},
+			matched: []string{"fuel"},
+			payee:   "Shell", tags: "car",
+		},
+		{
+			name: "added tags keep the existing ones",
+			rules: []models.Rule{
+				rule("fuel", models.Rule{PayeeContains: ptr("shell"), AddTags: ptr("Car, fuel")}),
+			},
+			tx:      models.Transaction{Type: models.TxExpense, Payee: ptr("Shell"), Tags: ptr("car,work")},
+			matched: []string{"fuel"},
+			payee:   "Shell", tags: "car,work,fuel",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := rules.New(tt.rules)
+			if err != nil {
+				t.Fatal(err)
+			}
+			e.Overwrite = tt.overwrite
+			tx := tt.tx
+			if got := e.Apply(&tx); !reflect.DeepEqual(got, tt.matched) {
+				t.Errorf("matched %v, want %v", got, tt.matched)
+			}
+			if !reflect.DeepEqual(tx.CategoryID, tt.category) {
+				t.Errorf("category = %v, want %v", deref(tx.CategoryID), deref(tt.category))
+			}
+			if got := deref(tx.Payee); got != tt.payee {
+				t.Errorf("payee = %q, want %q", got, tt.payee)
+			}
+			if got := deref(tx.Memo); got != tt.memo {
+				t.Errorf("memo = %q, want %q", got, tt.memo)
+			}
+			if got := deref(tx.Tags); got != tt.tags {
+				t.Errorf("tags = %q, want %q", got, tt.tags)
+			}
+		})
+	}
+}
+
+func TestApplyCategories(t *testing.T) {
+	// a user's rule may name a category of another of their households
+	mine, theirs := "cat-mine", "cat-theirs"
+	e, err := rules.New([]models.Rule{
+		rule("other household", models.Rule{PayeeContains: ptr("shell"), SetCategoryID: &theirs}),
+		rule("this household", models.Rule{PayeeContains: ptr("shell"), SetCategoryID: &mine}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	e.Categories = map[string]bool{mine: true}
+	tx := models.Transaction{Type: models.TxExpense, Payee: ptr("Shell")}
+	e.Apply(&tx)
+	if deref(tx.CategoryID) != mine {
+		t.Errorf("category = %q, want %q", deref(tx.CategoryID), mine)
+	}
+}
+
+func TestNewBadRegex(t *testing.T) {
+	if _, err := rules.New([]models.Rule{rule("bad", models.Rule{PayeeRegex: ptr("(")})}); err == nil {
+		t.Error("New accepted an invalid payee regex")
+	}
+	if err := rules.Compile(models.Rule{MemoRegex: ptr("[a-")}); err == nil {
+		t.Error("Compile accepted an invalid memo regex")
+	}
+}
+
+func TestAddTags(t *testing.T) {
+	tests := []struct{ existing, add, want string }{
+		{"", "", ""},
+		{"", "car", "car"},
+		{"car", "fuel", "car,fuel"},
+		{"car,fuel", "Fuel, CAR", "car,fuel"},
+		{" car , ,work ", "", "car,work"},
+		{"Car", "car,travel,travel", "Car,travel"},
+	}
+	for _, tt := range tests {
+		if got := rules.AddTags(tt.existing, tt.add); got != tt.want {
+			t.Errorf("AddTags(%q, %q) = %q, want %q", tt.existing, tt.add, got, tt.want)
+		}
+	}
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
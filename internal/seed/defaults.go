@@ -0,0 +1,100 @@
+// Package seed creates the default category tree for new households.
+package seed
+
+import "strings"
+
+// Version of the default trees. Bump it when the trees change; households
+// seeded with an older version get the missing categories on the next
+// explicit seed.
+const Version = 1
+
+// DefaultLocale is used when no variant matches the requested locale.
+const DefaultLocale = "en"
+
+// Node is a default category and its subcategories.
+type Node struct {
+	Name     string
+	Children []Node
+}
+
+func n(name string, children ...string) Node {
+	out := Node{Name: name}
+	for _, c := range children {
+		out.Children = append(out.Children, Node{Name: c})
+	}
+	return out
+}
+
+var trees = map[string][]Node{
+	"en": {
+		n("Housing", "Rent", "Mortgage", "Utilities", "Maintenance"),
+		n("Food", "Groceries", "Dining"),
+		n("Transport", "Fuel", "Public Transport", "Parking", "Car Maintenance"),
+		n("Health", "Medical", "Pharmacy", "Insurance"),
+		n("Personal", "Clothing", "Personal Care"),
+		n("Entertainment", "Subscriptions", "Hobbies", "Travel"),
+		n("Education"),
+		n("Gifts & Donations"),
+		n("Fees & Charges"),
+		n("Income", "Salary", "Interest", "Other Income"),
+	},
+	"de": {
+		n("Wohnen", "Miete", "Kredit", "Nebenkosten", "Instandhaltung"),
+		n("Essen", "Lebensmittel", "Restaurant"),
+		n("Mobilität", "Kraftstoff", "ÖPNV", "Parken", "Autowerkstatt"),
+		n("Gesundheit", "Arzt", "Apotheke", "Versicherung"),
+		n("Persönliches", "Kleidung", "Körperpflege"),
+		n("Freizeit", "Abos", "Hobbys", "Reisen"),
+		n("Bildung"),
+		n("Geschenke & Spenden"),
+		n("Gebühren"),
+		n("Einnahmen", "Gehalt", "Zinsen", "Sonstige Einnahmen"),
+	},
+	"fr": {
+		n("Logement", "Loyer", "Prêt immobilier", "Charges", "Entretien"),
+		n("Alimentation", "Courses", "Restaurants"),
+		n("Transport", "Carburant", "Transports en commun", "Stationnement", "Entretien auto"),
+		n("Santé", "Médecin", "Pharmacie", "Assurance"),
+		n("Personnel", "Vêtements", "Soins"),
+		n("Loisirs", "Abonnements", "Hobbies", "Voyages"),
+		n("Éducation"),
+		n("Cadeaux & Dons"),
+		n("Frais bancaires"),
+		n("Revenus", "Salaire", "Intérêts", "Autres revenus"),
+	},
+	"es": {
+		n("Vivienda", "Alquiler", "Hipoteca", "Suministros", "Mantenimiento"),
+		n("Comida", "Supermercado", "Restaurantes"),
+		n("Transporte", "Combustible", "Transporte público", "Aparcamiento", "Taller"),
+		n("Salud", "Médico", "Farmacia", "Seguro"),
+		n("Personal", "Ropa", "Cuidado personal"),
+		n("Ocio", "Suscripciones", "Aficiones", "Viajes"),
+		n("Educación"),
+		n("Regalos y donaciones"),
+		n("Comisiones"),
+		n("Ingresos", "Nómina", "Intereses", "Otros ingresos"),
+	},
+}
+
+// Locales lists the available variants.
+func Locales() []string {
+	return []string{"en", "de", "fr", "es"}
+}
+
+// Resolve maps a locale or Accept-Language value ("de-AT,de;q=0.9,en;q=0.8")
+// to the first available variant, falling back to DefaultLocale.
+func Resolve(locale string) string {
+	for _, part := range strings.Split(locale, ",") {
+		tag := strings.TrimSpace(strings.SplitN(part, ";", 2)[0])
+		lang := strings.ToLower(strings.SplitN(strings.ReplaceAll(tag, "_", "-"), "-", 2)[0])
+		if _, ok := trees[lang]; ok {
+			return lang
+		}
+	}
+	return DefaultLocale
+}
+
+// Tree returns the default tree for a resolved locale.
+func Tree(locale string) []Node {
+	return trees[Resolve(locale)]
+}
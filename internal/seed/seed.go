@@ -0,0 +1,95 @@
+package seed
+
+import (
+	"context"
+	"strings"
+
+	"budgex_backend/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Result reports what a seeding run did.
+type Result struct {
+	Version int    `json:"version"`
+	Locale  string `json:"locale"`
+	Created int    `json:"created"` // categories added
+	Skipped int    `json:"skipped"` // defaults the household already had by name
+}
+
+// Categories adds every default category of the locale's tree that the
+// household does not have yet; uid is recorded as their creator. Names are
+// compared case-insensitively across the whole tree, so a category the
+// household already has is reused as a parent instead of being duplicated.
+func Categories(ctx context.Context, db *gorm.DB, hid, uid, locale string) (Result, error) {
+	var res Result
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := lock(tx, hid); err != nil {
+			return err
+		}
+		var err error
+		res, err = apply(tx, hid, uid, Resolve(locale))
+		return err
+	})
+	return res, err
+}
+
+// lock serializes seeding per household across requests and replicas.
+func lock(tx *gorm.DB, hid string) error {
+	return tx.Exec(`SELECT pg_advisory_xact_lock(hashtext(?))`, "category_seed:"+hid).Error
+}
+
+func record(tx *gorm.DB, hid, locale string) error {
+	return tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "household_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"version", "locale", "updated_at"}),
+	}).Create(&models.CategorySeed{HouseholdID: hid, Version: Version, Locale: locale}).Error
+}
+
+func apply(tx *gorm.DB, hid, uid, locale string) (Result, error) {
+	res := Result{Version: Version, Locale: locale}
+
+	var existing []models.Category
+	if err := tx.Select("id", "name").
+		Where("household_id = ? AND deleted_at IS NULL", hid).Find(&existing).Error; err != nil {
+		return res, err
+	}
+	byName := make(map[string]string, len(existing))
+	for _, c := range existing {
+		byName[key(c.Name)] = c.ID
+	}
+
+	var walk func(nodes []Node, parent *string) error
+	walk = func(nodes []Node, parent *string) error {
+		for _, node := range nodes {
+			id, ok := byName[key(node.Name)]
+			if ok {
+				res.Skipped++
+			} else {
+				cat := models.Category{
+					HouseholdBase: models.HouseholdBase{HouseholdID: hid, CreatedBy: uid},
+					Name:          node.Name, ParentID: parent,
+				}
+				if err := tx.Create(&cat).Error; err != nil {
+					return err
+				}
+				id = cat.ID
+				byName[key(node.Name)] = id
+				res.Created++
+			}
+			if err := walk(node.Children, &id); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := walk(trees[locale], nil); err != nil {
+		return res, err
+	}
+	return res, record(tx, hid, locale)
+}
+
+func key(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
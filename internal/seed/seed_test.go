@@ -0,0 +1,177 @@
+package seed_test
+
+import (
+	"context"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	"budgex_backend/internal/db"
+	"budgex_backend/internal/models"
+	"budgex_backend/internal/seed"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+func size(nodes []seed.Node) int {
+	n := len(nodes)
+	for _, node := range nodes {
+		n += size(node.Children)
+	}
+	return n
+}
+
+func TestResolve(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"", "en"},
+		{"de", "de"},
+		{"de-AT,de;q=0.9,en;q=0.8", "de"},
+		{"fr_CA", "fr"},
+		{"ES", "es"},
+		{"pt-BR,es;q=0.5", "es"},
+		{"ja", "en"},
+	}
+	for _, tt := range tests {
+		if got := seed.Resolve(tt.in); got != tt.want {
+			t.Errorf("Resolve(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestTrees(t *testing.T) {
+	for _, locale := range seed.Locales() {
+		tree := seed.Tree(locale)
+		if len(tree) == 0 {
+			t.Errorf("%s: no tree", locale)
+			continue
+		}
+		// seeding matches names across the whole tree, so each must be unique
+		seen := map[string]bool{}
+		var walk func([]seed.Node)
+		walk = func(nodes []seed.Node) {
+			for _, n := range nodes {
+				k := strings.ToLower(strings.TrimSpace(n.Name))
+				if k == "" || seen[k] {
+					t.Errorf("%s: empty or repeated name %q", locale, n.Name)
+				}
+				seen[k] = true
+				walk(n.Children)
+			}
+		}
+		walk(tree)
+	}
+}
+
+// household creates a household to seed in the database named by
+// TEST_DATABASE_URL, which is migrated up, and returns it with the
+// connection.
+func household(t *testing.T) (*gorm.DB, string) {
+	t.Helper()
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+	ctx := context.Background()
+	gdb, err := db.Connect(dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Migrate(ctx, gdb); err != nil {
+		t.Fatal(err)
+	}
+	uid := "seed_" + uuid.NewString()
+	h := models.Household{Name: "Home", CreatedBy: uid}
+	if err := db.AsSystem(ctx, gdb, func(tx *gorm.DB) error { return tx.Create(&h).Error }); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = db.AsSystem(ctx, gdb, func(tx *gorm.DB) error {
+			tx.Where("household_id = ?", h.ID).Delete(&models.CategorySeed{})
+			tx.Exec("DELETE FROM categories WHERE household_id = ?", h.ID)
+			return tx.Delete(&h).Error
+		})
+	})
+	return gdb, h.ID
+}
+
+func run(gdb *gorm.DB, hid, locale string) (seed.Result, error) {
+	var res seed.Result
+	err := db.AsSystem(context.Background(), gdb, func(tx *gorm.DB) error {
+		var err error
+		res, err = seed.Categories(context.Background(), tx, hid, "seeder", locale)
+		return err
+	})
+	return res, err
+}
+
+func count(t *testing.T, gdb *gorm.DB, hid string) int64 {
+	t.Helper()
+	var n int64
+	if err := db.AsSystem(context.Background(), gdb, func(tx *gorm.DB) error {
+		return tx.Model(&models.Category{}).Where("household_id = ? AND deleted_at IS NULL", hid).Count(&n).Error
+	}); err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
+
+func TestCategoriesIdempotent(t *testing.T) {
+	gdb, hid := household(t)
+	want := size(seed.Tree("en"))
+
+	first, err := run(gdb, hid, "en-GB")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.Created != want || first.Skipped != 0 || first.Locale != "en" || first.Version != seed.Version {
+		t.Errorf("first run = %+v, want %d created", first, want)
+	}
+	again, err := run(gdb, hid, "en")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.Created != 0 || again.Skipped != want {
+		t.Errorf("second run = %+v, want %d skipped", again, want)
+	}
+	if n := count(t, gdb, hid); n != int64(want) {
+		t.Errorf("household has %d categories, want %d", n, want)
+	}
+}
+
+func TestCategoriesConcurrent(t *testing.T) {
+	gdb, hid := household(t)
+	want := size(seed.Tree("en"))
+
+	// a user's first requests arrive together and each seeds the household
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		created int
+		errs    []error
+	)
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			res, err := run(gdb, hid, "en")
+			mu.Lock()
+			defer mu.Unlock()
+			created += res.Created
+			if err != nil {
+				errs = append(errs, err)
+			}
+		}()
+	}
+	wg.Wait()
+	if len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	if created != want {
+		t.Errorf("runs created %d categories together, want %d", created, want)
+	}
+	if n := count(t, gdb, hid); n != int64(want) {
+		t.Errorf("household has %d categories, want %d", n, want)
+	}
+}
@@ -0,0 +1,246 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"budgex_backend/internal/apperr"
+	"budgex_backend/internal/fx"
+	"budgex_backend/internal/models"
+	"budgex_backend/internal/money"
+	"budgex_backend/internal/store"
+	"budgex_backend/internal/validate"
+)
+
+// Accounts manages the caller's own accounts. Members book them in any of
+// their households, so balances count the transactions of all of them.
+type Accounts struct{ st store.Stores }
+
+// NewAccount is what an account is created from.
+type NewAccount struct {
+	Name           string       `json:"name" validate:"required,max=100"`
+	Kind           string       `json:"kind" validate:"oneof=cash checking savings credit_card investment loan other"` // models.AccountKinds; defaults to checking
+	Currency       string       `json:"currency" validate:"currency"`                                                  // ISO 4217, defaults to the base currency
+	OpeningBalance money.Amount `json:"opening_balance"`
+}
+
+// AccountPatch changes only the fields that are set.
+type AccountPatch struct {
+	Name           *string       `json:"name" validate:"notempty,max=100"`
+	Kind           *string       `json:"kind" validate:"notempty,oneof=cash checking savings credit_card investment loan other"`
+	Currency       *string       `json:"currency" validate:"notempty,currency"`
+	OpeningBalance *money.Amount `json:"opening_balance"`
+	Archived       *bool         `json:"archived"`
+}
+
+// LedgerQuery picks the slice of an account's history to list. Dates are
+// YYYY-MM-DD in the user's timezone, both inclusive.
+type LedgerQuery struct {
+	From  string
+	To    string
+	Limit int
+}
+
+func init() {
+	validate.Register(NewAccount{}, AccountPatch{})
+}
+
+type AccountWithBalance struct {
+	models.Account
+	Balance money.Amount `json:"balance"`
+}
+
+type AccountBalanceResp struct {
+	AccountID string       `json:"account_id"`
+	AsOf      string       `json:"as_of"` // YYYY-MM-DD, inclusive
+	Currency  string       `json:"currency"`
+	Balance   money.Amount `json:"balance"`
+}
+
+type AccountTxRow struct {
+	models.Transaction
+	RunningBalance money.Amount `json:"running_balance"`
+}
+
+// List returns m's accounts by name with their current balances.
+func (s *Accounts) List(ctx context.Context, m Member, includeArchived bool) ([]AccountWithBalance, error) {
+	rows, err := s.st.Accounts.List(ctx, m.UserID, includeArchived)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]AccountWithBalance, len(rows))
+	for i, r := range rows {
+		out[i] = AccountWithBalance{Account: r.Account, Balance: r.Balance}
+	}
+	return out, nil
+}
+
+// Get returns one of m's accounts.
+func (s *Accounts) Get(ctx context.Context, m Member, id string) (*models.Account, error) {
+	acc, err := s.st.Accounts.Get(ctx, m.UserID, id)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, apperr.NotFound()
+	}
+	return acc, err
+}
+
+// Create opens an account, in the base currency unless another is given.
+func (s *Accounts) Create(ctx context.Context, m Member, in NewAccount) (*models.Account, error) {
+	if err := validate.Struct(in); err != nil {
+		return nil, err
+	}
+	if in.Kind == "" {
+		in.Kind = "checking"
+	}
+	if in.Currency == "" {
+		us, err := s.st.Settings.Get(ctx, m.UserID)
+		if err != nil {
+			return nil, err
+		}
+		in.Currency = us.BaseCurrency
+	}
+	cur, _ := fx.NormalizeCode(in.Currency)
+	acc := models.Account{
+		Base:           models.Base{UserID: m.UserID},
+		Name:           strings.TrimSpace(in.Name),
+		Kind:           in.Kind,
+		Currency:       cur,
+		OpeningBalance: in.OpeningBalance,
+	}
+	if err := s.st.Accounts.Create(ctx, &acc); err != nil {
+		return nil, err
+	}
+	return &acc, nil
+}
+
+// Update changes the fields set in the patch. A new currency relabels the
+// account's transactions: it was set up in the wrong currency, and they were
+// entered in the new one all along.
+func (s *Accounts) Update(ctx context.Context, m Member, id string, in AccountPatch) (*models.Account, error) {
+	if err := validate.Struct(in); err != nil {
+		return nil, err
+	}
+	var out *models.Account
+	err := s.st.Tx.InTx(ctx, func(ctx context.Context) error {
+		acc, err := s.Get(ctx, m, id)
+		if err != nil {
+			return err
+		}
+		changes := map[string]any{}
+		if in.Name != nil {
+			changes["name"] = strings.TrimSpace(*in.Name)
+		}
+		if in.Kind != nil {
+			changes["kind"] = *in.Kind
+		}
+		if in.OpeningBalance != nil {
+			changes["opening_balance"] = *in.OpeningBalance
+		}
+		if in.Archived != nil {
+			changes["archived"] = *in.Archived
+		}
+		if len(changes) > 0 {
+			if err := s.st.Accounts.Update(ctx, m.UserID, acc.ID, changes); err != nil {
+				return err
+			}
+		}
+		if in.Currency != nil {
+			if cur, _ := fx.NormalizeCode(*in.Currency); cur != acc.Currency {
+				if err := s.st.Accounts.SetCurrency(ctx, m.UserID, acc.ID, cur); err != nil {
+					return err
+				}
+			}
+		}
+		out, err = s.st.Accounts.Get(ctx, m.UserID, acc.ID)
+		return err
+	})
+	return out, err
+}
+
+// Delete removes an account nothing is booked on; one with transactions can
+// only be archived.
+func (s *Accounts) Delete(ctx context.Context, m Member, id string) error {
+	return s.st.Tx.InTx(ctx, func(ctx context.Context) error {
+		acc, err := s.Get(ctx, m, id)
+		if err != nil {
+			return err
+		}
+		n, err := s.st.Accounts.CountTransactions(ctx, m.UserID, acc.ID)
+		if err != nil {
+			return err
+		}
+		if n > 0 {
+			return apperr.Conflict("account_has_transactions")
+		}
+		return s.st.Accounts.Delete(ctx, m.UserID, acc.ID)
+	})
+}
+
+// Balance returns an account's balance at the end of asOf (YYYY-MM-DD in the
+// user's timezone), today when it is empty.
+func (s *Accounts) Balance(ctx context.Context, m Member, id, asOf string) (AccountBalanceResp, error) {
+	acc, err := s.Get(ctx, m, id)
+	if err != nil {
+		return AccountBalanceResp{}, err
+	}
+	cal, err := calendar(ctx, s.st, m.UserID)
+	if err != nil {
+		return AccountBalanceResp{}, err
+	}
+	day := cal.Midnight(cal.Day(time.Now()))
+	if asOf != "" {
+		if day, err = cal.ParseDay(asOf); err != nil {
+			return AccountBalanceResp{}, invalid("as_of", "as_of_must_be_YYYY-MM-DD")
+		}
+	}
+	bal, err := s.st.Accounts.Balance(ctx, acc, day.AddDate(0, 0, 1))
+	if err != nil {
+		return AccountBalanceResp{}, err
+	}
+	return AccountBalanceResp{
+		AccountID: acc.ID,
+		AsOf:      day.Format("2006-01-02"),
+		Currency:  acc.Currency,
+		Balance:   bal,
+	}, nil
+}
+
+// Ledger lists an account's transactions newest first, each with the
+// balance after it.
+func (s *Accounts) Ledger(ctx context.Context, m Member, id string, q LedgerQuery) ([]AccountTxRow, error) {
+	acc, err := s.Get(ctx, m, id)
+	if err != nil {
+		return nil, err
+	}
+	cal, err := calendar(ctx, s.st, m.UserID)
+	if err != nil {
+		return nil, err
+	}
+	var from, to *time.Time
+	if q.From != "" {
+		t, err := cal.ParseDay(q.From)
+		if err != nil {
+			return nil, invalid("from", "from_must_be_YYYY-MM-DD")
+		}
+		from = &t
+	}
+	if q.To != "" {
+		t, err := cal.ParseDay(q.To)
+		if err != nil {
+			return nil, invalid("to", "to_must_be_YYYY-MM-DD")
+		}
+		t = t.AddDate(0, 0, 1)
+		to = &t
+	}
+	rows, err := s.st.Accounts.Ledger(ctx, acc, from, to, q.Limit)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]AccountTxRow, len(rows))
+	for i, r := range rows {
+		out[i] = AccountTxRow{Transaction: r.Transaction, RunningBalance: r.RunningBalance}
+	}
+	return out, nil
+}
@@ -0,0 +1,139 @@
+package service_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"budgex_backend/internal/models"
+	"budgex_backend/internal/money"
+	"budgex_backend/internal/service"
+)
+
+// booked adds a transaction on acc to household hid.
+func booked(t *testing.T, f *fixture, hid string, acc *models.Account, typ string, amount money.Amount, date string) *models.Transaction {
+	t.Helper()
+	d, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx := &models.Transaction{
+		HouseholdBase: models.HouseholdBase{HouseholdID: hid, CreatedBy: acc.UserID},
+		Type:          typ, Amount: amount, Date: d, Currency: acc.Currency, AccountID: &acc.ID,
+	}
+	mustInsert(t, f.db, tx)
+	return tx
+}
+
+// otherHousehold adds a second household with the owner in it.
+func otherHousehold(t *testing.T, f *fixture) service.Member {
+	t.Helper()
+	h := &models.Household{Name: "Cabin", CreatedBy: f.owner.UserID}
+	mustInsert(t, f.db, h)
+	mustInsert(t, f.db, &models.HouseholdMember{HouseholdID: h.ID, UserID: f.owner.UserID, Role: models.RoleOwner})
+	return service.Member{UserID: f.owner.UserID, HouseholdID: h.ID, Role: models.RoleOwner}
+}
+
+func TestAccountsBalanceAcrossHouseholds(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	cabin := otherHousehold(t, f)
+	acc := f.account(t, "owner", "USD")
+	if _, err := f.svc.Accounts.Update(ctx, f.owner, acc.ID, service.AccountPatch{OpeningBalance: ptr(money.Amount(10000))}); err != nil {
+		t.Fatal(err)
+	}
+	booked(t, f, f.owner.HouseholdID, acc, models.TxIncome, 5000, "2024-03-01")
+	booked(t, f, cabin.HouseholdID, acc, models.TxExpense, 2000, "2024-03-02")
+	booked(t, f, cabin.HouseholdID, acc, models.TxExpense, 700, "2024-03-05")
+
+	for _, m := range []service.Member{f.owner, cabin} {
+		got, err := f.svc.Accounts.Balance(ctx, m, acc.ID, "2024-03-02")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Balance != 13000 || got.AsOf != "2024-03-02" || got.Currency != "USD" {
+			t.Errorf("Balance from %s = %+v, want 13000 USD as of 2024-03-02", m.HouseholdID, got)
+		}
+	}
+	list, err := f.svc.Accounts.List(ctx, f.owner, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 1 || list[0].Balance != 12300 {
+		t.Errorf("List = %+v, want one account at 12300", list)
+	}
+
+	rows, err := f.svc.Accounts.Ledger(ctx, f.owner, acc.ID, service.LedgerQuery{Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var running []money.Amount
+	for _, r := range rows {
+		running = append(running, r.RunningBalance)
+	}
+	if want := []money.Amount{12300, 13000, 15000}; !slices.Equal(running, want) {
+		t.Errorf("Ledger running balances = %v, want %v", running, want)
+	}
+
+	if _, err := f.svc.Accounts.Balance(ctx, f.owner, acc.ID, "March"); code(err) != "as_of:as_of_must_be_YYYY-MM-DD" {
+		t.Errorf("Balance with a bad date = %q", code(err))
+	}
+	if _, err := f.svc.Accounts.Balance(ctx, f.member(t, "partner", models.RoleEditor), acc.ID, ""); code(err) != "not_found" {
+		t.Errorf("Balance of another user's account = %q, want not_found", code(err))
+	}
+}
+
+func TestAccountsDelete(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	cabin := otherHousehold(t, f)
+	acc := f.account(t, "owner", "USD")
+	tx := booked(t, f, cabin.HouseholdID, acc, models.TxExpense, 500, "2024-03-01")
+
+	// the transaction is in the other household, but still on the account
+	if err := f.svc.Accounts.Delete(ctx, f.owner, acc.ID); code(err) != "account_has_transactions" {
+		t.Fatalf("Delete with a transaction = %q, want account_has_transactions", code(err))
+	}
+	if err := f.svc.Transactions.Delete(ctx, cabin, tx.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.svc.Accounts.Delete(ctx, f.owner, acc.ID); err != nil {
+		t.Fatalf("Delete with only deleted transactions: %v", err)
+	}
+	if _, err := f.svc.Accounts.Get(ctx, f.owner, acc.ID); code(err) != "not_found" {
+		t.Errorf("Get after Delete = %q, want not_found", code(err))
+	}
+}
+
+func TestAccountsUpdateCurrency(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	cabin := otherHousehold(t, f)
+	acc := f.account(t, "owner", "USD")
+	here := booked(t, f, f.owner.HouseholdID, acc, models.TxExpense, 500, "2024-03-01")
+	there := booked(t, f, cabin.HouseholdID, acc, models.TxExpense, 700, "2024-03-02")
+
+	got, err := f.svc.Accounts.Update(ctx, f.owner, acc.ID, service.AccountPatch{Currency: ptr("eur"), Name: ptr(" Euro ")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Currency != "EUR" || got.Name != "Euro" {
+		t.Errorf("Update = %s %q, want EUR %q", got.Currency, got.Name, "Euro")
+	}
+	for _, c := range []struct {
+		m  service.Member
+		id string
+	}{{f.owner, here.ID}, {cabin, there.ID}} {
+		tx, err := f.svc.Transactions.Get(ctx, c.m, c.id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tx.Currency != "EUR" {
+			t.Errorf("transaction in %s is in %s, want it relabelled to EUR", c.m.HouseholdID, tx.Currency)
+		}
+	}
+	if _, err := f.svc.Accounts.Update(ctx, f.owner, acc.ID, service.AccountPatch{Currency: ptr("EURO")}); code(err) != "currency:currency_must_be_iso_4217" {
+		t.Errorf("Update with a bad currency = %q", code(err))
+	}
+}
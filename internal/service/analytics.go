@@ -0,0 +1,242 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"budgex_backend/internal/fx"
+	"budgex_backend/internal/money"
+	"budgex_backend/internal/settings"
+	"budgex_backend/internal/store"
+)
+
+// Analytics reports on income and spending.
+type Analytics struct{ st store.Stores }
+
+type SpendSummaryRow struct {
+	CategoryID *string      `json:"category_id,omitempty"`
+	Category   *string      `json:"category,omitempty"`
+	Total      money.Amount `json:"total"`
+}
+
+type SpendSummaryResp struct {
+	Month        string            `json:"month"`    // YYYY-MM
+	Currency     string            `json:"currency"` // all amounts are in it
+	TotalExpense money.Amount      `json:"total_expense"`
+	TotalIncome  money.Amount      `json:"total_income"`
+	ByCategory   []SpendSummaryRow `json:"by_category"`
+	Rates        []fx.Used         `json:"rates"` // rates applied to other currencies
+}
+
+type CashflowPoint struct {
+	Month    string       `json:"month"` // YYYY-MM
+	Income   money.Amount `json:"income"`
+	Expense  money.Amount `json:"expense"`
+	Net      money.Amount `json:"net"`
+	Forecast bool         `json:"forecast"` // true for projected months
+}
+
+type CashflowResp struct {
+	WindowMonths int             `json:"window_months"`
+	Horizon      int             `json:"horizon"`
+	Currency     string          `json:"currency"`
+	Points       []CashflowPoint `json:"points"`
+	Rates        []fx.Used       `json:"rates"`
+}
+
+// convertDaySums converts each subtotal into cur at its day's rate, in place.
+// Rates are only loaded when some subtotal is in another currency.
+func convertDaySums(ctx context.Context, st store.Stores, uid, cur string, sets ...[]store.DaySum) (*fx.Converter, error) {
+	var from, to time.Time
+	foreign := false
+	for _, rows := range sets {
+		for _, r := range rows {
+			if r.Currency == cur {
+				continue
+			}
+			if !foreign || r.Day.Before(from) {
+				from = r.Day
+			}
+			if !foreign || r.Day.After(to) {
+				to = r.Day
+			}
+			foreign = true
+		}
+	}
+	cv := fx.New(cur, nil)
+	if foreign {
+		rates, err := st.Analytics.Rates(ctx, uid, from.Add(-fx.MaxRateAge), to)
+		if err != nil {
+			return nil, err
+		}
+		cv = fx.New(cur, rates)
+	}
+	for _, rows := range sets {
+		for i, r := range rows {
+			v, err := cv.Convert(r.Total, r.Currency, r.Day)
+			if err != nil {
+				return nil, err
+			}
+			rows[i].Total, rows[i].Currency = v, cur
+		}
+	}
+	return cv, nil
+}
+
+// reportCurrency returns the normalized currency code, or the base currency
+// when code is empty.
+func reportCurrency(base, code string) (string, error) {
+	if code == "" {
+		return base, nil
+	}
+	cur, ok := fx.NormalizeCode(code)
+	if !ok {
+		return "", invalid("currency", "currency_must_be_iso_4217")
+	}
+	return cur, nil
+}
+
+func clamp(v, lo, hi int) int {
+	return min(max(v, lo), hi)
+}
+
+// SpendSummary totals income and expense of a month (YYYY-MM, by default the
+// current one) and breaks expense down by category, in currency (by default
+// the base currency).
+func (s *Analytics) SpendSummary(ctx context.Context, m Member, month, currency string) (SpendSummaryResp, error) {
+	us, err := s.st.Settings.Get(ctx, m.UserID)
+	if err != nil {
+		return SpendSummaryResp{}, err
+	}
+	cur, err := reportCurrency(us.BaseCurrency, currency)
+	if err != nil {
+		return SpendSummaryResp{}, err
+	}
+	cal := settings.CalendarOf(us)
+	month, from, to, err := monthOrNow(cal, month)
+	if err != nil {
+		return SpendSummaryResp{}, err
+	}
+
+	// transfers only move money between accounts
+	totals, err := s.st.Analytics.Totals(ctx, m.HouseholdID, cal, from, to)
+	if err != nil {
+		return SpendSummaryResp{}, err
+	}
+	// split transactions count per line
+	catRows, err := s.st.Analytics.ExpenseByCategory(ctx, m.HouseholdID, cal, from, to)
+	if err != nil {
+		return SpendSummaryResp{}, err
+	}
+	cv, err := convertDaySums(ctx, s.st, m.UserID, cur, totals, catRows)
+	if err != nil {
+		return SpendSummaryResp{}, err
+	}
+
+	out := SpendSummaryResp{Month: month, Currency: cur, ByCategory: []SpendSummaryRow{}, Rates: cv.Used()}
+	for _, t := range totals {
+		switch *t.Key {
+		case "income":
+			out.TotalIncome += t.Total
+		case "expense":
+			out.TotalExpense += t.Total
+		}
+	}
+	ci, err := loadCategoryInfo(ctx, s.st, m.HouseholdID)
+	if err != nil {
+		return SpendSummaryResp{}, err
+	}
+	byID := map[string]int{}
+	for _, r := range catRows {
+		id := strOrEmpty(r.Key)
+		i, ok := byID[id]
+		if !ok {
+			i = len(out.ByCategory)
+			byID[id] = i
+			row := SpendSummaryRow{}
+			if id != "" {
+				row.CategoryID, row.Category = &id, ci.name(id)
+			}
+			out.ByCategory = append(out.ByCategory, row)
+		}
+		out.ByCategory[i].Total += r.Total
+	}
+	sort.SliceStable(out.ByCategory, func(i, j int) bool { return out.ByCategory[i].Total > out.ByCategory[j].Total })
+	return out, nil
+}
+
+// CashflowForecast reports income and expense of the latest window budget
+// months with activity and projects their average over the next horizon
+// months. Both are clamped to 1..12.
+func (s *Analytics) CashflowForecast(ctx context.Context, m Member, window, horizon int, currency string) (CashflowResp, error) {
+	window, horizon = clamp(window, 1, 12), clamp(horizon, 1, 12)
+	us, err := s.st.Settings.Get(ctx, m.UserID)
+	if err != nil {
+		return CashflowResp{}, err
+	}
+	cur, err := reportCurrency(us.BaseCurrency, currency)
+	if err != nil {
+		return CashflowResp{}, err
+	}
+	cal := settings.CalendarOf(us)
+
+	sums, err := s.st.Analytics.RecentMonths(ctx, m.HouseholdID, cal, window)
+	if err != nil {
+		return CashflowResp{}, err
+	}
+	cv, err := convertDaySums(ctx, s.st, m.UserID, cur, sums)
+	if err != nil {
+		return CashflowResp{}, err
+	}
+	byMonth := map[string]*CashflowPoint{}
+	var past []*CashflowPoint
+	for _, r := range sums {
+		mon := cal.MonthOfDay(r.Day)
+		p, ok := byMonth[mon]
+		if !ok {
+			p = &CashflowPoint{Month: mon}
+			byMonth[mon] = p
+			past = append(past, p)
+		}
+		if *r.Key == "income" {
+			p.Income += r.Total
+		} else {
+			p.Expense += r.Total
+		}
+	}
+	sort.Slice(past, func(i, j int) bool { return past[i].Month < past[j].Month })
+
+	points := []CashflowPoint{}
+	var avgIncome, avgExpense money.Amount
+	if len(past) > 0 {
+		for _, p := range past {
+			p.Net = p.Income - p.Expense
+			points = append(points, *p)
+			avgIncome += p.Income
+			avgExpense += p.Expense
+		}
+		avgIncome = money.FromFloat(avgIncome.Float64() / float64(len(past)))
+		avgExpense = money.FromFloat(avgExpense.Float64() / float64(len(past)))
+	}
+
+	// Forecast next H months using simple average (can be replaced with ARIMA later)
+	start, _, _ := cal.MonthRange(cal.Month(time.Now()))
+	for i := 1; i <= horizon; i++ {
+		points = append(points, CashflowPoint{
+			Month:    start.AddDate(0, i, 0).Format("2006-01"),
+			Income:   avgIncome,
+			Expense:  avgExpense,
+			Net:      avgIncome - avgExpense,
+			Forecast: true,
+		})
+	}
+
+	return CashflowResp{
+		WindowMonths: window,
+		Horizon:      horizon,
+		Currency:     cur,
+		Points:       points,
+		Rates:        cv.Used(),
+	}, nil
+}
@@ -0,0 +1,93 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"budgex_backend/internal/fx"
+	"budgex_backend/internal/models"
+	"budgex_backend/internal/money"
+)
+
+// spend books an expense in cur on a day of March 2024.
+func spend(t *testing.T, f *fixture, day int, amount money.Amount, cur string) {
+	t.Helper()
+	mustInsert(t, f.db, &models.Transaction{
+		HouseholdBase: models.HouseholdBase{HouseholdID: f.owner.HouseholdID, CreatedBy: "owner"},
+		Type:          models.TxExpense, Date: time.Date(2024, 3, day, 12, 0, 0, 0, time.UTC),
+		Amount: amount, Currency: cur,
+	})
+}
+
+func eurRate(day int, rate float64) *models.FxRate {
+	return &models.FxRate{UserID: "owner", Date: time.Date(2024, 3, day, 0, 0, 0, 0, time.UTC),
+		Base: "EUR", Quote: "USD", Rate: rate, Source: fx.SourceCSV}
+}
+
+func TestSpendSummaryConvertsAtEachDaysRate(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	mustInsert(t, f.db, eurRate(1, 1.10), eurRate(8, 1.20))
+	spend(t, f, 1, 10000, "EUR")
+	spend(t, f, 3, 10000, "EUR") // the rate of the 1st still applies
+	spend(t, f, 9, 10000, "EUR")
+	spend(t, f, 9, 500, "USD")
+
+	sum, err := f.svc.Analytics.SpendSummary(ctx, f.owner, "2024-03", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sum.Currency != "USD" || sum.TotalExpense != 11000+11000+12000+500 {
+		t.Errorf("total expense = %d %s, want 34500 USD", sum.TotalExpense, sum.Currency)
+	}
+	want := []fx.Used{
+		{From: "EUR", To: "USD", Date: "2024-03-01", Rate: 1.10},
+		{From: "EUR", To: "USD", Date: "2024-03-08", Rate: 1.20},
+	}
+	got := slices.Clone(sum.Rates)
+	slices.SortFunc(got, func(a, b fx.Used) int { return strings.Compare(a.Date, b.Date) })
+	if !slices.Equal(got, want) {
+		t.Errorf("rates used = %+v, want %+v", got, want)
+	}
+
+	// in EUR the USD row is converted the other way and the EUR rows are not
+	sum, err = f.svc.Analytics.SpendSummary(ctx, f.owner, "2024-03", "eur")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sum.Currency != "EUR" || sum.TotalExpense != 30000+417 || len(sum.Rates) != 1 {
+		t.Errorf("summary in EUR = %d %s with rates %+v, want 30417 EUR with one rate", sum.TotalExpense, sum.Currency, sum.Rates)
+	}
+}
+
+func TestSpendSummaryMissingRate(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	// the rate of the 1st covers a week; the 12th falls in the gap
+	mustInsert(t, f.db, eurRate(1, 1.10), eurRate(20, 1.20))
+	spend(t, f, 2, 10000, "EUR")
+	spend(t, f, 12, 10000, "EUR")
+	spend(t, f, 21, 10000, "EUR")
+	spend(t, f, 12, 500, "USD")
+
+	_, err := f.svc.Analytics.SpendSummary(ctx, f.owner, "2024-03", "")
+	var missing *fx.MissingRateError
+	if !errors.As(err, &missing) {
+		t.Fatalf("SpendSummary = %v, want a missing rate", err)
+	}
+	if missing.From != "EUR" || missing.To != "USD" || missing.Date.Format("2006-01-02") != "2024-03-12" {
+		t.Errorf("missing rate = %s/%s on %s, want EUR/USD on 2024-03-12", missing.From, missing.To, missing.Date.Format("2006-01-02"))
+	}
+
+	// a month without foreign spending needs no rates
+	if _, err := f.svc.Analytics.SpendSummary(ctx, f.owner, "2024-02", ""); err != nil {
+		t.Errorf("SpendSummary of a month without foreign spending: %v", err)
+	}
+	if _, err := f.svc.Analytics.SpendSummary(ctx, f.owner, "2024-03", "GBP"); !errors.As(err, &missing) || missing.To != "GBP" {
+		t.Errorf("SpendSummary in a currency without rates = %v, want a missing GBP rate", err)
+	}
+}
@@ -0,0 +1,429 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sort"
+	"time"
+
+	"budgex_backend/internal/apperr"
+	"budgex_backend/internal/models"
+	"budgex_backend/internal/money"
+	"budgex_backend/internal/settings"
+	"budgex_backend/internal/store"
+	"budgex_backend/internal/validate"
+)
+
+// Budgets plans spending per category and month and reports against it.
+type Budgets struct{ st store.Stores }
+
+// BudgetInput sets the budget of one category for one month.
+type BudgetInput struct {
+	Month      string       `json:"month" validate:"required,month"` // "YYYY-MM"
+	CategoryID string       `json:"category_id" validate:"required,uuid,ref=category"`
+	Amount     money.Amount `json:"amount" validate:"gte=0"`
+}
+
+// Rollover turns rollover on or off for a category.
+type Rollover struct {
+	CategoryID string `json:"category_id" validate:"required,uuid"`
+	Enabled    bool   `json:"enabled"`
+}
+
+func init() {
+	validate.Register(BudgetInput{}, Rollover{})
+}
+
+// A budget is "near" once this share of it has been spent.
+const budgetNearThreshold = 0.9
+
+const (
+	BudgetUnder = "under"
+	BudgetNear  = "near"
+	BudgetOver  = "over"
+)
+
+type BudgetProgressRow struct {
+	CategoryID  *string      `json:"category_id"`
+	Category    *string      `json:"category,omitempty"`
+	Budgeted    money.Amount `json:"budgeted"`
+	Rollover    bool         `json:"rollover"`
+	CarriedIn   money.Amount `json:"carried_in"` // leftover (+) or overspend (-) from earlier months
+	Available   money.Amount `json:"available"`  // budgeted + carried_in
+	Actual      money.Amount `json:"actual"`
+	Remaining   money.Amount `json:"remaining"`    // available - actual
+	PercentUsed *float64     `json:"percent_used"` // of available; null when nothing is available
+	Status      string       `json:"status"`       // under | near | over
+}
+
+type BudgetProgressResp struct {
+	Month         string              `json:"month"`    // YYYY-MM
+	Currency      string              `json:"currency"` // base currency; spend in others is converted
+	TotalBudgeted money.Amount        `json:"total_budgeted"`
+	TotalActual   money.Amount        `json:"total_actual"`
+	ReadyToAssign money.Amount        `json:"ready_to_assign"`
+	Budgeted      []BudgetProgressRow `json:"budgeted"`
+	Unbudgeted    []BudgetProgressRow `json:"unbudgeted"` // spend with no budget on its category path
+}
+
+type ReadyToAssignResp struct {
+	Month         string       `json:"month"`    // YYYY-MM
+	Currency      string       `json:"currency"` // base currency
+	Income        money.Amount `json:"income"`   // income received up to the end of month
+	Assigned      money.Amount `json:"assigned"` // budgets of month and every earlier month
+	ReadyToAssign money.Amount `json:"ready_to_assign"`
+}
+
+// List returns the budgets of month, by default the current budget month.
+func (s *Budgets) List(ctx context.Context, m Member, month string) ([]models.Budget, error) {
+	if month == "" {
+		cal, err := calendar(ctx, s.st, m.UserID)
+		if err != nil {
+			return nil, err
+		}
+		month = cal.Month(time.Now())
+	}
+	return s.st.Budgets.List(ctx, m.HouseholdID, month)
+}
+
+// Upsert sets the budget of a category for a month.
+func (s *Budgets) Upsert(ctx context.Context, m Member, in BudgetInput) (*models.Budget, error) {
+	if err := m.RequireEditor(); err != nil {
+		return nil, err
+	}
+	if err := check(ctx, s.st, m, in); err != nil {
+		return nil, err
+	}
+	row := models.Budget{
+		HouseholdBase: models.HouseholdBase{HouseholdID: m.HouseholdID, CreatedBy: m.UserID},
+		Month:         in.Month,
+		CategoryID:    in.CategoryID,
+		Amount:        in.Amount,
+	}
+	if err := s.st.Budgets.Upsert(ctx, &row); err != nil {
+		return nil, err
+	}
+	return &row, nil
+}
+
+// SetRollover turns budget rollover on or off for a category.
+func (s *Budgets) SetRollover(ctx context.Context, m Member, in Rollover) (*models.Category, error) {
+	if err := m.RequireEditor(); err != nil {
+		return nil, err
+	}
+	if err := validate.Struct(in); err != nil {
+		return nil, err
+	}
+	err := s.st.Categories.Update(ctx, m.HouseholdID, in.CategoryID, map[string]any{"budget_rollover": in.Enabled})
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, apperr.NotFound()
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.st.Categories.Get(ctx, m.HouseholdID, in.CategoryID)
+}
+
+// categoryInfo is the part of the category tree the budget reports need.
+type categoryInfo struct {
+	parents  map[string]string // id -> parent id, "" for top-level
+	names    map[string]string
+	rollover map[string]bool
+}
+
+func loadCategoryInfo(ctx context.Context, st store.Stores, hid string) (categoryInfo, error) {
+	cats, err := st.Categories.List(ctx, hid, true)
+	if err != nil {
+		return categoryInfo{}, err
+	}
+	ci := categoryInfo{
+		parents:  make(map[string]string, len(cats)),
+		names:    make(map[string]string, len(cats)),
+		rollover: map[string]bool{},
+	}
+	for _, c := range cats {
+		ci.parents[c.ID], ci.names[c.ID] = "", c.Name
+		if c.ParentID != nil {
+			ci.parents[c.ID] = *c.ParentID
+		}
+		if c.BudgetRollover {
+			ci.rollover[c.ID] = true
+		}
+	}
+	return ci, nil
+}
+
+// rollUpTo walks from id towards the root and returns the first category
+// accepted by stop, or the root itself when none is. Parents that are unknown
+// (deleted, another household's) end the walk.
+func (ci categoryInfo) rollUpTo(id string, stop func(string) bool) string {
+	seen := map[string]bool{}
+	for !stop(id) && !seen[id] {
+		seen[id] = true
+		p, ok := ci.parents[id]
+		if !ok || p == "" {
+			break
+		}
+		if _, known := ci.parents[p]; !known {
+			break
+		}
+		id = p
+	}
+	return id
+}
+
+func (ci categoryInfo) name(id string) *string {
+	if n, ok := ci.names[id]; ok {
+		return &n
+	}
+	return nil
+}
+
+func budgetStatus(available, actual money.Amount) (*float64, string) {
+	if available <= 0 {
+		if actual > 0 || available < 0 {
+			return nil, BudgetOver
+		}
+		return nil, BudgetUnder
+	}
+	pct := math.Round(actual.Float64()/available.Float64()*10000) / 100
+	switch {
+	case actual > available:
+		return &pct, BudgetOver
+	case actual.Float64() >= available.Float64()*budgetNearThreshold:
+		return &pct, BudgetNear
+	}
+	return &pct, BudgetUnder
+}
+
+type categorySpend struct {
+	CategoryID *string
+	Month      string
+	Total      money.Amount
+}
+
+// expenseByCategoryMonth sums expense lines in [from, to) per category and
+// budget month of cal, converted into cur.
+func (s *Budgets) expenseByCategoryMonth(ctx context.Context, m Member, cur string, cal settings.Calendar, from, to time.Time) ([]categorySpend, error) {
+	rows, err := s.st.Analytics.ExpenseByCategory(ctx, m.HouseholdID, cal, from, to)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := convertDaySums(ctx, s.st, m.UserID, cur, rows); err != nil {
+		return nil, err
+	}
+	type key struct{ category, month string }
+	idx := map[key]int{}
+	var out []categorySpend
+	for _, r := range rows {
+		k := key{month: cal.MonthOfDay(r.Day)}
+		if r.Key != nil {
+			k.category = *r.Key
+		}
+		i, ok := idx[k]
+		if !ok {
+			i = len(out)
+			idx[k] = i
+			out = append(out, categorySpend{CategoryID: r.Key, Month: k.month})
+		}
+		out[i].Total += r.Total
+	}
+	return out, nil
+}
+
+// carriedIn returns, for every rollover category budgeted before month, the
+// sum of its earlier budgets minus the spend attributed to it since its first
+// budgeted month. Spend is attributed the same way as in the current month.
+func (s *Budgets) carriedIn(ctx context.Context, m Member, cur string, cal settings.Calendar, month string, from time.Time, ci categoryInfo, attribute func(string) string) (map[string]money.Amount, error) {
+	ids := make([]string, 0, len(ci.rollover))
+	for id := range ci.rollover {
+		ids = append(ids, id)
+	}
+	if len(ids) == 0 {
+		return map[string]money.Amount{}, nil
+	}
+	past, err := s.st.Budgets.Before(ctx, m.HouseholdID, month, ids)
+	if err != nil {
+		return nil, err
+	}
+	out := map[string]money.Amount{}
+	first := map[string]string{}
+	earliest := month
+	for _, p := range past {
+		out[p.CategoryID] = p.Total
+		first[p.CategoryID] = p.First
+		if p.First < earliest {
+			earliest = p.First
+		}
+	}
+	if len(out) == 0 {
+		return out, nil
+	}
+	start, _, err := cal.MonthRange(earliest)
+	if err != nil {
+		return nil, err
+	}
+	spend, err := s.expenseByCategoryMonth(ctx, m, cur, cal, start, from)
+	if err != nil {
+		return nil, err
+	}
+	for _, sp := range spend {
+		if sp.CategoryID == nil {
+			continue
+		}
+		target := attribute(*sp.CategoryID)
+		if f, ok := first[target]; ok && sp.Month >= f {
+			out[target] -= sp.Total
+		}
+	}
+	return out, nil
+}
+
+// readyToAssign is income received up to the end of month, converted into
+// cur, minus everything assigned to budgets in that month and before.
+func (s *Budgets) readyToAssign(ctx context.Context, m Member, cur string, cal settings.Calendar, month string, to time.Time) (ReadyToAssignResp, error) {
+	out := ReadyToAssignResp{Month: month, Currency: cur}
+	income, err := s.st.Analytics.IncomeBefore(ctx, m.HouseholdID, cal, to)
+	if err != nil {
+		return out, err
+	}
+	if _, err := convertDaySums(ctx, s.st, m.UserID, cur, income); err != nil {
+		return out, err
+	}
+	for _, r := range income {
+		out.Income += r.Total
+	}
+	if out.Assigned, err = s.st.Budgets.Assigned(ctx, m.HouseholdID, month); err != nil {
+		return out, err
+	}
+	out.ReadyToAssign = out.Income - out.Assigned
+	return out, nil
+}
+
+// Progress compares budgets with spend for a month (YYYY-MM, by default the
+// current one), in the user's base currency. Spend in a subcategory counts
+// towards the nearest budgeted category above it; spend with none is
+// reported under its top-level category. Rollover categories add what was
+// left (or overspent) in earlier months.
+func (s *Budgets) Progress(ctx context.Context, m Member, month string) (BudgetProgressResp, error) {
+	us, err := s.st.Settings.Get(ctx, m.UserID)
+	if err != nil {
+		return BudgetProgressResp{}, err
+	}
+	cur, cal := us.BaseCurrency, settings.CalendarOf(us)
+	month, from, to, err := monthOrNow(cal, month)
+	if err != nil {
+		return BudgetProgressResp{}, err
+	}
+	out := BudgetProgressResp{Month: month, Currency: cur, Budgeted: []BudgetProgressRow{}, Unbudgeted: []BudgetProgressRow{}}
+
+	budgets, err := s.st.Budgets.List(ctx, m.HouseholdID, month)
+	if err != nil {
+		return out, err
+	}
+	ci, err := loadCategoryInfo(ctx, s.st, m.HouseholdID)
+	if err != nil {
+		return out, err
+	}
+	budgeted := map[string]money.Amount{}
+	for _, b := range budgets {
+		budgeted[b.CategoryID] += b.Amount
+	}
+
+	isBudgeted := func(id string) bool { _, ok := budgeted[id]; return ok }
+	carry, err := s.carriedIn(ctx, m, cur, cal, month, from, ci, func(id string) string {
+		return ci.rollUpTo(id, func(id string) bool { return isBudgeted(id) || ci.rollover[id] })
+	})
+	if err != nil {
+		return out, err
+	}
+	// Rollover categories with a balance from earlier months stay on the list
+	// even without a budget this month.
+	for id := range carry {
+		if !isBudgeted(id) {
+			budgeted[id] = 0
+		}
+	}
+
+	spend, err := s.expenseByCategoryMonth(ctx, m, cur, cal, from, to)
+	if err != nil {
+		return out, err
+	}
+	actual := map[string]money.Amount{}   // budgeted category -> rolled-up spend
+	unbudget := map[string]money.Amount{} // top-level category -> spend ("" = uncategorized)
+	for _, sp := range spend {
+		out.TotalActual += sp.Total
+		if sp.CategoryID == nil {
+			unbudget[""] += sp.Total
+			continue
+		}
+		target := ci.rollUpTo(*sp.CategoryID, isBudgeted)
+		if isBudgeted(target) {
+			actual[target] += sp.Total
+		} else {
+			unbudget[target] += sp.Total
+		}
+	}
+
+	for id, amt := range budgeted {
+		id := id
+		available := amt + carry[id]
+		pct, status := budgetStatus(available, actual[id])
+		out.TotalBudgeted += amt
+		out.Budgeted = append(out.Budgeted, BudgetProgressRow{
+			CategoryID: &id, Category: ci.name(id),
+			Budgeted: amt, Rollover: ci.rollover[id], CarriedIn: carry[id], Available: available,
+			Actual: actual[id], Remaining: available - actual[id],
+			PercentUsed: pct, Status: status,
+		})
+	}
+	for id, amt := range unbudget {
+		row := BudgetProgressRow{Actual: amt, Remaining: -amt, Status: BudgetOver}
+		if id != "" {
+			id := id
+			row.CategoryID, row.Category = &id, ci.name(id)
+		}
+		out.Unbudgeted = append(out.Unbudgeted, row)
+	}
+
+	rta, err := s.readyToAssign(ctx, m, cur, cal, month, to)
+	if err != nil {
+		return out, err
+	}
+	out.ReadyToAssign = rta.ReadyToAssign
+
+	// by category name, then id for categories of the same name;
+	// uncategorized and unknown last
+	byName := func(rows []BudgetProgressRow) func(i, j int) bool {
+		return func(i, j int) bool {
+			a, b := rows[i].Category, rows[j].Category
+			if a == nil || b == nil {
+				return a != nil && b == nil
+			}
+			if *a != *b {
+				return *a < *b
+			}
+			x, y := rows[i].CategoryID, rows[j].CategoryID
+			return x != nil && y != nil && *x < *y
+		}
+	}
+	sort.SliceStable(out.Budgeted, byName(out.Budgeted))
+	sort.SliceStable(out.Unbudgeted, byName(out.Unbudgeted))
+	return out, nil
+}
+
+// ReadyToAssign is income received up to the end of month minus all budget
+// amounts up to and including month, in the base currency.
+func (s *Budgets) ReadyToAssign(ctx context.Context, m Member, month string) (ReadyToAssignResp, error) {
+	us, err := s.st.Settings.Get(ctx, m.UserID)
+	if err != nil {
+		return ReadyToAssignResp{}, err
+	}
+	cal := settings.CalendarOf(us)
+	month, _, to, err := monthOrNow(cal, month)
+	if err != nil {
+		return ReadyToAssignResp{}, err
+	}
+	return s.readyToAssign(ctx, m, us.BaseCurrency, cal, month, to)
+}
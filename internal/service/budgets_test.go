@@ -0,0 +1,238 @@
+package service_test
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+
+	"budgex_backend/internal/models"
+	"budgex_backend/internal/money"
+	"budgex_backend/internal/service"
+)
+
+func TestBudgetsRollover(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	food := f.category(t, "Food")
+	groceries := &models.Category{
+		HouseholdBase: models.HouseholdBase{HouseholdID: f.owner.HouseholdID, CreatedBy: "owner"},
+		Name:          "Groceries", ParentID: &food.ID,
+	}
+	mustInsert(t, f.db, groceries)
+	fun := f.category(t, "Fun")
+	home := f.category(t, "Home")
+
+	for _, id := range []string{food.ID, fun.ID} {
+		if _, err := f.svc.Budgets.SetRollover(ctx, f.owner, service.Rollover{CategoryID: id, Enabled: true}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	budget := func(month string, cat *models.Category, amount money.Amount) {
+		t.Helper()
+		if _, err := f.svc.Budgets.Upsert(ctx, f.owner, service.BudgetInput{Month: month, CategoryID: cat.ID, Amount: amount}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	spend := func(date string, cat *models.Category, amount money.Amount) {
+		t.Helper()
+		if _, err := f.svc.Transactions.Create(ctx, f.owner, service.NewTransaction{
+			Type: "expense", Date: &date, Amount: amount, CategoryID: &cat.ID,
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	budget("2026-01", food, 10000)
+	budget("2026-01", fun, 2000)
+	budget("2026-01", home, 5000)
+	spend("2026-01-10", food, 3000)
+	spend("2026-01-20", fun, 5000)
+	budget("2026-02", food, 10000)
+	budget("2026-02", home, 5000)
+	spend("2026-02-03", groceries, 15000) // rolls up to Food
+
+	type row struct{ budgeted, carried, available, actual money.Amount }
+	tests := []struct {
+		month string
+		want  map[string]row
+	}{
+		{"2026-01", map[string]row{
+			"Food": {10000, 0, 10000, 3000},
+			"Fun":  {2000, 0, 2000, 5000},
+			"Home": {5000, 0, 5000, 0},
+		}},
+		{"2026-02", map[string]row{
+			"Food": {10000, 7000, 17000, 15000}, // 30.00 of 100.00 spent in January
+			"Fun":  {0, -3000, -3000, 0},        // overspent, listed without a budget
+			"Home": {5000, 0, 5000, 0},          // no rollover
+		}},
+		{"2026-03", map[string]row{
+			"Food": {0, 2000, 2000, 0}, // 200.00 budgeted, 180.00 spent
+			"Fun":  {0, -3000, -3000, 0},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.month, func(t *testing.T) {
+			p, err := f.svc.Budgets.Progress(ctx, f.owner, tt.month)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := map[string]row{}
+			for _, r := range p.Budgeted {
+				got[*r.Category] = row{r.Budgeted, r.CarriedIn, r.Available, r.Actual}
+				if r.Remaining != r.Available-r.Actual {
+					t.Errorf("%s: remaining %v, want %v", *r.Category, r.Remaining, r.Available-r.Actual)
+				}
+			}
+			if len(got) != len(tt.want) {
+				t.Errorf("budgeted rows %v, want %v", got, tt.want)
+			}
+			for name, w := range tt.want {
+				if got[name] != w {
+					t.Errorf("%s = %+v, want %+v", name, got[name], w)
+				}
+			}
+		})
+	}
+
+	// turning rollover off forgets the earlier months
+	if _, err := f.svc.Budgets.SetRollover(ctx, f.owner, service.Rollover{CategoryID: food.ID, Enabled: false}); err != nil {
+		t.Fatal(err)
+	}
+	p, err := f.svc.Budgets.Progress(ctx, f.owner, "2026-02")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range p.Budgeted {
+		if *r.Category == "Food" && (r.Rollover || r.CarriedIn != 0) {
+			t.Errorf("Food after rollover off: rollover %v, carried in %v", r.Rollover, r.CarriedIn)
+		}
+	}
+}
+
+func TestBudgetsSetRolloverValidation(t *testing.T) {
+	f := newFixture(t)
+	viewer := f.member(t, "viewer", models.RoleViewer)
+	food := f.category(t, "Food")
+
+	tests := []struct {
+		name string
+		m    service.Member
+		in   service.Rollover
+		want string
+	}{
+		{"viewer", viewer, service.Rollover{CategoryID: food.ID, Enabled: true}, "household_read_only"},
+		{"no category", f.owner, service.Rollover{Enabled: true}, "category_id:category_id_required"},
+		{"bad id", f.owner, service.Rollover{CategoryID: "food", Enabled: true}, "category_id:category_id_must_be_uuid"},
+		{"unknown", f.owner, service.Rollover{CategoryID: "6f1c2f43-9a4e-4d4b-8f61-1d2b4b7a0c11", Enabled: true}, "not_found"},
+		{"ok", f.owner, service.Rollover{CategoryID: food.ID, Enabled: true}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := f.svc.Budgets.SetRollover(context.Background(), tt.m, tt.in)
+			if got := code(err); got != tt.want {
+				t.Errorf("SetRollover = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBudgetsProgressOrder(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	spend := func(cat *models.Category) {
+		t.Helper()
+		in := service.NewTransaction{Type: "expense", Date: ptr("2026-02-03"), Amount: 100}
+		if cat != nil {
+			in.CategoryID = &cat.ID
+		}
+		if _, err := f.svc.Transactions.Create(ctx, f.owner, in); err != nil {
+			t.Fatal(err)
+		}
+	}
+	spend(nil)
+	spend(f.category(t, "🍕 Pizza")) // sorts after U+FFFF
+	spend(f.category(t, "Zoo"))
+	spend(f.category(t, "Apples"))
+
+	p, err := f.svc.Budgets.Progress(ctx, f.owner, "2026-02")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, r := range p.Unbudgeted {
+		name := "<none>"
+		if r.Category != nil {
+			name = *r.Category
+		}
+		got = append(got, name)
+	}
+	if want := []string{"Apples", "Zoo", "🍕 Pizza", "<none>"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("unbudgeted rows %v, want %v", got, want)
+	}
+}
+
+func TestBudgetsProgressOrderTies(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	// names are unique only among live siblings, so two top-level
+	// categories can share one
+	var ids []string
+	for range 3 {
+		c := f.category(t, "Food")
+		ids = append(ids, c.ID)
+		if _, err := f.svc.Transactions.Create(ctx, f.owner, service.NewTransaction{
+			Type: "expense", Date: ptr("2026-02-03"), Amount: 100, CategoryID: &c.ID,
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	sort.Strings(ids)
+	for range 5 {
+		p, err := f.svc.Budgets.Progress(ctx, f.owner, "2026-02")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, r := range p.Unbudgeted {
+			got = append(got, *r.CategoryID)
+		}
+		if !reflect.DeepEqual(got, ids) {
+			t.Fatalf("rows of the same name %v, want by id %v", got, ids)
+		}
+	}
+}
+
+func TestMonthParameter(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	calls := map[string]func(month string) error{
+		"progress": func(month string) error {
+			_, err := f.svc.Budgets.Progress(ctx, f.owner, month)
+			return err
+		},
+		"ready to assign": func(month string) error {
+			_, err := f.svc.Budgets.ReadyToAssign(ctx, f.owner, month)
+			return err
+		},
+		"spend summary": func(month string) error {
+			_, err := f.svc.Analytics.SpendSummary(ctx, f.owner, month, "")
+			return err
+		},
+	}
+	for name, call := range calls {
+		t.Run(name, func(t *testing.T) {
+			for _, bad := range []string{"2026-13", "2026-2", "March", "2026-02-01"} {
+				if got := code(call(bad)); got != "month:invalid_month" {
+					t.Errorf("month %q = %q, want month:invalid_month", bad, got)
+				}
+			}
+			// an empty month means the current one
+			for _, ok := range []string{"", "2026-02"} {
+				if err := call(ok); err != nil {
+					t.Errorf("month %q = %v", ok, err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,269 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"budgex_backend/internal/apperr"
+	"budgex_backend/internal/models"
+	"budgex_backend/internal/seed"
+	"budgex_backend/internal/store"
+	"budgex_backend/internal/validate"
+)
+
+// Categories manages the category tree.
+type Categories struct{ st store.Stores }
+
+// NewCategory is what a category is created from.
+type NewCategory struct {
+	Name     string  `json:"name" validate:"required,max=100"`
+	ParentID *string `json:"parent_id" validate:"uuid"`
+}
+
+// CategoryPatch changes only the fields that are set.
+type CategoryPatch struct {
+	Name     *string `json:"name" validate:"notempty,max=100"`
+	ParentID *string `json:"parent_id" validate:"uuid"` // "" moves the category to the top level
+}
+
+func init() {
+	validate.Register(NewCategory{}, CategoryPatch{})
+}
+
+// CategoryNode is a category with its children, for the tree view.
+type CategoryNode struct {
+	models.Category
+	Children []*CategoryNode `json:"children"`
+}
+
+// get loads a live category the caller acts on.
+func (s *Categories) get(ctx context.Context, hid, id string) (*models.Category, error) {
+	cat, err := s.st.Categories.Get(ctx, hid, id)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, apperr.NotFound()
+	}
+	return cat, err
+}
+
+// checkParent validates a new parent for category id ("" for a new
+// category).
+func (s *Categories) checkParent(ctx context.Context, hid, id, parentID string) error {
+	if parentID == id {
+		return invalid("parent_id", "category_cycle")
+	}
+	if _, err := s.st.Categories.Get(ctx, hid, parentID); err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return invalid("parent_id", "parent_not_found")
+		}
+		return err
+	}
+	if id == "" {
+		return nil
+	}
+	// the new parent must not sit below the category itself
+	below, err := s.st.Categories.InTree(ctx, hid, id, parentID)
+	if err != nil {
+		return err
+	}
+	if below {
+		return invalid("parent_id", "category_cycle")
+	}
+	return nil
+}
+
+// List returns the household's categories by name.
+func (s *Categories) List(ctx context.Context, m Member, includeArchived bool) ([]models.Category, error) {
+	return s.st.Categories.List(ctx, m.HouseholdID, includeArchived)
+}
+
+// Tree nests the categories under their parents. Categories whose parent is
+// missing are listed at the top level.
+func (s *Categories) Tree(ctx context.Context, m Member, includeArchived bool) ([]*CategoryNode, error) {
+	cats, err := s.st.Categories.List(ctx, m.HouseholdID, includeArchived)
+	if err != nil {
+		return nil, err
+	}
+	nodes := make(map[string]*CategoryNode, len(cats))
+	for _, cat := range cats {
+		nodes[cat.ID] = &CategoryNode{Category: cat, Children: []*CategoryNode{}}
+	}
+	roots := []*CategoryNode{}
+	for _, cat := range cats { // keeps name order among siblings
+		n := nodes[cat.ID]
+		if cat.ParentID != nil {
+			if p, ok := nodes[*cat.ParentID]; ok {
+				p.Children = append(p.Children, n)
+				continue
+			}
+		}
+		roots = append(roots, n)
+	}
+	return roots, nil
+}
+
+// Create adds a category.
+func (s *Categories) Create(ctx context.Context, m Member, in NewCategory) (*models.Category, error) {
+	if err := m.RequireEditor(); err != nil {
+		return nil, err
+	}
+	// names are checked as they are stored, so blanks are not a name
+	in.Name = strings.TrimSpace(in.Name)
+	if err := validate.Struct(in); err != nil {
+		return nil, err
+	}
+	in.ParentID = models.NilIfEmpty(in.ParentID)
+	if in.ParentID != nil {
+		if err := s.checkParent(ctx, m.HouseholdID, "", *in.ParentID); err != nil {
+			return nil, err
+		}
+	}
+	cat := models.Category{
+		HouseholdBase: models.HouseholdBase{HouseholdID: m.HouseholdID, CreatedBy: m.UserID},
+		Name:          in.Name,
+		ParentID:      in.ParentID,
+	}
+	if err := s.st.Categories.Create(ctx, &cat); err != nil {
+		return nil, err
+	}
+	return &cat, nil
+}
+
+// Seed adds the default categories of locale that the household does not
+// have.
+func (s *Categories) Seed(ctx context.Context, m Member, locale string) (seed.Result, error) {
+	if err := m.RequireEditor(); err != nil {
+		return seed.Result{}, err
+	}
+	return s.st.Categories.Seed(ctx, m.HouseholdID, m.UserID, locale)
+}
+
+// Update renames or moves a category.
+func (s *Categories) Update(ctx context.Context, m Member, id string, in CategoryPatch) (*models.Category, error) {
+	if err := m.RequireEditor(); err != nil {
+		return nil, err
+	}
+	cat, err := s.get(ctx, m.HouseholdID, id)
+	if err != nil {
+		return nil, err
+	}
+	if in.Name != nil {
+		name := strings.TrimSpace(*in.Name)
+		in.Name = &name
+	}
+	if err := validate.Struct(in); err != nil {
+		return nil, err
+	}
+	changes := map[string]any{}
+	if in.Name != nil {
+		changes["name"] = *in.Name
+	}
+	if in.ParentID != nil {
+		parent := models.NilIfEmpty(in.ParentID)
+		if parent != nil {
+			if err := s.checkParent(ctx, m.HouseholdID, cat.ID, *parent); err != nil {
+				return nil, err
+			}
+		}
+		changes["parent_id"] = parent
+	}
+	if len(changes) > 0 {
+		if err := s.st.Categories.Update(ctx, m.HouseholdID, cat.ID, changes); err != nil {
+			return nil, err
+		}
+	}
+	return s.st.Categories.Get(ctx, m.HouseholdID, cat.ID)
+}
+
+// SetArchived hides a category from lists, or shows it again. Its
+// transactions and budgets are kept.
+func (s *Categories) SetArchived(ctx context.Context, m Member, id string, archived bool) (*models.Category, error) {
+	if err := m.RequireEditor(); err != nil {
+		return nil, err
+	}
+	return s.set(ctx, m.HouseholdID, id, "archived", archived)
+}
+
+func (s *Categories) set(ctx context.Context, hid, id, col string, v any) (*models.Category, error) {
+	err := s.st.Categories.Update(ctx, hid, id, map[string]any{col: v})
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, apperr.NotFound()
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.st.Categories.Get(ctx, hid, id)
+}
+
+// Delete removes a category. While transactions use it, it is refused unless
+// replacementID names a category to move them to first. Subcategories move
+// up to the deleted category's parent.
+func (s *Categories) Delete(ctx context.Context, m Member, id, replacementID string) error {
+	if err := m.RequireEditor(); err != nil {
+		return err
+	}
+	cat, err := s.get(ctx, m.HouseholdID, id)
+	if err != nil {
+		return err
+	}
+	if replacementID != "" {
+		target, err := s.mergeTarget(ctx, m.HouseholdID, cat, replacementID)
+		if err != nil {
+			return err
+		}
+		return s.st.Tx.InTx(ctx, func(ctx context.Context) error {
+			return s.st.Categories.Merge(ctx, m.HouseholdID, cat, target)
+		})
+	}
+
+	n, err := s.st.Categories.CountTransactions(ctx, m.HouseholdID, cat.ID)
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		return apperr.Conflict("category_has_transactions").With("transactions", n)
+	}
+	return s.st.Tx.InTx(ctx, func(ctx context.Context) error {
+		return s.st.Categories.Delete(ctx, m.HouseholdID, cat)
+	})
+}
+
+// Merge moves everything filed under category id to targetID and deletes
+// it, returning the target.
+func (s *Categories) Merge(ctx context.Context, m Member, id, targetID string) (*models.Category, error) {
+	if err := m.RequireEditor(); err != nil {
+		return nil, err
+	}
+	cat, err := s.get(ctx, m.HouseholdID, id)
+	if err != nil {
+		return nil, err
+	}
+	target, err := s.mergeTarget(ctx, m.HouseholdID, cat, targetID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.st.Tx.InTx(ctx, func(ctx context.Context) error {
+		return s.st.Categories.Merge(ctx, m.HouseholdID, cat, target)
+	}); err != nil {
+		return nil, err
+	}
+	return s.st.Categories.Get(ctx, m.HouseholdID, target.ID)
+}
+
+// mergeTarget loads the category that receives src's data.
+func (s *Categories) mergeTarget(ctx context.Context, hid string, src *models.Category, targetID string) (*models.Category, error) {
+	if targetID == "" {
+		return nil, invalid("target_id", "target_id_required")
+	}
+	if targetID == src.ID {
+		return nil, invalid("target_id", "cannot_merge_into_itself")
+	}
+	target, err := s.st.Categories.Get(ctx, hid, targetID)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, invalid("target_id", "target_not_found")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return target, nil
+}
@@ -0,0 +1,257 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"budgex_backend/internal/models"
+	"budgex_backend/internal/money"
+	"budgex_backend/internal/service"
+	"budgex_backend/internal/store"
+)
+
+func TestCategoriesMerge(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	st := f.db.Stores()
+	hid := f.owner.HouseholdID
+
+	food := f.category(t, "Food")
+	home := f.category(t, "Home")
+	groceries := &models.Category{
+		HouseholdBase: models.HouseholdBase{HouseholdID: hid, CreatedBy: "owner"},
+		Name:          "Groceries", ParentID: &food.ID,
+	}
+	mustInsert(t, f.db, groceries)
+	snacks := &models.Category{
+		HouseholdBase: models.HouseholdBase{HouseholdID: hid, CreatedBy: "owner"},
+		Name:          "Snacks", ParentID: &groceries.ID,
+	}
+	mustInsert(t, f.db, snacks)
+
+	tx, err := f.svc.Transactions.Create(ctx, f.owner, service.NewTransaction{Type: "expense", Amount: 1200, CategoryID: &groceries.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	split, err := f.svc.Transactions.Create(ctx, f.owner, service.NewTransaction{
+		Type: "expense", Amount: 1000,
+		Splits: []service.Split{{CategoryID: &groceries.ID, Amount: 700}, {CategoryID: &home.ID, Amount: 300}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// another member's rule names the category too
+	rule := &models.Rule{Base: models.Base{UserID: "editor"}, Name: "Shop", Active: true, SetCategoryID: &groceries.ID}
+	mustInsert(t, f.db, rule)
+	for _, b := range []service.BudgetInput{
+		{Month: "2026-01", CategoryID: groceries.ID, Amount: 4000},
+		{Month: "2026-01", CategoryID: home.ID, Amount: 5000},
+		{Month: "2026-02", CategoryID: groceries.ID, Amount: 1000},
+	} {
+		if _, err := f.svc.Budgets.Upsert(ctx, f.owner, b); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := f.svc.Categories.Merge(ctx, f.owner, groceries.ID, home.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.ID != home.ID {
+		t.Fatalf("Merge returned %q, want the target %q", got.ID, home.ID)
+	}
+
+	if _, err := st.Categories.Get(ctx, hid, groceries.ID); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("merged category still there: %v", err)
+	}
+	moved, err := st.Transactions.Get(ctx, hid, tx.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if moved.CategoryID == nil || *moved.CategoryID != home.ID {
+		t.Errorf("transaction category = %v, want Home", moved.CategoryID)
+	}
+	if moved, err = st.Transactions.Get(ctx, hid, split.ID); err != nil {
+		t.Fatal(err)
+	}
+	for _, sp := range moved.Splits {
+		if sp.CategoryID == nil || *sp.CategoryID != home.ID {
+			t.Errorf("split line category = %v, want Home", sp.CategoryID)
+		}
+	}
+	sub, err := st.Categories.Get(ctx, hid, snacks.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sub.ParentID == nil || *sub.ParentID != home.ID {
+		t.Errorf("subcategory parent = %v, want Home", sub.ParentID)
+	}
+	rs, err := st.Rules.Active(ctx, "editor")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rs) != 1 || rs[0].SetCategoryID == nil || *rs[0].SetCategoryID != home.ID {
+		t.Errorf("rule category = %+v, want Home", rs)
+	}
+	// budgets of the same month add up
+	for month, want := range map[string]money.Amount{"2026-01": 9000, "2026-02": 1000} {
+		bs, err := f.svc.Budgets.List(ctx, f.owner, month)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(bs) != 1 || bs[0].CategoryID != home.ID || bs[0].Amount != want {
+			t.Errorf("%s budgets = %+v, want one for Home of %v", month, bs, want)
+		}
+	}
+}
+
+func TestCategoriesMergeIntoChild(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	food := f.category(t, "Food")
+	groceries := &models.Category{
+		HouseholdBase: models.HouseholdBase{HouseholdID: f.owner.HouseholdID, CreatedBy: "owner"},
+		Name:          "Groceries", ParentID: &food.ID,
+	}
+	mustInsert(t, f.db, groceries)
+
+	// the target takes the merged category's place in the tree
+	got, err := f.svc.Categories.Merge(ctx, f.owner, food.ID, groceries.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.ParentID != nil {
+		t.Errorf("target parent = %v, want top level", *got.ParentID)
+	}
+}
+
+func TestCategoriesDeleteDetachesRules(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	food := f.category(t, "Food")
+	rule := &models.Rule{Base: models.Base{UserID: "owner"}, Name: "Shop", Active: true,
+		SetCategoryID: &food.ID, AddTags: ptr("food")}
+	mustInsert(t, f.db, rule)
+
+	if err := f.svc.Categories.Delete(ctx, f.owner, food.ID, ""); err != nil {
+		t.Fatal(err)
+	}
+	rs, err := f.db.Stores().Rules.Active(ctx, "owner")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rs) != 1 || rs[0].SetCategoryID != nil {
+		t.Errorf("rules = %+v, want the category cleared", rs)
+	}
+}
+
+func TestCategoriesMergeValidation(t *testing.T) {
+	f := newFixture(t)
+	viewer := f.member(t, "viewer", models.RoleViewer)
+	food := f.category(t, "Food")
+	home := f.category(t, "Home")
+	unknown := "6f1c2f43-9a4e-4d4b-8f61-1d2b4b7a0c11"
+
+	tests := []struct {
+		name       string
+		m          service.Member
+		id, target string
+		want       string
+	}{
+		{"viewer", viewer, food.ID, home.ID, "household_read_only"},
+		{"unknown source", f.owner, unknown, home.ID, "not_found"},
+		{"no target", f.owner, food.ID, "", "target_id:target_id_required"},
+		{"into itself", f.owner, food.ID, food.ID, "target_id:cannot_merge_into_itself"},
+		{"unknown target", f.owner, food.ID, unknown, "target_id:target_not_found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := f.svc.Categories.Merge(context.Background(), tt.m, tt.id, tt.target)
+			if got := code(err); got != tt.want {
+				t.Errorf("Merge = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCategoriesSeed(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	// a default the household already has, in other case, is reused
+	food := f.category(t, "food")
+
+	first, err := f.svc.Categories.Seed(ctx, f.owner, "en")
+	if err != nil {
+		t.Fatal(err)
+	}
+	all, err := f.svc.Categories.List(ctx, f.owner, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.Skipped != 1 || first.Created != len(all)-1 {
+		t.Errorf("first seed = %+v with %d categories", first, len(all))
+	}
+	for _, c := range all {
+		if (c.Name == "Groceries" || c.Name == "Dining") && (c.ParentID == nil || *c.ParentID != food.ID) {
+			t.Errorf("%s parent = %v, want the existing food", c.Name, c.ParentID)
+		}
+	}
+
+	again, err := f.svc.Categories.Seed(ctx, f.owner, "en")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.Created != 0 || again.Skipped != len(all) {
+		t.Errorf("second seed = %+v, want all %d skipped", again, len(all))
+	}
+
+	viewer := f.member(t, "viewer", models.RoleViewer)
+	if _, err := f.svc.Categories.Seed(ctx, viewer, "en"); code(err) != "household_read_only" {
+		t.Errorf("Seed by viewer = %q, want household_read_only", code(err))
+	}
+}
+
+func TestCategoriesName(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	food := f.category(t, "Food")
+	long := strings.Repeat("x", 100)
+
+	tests := []struct {
+		name string
+		in   string
+		want string // the stored name, or the error code
+	}{
+		{"blank", " ", "name:name_required"},
+		{"whitespace", "\t\n ", "name:name_required"},
+		{"empty", "", "name:name_required"},
+		{"trimmed", "  Groceries ", "Groceries"},
+		{"long after trimming", " " + long + " ", long},
+		{"too long", long + "y", "name:name_too_long"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cat, err := f.svc.Categories.Create(ctx, f.owner, service.NewCategory{Name: tt.in})
+			if err == nil {
+				err = f.svc.Categories.Delete(ctx, f.owner, cat.ID, "")
+			}
+			if got := result(cat, err); got != tt.want {
+				t.Errorf("Create = %q, want %q", got, tt.want)
+			}
+			cat, err = f.svc.Categories.Update(ctx, f.owner, food.ID, service.CategoryPatch{Name: &tt.in})
+			if got := result(cat, err); got != tt.want {
+				t.Errorf("Update = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// result is the category's name, or the code of err.
+func result(cat *models.Category, err error) string {
+	if err != nil {
+		return code(err)
+	}
+	return cat.Name
+}
@@ -0,0 +1,214 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"budgex_backend/internal/apperr"
+	"budgex_backend/internal/dedupe"
+	"budgex_backend/internal/models"
+	"budgex_backend/internal/money"
+	"budgex_backend/internal/rules"
+	"budgex_backend/internal/store"
+	"budgex_backend/internal/validate"
+)
+
+const (
+	DefaultDuplicateDays = 3
+	maxDuplicatePairs    = 5000
+)
+
+// DuplicateQuery selects where to look for duplicates. From and To are
+// YYYY-MM-DD, both inclusive; From defaults to one year ago.
+type DuplicateQuery struct {
+	Days int    `json:"days" validate:"gte=0,lte=31"`
+	From string `json:"from" validate:"date"`
+	To   string `json:"to" validate:"date"`
+}
+
+// DuplicateGroup is a set of transactions that look like the same payment.
+type DuplicateGroup struct {
+	Amount       money.Amount         `json:"amount"`
+	Transactions []models.Transaction `json:"transactions"` // oldest first
+}
+
+// MergeTransactions names the rows to merge.
+type MergeTransactions struct {
+	KeepID string   `json:"keep_id" validate:"required,uuid"`      // row that survives
+	IDs    []string `json:"ids" validate:"min=1,max=50,dive,uuid"` // rows folded into it and soft-deleted
+}
+
+func init() {
+	validate.Register(DuplicateQuery{}, MergeTransactions{})
+}
+
+// Duplicates groups income and expense rows with the same type and amount,
+// dated at most q.Days apart, whose payees look alike.
+func (s *Transactions) Duplicates(ctx context.Context, m Member, q DuplicateQuery) ([]DuplicateGroup, error) {
+	if err := validate.Struct(q); err != nil {
+		return nil, err
+	}
+	cal, err := calendar(ctx, s.st, m.UserID)
+	if err != nil {
+		return nil, err
+	}
+	from := time.Now().UTC().AddDate(-1, 0, 0)
+	to := time.Now().UTC().AddDate(0, 0, 1)
+	if q.From != "" {
+		t, err := parseDate(q.From, cal.Loc)
+		if err != nil {
+			return nil, invalid("from", "from_must_be_YYYY-MM-DD")
+		}
+		from = t
+	}
+	if q.To != "" {
+		t, err := parseDate(q.To, cal.Loc)
+		if err != nil {
+			return nil, invalid("to", "to_must_be_YYYY-MM-DD")
+		}
+		to = t.AddDate(0, 0, 1)
+	}
+
+	rows, err := s.st.Transactions.DuplicatePairs(ctx, m.HouseholdID, q.Days, from, to, maxDuplicatePairs)
+	if err != nil {
+		return nil, err
+	}
+	var pairs [][2]string
+	for _, r := range rows {
+		if dedupe.SimilarPayees(strOrEmpty(r.APayee), strOrEmpty(r.BPayee)) {
+			pairs = append(pairs, [2]string{r.AID, r.BID})
+		}
+	}
+	groups := dedupe.Groups(pairs)
+	out := []DuplicateGroup{}
+	if len(groups) == 0 {
+		return out, nil
+	}
+
+	var ids []string
+	for _, g := range groups {
+		ids = append(ids, g...)
+	}
+	txs, err := s.st.Transactions.GetMany(ctx, m.HouseholdID, ids)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]models.Transaction, len(txs))
+	for _, t := range txs {
+		byID[t.ID] = t
+	}
+	for _, g := range groups {
+		grp := DuplicateGroup{}
+		for _, id := range g {
+			if t, ok := byID[id]; ok {
+				grp.Transactions = append(grp.Transactions, t)
+			}
+		}
+		if len(grp.Transactions) < 2 {
+			continue
+		}
+		sort.Slice(grp.Transactions, func(i, j int) bool {
+			a, b := grp.Transactions[i], grp.Transactions[j]
+			if !a.Date.Equal(b.Date) {
+				return a.Date.Before(b.Date)
+			}
+			return a.CreatedAt.Before(b.CreatedAt)
+		})
+		grp.Amount = grp.Transactions[0].Amount
+		out = append(out, grp)
+	}
+	return out, nil
+}
+
+// Merge keeps in.KeepID, soft-deletes the others and folds their tags and
+// memos into the kept row. A missing payee or category on the kept row is
+// taken from the others.
+func (s *Transactions) Merge(ctx context.Context, m Member, in MergeTransactions) (*models.Transaction, error) {
+	if err := m.RequireEditor(); err != nil {
+		return nil, err
+	}
+	if err := validate.Struct(in); err != nil {
+		return nil, err
+	}
+	keep, err := s.Get(ctx, m, in.KeepID)
+	if err != nil {
+		return nil, err
+	}
+	if keep.Type == models.TxTransfer {
+		return nil, invalid("keep_id", "cannot_merge_transfers")
+	}
+
+	seen := map[string]bool{keep.ID: true}
+	var others []models.Transaction
+	for _, id := range in.IDs {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		t, err := s.st.Transactions.Get(ctx, m.HouseholdID, id)
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, apperr.NotFound().With("id", id)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if t.Type != keep.Type {
+			return nil, invalid("ids", "cannot_merge_different_types")
+		}
+		others = append(others, *t)
+	}
+	if len(others) == 0 {
+		return nil, invalid("ids", "ids_required")
+	}
+
+	tags := strOrEmpty(keep.Tags)
+	memos := []string{}
+	memoSeen := map[string]bool{}
+	addMemo := func(memo *string) {
+		v := strings.TrimSpace(strOrEmpty(memo))
+		if v != "" && !memoSeen[strings.ToLower(v)] {
+			memoSeen[strings.ToLower(v)] = true
+			memos = append(memos, v)
+		}
+	}
+	addMemo(keep.Memo)
+	changes := map[string]any{}
+	for _, o := range others {
+		tags = rules.AddTags(tags, strOrEmpty(o.Tags))
+		addMemo(o.Memo)
+		if keep.Payee == nil && o.Payee != nil {
+			keep.Payee = o.Payee
+			changes["payee"] = o.Payee
+		}
+		if keep.CategoryID == nil && len(keep.Splits) == 0 && o.CategoryID != nil {
+			keep.CategoryID = o.CategoryID
+			changes["category_id"] = o.CategoryID
+		}
+	}
+	if tags != rules.AddTags(strOrEmpty(keep.Tags), "") {
+		changes["tags"] = models.NilIfEmpty(&tags)
+	}
+	if memo := strings.Join(memos, " | "); memo != strings.TrimSpace(strOrEmpty(keep.Memo)) {
+		changes["memo"] = models.NilIfEmpty(&memo)
+	}
+
+	ids := make([]string, len(others))
+	for i, o := range others {
+		ids[i] = o.ID
+	}
+	err = s.st.Tx.InTx(ctx, func(ctx context.Context) error {
+		if len(changes) > 0 {
+			if err := s.st.Transactions.Update(ctx, m.HouseholdID, keep.ID, changes); err != nil {
+				return err
+			}
+		}
+		return s.st.Transactions.DeleteMany(ctx, m.HouseholdID, ids)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.st.Transactions.Get(ctx, m.HouseholdID, keep.ID)
+}
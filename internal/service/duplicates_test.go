@@ -0,0 +1,112 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"budgex_backend/internal/models"
+	"budgex_backend/internal/service"
+)
+
+func TestTransactionsMerge(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	food := f.category(t, "Food")
+	own := models.HouseholdBase{HouseholdID: f.owner.HouseholdID, CreatedBy: "owner"}
+	day := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	keep := &models.Transaction{HouseholdBase: own, Type: models.TxExpense, Date: day, Amount: 1200,
+		Currency: "USD", Memo: ptr("lunch"), Tags: ptr("work")}
+	dup := &models.Transaction{HouseholdBase: own, Type: models.TxExpense, Date: day.AddDate(0, 0, 1), Amount: 1200,
+		Currency: "USD", Payee: ptr("Deli"), CategoryID: &food.ID, Memo: ptr("Lunch"), Tags: ptr("Work,team")}
+	dup2 := &models.Transaction{HouseholdBase: own, Type: models.TxExpense, Date: day, Amount: 1200,
+		Currency: "USD", Payee: ptr("DELI #2"), Memo: ptr("with Sam")}
+	mustInsert(t, f.db, keep, dup, dup2)
+
+	got, err := f.svc.Transactions.Merge(ctx, f.owner, service.MergeTransactions{
+		KeepID: keep.ID, IDs: []string{dup.ID, keep.ID, dup2.ID, dup.ID},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.ID != keep.ID {
+		t.Errorf("Merge kept %s, want %s", got.ID, keep.ID)
+	}
+	// the first payee and category found fill the gaps; tags and memos add up
+	if got.Payee == nil || *got.Payee != "Deli" || got.CategoryID == nil || *got.CategoryID != food.ID {
+		t.Errorf("kept row payee %v, category %v; want Deli and %s", got.Payee, got.CategoryID, food.ID)
+	}
+	if got.Tags == nil || *got.Tags != "work,team" {
+		t.Errorf("kept row tags = %v, want work,team", got.Tags)
+	}
+	if got.Memo == nil || *got.Memo != "lunch | with Sam" {
+		t.Errorf("kept row memo = %v, want %q", got.Memo, "lunch | with Sam")
+	}
+	for _, id := range []string{dup.ID, dup2.ID} {
+		if _, err := f.svc.Transactions.Get(ctx, f.owner, id); code(err) != "not_found" {
+			t.Errorf("merged row %s = %q, want it deleted", id, code(err))
+		}
+	}
+
+	// the merged rows are gone, so merging them again fails
+	if _, err := f.svc.Transactions.Merge(ctx, f.owner, service.MergeTransactions{KeepID: keep.ID, IDs: []string{dup.ID}}); code(err) != "not_found" {
+		t.Errorf("Merge of a deleted row = %q, want not_found", code(err))
+	}
+	if _, err := f.svc.Transactions.Merge(ctx, f.owner, service.MergeTransactions{KeepID: dup.ID, IDs: []string{keep.ID}}); code(err) != "not_found" {
+		t.Errorf("Merge into a deleted row = %q, want not_found", code(err))
+	}
+}
+
+func TestTransactionsMergeValidation(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	own := models.HouseholdBase{HouseholdID: f.owner.HouseholdID, CreatedBy: "owner"}
+	expense := &models.Transaction{HouseholdBase: own, Type: models.TxExpense, Amount: 500, Currency: "USD"}
+	expense2 := &models.Transaction{HouseholdBase: own, Type: models.TxExpense, Amount: 500, Currency: "USD"}
+	income := &models.Transaction{HouseholdBase: own, Type: models.TxIncome, Amount: 500, Currency: "USD"}
+	mustInsert(t, f.db, expense, expense2, income)
+
+	other := &models.Household{Name: "Next door", CreatedBy: "neighbour"}
+	mustInsert(t, f.db, other)
+	theirs := &models.Transaction{HouseholdBase: models.HouseholdBase{HouseholdID: other.ID, CreatedBy: "neighbour"},
+		Type: models.TxExpense, Amount: 500, Currency: "USD"}
+	mustInsert(t, f.db, theirs)
+
+	checking, savings := f.account(t, "owner", "USD"), f.account(t, "owner", "USD")
+	tr, err := f.svc.Transactions.CreateTransfer(ctx, f.owner, service.NewTransaction{
+		Type: "transfer", Amount: 500, AccountID: &checking.ID, ToAccountID: &savings.ID,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	viewer := f.member(t, "viewer", models.RoleViewer)
+
+	tests := []struct {
+		name string
+		m    service.Member
+		in   service.MergeTransactions
+		want string
+	}{
+		{"viewer", viewer, service.MergeTransactions{KeepID: expense.ID, IDs: []string{expense2.ID}}, "household_read_only"},
+		{"no ids", f.owner, service.MergeTransactions{KeepID: expense.ID}, "ids:ids_required"},
+		{"only the kept row", f.owner, service.MergeTransactions{KeepID: expense.ID, IDs: []string{expense.ID}}, "ids:ids_required"},
+		{"different types", f.owner, service.MergeTransactions{KeepID: expense.ID, IDs: []string{income.ID}}, "ids:cannot_merge_different_types"},
+		{"transfer", f.owner, service.MergeTransactions{KeepID: tr.From.ID, IDs: []string{tr.To.ID}}, "keep_id:cannot_merge_transfers"},
+		{"row of another household", f.owner, service.MergeTransactions{KeepID: expense.ID, IDs: []string{theirs.ID}}, "not_found"},
+		{"into a row of another household", f.owner, service.MergeTransactions{KeepID: theirs.ID, IDs: []string{expense.ID}}, "not_found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := f.svc.Transactions.Merge(ctx, tt.m, tt.in)
+			if got := code(err); got != tt.want {
+				t.Errorf("Merge = %q, want %q", got, tt.want)
+			}
+		})
+	}
+	// nothing was deleted along the way
+	for _, id := range []string{expense.ID, expense2.ID, income.ID} {
+		if _, err := f.svc.Transactions.Get(ctx, f.owner, id); err != nil {
+			t.Errorf("Get(%s) after failed merges: %v", id, err)
+		}
+	}
+}
@@ -0,0 +1,20 @@
+package service
+
+import (
+	"context"
+
+	"budgex_backend/internal/apperr"
+	"budgex_backend/internal/store"
+)
+
+// Health reports whether the service can do its work.
+type Health struct{ st store.Stores }
+
+// Check fails with 503 when the stores cannot be reached. The cause is
+// logged, not sent.
+func (s *Health) Check(ctx context.Context) error {
+	if err := s.st.Health.Ping(ctx); err != nil {
+		return apperr.Unavailable("db_unavailable").Wrap(err)
+	}
+	return nil
+}
@@ -0,0 +1,353 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"strings"
+	"time"
+
+	"budgex_backend/internal/apperr"
+	"budgex_backend/internal/models"
+	"budgex_backend/internal/store"
+	"budgex_backend/internal/validate"
+)
+
+// Households manages households, their members and invites.
+type Households struct{ st store.Stores }
+
+// Member is a user acting in one of their households. Categories,
+// transactions and budgets are read and written in HouseholdID; accounts,
+// rules, settings and exchange rates stay UserID's own.
+type Member struct {
+	UserID      string
+	HouseholdID string
+	Role        string // models.RoleOwner, RoleEditor or RoleViewer
+}
+
+// RequireEditor fails unless m may change the household's data.
+func (m Member) RequireEditor() error {
+	if m.Role != models.RoleOwner && m.Role != models.RoleEditor {
+		return apperr.Forbidden("household_read_only")
+	}
+	return nil
+}
+
+// RequireOwner fails unless m may manage the household itself.
+func (m Member) RequireOwner() error {
+	if m.Role != models.RoleOwner {
+		return apperr.Forbidden("household_owner_only")
+	}
+	return nil
+}
+
+// InviteTTL is how long an invite code can be redeemed.
+const InviteTTL = 7 * 24 * time.Hour
+
+// personalHousehold names the household a user gets on first use.
+const personalHousehold = "Personal"
+
+// NewHousehold is what a household is created from.
+type NewHousehold struct {
+	Name string `json:"name" validate:"required,max=100"`
+}
+
+// HouseholdPatch changes only the fields that are set.
+type HouseholdPatch struct {
+	Name *string `json:"name" validate:"notempty,max=100"`
+}
+
+// MemberRole changes a member's role.
+type MemberRole struct {
+	Role string `json:"role" validate:"required,oneof=owner editor viewer"`
+}
+
+// NewInvite is what an invite is created from. Owners are made by promoting
+// a member, never by invite.
+type NewInvite struct {
+	Role string `json:"role" validate:"required,oneof=editor viewer"`
+}
+
+// JoinHousehold redeems an invite code.
+type JoinHousehold struct {
+	Code string `json:"code" validate:"required,max=64"`
+}
+
+func init() {
+	validate.Register(NewHousehold{}, HouseholdPatch{}, MemberRole{}, NewInvite{}, JoinHousehold{})
+}
+
+func householdNotFound() error {
+	return apperr.New(apperr.KindNotFound, "household_not_found")
+}
+
+// Resolve picks the household a request of uid acts in: hid when set, else
+// the first household uid joined. A user in no household gets a personal
+// one holding the default categories of locale.
+func (s *Households) Resolve(ctx context.Context, uid, hid, locale string) (Member, error) {
+	if hid != "" {
+		h, err := s.st.Households.Get(ctx, uid, hid)
+		if errors.Is(err, store.ErrNotFound) {
+			return Member{}, householdNotFound()
+		}
+		if err != nil {
+			return Member{}, err
+		}
+		return Member{UserID: uid, HouseholdID: h.ID, Role: h.Role}, nil
+	}
+	var m Member
+	err := s.st.Tx.InTx(ctx, func(ctx context.Context) error {
+		h, created, err := s.st.Households.EnsureFirst(ctx, uid, personalHousehold)
+		if err != nil {
+			return err
+		}
+		m = Member{UserID: uid, HouseholdID: h.ID, Role: h.Role}
+		if created {
+			_, err = s.st.Categories.Seed(ctx, h.ID, uid, locale)
+		}
+		return err
+	})
+	return m, err
+}
+
+// List returns the households uid belongs to, with uid's role in each.
+func (s *Households) List(ctx context.Context, uid string) ([]models.Household, error) {
+	return s.st.Households.List(ctx, uid)
+}
+
+// Get returns m's household.
+func (s *Households) Get(ctx context.Context, m Member) (*models.Household, error) {
+	h, err := s.st.Households.Get(ctx, m.UserID, m.HouseholdID)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, householdNotFound()
+	}
+	return h, err
+}
+
+// Create adds a household owned by uid, holding the default categories of
+// locale.
+func (s *Households) Create(ctx context.Context, uid string, in NewHousehold, locale string) (*models.Household, error) {
+	if err := validate.Struct(in); err != nil {
+		return nil, err
+	}
+	h := models.Household{Name: strings.TrimSpace(in.Name), CreatedBy: uid}
+	err := s.st.Tx.InTx(ctx, func(ctx context.Context) error {
+		if err := s.st.Households.Create(ctx, &h); err != nil {
+			return err
+		}
+		_, err := s.st.Categories.Seed(ctx, h.ID, uid, locale)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &h, nil
+}
+
+// Update renames m's household.
+func (s *Households) Update(ctx context.Context, m Member, in HouseholdPatch) (*models.Household, error) {
+	if err := m.RequireOwner(); err != nil {
+		return nil, err
+	}
+	if err := validate.Struct(in); err != nil {
+		return nil, err
+	}
+	if in.Name != nil {
+		err := s.st.Households.Update(ctx, m.HouseholdID, map[string]any{"name": strings.TrimSpace(*in.Name)})
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, householdNotFound()
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return s.Get(ctx, m)
+}
+
+// Delete removes m's household and every membership of it. Its data stays
+// in the database but nobody can reach it any more.
+func (s *Households) Delete(ctx context.Context, m Member) error {
+	if err := m.RequireOwner(); err != nil {
+		return err
+	}
+	err := s.st.Households.Delete(ctx, m.HouseholdID)
+	if errors.Is(err, store.ErrNotFound) {
+		return householdNotFound()
+	}
+	return err
+}
+
+// Members lists the members of m's household in the order they joined.
+func (s *Households) Members(ctx context.Context, m Member) ([]models.HouseholdMember, error) {
+	return s.st.Households.Members(ctx, m.HouseholdID)
+}
+
+// member finds uid among the members of m's household and counts its
+// owners.
+func (s *Households) member(ctx context.Context, m Member, uid string) (*models.HouseholdMember, int, error) {
+	ms, err := s.st.Households.Members(ctx, m.HouseholdID)
+	if err != nil {
+		return nil, 0, err
+	}
+	var found *models.HouseholdMember
+	owners := 0
+	for i, mb := range ms {
+		if mb.Role == models.RoleOwner {
+			owners++
+		}
+		if mb.UserID == uid {
+			found = &ms[i]
+		}
+	}
+	if found == nil {
+		return nil, 0, apperr.NotFound()
+	}
+	return found, owners, nil
+}
+
+// SetRole changes the role of member uid. A household always keeps an
+// owner.
+func (s *Households) SetRole(ctx context.Context, m Member, uid string, in MemberRole) (*models.HouseholdMember, error) {
+	if err := m.RequireOwner(); err != nil {
+		return nil, err
+	}
+	if err := validate.Struct(in); err != nil {
+		return nil, err
+	}
+	err := s.st.Tx.InTx(ctx, func(ctx context.Context) error {
+		mb, owners, err := s.member(ctx, m, uid)
+		if err != nil {
+			return err
+		}
+		if mb.Role == models.RoleOwner && in.Role != models.RoleOwner && owners == 1 {
+			return apperr.Conflict("last_owner")
+		}
+		return s.st.Households.SetRole(ctx, m.HouseholdID, uid, in.Role)
+	})
+	if err != nil {
+		return nil, err
+	}
+	mb, _, err := s.member(ctx, m, uid)
+	return mb, err
+}
+
+// RemoveMember ends the membership of uid. Owners remove anyone; every
+// member may remove themselves, except a household's last owner.
+func (s *Households) RemoveMember(ctx context.Context, m Member, uid string) error {
+	if uid != m.UserID {
+		if err := m.RequireOwner(); err != nil {
+			return err
+		}
+	}
+	return s.st.Tx.InTx(ctx, func(ctx context.Context) error {
+		mb, owners, err := s.member(ctx, m, uid)
+		if err != nil {
+			return err
+		}
+		if mb.Role == models.RoleOwner && owners == 1 {
+			return apperr.Conflict("last_owner")
+		}
+		return s.st.Households.RemoveMember(ctx, m.HouseholdID, uid)
+	})
+}
+
+// CreateInvite issues a single-use code that lets someone join m's household
+// with the role of in, for InviteTTL.
+func (s *Households) CreateInvite(ctx context.Context, m Member, in NewInvite) (*models.HouseholdInvite, error) {
+	if err := m.RequireOwner(); err != nil {
+		return nil, err
+	}
+	if err := validate.Struct(in); err != nil {
+		return nil, err
+	}
+	code, err := inviteCode()
+	if err != nil {
+		return nil, err
+	}
+	inv := models.HouseholdInvite{
+		HouseholdID: m.HouseholdID, Code: code, Role: in.Role,
+		CreatedBy: m.UserID, ExpiresAt: time.Now().UTC().Add(InviteTTL),
+	}
+	if err := s.st.Households.CreateInvite(ctx, &inv); err != nil {
+		return nil, err
+	}
+	return &inv, nil
+}
+
+// Invites lists the invites of m's household that were not redeemed yet.
+func (s *Households) Invites(ctx context.Context, m Member) ([]models.HouseholdInvite, error) {
+	if err := m.RequireOwner(); err != nil {
+		return nil, err
+	}
+	return s.st.Households.Invites(ctx, m.HouseholdID)
+}
+
+// RevokeInvite deletes an invite that was not redeemed yet.
+func (s *Households) RevokeInvite(ctx context.Context, m Member, id string) error {
+	if err := m.RequireOwner(); err != nil {
+		return err
+	}
+	err := s.st.Households.DeleteInvite(ctx, m.HouseholdID, id)
+	if errors.Is(err, store.ErrNotFound) {
+		return apperr.NotFound()
+	}
+	return err
+}
+
+// Join redeems an invite code for uid and returns the household joined.
+func (s *Households) Join(ctx context.Context, uid string, in JoinHousehold) (*models.Household, error) {
+	if err := validate.Struct(in); err != nil {
+		return nil, err
+	}
+	inv, err := s.st.Households.InviteByCode(ctx, normalizeCode(in.Code))
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, apperr.New(apperr.KindNotFound, "invite_not_found")
+	}
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	switch {
+	case inv.AcceptedBy != nil:
+		return nil, apperr.Conflict("invite_used")
+	case !now.Before(inv.ExpiresAt):
+		return nil, apperr.Conflict("invite_expired")
+	}
+	if _, err := s.st.Households.Get(ctx, uid, inv.HouseholdID); err == nil {
+		return nil, apperr.Conflict("already_member")
+	} else if !errors.Is(err, store.ErrNotFound) {
+		return nil, err
+	}
+	err = s.st.Households.Accept(ctx, inv, uid, now)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, apperr.Conflict("invite_used")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.Get(ctx, Member{UserID: uid, HouseholdID: inv.HouseholdID})
+}
+
+// Invite codes are 16 characters of unpadded base32: 80 random bits, easy
+// to read out and type.
+var codeEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+func inviteCode() (string, error) {
+	b := make([]byte, 10)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return codeEncoding.EncodeToString(b), nil
+}
+
+// normalizeCode forgives case and the spaces or dashes people add when
+// copying a code.
+func normalizeCode(code string) string {
+	return strings.Map(func(r rune) rune {
+		if r == ' ' || r == '-' {
+			return -1
+		}
+		return r
+	}, strings.ToUpper(strings.TrimSpace(code)))
+}
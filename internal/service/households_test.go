@@ -0,0 +1,277 @@
+package service_test
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"budgex_backend/internal/models"
+	"budgex_backend/internal/service"
+)
+
+func TestHouseholdsResolve(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+
+	m, err := f.svc.Households.Resolve(ctx, "newcomer", "", "en")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Role != models.RoleOwner || m.HouseholdID == f.owner.HouseholdID {
+		t.Errorf("first use = %+v, want the owner of a new household", m)
+	}
+	cats, err := f.svc.Categories.List(ctx, m, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cats) == 0 {
+		t.Error("personal household has no default categories")
+	}
+	again, err := f.svc.Households.Resolve(ctx, "newcomer", "", "en")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again != m {
+		t.Errorf("second use = %+v, want %+v", again, m)
+	}
+
+	if _, err := f.svc.Households.Resolve(ctx, "newcomer", f.owner.HouseholdID, "en"); code(err) != "household_not_found" {
+		t.Errorf("Resolve of another household = %q, want household_not_found", code(err))
+	}
+	got, err := f.svc.Households.Resolve(ctx, "owner", f.owner.HouseholdID, "en")
+	if err != nil || got != f.owner {
+		t.Errorf("Resolve = %+v, %v, want %+v", got, err, f.owner)
+	}
+}
+
+func TestHouseholdsResolveConcurrent(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+
+	// a new user's first requests arrive together
+	var wg sync.WaitGroup
+	got := make([]service.Member, 4)
+	errs := make([]error, 4)
+	for i := range got {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			got[i], errs[i] = f.svc.Households.Resolve(ctx, "newcomer", "", "en")
+		}(i)
+	}
+	wg.Wait()
+	for i := range got {
+		if errs[i] != nil {
+			t.Fatal(errs[i])
+		}
+		if got[i] != got[0] {
+			t.Errorf("request %d acts in %+v, request 0 in %+v", i, got[i], got[0])
+		}
+	}
+	hs, err := f.svc.Households.List(ctx, "newcomer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hs) != 1 {
+		t.Errorf("newcomer has %d households, want 1", len(hs))
+	}
+	cats, err := f.svc.Categories.List(ctx, got[0], false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, err := f.svc.Categories.Seed(ctx, got[0], "en")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.Created != 0 || again.Skipped != len(cats) {
+		t.Errorf("categories seeded more than once: %d, then %+v", len(cats), again)
+	}
+}
+
+func TestHouseholdsInviteAndJoin(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+
+	inv, err := f.svc.Households.CreateInvite(ctx, f.owner, service.NewInvite{Role: models.RoleEditor})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(inv.Code) != 16 || !inv.ExpiresAt.After(time.Now().Add(service.InviteTTL-time.Minute)) {
+		t.Errorf("invite = %+v", inv)
+	}
+	// codes are forgiven their case and the dashes people add
+	typed := strings.ToLower(inv.Code[:8] + "-" + inv.Code[8:])
+	h, err := f.svc.Households.Join(ctx, "friend", service.JoinHousehold{Code: typed})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h.ID != f.owner.HouseholdID || h.Role != models.RoleEditor {
+		t.Errorf("joined %+v, want the household as editor", h)
+	}
+	ms, err := f.svc.Households.Members(ctx, f.owner)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ms) != 2 || ms[1].UserID != "friend" || ms[1].Role != models.RoleEditor {
+		t.Errorf("members = %+v", ms)
+	}
+	pending, err := f.svc.Households.Invites(ctx, f.owner)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 0 {
+		t.Errorf("redeemed invite still listed: %+v", pending)
+	}
+
+	open, err := f.svc.Households.CreateInvite(ctx, f.owner, service.NewInvite{Role: models.RoleViewer})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expired := &models.HouseholdInvite{
+		HouseholdID: f.owner.HouseholdID, Code: "EXPIREDEXPIRED00", Role: models.RoleViewer,
+		CreatedBy: "owner", ExpiresAt: time.Now().Add(-time.Hour),
+	}
+	mustInsert(t, f.db, expired)
+
+	tests := []struct {
+		name string
+		uid  string
+		code string
+		want string
+	}{
+		{"used", "stranger", inv.Code, "invite_used"},
+		{"expired", "stranger", expired.Code, "invite_expired"},
+		{"unknown", "stranger", "AAAAAAAAAAAAAAAA", "invite_not_found"},
+		{"no code", "stranger", "", "code:code_required"},
+		{"already a member", "friend", open.Code, "already_member"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := f.svc.Households.Join(ctx, tt.uid, service.JoinHousehold{Code: tt.code})
+			if got := code(err); got != tt.want {
+				t.Errorf("Join = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHouseholdsInviteValidation(t *testing.T) {
+	f := newFixture(t)
+	editor := f.member(t, "editor", models.RoleEditor)
+	tests := []struct {
+		name string
+		m    service.Member
+		role string
+		want string
+	}{
+		{"editor", editor, models.RoleViewer, "household_owner_only"},
+		{"owner role", f.owner, models.RoleOwner, "role:role_must_be_editor_or_viewer"},
+		{"no role", f.owner, "", "role:role_required"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := f.svc.Households.CreateInvite(context.Background(), tt.m, service.NewInvite{Role: tt.role})
+			if got := code(err); got != tt.want {
+				t.Errorf("CreateInvite = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHouseholdsSetRole(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	editor := f.member(t, "editor", models.RoleEditor)
+
+	tests := []struct {
+		name string
+		m    service.Member
+		uid  string
+		role string
+		want string
+	}{
+		{"editor", editor, "owner", models.RoleViewer, "household_owner_only"},
+		{"last owner", f.owner, "owner", models.RoleEditor, "last_owner"},
+		{"not a member", f.owner, "stranger", models.RoleViewer, "not_found"},
+		{"bad role", f.owner, "editor", "admin", "role:role_must_be_owner_editor_or_viewer"},
+		{"demote editor", f.owner, "editor", models.RoleViewer, ""},
+		{"promote to owner", f.owner, "editor", models.RoleOwner, ""},
+		// with a second owner the first may step down
+		{"owner steps down", f.owner, "owner", models.RoleEditor, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mb, err := f.svc.Households.SetRole(ctx, tt.m, tt.uid, service.MemberRole{Role: tt.role})
+			if got := code(err); got != tt.want {
+				t.Fatalf("SetRole = %q, want %q", got, tt.want)
+			}
+			if err == nil && mb.Role != tt.role {
+				t.Errorf("role = %s, want %s", mb.Role, tt.role)
+			}
+		})
+	}
+}
+
+func TestHouseholdsRemoveMember(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	editor := f.member(t, "editor", models.RoleEditor)
+	viewer := f.member(t, "viewer", models.RoleViewer)
+	f.member(t, "other", models.RoleViewer)
+
+	tests := []struct {
+		name string
+		m    service.Member
+		uid  string
+		want string
+	}{
+		{"editor removes someone else", editor, "other", "household_owner_only"},
+		{"last owner leaves", f.owner, "owner", "last_owner"},
+		{"not a member", f.owner, "stranger", "not_found"},
+		{"viewer leaves", viewer, "viewer", ""},
+		{"owner removes editor", f.owner, "editor", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := code(f.svc.Households.RemoveMember(ctx, tt.m, tt.uid)); got != tt.want {
+				t.Errorf("RemoveMember = %q, want %q", got, tt.want)
+			}
+		})
+	}
+	ms, err := f.svc.Households.Members(ctx, f.owner)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var left []string
+	for _, mb := range ms {
+		left = append(left, mb.UserID)
+	}
+	if got := strings.Join(left, ","); got != "owner,other" {
+		t.Errorf("members = %s, want owner,other", got)
+	}
+}
+
+func TestHouseholdsOwnerOnly(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	editor := f.member(t, "editor", models.RoleEditor)
+
+	if _, err := f.svc.Households.Update(ctx, editor, service.HouseholdPatch{Name: ptr("Ours")}); code(err) != "household_owner_only" {
+		t.Errorf("Update by editor = %q", code(err))
+	}
+	if err := f.svc.Households.Delete(ctx, editor); code(err) != "household_owner_only" {
+		t.Errorf("Delete by editor = %q", code(err))
+	}
+	h, err := f.svc.Households.Update(ctx, f.owner, service.HouseholdPatch{Name: ptr(" Ours ")})
+	if err != nil || h.Name != "Ours" {
+		t.Fatalf("Update = %+v, %v", h, err)
+	}
+	if err := f.svc.Households.Delete(ctx, f.owner); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.svc.Households.Get(ctx, editor); code(err) != "household_not_found" {
+		t.Errorf("Get after delete = %q, want household_not_found", code(err))
+	}
+}
@@ -0,0 +1,231 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"budgex_backend/internal/apperr"
+	"budgex_backend/internal/imports"
+	"budgex_backend/internal/models"
+	"budgex_backend/internal/rules"
+	"budgex_backend/internal/store"
+)
+
+// Imports turns bank statements into transactions in two steps: a preview
+// batch the caller reviews, then a commit into the household. A committed
+// batch can be rolled back as a whole.
+type Imports struct{ st store.Stores }
+
+type ImportPreview struct {
+	Batch models.ImportBatch `json:"batch"`
+	Rows  []imports.Row      `json:"rows"`
+}
+
+// List returns m's batches, newest first.
+func (s *Imports) List(ctx context.Context, m Member) ([]models.ImportBatch, error) {
+	return s.st.Imports.List(ctx, m.UserID)
+}
+
+// Preview stores rows parsed from filename as a new batch of source, flagging
+// rows already imported into m's household. accountID, when set, names one
+// of m's accounts the statement belongs to.
+func (s *Imports) Preview(ctx context.Context, m Member, source, filename, accountID string, rows []imports.Row) (*ImportPreview, error) {
+	var account *string
+	if accountID != "" {
+		acc, err := s.st.Accounts.Get(ctx, m.UserID, accountID)
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, invalid("account_id", "account_not_found")
+		}
+		if err != nil {
+			return nil, err
+		}
+		account = &acc.ID
+	}
+
+	seen := map[string]bool{}
+	if ids := imports.ExternalIDs(rows); len(ids) > 0 {
+		existing, err := s.st.Transactions.ExternalIDs(ctx, m.HouseholdID, ids)
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range existing {
+			seen[id] = true
+		}
+	}
+	imports.MarkDuplicates(rows, seen)
+
+	payload, err := json.Marshal(rows)
+	if err != nil {
+		return nil, err
+	}
+	hid := m.HouseholdID
+	batch := models.ImportBatch{
+		Base:        models.Base{UserID: m.UserID},
+		Source:      source,
+		Filename:    filename,
+		AccountID:   account,
+		HouseholdID: &hid,
+		Status:      models.ImportPreview,
+		RowCount:    len(rows),
+		ErrorCount:  imports.CountErrors(rows),
+		Preview:     payload,
+	}
+	if err := s.st.Imports.Create(ctx, &batch); err != nil {
+		return nil, err
+	}
+	return &ImportPreview{Batch: batch, Rows: rows}, nil
+}
+
+// Get returns one of m's batches with its preview rows.
+func (s *Imports) Get(ctx context.Context, m Member, id string) (*ImportPreview, error) {
+	batch, err := s.batch(ctx, m, id, false)
+	if err != nil {
+		return nil, err
+	}
+	rows := []imports.Row{}
+	if len(batch.Preview) > 0 {
+		if err := json.Unmarshal(batch.Preview, &rows); err != nil {
+			return nil, err
+		}
+	}
+	return &ImportPreview{Batch: *batch, Rows: rows}, nil
+}
+
+// Commit creates one transaction per valid preview row, skipping rows with
+// errors and duplicates, and runs m's rules on them. m must act in the
+// household the batch was previewed in.
+func (s *Imports) Commit(ctx context.Context, m Member, id string) (*models.ImportBatch, error) {
+	if err := m.RequireEditor(); err != nil {
+		return nil, err
+	}
+	var batch *models.ImportBatch
+	err := s.st.Tx.InTx(ctx, func(ctx context.Context) error {
+		var err error
+		if batch, err = s.batch(ctx, m, id, true); err != nil {
+			return err
+		}
+		if batch.Status != models.ImportPreview {
+			return batchState(batch)
+		}
+		// duplicates were flagged against the preview's household
+		if err := batchHousehold(batch, m); err != nil {
+			return err
+		}
+		var rows []imports.Row
+		if err := json.Unmarshal(batch.Preview, &rows); err != nil {
+			return err
+		}
+
+		rs, err := s.st.Rules.Active(ctx, batch.UserID)
+		if err != nil {
+			return err
+		}
+		engine, err := rules.New(rs)
+		if err != nil {
+			return err
+		}
+		if engine.Categories, err = categoryIDs(ctx, s.st, m.HouseholdID); err != nil {
+			return err
+		}
+		cur, err := currency(ctx, s.st, batch.UserID, batch.AccountID, nil)
+		if err != nil {
+			return err
+		}
+		cal, err := calendar(ctx, s.st, batch.UserID)
+		if err != nil {
+			return err
+		}
+		txs := make([]models.Transaction, 0, len(rows))
+		for _, r := range rows {
+			if r.Transaction == nil || r.Duplicate {
+				continue
+			}
+			tx := *r.Transaction
+			tx.HouseholdBase = models.HouseholdBase{HouseholdID: m.HouseholdID, CreatedBy: batch.UserID}
+			tx.Source = batch.Source
+			tx.AccountID = batch.AccountID
+			tx.Currency = cur
+			tx.Date = cal.Midnight(tx.Date) // statement dates are days in the user's timezone
+			tx.ImportBatchID = &batch.ID
+			engine.Apply(&tx)
+			txs = append(txs, tx)
+		}
+		// rows imported by another batch since the preview are skipped
+		imported, err := s.st.Transactions.Import(ctx, txs)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now().UTC()
+		batch.Status = models.ImportCommitted
+		batch.Imported = imported
+		batch.CommittedAt = &now
+		return s.st.Imports.Update(ctx, m.UserID, batch.ID, map[string]any{
+			"status": batch.Status, "imported": batch.Imported, "committed_at": now,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return batch, nil
+}
+
+// Rollback soft-deletes every transaction a committed batch created. m must
+// act in the household the batch was committed to.
+func (s *Imports) Rollback(ctx context.Context, m Member, id string) (*models.ImportBatch, error) {
+	if err := m.RequireEditor(); err != nil {
+		return nil, err
+	}
+	var batch *models.ImportBatch
+	err := s.st.Tx.InTx(ctx, func(ctx context.Context) error {
+		var err error
+		if batch, err = s.batch(ctx, m, id, true); err != nil {
+			return err
+		}
+		if batch.Status != models.ImportCommitted {
+			return batchState(batch)
+		}
+		// its transactions are in the household it was committed to, and
+		// row-level security hides them from any other
+		if err := batchHousehold(batch, m); err != nil {
+			return err
+		}
+		if err := s.st.Transactions.DeleteImported(ctx, m.HouseholdID, batch.ID); err != nil {
+			return err
+		}
+		now := time.Now().UTC()
+		batch.Status = models.ImportRolledBack
+		batch.RolledBackAt = &now
+		return s.st.Imports.Update(ctx, m.UserID, batch.ID, map[string]any{
+			"status": batch.Status, "rolled_back_at": now,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return batch, nil
+}
+
+// batch loads one of m's batches, optionally locking it.
+func (s *Imports) batch(ctx context.Context, m Member, id string, lock bool) (*models.ImportBatch, error) {
+	b, err := s.st.Imports.Get(ctx, m.UserID, id, lock)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, apperr.NotFound()
+	}
+	return b, err
+}
+
+// batchState reports a batch that is not in the state a step needs.
+func batchState(b *models.ImportBatch) error {
+	return apperr.Conflict("batch_" + b.Status)
+}
+
+// batchHousehold fails unless m acts in the household of the batch.
+func batchHousehold(b *models.ImportBatch, m Member) error {
+	if b.HouseholdID == nil || *b.HouseholdID != m.HouseholdID {
+		return apperr.Conflict("batch_previewed_in_other_household").With("household_id", b.HouseholdID)
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package service_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"budgex_backend/internal/imports"
+	"budgex_backend/internal/models"
+	"budgex_backend/internal/money"
+	"budgex_backend/internal/service"
+)
+
+// statementRow is a parsed expense with an external id.
+func statementRow(line int, fitid string, amount money.Amount) imports.Row {
+	return imports.Row{Line: line, Transaction: &models.Transaction{
+		Type: models.TxExpense, Amount: amount, Date: time.Date(2024, 3, line, 0, 0, 0, 0, time.UTC),
+		Payee: ptr("Shop"), ExternalID: ptr(fitid),
+	}}
+}
+
+func TestImportsCommitAndRollback(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	acc := f.account(t, "owner", "EUR")
+	// a deleted import does not make its row a duplicate
+	for _, fitid := range []string{"A", "B"} {
+		mustInsert(t, f.db, &models.Transaction{
+			HouseholdBase: models.HouseholdBase{HouseholdID: f.owner.HouseholdID, CreatedBy: "owner"},
+			Type:          models.TxExpense, Amount: 100, Currency: "EUR", ExternalID: ptr(fitid),
+		})
+	}
+	page, err := f.svc.Transactions.List(ctx, f.owner, service.TxQuery{Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tx := range page.Items {
+		if *tx.ExternalID == "A" {
+			if err := f.svc.Transactions.Delete(ctx, f.owner, tx.ID); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	rows := []imports.Row{
+		statementRow(1, "A", 300), statementRow(2, "B", 400), statementRow(3, "C", 500),
+		statementRow(4, "C", 500), {Line: 5, Error: "bad_amount"},
+	}
+	p, err := f.svc.Imports.Preview(ctx, f.owner, "csv", "march.csv", acc.ID, rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var dup []bool
+	for _, r := range p.Rows {
+		dup = append(dup, r.Duplicate)
+	}
+	if want := []bool{false, true, false, true, false}; !slices.Equal(dup, want) {
+		t.Errorf("Preview duplicates = %v, want %v", dup, want)
+	}
+	if p.Batch.RowCount != 5 || p.Batch.ErrorCount != 1 {
+		t.Errorf("Preview counts = %d rows, %d errors, want 5 and 1", p.Batch.RowCount, p.Batch.ErrorCount)
+	}
+
+	cabin := otherHousehold(t, f)
+	if _, err := f.svc.Imports.Commit(ctx, cabin, p.Batch.ID); code(err) != "batch_previewed_in_other_household" {
+		t.Errorf("Commit in another household = %q, want batch_previewed_in_other_household", code(err))
+	}
+	if _, err := f.svc.Imports.Commit(ctx, f.member(t, "viewer", models.RoleViewer), p.Batch.ID); code(err) != "household_read_only" {
+		t.Errorf("Commit by a viewer = %q, want household_read_only", code(err))
+	}
+	b, err := f.svc.Imports.Commit(ctx, f.owner, p.Batch.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.Status != models.ImportCommitted || b.Imported != 2 {
+		t.Errorf("Commit = %s with %d imported, want committed with 2", b.Status, b.Imported)
+	}
+	if page, err = f.svc.Transactions.List(ctx, f.owner, service.TxQuery{Limit: 10, AccountID: acc.ID}); err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Items) != 2 {
+		t.Fatalf("account has %d transactions after Commit, want 2", len(page.Items))
+	}
+	for _, tx := range page.Items {
+		if tx.Currency != "EUR" || tx.ImportBatchID == nil || *tx.ImportBatchID != b.ID {
+			t.Errorf("imported transaction = %s from batch %v, want EUR from %s", tx.Currency, tx.ImportBatchID, b.ID)
+		}
+	}
+	if _, err := f.svc.Imports.Commit(ctx, f.owner, p.Batch.ID); code(err) != "batch_committed" {
+		t.Errorf("second Commit = %q, want batch_committed", code(err))
+	}
+
+	if _, err := f.svc.Imports.Rollback(ctx, cabin, b.ID); code(err) != "batch_previewed_in_other_household" {
+		t.Errorf("Rollback in another household = %q, want batch_previewed_in_other_household", code(err))
+	}
+	if b, err = f.svc.Imports.Rollback(ctx, f.owner, b.ID); err != nil {
+		t.Fatal(err)
+	}
+	if b.Status != models.ImportRolledBack {
+		t.Errorf("Rollback = %s, want rolled back", b.Status)
+	}
+	if page, err = f.svc.Transactions.List(ctx, f.owner, service.TxQuery{Limit: 10, AccountID: acc.ID}); err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Items) != 0 {
+		t.Errorf("account has %d transactions after Rollback, want none", len(page.Items))
+	}
+}
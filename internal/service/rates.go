@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"budgex_backend/internal/fx"
+	"budgex_backend/internal/models"
+	"budgex_backend/internal/store"
+)
+
+// Rates holds the exchange rates the caller uploads for conversions.
+type Rates struct{ st store.Stores }
+
+// RateQuery filters the rate listing. Dates are YYYY-MM-DD, both inclusive.
+type RateQuery struct {
+	Base  string
+	Quote string
+	From  string
+	To    string
+	Limit int
+}
+
+type RateUploadResp struct {
+	Source string `json:"source"`
+	Saved  int    `json:"saved"` // rates inserted or replaced
+	From   string `json:"from,omitempty"`
+	To     string `json:"to,omitempty"`
+}
+
+// List returns m's rates matching q, newest first.
+func (s *Rates) List(ctx context.Context, m Member, q RateQuery) ([]models.FxRate, error) {
+	f := store.RateFilter{Limit: q.Limit}
+	for _, p := range []struct {
+		name, in string
+		out      *string
+	}{{"base", q.Base, &f.Base}, {"quote", q.Quote, &f.Quote}} {
+		if p.in == "" {
+			continue
+		}
+		cur, ok := fx.NormalizeCode(p.in)
+		if !ok {
+			return nil, invalid(p.name, p.name+"_must_be_iso_4217")
+		}
+		*p.out = cur
+	}
+	if q.From != "" {
+		t, err := time.Parse("2006-01-02", q.From)
+		if err != nil {
+			return nil, invalid("from", "from_must_be_YYYY-MM-DD")
+		}
+		f.From = &t
+	}
+	if q.To != "" {
+		t, err := time.Parse("2006-01-02", q.To)
+		if err != nil {
+			return nil, invalid("to", "to_must_be_YYYY-MM-DD")
+		}
+		f.To = &t
+	}
+	return s.st.Rates.List(ctx, m.UserID, f)
+}
+
+// Save stores rates parsed from an upload of source; a rate for a day and
+// pair that already exists is replaced.
+func (s *Rates) Save(ctx context.Context, m Member, source string, rates []models.FxRate) (RateUploadResp, error) {
+	if len(rates) == 0 {
+		return RateUploadResp{}, invalid("file", "no_rates")
+	}
+	saved, err := s.st.Rates.Save(ctx, m.UserID, source, rates)
+	if err != nil {
+		return RateUploadResp{}, err
+	}
+	first, last := rates[0].Date, rates[0].Date
+	for _, r := range rates {
+		if r.Date.Before(first) {
+			first = r.Date
+		}
+		if r.Date.After(last) {
+			last = r.Date
+		}
+	}
+	return RateUploadResp{
+		Source: source, Saved: saved,
+		From: first.Format("2006-01-02"), To: last.Format("2006-01-02"),
+	}, nil
+}
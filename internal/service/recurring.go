@@ -0,0 +1,244 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"budgex_backend/internal/apperr"
+	"budgex_backend/internal/models"
+	"budgex_backend/internal/money"
+	"budgex_backend/internal/recurring"
+	"budgex_backend/internal/store"
+	"budgex_backend/internal/validate"
+)
+
+// Recurring manages a household's recurring transactions. The worker in
+// package recurring books the occurrences on behalf of each rule's creator.
+type Recurring struct{ st store.Stores }
+
+// RecurringInput creates a recurring rule, or changes the fields that are
+// set.
+type RecurringInput struct {
+	Name       *string       `json:"name" validate:"notempty,max=100"`
+	Type       *string       `json:"type" validate:"oneof=income expense"`
+	Amount     *money.Amount `json:"amount" validate:"gt=0"`
+	Payee      *string       `json:"payee" validate:"max=200"`
+	Memo       *string       `json:"memo" validate:"max=1000"`
+	CategoryID *string       `json:"category_id" validate:"uuid,ref=category"`
+	AccountID  *string       `json:"account_id" validate:"uuid,ref=account"`
+	Tags       *string       `json:"tags" validate:"tags"`
+	Freq       *string       `json:"freq" validate:"oneof=daily weekly monthly yearly"`
+	Interval   *int          `json:"interval" validate:"gte=0"`  // every N periods (default 1)
+	MonthDay   *int          `json:"month_day"`                  // monthly: 1..31, -1 = last day
+	StartDate  *string       `json:"start_date" validate:"date"` // YYYY-MM-DD
+	EndDate    *string       `json:"end_date" validate:"date"`   // YYYY-MM-DD, inclusive; "" clears
+	Count      *int          `json:"count" validate:"gte=0"`     // total occurrences; 0 clears
+	Active     *bool         `json:"active"`
+}
+
+func init() {
+	validate.Register(RecurringInput{})
+}
+
+type UpcomingItem struct {
+	RuleID     string       `json:"rule_id"`
+	Name       string       `json:"name"`
+	Date       string       `json:"date"` // YYYY-MM-DD
+	Type       string       `json:"type"`
+	Amount     money.Amount `json:"amount"`
+	Payee      *string      `json:"payee,omitempty"`
+	CategoryID *string      `json:"category_id,omitempty"`
+	AccountID  *string      `json:"account_id,omitempty"`
+}
+
+// MaxUpcomingDays is the furthest Upcoming looks ahead.
+const MaxUpcomingDays = 366
+
+// apply copies the fields present in the input onto r and validates the
+// result.
+func (in RecurringInput) apply(r *models.RecurringRule) error {
+	if in.Name != nil {
+		r.Name = strings.TrimSpace(*in.Name)
+	}
+	if in.Type != nil {
+		r.Type = *in.Type
+	}
+	if in.Amount != nil {
+		r.Amount = *in.Amount
+	}
+	if in.Payee != nil {
+		r.Payee = models.NilIfEmpty(in.Payee)
+	}
+	if in.Memo != nil {
+		r.Memo = models.NilIfEmpty(in.Memo)
+	}
+	if in.CategoryID != nil {
+		r.CategoryID = models.NilIfEmpty(in.CategoryID)
+	}
+	if in.AccountID != nil {
+		r.AccountID = models.NilIfEmpty(in.AccountID)
+	}
+	if in.Tags != nil {
+		r.Tags = models.NilIfEmpty(in.Tags)
+	}
+	if in.Freq != nil {
+		r.Freq = *in.Freq
+	}
+	if in.Interval != nil {
+		r.Interval = *in.Interval
+	}
+	if in.MonthDay != nil {
+		r.MonthDay = *in.MonthDay
+	}
+	if in.StartDate != nil {
+		t, err := time.Parse("2006-01-02", *in.StartDate)
+		if err != nil {
+			return invalid("start_date", "start_date_must_be_YYYY-MM-DD")
+		}
+		r.StartDate = t
+	}
+	if in.EndDate != nil {
+		if *in.EndDate == "" {
+			r.EndDate = nil
+		} else {
+			t, err := time.Parse("2006-01-02", *in.EndDate)
+			if err != nil {
+				return invalid("end_date", "end_date_must_be_YYYY-MM-DD")
+			}
+			r.EndDate = &t
+		}
+	}
+	if in.Count != nil {
+		if *in.Count == 0 {
+			r.Count = nil
+		} else {
+			r.Count = in.Count
+		}
+	}
+	if in.Active != nil {
+		r.Active = *in.Active
+	}
+
+	if r.Name == "" {
+		return invalid("name", "name_required")
+	}
+	if !models.IsIncomeOrExpense(r.Type) {
+		return invalid("type", "type_must_be_income_or_expense")
+	}
+	if r.Amount <= 0 {
+		return invalid("amount", "amount_must_be_positive")
+	}
+	if r.Interval == 0 {
+		r.Interval = 1
+	}
+	if err := recurring.ScheduleOf(*r).Validate(); err != nil {
+		return apperr.Invalid("bad_schedule").WithDetail(err.Error())
+	}
+	return nil
+}
+
+// List returns the household's recurring rules by name.
+func (s *Recurring) List(ctx context.Context, m Member) ([]models.RecurringRule, error) {
+	return s.st.Recurring.List(ctx, m.HouseholdID)
+}
+
+// Get returns one of the household's recurring rules.
+func (s *Recurring) Get(ctx context.Context, m Member, id string) (*models.RecurringRule, error) {
+	r, err := s.st.Recurring.Get(ctx, m.HouseholdID, id)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, apperr.NotFound()
+	}
+	return r, err
+}
+
+// Create adds a recurring rule created by m. Occurrences from its start date
+// on are booked by the next worker run.
+func (s *Recurring) Create(ctx context.Context, m Member, in RecurringInput) (*models.RecurringRule, error) {
+	if err := m.RequireEditor(); err != nil {
+		return nil, err
+	}
+	if err := check(ctx, s.st, m, in); err != nil {
+		return nil, err
+	}
+	r := models.RecurringRule{
+		HouseholdBase: models.HouseholdBase{HouseholdID: m.HouseholdID, CreatedBy: m.UserID},
+		Active:        true,
+	}
+	if err := in.apply(&r); err != nil {
+		return nil, err
+	}
+	if err := s.st.Recurring.Create(ctx, &r); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// Update changes the fields set in the input; transactions already booked
+// stay as they are. Only the rule's creator may change its account, since
+// occurrences are booked on their behalf.
+func (s *Recurring) Update(ctx context.Context, m Member, id string, in RecurringInput) (*models.RecurringRule, error) {
+	if err := m.RequireEditor(); err != nil {
+		return nil, err
+	}
+	r, err := s.Get(ctx, m, id)
+	if err != nil {
+		return nil, err
+	}
+	if in.AccountID != nil && r.CreatedBy != m.UserID {
+		return nil, apperr.Forbidden("account_belongs_to_creator")
+	}
+	if err := check(ctx, s.st, m, in); err != nil {
+		return nil, err
+	}
+	if err := in.apply(r); err != nil {
+		return nil, err
+	}
+	if err := s.st.Recurring.Save(ctx, r); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Delete stops a recurring rule; transactions already booked are kept.
+func (s *Recurring) Delete(ctx context.Context, m Member, id string) error {
+	if err := m.RequireEditor(); err != nil {
+		return err
+	}
+	if err := s.st.Recurring.Delete(ctx, m.HouseholdID, id); errors.Is(err, store.ErrNotFound) {
+		return apperr.NotFound()
+	} else if err != nil {
+		return err
+	}
+	return nil
+}
+
+// Upcoming lists the occurrences of the household's active rules due within
+// days (1..MaxUpcomingDays) of m's today, overdue ones the worker has not
+// booked yet included, by date.
+func (s *Recurring) Upcoming(ctx context.Context, m Member, days int) ([]UpcomingItem, error) {
+	days = min(max(days, 1), MaxUpcomingDays)
+	rs, err := s.st.Recurring.Active(ctx, m.HouseholdID)
+	if err != nil {
+		return nil, err
+	}
+	cal, err := calendar(ctx, s.st, m.UserID)
+	if err != nil {
+		return nil, err
+	}
+	through := cal.Day(time.Now()).AddDate(0, 0, days)
+	out := []UpcomingItem{}
+	for _, r := range rs {
+		for _, d := range recurring.Due(r, through) {
+			out = append(out, UpcomingItem{
+				RuleID: r.ID, Name: r.Name, Date: d.Format("2006-01-02"),
+				Type: r.Type, Amount: r.Amount, Payee: r.Payee,
+				CategoryID: r.CategoryID, AccountID: r.AccountID,
+			})
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Date < out[j].Date })
+	return out, nil
+}
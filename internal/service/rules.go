@@ -0,0 +1,258 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"budgex_backend/internal/apperr"
+	"budgex_backend/internal/models"
+	"budgex_backend/internal/money"
+	"budgex_backend/internal/rules"
+	"budgex_backend/internal/store"
+	"budgex_backend/internal/validate"
+)
+
+// Rules manages the caller's categorization rules, which run on their new
+// and imported transactions in any household.
+type Rules struct{ st store.Stores }
+
+// RuleInput creates a rule, or changes the fields that are set. Empty strings
+// and zero amounts clear optional fields.
+type RuleInput struct {
+	Name     *string `json:"name" validate:"notempty,max=100"`
+	Priority *int    `json:"priority"` // lower runs first (default 100)
+	Active   *bool   `json:"active"`
+	Stop     *bool   `json:"stop"`
+
+	PayeeContains *string       `json:"payee_contains" validate:"max=200"`
+	PayeeRegex    *string       `json:"payee_regex" validate:"max=200,regex"`
+	MemoContains  *string       `json:"memo_contains" validate:"max=200"`
+	MemoRegex     *string       `json:"memo_regex" validate:"max=200,regex"`
+	MinAmount     *money.Amount `json:"min_amount"` // 0 clears
+	MaxAmount     *money.Amount `json:"max_amount"` // 0 clears
+	TxType        *string       `json:"tx_type" validate:"oneof=income expense"`
+	AccountID     *string       `json:"account_id" validate:"uuid,ref=account"`
+
+	SetCategoryID *string `json:"set_category_id" validate:"uuid,ref=category"`
+	AddTags       *string `json:"add_tags" validate:"tags"`
+	SetPayee      *string `json:"set_payee" validate:"max=200"`
+	SetMemo       *string `json:"set_memo" validate:"max=1000"`
+}
+
+func init() {
+	validate.Register(RuleInput{})
+}
+
+type RuleApplyResp struct {
+	Matched int  `json:"matched"` // transactions whose conditions match
+	Changed int  `json:"changed"` // of those, transactions the actions change
+	DryRun  bool `json:"dry_run"` // nothing was written
+}
+
+// apply copies the fields present in the input onto r and validates the
+// result.
+func (in RuleInput) apply(r *models.Rule) error {
+	if in.Name != nil {
+		r.Name = strings.TrimSpace(*in.Name)
+	}
+	if in.Priority != nil {
+		r.Priority = *in.Priority
+	}
+	if in.Active != nil {
+		r.Active = *in.Active
+	}
+	if in.Stop != nil {
+		r.Stop = *in.Stop
+	}
+	for _, f := range []struct{ in, out **string }{
+		{&in.PayeeContains, &r.PayeeContains}, {&in.PayeeRegex, &r.PayeeRegex},
+		{&in.MemoContains, &r.MemoContains}, {&in.MemoRegex, &r.MemoRegex},
+		{&in.TxType, &r.TxType}, {&in.AccountID, &r.AccountID},
+		{&in.SetCategoryID, &r.SetCategoryID}, {&in.AddTags, &r.AddTags},
+		{&in.SetPayee, &r.SetPayee}, {&in.SetMemo, &r.SetMemo},
+	} {
+		if *f.in != nil {
+			*f.out = models.NilIfEmpty(*f.in)
+		}
+	}
+	for _, f := range []struct{ in, out **money.Amount }{
+		{&in.MinAmount, &r.MinAmount}, {&in.MaxAmount, &r.MaxAmount},
+	} {
+		if *f.in != nil {
+			if **f.in == 0 {
+				*f.out = nil
+			} else {
+				*f.out = *f.in
+			}
+		}
+	}
+
+	if r.Name == "" {
+		return invalid("name", "name_required")
+	}
+	if r.PayeeContains == nil && r.PayeeRegex == nil && r.MemoContains == nil && r.MemoRegex == nil &&
+		r.MinAmount == nil && r.MaxAmount == nil && r.TxType == nil && r.AccountID == nil {
+		return invalid("", "rule_needs_a_condition")
+	}
+	if r.SetCategoryID == nil && r.AddTags == nil && r.SetPayee == nil && r.SetMemo == nil {
+		return invalid("", "rule_needs_an_action")
+	}
+	if r.MinAmount != nil && r.MaxAmount != nil && *r.MinAmount > *r.MaxAmount {
+		return invalid("min_amount", "min_amount_above_max_amount")
+	}
+	if err := rules.Compile(*r); err != nil {
+		return apperr.Invalid("bad_regex").WithDetail(err.Error())
+	}
+	return nil
+}
+
+// List returns m's rules in the order they run.
+func (s *Rules) List(ctx context.Context, m Member) ([]models.Rule, error) {
+	return s.st.Rules.List(ctx, m.UserID)
+}
+
+// Get returns one of m's rules.
+func (s *Rules) Get(ctx context.Context, m Member, id string) (*models.Rule, error) {
+	r, err := s.st.Rules.Get(ctx, m.UserID, id)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, apperr.NotFound()
+	}
+	return r, err
+}
+
+// Create adds a rule. It needs a name, a condition and an action.
+func (s *Rules) Create(ctx context.Context, m Member, in RuleInput) (*models.Rule, error) {
+	if err := check(ctx, s.st, m, in); err != nil {
+		return nil, err
+	}
+	r := models.Rule{Base: models.Base{UserID: m.UserID}, Priority: 100, Active: true}
+	if err := in.apply(&r); err != nil {
+		return nil, err
+	}
+	if err := s.st.Rules.Create(ctx, &r); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// Update changes the fields set in the input.
+func (s *Rules) Update(ctx context.Context, m Member, id string, in RuleInput) (*models.Rule, error) {
+	r, err := s.Get(ctx, m, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := check(ctx, s.st, m, in); err != nil {
+		return nil, err
+	}
+	if err := in.apply(r); err != nil {
+		return nil, err
+	}
+	if err := s.st.Rules.Save(ctx, r); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Delete removes one of m's rules.
+func (s *Rules) Delete(ctx context.Context, m Member, id string) error {
+	if err := s.st.Rules.Delete(ctx, m.UserID, id); errors.Is(err, store.ErrNotFound) {
+		return apperr.NotFound()
+	} else if err != nil {
+		return err
+	}
+	return nil
+}
+
+// applyPageSize is how many transactions Apply reads at a time.
+const applyPageSize = 500
+
+// Apply runs a rule over the income and expense of m's household, whether or
+// not it is active. The rule's category is only set if it belongs to that
+// household. By default only uncategorized transactions get a category;
+// overwrite replaces existing categories and memos. A dry run only counts.
+func (s *Rules) Apply(ctx context.Context, m Member, id string, dryRun, overwrite bool) (RuleApplyResp, error) {
+	out := RuleApplyResp{DryRun: dryRun}
+	r, err := s.Get(ctx, m, id)
+	if err != nil {
+		return out, err
+	}
+	if !dryRun {
+		if err := m.RequireEditor(); err != nil {
+			return out, err
+		}
+	}
+	rule := *r
+	rule.Active, rule.Stop = true, false
+	engine, err := rules.New([]models.Rule{rule})
+	if err != nil {
+		return out, err
+	}
+	engine.Overwrite = overwrite
+	if engine.Categories, err = categoryIDs(ctx, s.st, m.HouseholdID); err != nil {
+		return out, err
+	}
+
+	// cheap conditions narrow the scan; the engine checks the rest
+	f := store.TxFilter{
+		Type: strOrEmpty(r.TxType), AccountID: strOrEmpty(r.AccountID),
+		MinAmount: r.MinAmount, MaxAmount: r.MaxAmount,
+		Payee: strOrEmpty(r.PayeeContains), Memo: strOrEmpty(r.MemoContains),
+		Limit: applyPageSize,
+	}
+	err = s.st.Tx.InTx(ctx, func(ctx context.Context) error {
+		for {
+			page, err := s.st.Transactions.List(ctx, m.HouseholdID, f)
+			if err != nil {
+				return err
+			}
+			for i := range page {
+				before := page[i]
+				if !models.IsIncomeOrExpense(before.Type) || len(engine.Apply(&page[i])) == 0 {
+					continue
+				}
+				out.Matched++
+				changes := txRuleChanges(before, page[i])
+				if len(changes) == 0 {
+					continue
+				}
+				out.Changed++
+				if dryRun {
+					continue
+				}
+				if err := s.st.Transactions.Update(ctx, m.HouseholdID, before.ID, changes); err != nil {
+					return err
+				}
+			}
+			if len(page) < applyPageSize {
+				return nil
+			}
+			last := page[len(page)-1]
+			f.After = &store.TxCursor{Date: last.Date, CreatedAt: last.CreatedAt, ID: last.ID}
+		}
+	})
+	return out, err
+}
+
+// txRuleChanges lists the columns a rule changed between before and after.
+func txRuleChanges(before, after models.Transaction) map[string]any {
+	changes := map[string]any{}
+	for col, v := range map[string][2]*string{
+		"category_id": {before.CategoryID, after.CategoryID},
+		"payee":       {before.Payee, after.Payee},
+		"memo":        {before.Memo, after.Memo},
+		"tags":        {before.Tags, after.Tags},
+	} {
+		if !sameString(v[0], v[1]) {
+			changes[col] = v[1]
+		}
+	}
+	return changes
+}
+
+func sameString(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
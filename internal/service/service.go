@@ -0,0 +1,144 @@
+// Package service holds the business rules for households, transactions,
+// categories, budgets, analytics, accounts, imports, recurring transactions,
+// rules, exchange rates and settings. Services validate input, decide what to
+// write and read through the stores, so the same rules serve HTTP handlers,
+// workers and tests running against the in-memory stores. Failures the caller can fix are
+// *apperr.Error values.
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"budgex_backend/internal/apperr"
+	"budgex_backend/internal/settings"
+	"budgex_backend/internal/store"
+	"budgex_backend/internal/validate"
+)
+
+// Services bundles every service.
+type Services struct {
+	Households   *Households
+	Transactions *Transactions
+	Categories   *Categories
+	Budgets      *Budgets
+	Analytics    *Analytics
+	Accounts     *Accounts
+	Imports      *Imports
+	Recurring    *Recurring
+	Rules        *Rules
+	Rates        *Rates
+	Settings     *Settings
+	Health       *Health
+
+	st store.Stores
+}
+
+// New builds the services over st.
+func New(st store.Stores) *Services {
+	return &Services{
+		Households:   &Households{st},
+		Transactions: &Transactions{st},
+		Categories:   &Categories{st},
+		Budgets:      &Budgets{st},
+		Analytics:    &Analytics{st},
+		Accounts:     &Accounts{st},
+		Imports:      &Imports{st},
+		Recurring:    &Recurring{st},
+		Rules:        &Rules{st},
+		Rates:        &Rates{st},
+		Settings:     &Settings{st},
+		Health:       &Health{st},
+		st:           st,
+	}
+}
+
+// AsUser runs fn as one unit of work on behalf of uid: service calls made
+// with the ctx passed to fn share one transaction, which commits when fn
+// returns nil and which row-level security scopes to uid.
+func (s *Services) AsUser(ctx context.Context, uid string, fn func(ctx context.Context) error) error {
+	return s.st.Tx.AsUser(ctx, uid, fn)
+}
+
+// invalid reports input that breaks a rule; field names the JSON field at
+// fault, "" when no single field is.
+func invalid(field, code string) error {
+	if field == "" {
+		return apperr.Invalid(code)
+	}
+	return apperr.Field(field, code)
+}
+
+// check validates in against its tags, looking up account refs among m's
+// own accounts and category refs in m's household, and reports every failing
+// field at once.
+func check(ctx context.Context, st store.Stores, m Member, in any) error {
+	return validate.Check(ctx, in, func(ctx context.Context, kind, id string) (bool, error) {
+		var err error
+		switch kind {
+		case "account":
+			_, err = st.Accounts.Get(ctx, m.UserID, id)
+		case "category":
+			_, err = st.Categories.Get(ctx, m.HouseholdID, id)
+		default:
+			return false, fmt.Errorf("service: no ref lookup for %q", kind)
+		}
+		if errors.Is(err, store.ErrNotFound) {
+			return false, nil
+		}
+		return err == nil, err
+	})
+}
+
+// categoryIDs returns the ids of the household's categories, archived ones
+// included, for rules.Engine.Categories.
+func categoryIDs(ctx context.Context, st store.Stores, hid string) (map[string]bool, error) {
+	cats, err := st.Categories.List(ctx, hid, true)
+	if err != nil {
+		return nil, err
+	}
+	ids := make(map[string]bool, len(cats))
+	for _, c := range cats {
+		ids[c.ID] = true
+	}
+	return ids, nil
+}
+
+func strOrEmpty(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// parseDate accepts RFC3339, or YYYY-MM-DD for the start of that day in loc.
+func parseDate(s string, loc *time.Location) (time.Time, error) {
+	if t, err := time.ParseInLocation("2006-01-02", s, loc); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, s)
+}
+
+func calendar(ctx context.Context, st store.Stores, uid string) (settings.Calendar, error) {
+	us, err := st.Settings.Get(ctx, uid)
+	if err != nil {
+		return settings.Calendar{}, err
+	}
+	return settings.CalendarOf(us), nil
+}
+
+// monthOrNow reads a budget month (YYYY-MM), defaulting to the one the user
+// is in now when m is empty, and returns the instants [from, to) it spans in
+// cal.
+func monthOrNow(cal settings.Calendar, m string) (string, time.Time, time.Time, error) {
+	if m == "" {
+		m = cal.Month(time.Now())
+	}
+	from, to, err := cal.MonthRange(m)
+	if err != nil {
+		return "", time.Time{}, time.Time{}, invalid("month", "invalid_month")
+	}
+	return m, from, to, nil
+}
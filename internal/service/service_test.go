@@ -0,0 +1,78 @@
+package service_test
+
+import (
+	"testing"
+
+	"budgex_backend/internal/apperr"
+	"budgex_backend/internal/models"
+	"budgex_backend/internal/service"
+	"budgex_backend/internal/store/memory"
+)
+
+// fixture is a household with an owner, over the memory stores.
+type fixture struct {
+	db    *memory.DB
+	svc   *service.Services
+	owner service.Member
+}
+
+func newFixture(t *testing.T) *fixture {
+	t.Helper()
+	db := memory.New()
+	h := &models.Household{Name: "Home", CreatedBy: "owner"}
+	mustInsert(t, db, h)
+	mustInsert(t, db, &models.HouseholdMember{HouseholdID: h.ID, UserID: "owner", Role: models.RoleOwner})
+	return &fixture{
+		db:    db,
+		svc:   service.New(db.Stores()),
+		owner: service.Member{UserID: "owner", HouseholdID: h.ID, Role: models.RoleOwner},
+	}
+}
+
+// member adds uid to the household with role.
+func (f *fixture) member(t *testing.T, uid, role string) service.Member {
+	t.Helper()
+	mustInsert(t, f.db, &models.HouseholdMember{HouseholdID: f.owner.HouseholdID, UserID: uid, Role: role})
+	return service.Member{UserID: uid, HouseholdID: f.owner.HouseholdID, Role: role}
+}
+
+func (f *fixture) category(t *testing.T, name string) *models.Category {
+	t.Helper()
+	c := &models.Category{
+		HouseholdBase: models.HouseholdBase{HouseholdID: f.owner.HouseholdID, CreatedBy: f.owner.UserID},
+		Name:          name,
+	}
+	mustInsert(t, f.db, c)
+	return c
+}
+
+func (f *fixture) account(t *testing.T, uid, currency string) *models.Account {
+	t.Helper()
+	a := &models.Account{Base: models.Base{UserID: uid}, Name: "Checking", Kind: "checking", Currency: currency}
+	mustInsert(t, f.db, a)
+	return a
+}
+
+func mustInsert(t *testing.T, db *memory.DB, rows ...any) {
+	t.Helper()
+	if err := db.Insert(rows...); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// code returns the apperr code of err, the first field's when it names one,
+// so tests can match on "field:code".
+func code(err error) string {
+	e := apperr.As(err)
+	switch {
+	case err == nil:
+		return ""
+	case e == nil:
+		return err.Error()
+	case len(e.Fields) > 0:
+		return e.Fields[0].Field + ":" + e.Fields[0].Code
+	}
+	return e.Code
+}
+
+func ptr[T any](v T) *T { return &v }
@@ -0,0 +1,61 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"budgex_backend/internal/fx"
+	"budgex_backend/internal/models"
+	"budgex_backend/internal/store"
+	"budgex_backend/internal/validate"
+)
+
+// Settings reads and changes the caller's preferences.
+type Settings struct{ st store.Stores }
+
+// SettingsPatch changes only the fields that are set; fields left out keep
+// their current value.
+type SettingsPatch struct {
+	BaseCurrency  *string `json:"base_currency" validate:"notempty,currency"` // ISO 4217
+	Timezone      *string `json:"timezone" validate:"notempty,timezone"`      // IANA name, e.g. Asia/Colombo
+	WeekStart     *int    `json:"week_start" validate:"gte=0,lte=6"`          // 0 = Sunday … 6 = Saturday
+	MonthStartDay *int    `json:"month_start_day" validate:"gte=1,lte=28"`    // 1..28
+}
+
+func init() {
+	validate.Register(SettingsPatch{})
+}
+
+// Get returns m's settings, or the defaults if none are saved.
+func (s *Settings) Get(ctx context.Context, m Member) (models.UserSettings, error) {
+	return s.st.Settings.Get(ctx, m.UserID)
+}
+
+// Update changes the settings set in the patch. Amounts already recorded are
+// not converted to a new base currency.
+func (s *Settings) Update(ctx context.Context, m Member, in SettingsPatch) (models.UserSettings, error) {
+	if err := validate.Struct(in); err != nil {
+		return models.UserSettings{}, err
+	}
+	us, err := s.st.Settings.Get(ctx, m.UserID)
+	if err != nil {
+		return us, err
+	}
+	if in.BaseCurrency != nil {
+		us.BaseCurrency, _ = fx.NormalizeCode(*in.BaseCurrency)
+	}
+	if in.Timezone != nil {
+		loc, _ := time.LoadLocation(*in.Timezone)
+		us.Timezone = loc.String()
+	}
+	if in.WeekStart != nil {
+		us.WeekStart = *in.WeekStart
+	}
+	if in.MonthStartDay != nil {
+		us.MonthStartDay = *in.MonthStartDay
+	}
+	if err := s.st.Settings.Save(ctx, &us); err != nil {
+		return us, err
+	}
+	return us, nil
+}
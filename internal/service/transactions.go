@@ -0,0 +1,522 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"time"
+
+	"budgex_backend/internal/apperr"
+	"budgex_backend/internal/fx"
+	"budgex_backend/internal/models"
+	"budgex_backend/internal/money"
+	"budgex_backend/internal/rules"
+	"budgex_backend/internal/store"
+	"budgex_backend/internal/validate"
+)
+
+// Transactions records income, expenses and transfers.
+type Transactions struct{ st store.Stores }
+
+// NewTransaction is what a transaction is created from.
+type NewTransaction struct {
+	Type        string        `json:"type" validate:"required,oneof=income expense transfer"`
+	Date        *string       `json:"date,omitempty" validate:"datetime"` // RFC3339, or YYYY-MM-DD in the user's timezone; defaults now
+	Amount      money.Amount  `json:"amount" validate:"gt=0"`
+	Currency    *string       `json:"currency,omitempty" validate:"currency"` // ISO 4217; defaults to the account's, else the base currency
+	Payee       *string       `json:"payee" validate:"max=200"`
+	Memo        *string       `json:"memo" validate:"max=1000"`
+	CategoryID  *string       `json:"category_id" validate:"uuid,ref=category"`
+	AccountID   *string       `json:"account_id" validate:"uuid,ref=account"`              // transfers: source account
+	ToAccountID *string       `json:"to_account_id,omitempty" validate:"uuid,ref=account"` // transfers only: destination account
+	ToAmount    *money.Amount `json:"to_amount,omitempty" validate:"gt=0"`                 // transfers between currencies: amount arriving on to_account
+	Tags        *string       `json:"tags" validate:"tags"`
+	Splits      []Split       `json:"splits,omitempty" validate:"max=100"` // category lines; must add up to amount
+}
+
+// TransactionPatch changes only the fields that are set.
+// An empty string clears payee, memo, category_id, account_id or tags.
+// Splits, when set, replaces all split lines; [] removes them.
+type TransactionPatch struct {
+	Type       *string       `json:"type" validate:"oneof=income expense transfer"`
+	Date       *string       `json:"date" validate:"datetime"` // RFC3339, or YYYY-MM-DD in the user's timezone
+	Amount     *money.Amount `json:"amount" validate:"gt=0"`
+	Currency   *string       `json:"currency" validate:"currency"` // only for rows without an account; others follow their account
+	Payee      *string       `json:"payee" validate:"max=200"`
+	Memo       *string       `json:"memo" validate:"max=1000"`
+	CategoryID *string       `json:"category_id" validate:"uuid,ref=category"`
+	AccountID  *string       `json:"account_id" validate:"uuid,ref=account"`
+	Tags       *string       `json:"tags" validate:"tags"`
+	Splits     *[]Split      `json:"splits" validate:"max=100"`
+}
+
+// Split is one category line of a split transaction.
+type Split struct {
+	CategoryID *string      `json:"category_id" validate:"uuid,ref=category"`
+	Amount     money.Amount `json:"amount" validate:"gt=0"`
+	Memo       *string      `json:"memo" validate:"max=1000"`
+}
+
+// TxQuery filters the transaction listing. Dates are RFC3339, or YYYY-MM-DD
+// in the user's timezone; a bare To date includes that whole day.
+type TxQuery struct {
+	Limit              int    `json:"limit" validate:"min=1"`
+	Cursor             string `json:"cursor"` // NextCursor of the previous page
+	From               string `json:"from" validate:"datetime"`
+	To                 string `json:"to" validate:"datetime"`
+	Type               string `json:"type" validate:"oneof=income expense transfer"`
+	CategoryID         string `json:"category_id" validate:"uuid"`
+	IncludeDescendants bool   `json:"include_descendants"`
+	AccountID          string `json:"account_id" validate:"uuid"`
+	Payee              string `json:"payee" validate:"max=200"`
+	Tag                string `json:"tag" validate:"max=32"`
+	MinAmount          string `json:"min_amount"`
+	MaxAmount          string `json:"max_amount"`
+	Source             string `json:"source"`
+}
+
+func init() {
+	validate.Register(NewTransaction{}, TransactionPatch{}, TxQuery{})
+}
+
+// TxPage is one page of the transaction listing.
+type TxPage struct {
+	Items      []models.Transaction `json:"items"`
+	NextCursor *string              `json:"next_cursor,omitempty"` // absent on the last page
+}
+
+func encodeTxCursor(k store.TxCursor) string {
+	raw := k.Date.UTC().Format(time.RFC3339Nano) + "|" +
+		k.CreatedAt.UTC().Format(time.RFC3339Nano) + "|" + k.ID
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeTxCursor(s string) (store.TxCursor, error) {
+	var k store.TxCursor
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return k, err
+	}
+	parts := strings.Split(string(raw), "|")
+	if len(parts) != 3 || !validate.ID(parts[2]) {
+		return k, errors.New("malformed cursor")
+	}
+	if k.Date, err = time.Parse(time.RFC3339Nano, parts[0]); err != nil {
+		return k, err
+	}
+	if k.CreatedAt, err = time.Parse(time.RFC3339Nano, parts[1]); err != nil {
+		return k, err
+	}
+	k.ID = parts[2]
+	return k, nil
+}
+
+// filter turns q, already validated, into a store filter; bare dates are
+// days in loc.
+func (q TxQuery) filter(loc *time.Location) (store.TxFilter, error) {
+	f := store.TxFilter{
+		Type: q.Type, IncludeDescendants: q.IncludeDescendants,
+		Payee: q.Payee, Tag: q.Tag, Source: q.Source,
+	}
+	if q.From != "" {
+		t, err := parseDate(q.From, loc)
+		if err != nil {
+			return f, invalid("from", "from_must_be_rfc3339_or_YYYY-MM-DD")
+		}
+		f.From = &t
+	}
+	if q.To != "" {
+		t, err := parseDate(q.To, loc)
+		if err != nil {
+			return f, invalid("to", "to_must_be_rfc3339_or_YYYY-MM-DD")
+		}
+		// a bare date is inclusive of the whole day
+		if len(q.To) == len("2006-01-02") {
+			t = t.AddDate(0, 0, 1)
+		}
+		f.To = &t
+	}
+	f.CategoryID, f.AccountID = q.CategoryID, q.AccountID
+	if q.MinAmount != "" {
+		a, err := money.Parse(q.MinAmount)
+		if err != nil {
+			return f, invalid("min_amount", "min_amount_must_be_number")
+		}
+		f.MinAmount = &a
+	}
+	if q.MaxAmount != "" {
+		a, err := money.Parse(q.MaxAmount)
+		if err != nil {
+			return f, invalid("max_amount", "max_amount_must_be_number")
+		}
+		f.MaxAmount = &a
+	}
+	if q.Cursor != "" {
+		k, err := decodeTxCursor(q.Cursor)
+		if err != nil {
+			return f, invalid("cursor", "bad_cursor")
+		}
+		f.After = &k
+	}
+	return f, nil
+}
+
+// List returns a page of live transactions, newest first.
+func (s *Transactions) List(ctx context.Context, m Member, q TxQuery) (TxPage, error) {
+	if err := validate.Struct(q); err != nil {
+		return TxPage{}, err
+	}
+	cal, err := calendar(ctx, s.st, m.UserID)
+	if err != nil {
+		return TxPage{}, err
+	}
+	f, err := q.filter(cal.Loc)
+	if err != nil {
+		return TxPage{}, err
+	}
+	// fetch one extra row to learn whether another page exists
+	f.Limit = q.Limit + 1
+	out, err := s.st.Transactions.List(ctx, m.HouseholdID, f)
+	if err != nil {
+		return TxPage{}, err
+	}
+	page := TxPage{Items: out}
+	if len(out) > q.Limit {
+		page.Items = out[:q.Limit]
+		last := page.Items[q.Limit-1]
+		next := encodeTxCursor(store.TxCursor{Date: last.Date, CreatedAt: last.CreatedAt, ID: last.ID})
+		page.NextCursor = &next
+	}
+	return page, nil
+}
+
+// Get returns a live transaction.
+func (s *Transactions) Get(ctx context.Context, m Member, id string) (*models.Transaction, error) {
+	tx, err := s.st.Transactions.Get(ctx, m.HouseholdID, id)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, apperr.NotFound()
+	}
+	return tx, err
+}
+
+// dateOrNow reads an optional transaction date; a missing one is now.
+func (s *Transactions) dateOrNow(ctx context.Context, uid string, v *string) (time.Time, error) {
+	if v == nil || *v == "" {
+		return time.Now().UTC(), nil
+	}
+	cal, err := calendar(ctx, s.st, uid)
+	if err != nil {
+		return time.Time{}, err
+	}
+	t, err := parseDate(*v, cal.Loc)
+	if err != nil {
+		return time.Time{}, invalid("date", "date_must_be_rfc3339_or_YYYY-MM-DD")
+	}
+	return t, nil
+}
+
+// usableAccount loads an account a transaction may be booked on.
+func (s *Transactions) usableAccount(ctx context.Context, uid, id string) (*models.Account, error) {
+	acc, err := s.st.Accounts.Get(ctx, uid, id)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, invalid("account_id", "account_not_found")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if acc.Archived {
+		return nil, invalid("account_id", "account_archived")
+	}
+	return acc, nil
+}
+
+// currency picks a transaction's currency: its account's when it has one,
+// else the requested currency, else the base currency of uid, who created
+// the transaction and owns its account. A requested currency that differs
+// from the account's is rejected.
+func currency(ctx context.Context, st store.Stores, uid string, accountID, requested *string) (string, error) {
+	want := ""
+	if r := models.NilIfEmpty(requested); r != nil {
+		var ok bool
+		if want, ok = fx.NormalizeCode(*r); !ok {
+			return "", invalid("currency", "currency_must_be_iso_4217")
+		}
+	}
+	if accountID == nil && want != "" {
+		return want, nil
+	}
+	cur := ""
+	if accountID != nil {
+		acc, err := st.Accounts.Get(ctx, uid, *accountID)
+		if err != nil && !errors.Is(err, store.ErrNotFound) {
+			return "", err
+		}
+		if acc != nil {
+			cur = acc.Currency
+		}
+	}
+	if cur == "" {
+		us, err := st.Settings.Get(ctx, uid)
+		if err != nil {
+			return "", err
+		}
+		cur = us.BaseCurrency
+	}
+	if want != "" && want != cur {
+		return "", invalid("currency", "currency_must_match_account")
+	}
+	return cur, nil
+}
+
+// Create records an income or expense. The caller's rules run on it first.
+func (s *Transactions) Create(ctx context.Context, m Member, in NewTransaction) (*models.Transaction, error) {
+	if err := m.RequireEditor(); err != nil {
+		return nil, err
+	}
+	if err := check(ctx, s.st, m, in); err != nil {
+		return nil, err
+	}
+	if !models.IsIncomeOrExpense(in.Type) {
+		return nil, invalid("type", "type_must_be_income_expense_or_transfer")
+	}
+	d, err := s.dateOrNow(ctx, m.UserID, in.Date)
+	if err != nil {
+		return nil, err
+	}
+	in.AccountID = models.NilIfEmpty(in.AccountID)
+	if in.AccountID != nil {
+		if _, err := s.usableAccount(ctx, m.UserID, *in.AccountID); err != nil {
+			return nil, err
+		}
+	}
+	cur, err := currency(ctx, s.st, m.UserID, in.AccountID, in.Currency)
+	if err != nil {
+		return nil, err
+	}
+	tx := models.Transaction{
+		HouseholdBase: models.HouseholdBase{HouseholdID: m.HouseholdID, CreatedBy: m.UserID},
+		Type:          in.Type, Date: d, Amount: in.Amount, Currency: cur,
+		Payee: in.Payee, Memo: in.Memo, CategoryID: in.CategoryID, Tags: in.Tags,
+		AccountID: in.AccountID,
+	}
+	if len(in.Splits) > 0 {
+		splits, err := buildSplits(m, in.Splits, tx.Amount)
+		if err != nil {
+			return nil, err
+		}
+		tx.Splits = splits
+		tx.CategoryID = nil
+	}
+	rs, err := s.st.Rules.Active(ctx, m.UserID)
+	if err != nil {
+		return nil, err
+	}
+	engine, err := rules.New(rs)
+	if err != nil {
+		return nil, err
+	}
+	if engine.Categories, err = categoryIDs(ctx, s.st, m.HouseholdID); err != nil {
+		return nil, err
+	}
+	engine.Apply(&tx)
+	if err := s.st.Transactions.Create(ctx, &tx); err != nil {
+		return nil, err
+	}
+	return &tx, nil
+}
+
+// Update changes the fields set in the patch. Transfers keep both legs in
+// step; split lines must keep adding up to the amount.
+func (s *Transactions) Update(ctx context.Context, m Member, id string, in TransactionPatch) (*models.Transaction, error) {
+	if err := m.RequireEditor(); err != nil {
+		return nil, err
+	}
+	tx, err := s.Get(ctx, m, id)
+	if err != nil {
+		return nil, err
+	}
+	// accounts are personal: only the creator can move a row between theirs
+	if in.AccountID != nil && tx.CreatedBy != m.UserID {
+		return nil, apperr.Forbidden("account_belongs_to_creator")
+	}
+	if err := check(ctx, s.st, m, in); err != nil {
+		return nil, err
+	}
+
+	changes := map[string]any{}
+	if in.Type != nil && tx.Type == models.TxTransfer {
+		if *in.Type != models.TxTransfer {
+			return nil, invalid("type", "transfer_type_is_fixed")
+		}
+	} else if in.Type != nil {
+		if !models.IsIncomeOrExpense(*in.Type) {
+			return nil, invalid("type", "type_must_be_income_or_expense")
+		}
+		changes["type"] = *in.Type
+	}
+	if in.Date != nil {
+		cal, err := calendar(ctx, s.st, m.UserID)
+		if err != nil {
+			return nil, err
+		}
+		t, err := parseDate(*in.Date, cal.Loc)
+		if err != nil {
+			return nil, invalid("date", "date_must_be_rfc3339_or_YYYY-MM-DD")
+		}
+		changes["date"] = t
+	}
+	if in.Amount != nil {
+		changes["amount"] = *in.Amount
+	}
+	if in.Payee != nil {
+		changes["payee"] = models.NilIfEmpty(in.Payee)
+	}
+	if in.Memo != nil {
+		changes["memo"] = models.NilIfEmpty(in.Memo)
+	}
+	if in.CategoryID != nil {
+		changes["category_id"] = models.NilIfEmpty(in.CategoryID)
+	}
+	if in.Tags != nil {
+		changes["tags"] = models.NilIfEmpty(in.Tags)
+	}
+	if in.AccountID != nil {
+		if id := models.NilIfEmpty(in.AccountID); id != nil {
+			_, err := s.st.Accounts.Get(ctx, m.UserID, *id)
+			if errors.Is(err, store.ErrNotFound) {
+				return nil, invalid("account_id", "account_not_found")
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+		changes["account_id"] = models.NilIfEmpty(in.AccountID)
+	}
+	if in.Currency != nil || in.AccountID != nil {
+		accountID, requested := tx.AccountID, in.Currency
+		if in.AccountID != nil {
+			accountID = models.NilIfEmpty(in.AccountID)
+		}
+		if requested == nil && accountID == nil {
+			requested = &tx.Currency // leaving an account keeps the currency
+		}
+		cur, err := currency(ctx, s.st, tx.CreatedBy, accountID, requested)
+		if err != nil {
+			return nil, err
+		}
+		if cur != tx.Currency {
+			changes["currency"] = cur
+		}
+	}
+	if tx.Type == models.TxTransfer {
+		if in.Splits != nil {
+			return nil, invalid("splits", "transfer_cannot_be_split")
+		}
+		if len(changes) == 0 {
+			return tx, nil
+		}
+		return s.updateTransfer(ctx, tx, changes)
+	}
+
+	// split lines must keep adding up to the amount
+	amount := tx.Amount
+	if in.Amount != nil {
+		amount = *in.Amount
+	}
+	var splits []models.TransactionSplit
+	if in.Splits != nil {
+		if splits, err = buildSplits(m, *in.Splits, amount); err != nil {
+			return nil, err
+		}
+		if len(splits) > 0 {
+			if cat, ok := changes["category_id"].(*string); ok && cat != nil {
+				return nil, invalid("category_id", "split_transaction_has_no_category")
+			}
+			changes["category_id"] = nil
+		}
+	} else if len(tx.Splits) > 0 {
+		if cat, ok := changes["category_id"].(*string); ok && cat != nil {
+			return nil, invalid("category_id", "split_transaction_has_no_category")
+		}
+		if !splitsMatch(tx.Splits, amount) {
+			return nil, invalid("amount", "splits_must_sum_to_amount")
+		}
+	}
+	if len(changes) == 0 && in.Splits == nil {
+		return tx, nil
+	}
+
+	err = s.st.Tx.InTx(ctx, func(ctx context.Context) error {
+		if len(changes) > 0 {
+			if err := s.st.Transactions.Update(ctx, m.HouseholdID, tx.ID, changes); err != nil {
+				return err
+			}
+		}
+		if in.Splits != nil {
+			return s.st.Transactions.ReplaceSplits(ctx, tx, splits)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.st.Transactions.Get(ctx, m.HouseholdID, tx.ID)
+}
+
+// Delete soft-deletes a transaction; deleting either leg of a transfer
+// deletes both.
+func (s *Transactions) Delete(ctx context.Context, m Member, id string) error {
+	if err := m.RequireEditor(); err != nil {
+		return err
+	}
+	err := s.st.Transactions.SetDeleted(ctx, m.HouseholdID, id, true)
+	if errors.Is(err, store.ErrNotFound) {
+		return apperr.NotFound()
+	}
+	return err
+}
+
+// Restore brings back a soft-deleted transaction, and the other leg of a
+// transfer.
+func (s *Transactions) Restore(ctx context.Context, m Member, id string) (*models.Transaction, error) {
+	if err := m.RequireEditor(); err != nil {
+		return nil, err
+	}
+	err := s.st.Transactions.SetDeleted(ctx, m.HouseholdID, id, false)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, apperr.NotFound()
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.st.Transactions.Get(ctx, m.HouseholdID, id)
+}
+
+// buildSplits checks that split lines, already validated one by one, add up
+// to the parent amount and returns them ready to insert.
+func buildSplits(m Member, in []Split, amount money.Amount) ([]models.TransactionSplit, error) {
+	out := make([]models.TransactionSplit, 0, len(in))
+	var sum money.Amount
+	for _, l := range in {
+		sum += l.Amount
+		out = append(out, models.TransactionSplit{
+			HouseholdBase: models.HouseholdBase{HouseholdID: m.HouseholdID, CreatedBy: m.UserID},
+			CategoryID:    models.NilIfEmpty(l.CategoryID),
+			Amount:        l.Amount,
+			Memo:          models.NilIfEmpty(l.Memo),
+		})
+	}
+	if len(out) > 0 && sum != amount {
+		return nil, invalid("splits", "splits_must_sum_to_amount")
+	}
+	return out, nil
+}
+
+func splitsMatch(splits []models.TransactionSplit, amount money.Amount) bool {
+	var sum money.Amount
+	for _, s := range splits {
+		sum += s.Amount
+	}
+	return sum == amount
+}
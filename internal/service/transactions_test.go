@@ -0,0 +1,443 @@
+package service_test
+
+import (
+	"context"
+	"encoding/base64"
+	"slices"
+	"testing"
+	"time"
+
+	"budgex_backend/internal/apperr"
+	"budgex_backend/internal/models"
+	"budgex_backend/internal/money"
+	"budgex_backend/internal/service"
+)
+
+func TestTransactionsCreateValidation(t *testing.T) {
+	f := newFixture(t)
+	food := f.category(t, "Food")
+	eur := f.account(t, "owner", "EUR")
+	closed := &models.Account{Base: models.Base{UserID: "owner"}, Name: "Old", Currency: "USD", Archived: true}
+	mustInsert(t, f.db, closed)
+	theirs := f.account(t, "someone-else", "USD")
+	viewer := f.member(t, "viewer", models.RoleViewer)
+
+	unknown := "6f1c2f43-9a4e-4d4b-8f61-1d2b4b7a0c11"
+	tests := []struct {
+		name string
+		m    service.Member
+		in   service.NewTransaction
+		want string
+	}{
+		{"viewer", viewer, service.NewTransaction{Type: "expense", Amount: 100}, "household_read_only"},
+		{"no type", f.owner, service.NewTransaction{Amount: 100}, "type:type_required"},
+		{"bad type", f.owner, service.NewTransaction{Type: "refund", Amount: 100}, "type:type_must_be_income_expense_or_transfer"},
+		{"transfer", f.owner, service.NewTransaction{Type: "transfer", Amount: 100}, "type:type_must_be_income_expense_or_transfer"},
+		{"zero amount", f.owner, service.NewTransaction{Type: "expense"}, "amount:amount_must_be_positive"},
+		{"negative amount", f.owner, service.NewTransaction{Type: "expense", Amount: -1}, "amount:amount_must_be_positive"},
+		{"bad date", f.owner, service.NewTransaction{Type: "expense", Amount: 100, Date: ptr("yesterday")}, "date:date_must_be_rfc3339_or_YYYY-MM-DD"},
+		{"bad category id", f.owner, service.NewTransaction{Type: "expense", Amount: 100, CategoryID: ptr("food")}, "category_id:category_id_must_be_uuid"},
+		{"unknown category", f.owner, service.NewTransaction{Type: "expense", Amount: 100, CategoryID: &unknown}, "category_id:category_not_found"},
+		{"other user's account", f.owner, service.NewTransaction{Type: "expense", Amount: 100, AccountID: &theirs.ID}, "account_id:account_not_found"},
+		{"archived account", f.owner, service.NewTransaction{Type: "expense", Amount: 100, AccountID: &closed.ID}, "account_id:account_archived"},
+		{"currency of another account", f.owner, service.NewTransaction{Type: "expense", Amount: 100, AccountID: &eur.ID, Currency: ptr("USD")}, "currency:currency_must_match_account"},
+		{"splits off by a cent", f.owner, service.NewTransaction{Type: "expense", Amount: 1000, Splits: []service.Split{
+			{CategoryID: &food.ID, Amount: 600}, {Amount: 399},
+		}}, "splits:splits_must_sum_to_amount"},
+		{"split without amount", f.owner, service.NewTransaction{Type: "expense", Amount: 1000, Splits: []service.Split{
+			{CategoryID: &food.ID, Amount: 1000}, {},
+		}}, "splits.1.amount:amount_must_be_positive"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := f.svc.Transactions.Create(context.Background(), tt.m, tt.in)
+			if got := code(err); got != tt.want {
+				t.Errorf("Create = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTransactionsCreate(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	food := f.category(t, "Food")
+	home := f.category(t, "Home")
+	eur := f.account(t, "owner", "EUR")
+
+	tx, err := f.svc.Transactions.Create(ctx, f.owner, service.NewTransaction{
+		Type: "expense", Amount: 1000, Date: ptr("2026-03-14"), AccountID: &eur.ID, CategoryID: &food.ID,
+		Splits: []service.Split{{CategoryID: &food.ID, Amount: 600}, {CategoryID: &home.ID, Amount: 400}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tx.Currency != "EUR" {
+		t.Errorf("currency = %q, want the account's EUR", tx.Currency)
+	}
+	if tx.CategoryID != nil {
+		t.Errorf("category_id = %v, want none on a split transaction", *tx.CategoryID)
+	}
+	if len(tx.Splits) != 2 || tx.CreatedBy != "owner" || tx.HouseholdID != f.owner.HouseholdID {
+		t.Errorf("got %d splits, created by %q in %q", len(tx.Splits), tx.CreatedBy, tx.HouseholdID)
+	}
+
+	plain, err := f.svc.Transactions.Create(ctx, f.owner, service.NewTransaction{Type: "income", Amount: 250})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plain.Currency != "USD" {
+		t.Errorf("currency = %q, want the base currency USD", plain.Currency)
+	}
+}
+
+func TestTransactionsUpdateValidation(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	food := f.category(t, "Food")
+	home := f.category(t, "Home")
+	eur := f.account(t, "owner", "EUR")
+	viewer := f.member(t, "viewer", models.RoleViewer)
+
+	split, err := f.svc.Transactions.Create(ctx, f.owner, service.NewTransaction{
+		Type: "expense", Amount: 1000,
+		Splits: []service.Split{{CategoryID: &food.ID, Amount: 600}, {CategoryID: &home.ID, Amount: 400}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	onAccount, err := f.svc.Transactions.Create(ctx, f.owner, service.NewTransaction{Type: "expense", Amount: 500, AccountID: &eur.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		m    service.Member
+		id   string
+		in   service.TransactionPatch
+		want string
+	}{
+		{"viewer", viewer, split.ID, service.TransactionPatch{Payee: ptr("Shop")}, "household_read_only"},
+		{"missing", f.owner, "6f1c2f43-9a4e-4d4b-8f61-1d2b4b7a0c11", service.TransactionPatch{}, "not_found"},
+		{"bad type", f.owner, split.ID, service.TransactionPatch{Type: ptr("refund")}, "type:type_must_be_income_expense_or_transfer"},
+		{"to transfer", f.owner, split.ID, service.TransactionPatch{Type: ptr("transfer")}, "type:type_must_be_income_or_expense"},
+		{"zero amount", f.owner, split.ID, service.TransactionPatch{Amount: ptr(money.Amount(0))}, "amount:amount_must_be_positive"},
+		{"amount no longer matches splits", f.owner, split.ID, service.TransactionPatch{Amount: ptr(money.Amount(900))}, "amount:splits_must_sum_to_amount"},
+		{"category on a split", f.owner, split.ID, service.TransactionPatch{CategoryID: &food.ID}, "category_id:split_transaction_has_no_category"},
+		{"new splits off", f.owner, split.ID, service.TransactionPatch{Splits: &[]service.Split{{Amount: 999}}}, "splits:splits_must_sum_to_amount"},
+		{"currency of another account", f.owner, onAccount.ID, service.TransactionPatch{Currency: ptr("USD")}, "currency:currency_must_match_account"},
+		{"bad currency", f.owner, onAccount.ID, service.TransactionPatch{Currency: ptr("dollars")}, "currency:currency_must_be_iso_4217"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := f.svc.Transactions.Update(ctx, tt.m, tt.id, tt.in)
+			if got := code(err); got != tt.want {
+				t.Errorf("Update = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTransactionsUpdate(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	food := f.category(t, "Food")
+	eur := f.account(t, "owner", "EUR")
+
+	tx, err := f.svc.Transactions.Create(ctx, f.owner, service.NewTransaction{
+		Type: "expense", Amount: 1000, Payee: ptr("Shop"), CategoryID: &food.ID,
+		Splits: []service.Split{{CategoryID: &food.ID, Amount: 1000}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// new amount and splits together; "" clears the payee
+	got, err := f.svc.Transactions.Update(ctx, f.owner, tx.ID, service.TransactionPatch{
+		Amount: ptr(money.Amount(1500)),
+		Payee:  ptr(""),
+		Splits: &[]service.Split{{CategoryID: &food.ID, Amount: 1000}, {Amount: 500}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Amount != 1500 || got.Payee != nil || len(got.Splits) != 2 {
+		t.Errorf("amount %v, payee %v, %d splits; want 15.00, none, 2", got.Amount, got.Payee, len(got.Splits))
+	}
+
+	// [] removes the splits, so a category may be set again
+	got, err = f.svc.Transactions.Update(ctx, f.owner, tx.ID, service.TransactionPatch{
+		Splits: &[]service.Split{}, CategoryID: &food.ID,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Splits) != 0 || got.CategoryID == nil || *got.CategoryID != food.ID {
+		t.Errorf("%d splits, category %v; want none and Food", len(got.Splits), got.CategoryID)
+	}
+
+	// moving onto an account takes its currency; leaving it keeps it
+	got, err = f.svc.Transactions.Update(ctx, f.owner, tx.ID, service.TransactionPatch{AccountID: &eur.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Currency != "EUR" {
+		t.Errorf("currency = %q after moving to the EUR account", got.Currency)
+	}
+	got, err = f.svc.Transactions.Update(ctx, f.owner, tx.ID, service.TransactionPatch{AccountID: ptr("")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.AccountID != nil || got.Currency != "EUR" {
+		t.Errorf("account %v, currency %q after leaving the account; want none, EUR", got.AccountID, got.Currency)
+	}
+}
+
+func TestTransactionsUpdateByAnotherMember(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	eur := f.account(t, "owner", "EUR")
+	editor := f.member(t, "editor", models.RoleEditor)
+	mine := f.account(t, "editor", "USD")
+
+	tx, err := f.svc.Transactions.Create(ctx, f.owner, service.NewTransaction{Type: "expense", Amount: 500, AccountID: &eur.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the currency still comes from the creator's account
+	got, err := f.svc.Transactions.Update(ctx, editor, tx.ID, service.TransactionPatch{Currency: ptr("EUR"), Payee: ptr("Bakery")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Currency != "EUR" || got.Payee == nil || *got.Payee != "Bakery" {
+		t.Errorf("currency %q, payee %v; want EUR, Bakery", got.Currency, got.Payee)
+	}
+	if _, err := f.svc.Transactions.Update(ctx, editor, tx.ID, service.TransactionPatch{Currency: ptr("USD")}); code(err) != "currency:currency_must_match_account" {
+		t.Errorf("USD on the EUR account = %q", code(err))
+	}
+
+	for _, id := range []string{mine.ID, ""} {
+		_, err := f.svc.Transactions.Update(ctx, editor, tx.ID, service.TransactionPatch{AccountID: &id})
+		if code(err) != "account_belongs_to_creator" {
+			t.Errorf("account %q set by another member = %q, want account_belongs_to_creator", id, code(err))
+		}
+	}
+}
+
+func TestTransactionsListPages(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	// five rows share a date, three of them also created_at
+	day := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	at := time.Date(2024, 3, 2, 9, 0, 0, 0, time.UTC)
+	for _, k := range []struct{ date, created time.Time }{
+		{day, at}, {day, at}, {day, at}, {day, at.Add(time.Second)}, {day, at.Add(-time.Second)},
+		{day.AddDate(0, 0, 1), at}, {day.AddDate(0, 0, -1), at},
+	} {
+		mustInsert(t, f.db, &models.Transaction{
+			HouseholdBase: models.HouseholdBase{HouseholdID: f.owner.HouseholdID, CreatedBy: "owner", CreatedAt: k.created},
+			Type:          models.TxExpense, Date: k.date, Amount: 100, Currency: "USD",
+		})
+	}
+
+	all, err := f.svc.Transactions.List(ctx, f.owner, service.TxQuery{Limit: 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all.Items) != 7 || all.NextCursor != nil {
+		t.Fatalf("one big page = %d items, next cursor %v; want 7 and none", len(all.Items), all.NextCursor)
+	}
+	var got []string
+	q := service.TxQuery{Limit: 3}
+	for pages := 0; ; pages++ {
+		if pages > 3 {
+			t.Fatal("paging did not end")
+		}
+		page, err := f.svc.Transactions.List(ctx, f.owner, q)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, tx := range page.Items {
+			got = append(got, tx.ID)
+		}
+		if page.NextCursor == nil {
+			if len(page.Items) != 1 {
+				t.Errorf("last page has %d items, want 1", len(page.Items))
+			}
+			break
+		}
+		q.Cursor = *page.NextCursor
+	}
+	var want []string
+	for _, tx := range all.Items {
+		want = append(want, tx.ID)
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("paged ids = %v, want %v", got, want)
+	}
+
+	// a page that ends exactly on the last row has no next cursor either
+	page, err := f.svc.Transactions.List(ctx, f.owner, service.TxQuery{Limit: 7})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page.NextCursor != nil {
+		t.Errorf("full last page has next cursor %q", *page.NextCursor)
+	}
+
+	enc := base64.RawURLEncoding.EncodeToString
+	for _, cursor := range []string{
+		"not a cursor!",
+		enc([]byte("2024-03-01T00:00:00Z|2024-03-02T09:00:00Z")),
+		enc([]byte("2024-03-01T00:00:00Z|2024-03-02T09:00:00Z|not-an-id")),
+		enc([]byte("yesterday|2024-03-02T09:00:00Z|" + all.Items[0].ID)),
+		enc([]byte("2024-03-01T00:00:00Z|09:00|" + all.Items[0].ID)),
+	} {
+		_, err := f.svc.Transactions.List(ctx, f.owner, service.TxQuery{Limit: 3, Cursor: cursor})
+		if code(err) != "cursor:bad_cursor" || apperr.As(err).Status() != 422 {
+			t.Errorf("List with cursor %q = %v, want a 422 cursor:bad_cursor", cursor, err)
+		}
+	}
+}
+
+func TestTransactionsListFilters(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	food := f.category(t, "Food")
+	acc := f.account(t, "owner", "USD")
+	own := models.HouseholdBase{HouseholdID: f.owner.HouseholdID, CreatedBy: "owner"}
+	day := func(d int) time.Time { return time.Date(2024, 3, d, 0, 0, 0, 0, time.UTC) }
+	grocer := &models.Transaction{HouseholdBase: own, Type: models.TxExpense, Date: day(1), Amount: 1500,
+		Currency: "USD", Payee: ptr("Corner Grocer"), CategoryID: &food.ID, Tags: ptr("weekly,home")}
+	market := &models.Transaction{HouseholdBase: own, Type: models.TxExpense, Date: day(5), Amount: 800,
+		Currency: "USD", Payee: ptr("Market"), AccountID: &acc.ID}
+	salary := &models.Transaction{HouseholdBase: own, Type: models.TxIncome, Date: day(10), Amount: 300000,
+		Currency: "USD", Payee: ptr("Employer"), AccountID: &acc.ID}
+	mustInsert(t, f.db, grocer, market, salary)
+
+	tests := []struct {
+		name string
+		q    service.TxQuery
+		want []*models.Transaction
+	}{
+		{"date range", service.TxQuery{From: "2024-03-05", To: "2024-03-10"}, []*models.Transaction{salary, market}},
+		{"category", service.TxQuery{CategoryID: food.ID}, []*models.Transaction{grocer}},
+		{"account", service.TxQuery{AccountID: acc.ID}, []*models.Transaction{salary, market}},
+		{"type", service.TxQuery{Type: "income"}, []*models.Transaction{salary}},
+		{"tag", service.TxQuery{Tag: "home"}, []*models.Transaction{grocer}},
+		{"amount range", service.TxQuery{MinAmount: "8.00", MaxAmount: "15"}, []*models.Transaction{market, grocer}},
+		{"payee", service.TxQuery{Payee: "GROCER"}, []*models.Transaction{grocer}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.q.Limit = 10
+			page, err := f.svc.Transactions.List(ctx, f.owner, tt.q)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got, want []string
+			for _, tx := range page.Items {
+				got = append(got, tx.ID)
+			}
+			for _, tx := range tt.want {
+				want = append(want, tx.ID)
+			}
+			if !slices.Equal(got, want) {
+				t.Errorf("ids = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestTransactionsOtherHousehold(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	other := &models.Household{Name: "Next door", CreatedBy: "neighbour"}
+	mustInsert(t, f.db, other)
+	theirs := &models.Transaction{HouseholdBase: models.HouseholdBase{HouseholdID: other.ID, CreatedBy: "neighbour"},
+		Type: models.TxExpense, Amount: 500, Currency: "USD"}
+	now := time.Now()
+	gone := &models.Transaction{HouseholdBase: models.HouseholdBase{HouseholdID: other.ID, CreatedBy: "neighbour", DeletedAt: &now},
+		Type: models.TxExpense, Amount: 700, Currency: "USD"}
+	mustInsert(t, f.db, theirs, gone)
+
+	if _, err := f.svc.Transactions.Get(ctx, f.owner, theirs.ID); code(err) != "not_found" {
+		t.Errorf("Get = %q, want not_found", code(err))
+	}
+	if _, err := f.svc.Transactions.Update(ctx, f.owner, theirs.ID, service.TransactionPatch{Payee: ptr("mine")}); code(err) != "not_found" {
+		t.Errorf("Update = %q, want not_found", code(err))
+	}
+	if err := f.svc.Transactions.Delete(ctx, f.owner, theirs.ID); code(err) != "not_found" {
+		t.Errorf("Delete = %q, want not_found", code(err))
+	}
+	if _, err := f.svc.Transactions.Restore(ctx, f.owner, gone.ID); code(err) != "not_found" {
+		t.Errorf("Restore = %q, want not_found", code(err))
+	}
+	if _, err := f.svc.Transactions.Get(ctx, f.owner, "not-a-uuid"); code(err) != "not_found" {
+		t.Errorf("Get of a malformed id = %q, want not_found", code(err))
+	}
+
+	// their rows are untouched
+	neighbour := service.Member{UserID: "neighbour", HouseholdID: other.ID, Role: models.RoleOwner}
+	mustInsert(t, f.db, &models.HouseholdMember{HouseholdID: other.ID, UserID: "neighbour", Role: models.RoleOwner})
+	tx, err := f.svc.Transactions.Get(ctx, neighbour, theirs.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tx.Payee != nil {
+		t.Errorf("their payee = %q, want none", *tx.Payee)
+	}
+	if _, err := f.svc.Transactions.Get(ctx, neighbour, gone.ID); code(err) != "not_found" {
+		t.Errorf("Get of their deleted row = %q, want not_found", code(err))
+	}
+}
+
+func TestTransactionsListHidesDeleted(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	var ids []string
+	for _, amount := range []money.Amount{100, 200, 300} {
+		tx, err := f.svc.Transactions.Create(ctx, f.owner, service.NewTransaction{Type: "expense", Amount: amount})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, tx.ID)
+	}
+	if err := f.svc.Transactions.Delete(ctx, f.owner, ids[1]); err != nil {
+		t.Fatal(err)
+	}
+	listed := func() []string {
+		page, err := f.svc.Transactions.List(ctx, f.owner, service.TxQuery{Limit: 10})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var out []string
+		for _, tx := range page.Items {
+			out = append(out, tx.ID)
+		}
+		slices.Sort(out)
+		return out
+	}
+	want := []string{ids[0], ids[2]}
+	slices.Sort(want)
+	if got := listed(); !slices.Equal(got, want) {
+		t.Errorf("List after Delete = %v, want %v", got, want)
+	}
+	if _, err := f.svc.Transactions.Get(ctx, f.owner, ids[1]); code(err) != "not_found" {
+		t.Errorf("Get of a deleted row = %q, want not_found", code(err))
+	}
+	if err := f.svc.Transactions.Delete(ctx, f.owner, ids[1]); code(err) != "not_found" {
+		t.Errorf("second Delete = %q, want not_found", code(err))
+	}
+
+	if _, err := f.svc.Transactions.Restore(ctx, f.owner, ids[1]); err != nil {
+		t.Fatal(err)
+	}
+	want = slices.Clone(ids)
+	slices.Sort(want)
+	if got := listed(); !slices.Equal(got, want) {
+		t.Errorf("List after Restore = %v, want %v", got, want)
+	}
+}
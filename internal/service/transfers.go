@@ -0,0 +1,149 @@
+package service
+
+import (
+	"context"
+
+	"budgex_backend/internal/models"
+	"budgex_backend/internal/money"
+
+	"github.com/google/uuid"
+)
+
+// Transfer is both legs of a new transfer.
+type Transfer struct {
+	TransferID string             `json:"transfer_id"`
+	From       models.Transaction `json:"from"` // leg on the source account (negative amount)
+	To         models.Transaction `json:"to"`   // leg on the destination account
+}
+
+// Fields copied from one transfer leg to the other on update.
+var mirroredTransferFields = []string{"date", "payee", "memo", "tags"}
+
+// CreateTransfer moves money from in.AccountID to in.ToAccountID as two
+// linked transactions.
+func (s *Transactions) CreateTransfer(ctx context.Context, m Member, in NewTransaction) (*Transfer, error) {
+	if err := m.RequireEditor(); err != nil {
+		return nil, err
+	}
+	if err := check(ctx, s.st, m, in); err != nil {
+		return nil, err
+	}
+	if len(in.Splits) > 0 {
+		return nil, invalid("splits", "transfer_cannot_be_split")
+	}
+	d, err := s.dateOrNow(ctx, m.UserID, in.Date)
+	if err != nil {
+		return nil, err
+	}
+	if models.NilIfEmpty(in.AccountID) == nil || models.NilIfEmpty(in.ToAccountID) == nil {
+		return nil, invalid("", "transfer_needs_account_and_to_account")
+	}
+	if *in.AccountID == *in.ToAccountID {
+		return nil, invalid("to_account_id", "transfer_accounts_must_differ")
+	}
+	var accs [2]*models.Account
+	for i, id := range []string{*in.AccountID, *in.ToAccountID} {
+		if accs[i], err = s.usableAccount(ctx, m.UserID, id); err != nil {
+			return nil, err
+		}
+	}
+	// between currencies the caller says what arrived; otherwise it is the
+	// amount sent
+	toAmount := in.Amount
+	if accs[0].Currency != accs[1].Currency {
+		if in.ToAmount == nil {
+			return nil, invalid("to_amount", "to_amount_required_between_currencies")
+		}
+		toAmount = *in.ToAmount
+	} else if in.ToAmount != nil && *in.ToAmount != in.Amount {
+		return nil, invalid("to_amount", "to_amount_must_equal_amount")
+	}
+
+	transferID := uuid.NewString()
+	leg := func(acc *models.Account, amount money.Amount) models.Transaction {
+		return models.Transaction{
+			HouseholdBase: models.HouseholdBase{HouseholdID: m.HouseholdID, CreatedBy: m.UserID},
+			Type:          models.TxTransfer, Date: d, Amount: amount, Currency: acc.Currency,
+			Payee: in.Payee, Memo: in.Memo, Tags: in.Tags,
+			AccountID: &acc.ID, TransferID: &transferID,
+		}
+	}
+	out := Transfer{
+		TransferID: transferID,
+		From:       leg(accs[0], -in.Amount),
+		To:         leg(accs[1], toAmount),
+	}
+	err = s.st.Tx.InTx(ctx, func(ctx context.Context) error {
+		if err := s.st.Transactions.Create(ctx, &out.From); err != nil {
+			return err
+		}
+		return s.st.Transactions.Create(ctx, &out.To)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// updateTransfer applies changes to one leg and keeps the other leg in step.
+func (s *Transactions) updateTransfer(ctx context.Context, tx *models.Transaction, changes map[string]any) (*models.Transaction, error) {
+	if _, ok := changes["category_id"]; ok {
+		return nil, invalid("category_id", "transfer_has_no_category")
+	}
+	delete(changes, "type")
+
+	peer, err := s.st.Transactions.TransferPeer(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+	if v, ok := changes["account_id"]; ok {
+		id, _ := v.(*string)
+		if id == nil {
+			return nil, invalid("account_id", "transfer_needs_account_and_to_account")
+		}
+		if peer.AccountID != nil && *id == *peer.AccountID {
+			return nil, invalid("account_id", "transfer_accounts_must_differ")
+		}
+	}
+	// moving a leg to an account in another currency would leave its amount
+	// in the wrong unit
+	if _, ok := changes["currency"]; ok {
+		return nil, invalid("", "transfer_account_currency_must_not_change")
+	}
+
+	peerChanges := map[string]any{}
+	for _, f := range mirroredTransferFields {
+		if v, ok := changes[f]; ok {
+			peerChanges[f] = v
+		}
+	}
+	if v, ok := changes["amount"]; ok {
+		amt := v.(money.Amount)
+		// each leg keeps its direction; between currencies the legs'
+		// amounts are independent
+		if tx.Amount < 0 {
+			changes["amount"], peerChanges["amount"] = -amt, amt
+		} else {
+			changes["amount"], peerChanges["amount"] = amt, -amt
+		}
+		if tx.Currency != peer.Currency {
+			delete(peerChanges, "amount")
+		}
+	}
+
+	err = s.st.Tx.InTx(ctx, func(ctx context.Context) error {
+		if len(changes) > 0 {
+			if err := s.st.Transactions.Update(ctx, tx.HouseholdID, tx.ID, changes); err != nil {
+				return err
+			}
+		}
+		if len(peerChanges) > 0 {
+			return s.st.Transactions.Update(ctx, tx.HouseholdID, peer.ID, peerChanges)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.st.Transactions.Get(ctx, tx.HouseholdID, tx.ID)
+}
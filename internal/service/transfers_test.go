@@ -0,0 +1,230 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"budgex_backend/internal/models"
+	"budgex_backend/internal/money"
+	"budgex_backend/internal/service"
+)
+
+func TestTransfersCreate(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	checking, savings := f.account(t, "owner", "USD"), f.account(t, "owner", "USD")
+	euro := f.account(t, "owner", "EUR")
+
+	tr, err := f.svc.Transactions.CreateTransfer(ctx, f.owner, service.NewTransaction{
+		Type: "transfer", Amount: 1000, AccountID: &checking.ID, ToAccountID: &savings.ID, Payee: ptr("Savings"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tr.From.Amount != -1000 || tr.To.Amount != 1000 {
+		t.Errorf("legs = %d and %d, want -1000 and 1000", tr.From.Amount, tr.To.Amount)
+	}
+	for _, leg := range []models.Transaction{tr.From, tr.To} {
+		if leg.Type != models.TxTransfer || leg.TransferID == nil || *leg.TransferID != tr.TransferID {
+			t.Errorf("leg %s = %s in transfer %v, want a transfer in %s", leg.ID, leg.Type, leg.TransferID, tr.TransferID)
+		}
+	}
+
+	tr, err = f.svc.Transactions.CreateTransfer(ctx, f.owner, service.NewTransaction{
+		Type: "transfer", Amount: 1000, ToAmount: ptr(money.Amount(900)), AccountID: &checking.ID, ToAccountID: &euro.ID,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tr.From.Amount != -1000 || tr.From.Currency != "USD" || tr.To.Amount != 900 || tr.To.Currency != "EUR" {
+		t.Errorf("legs = %d %s and %d %s, want -1000 USD and 900 EUR",
+			tr.From.Amount, tr.From.Currency, tr.To.Amount, tr.To.Currency)
+	}
+
+	tests := []struct {
+		name string
+		in   service.NewTransaction
+		want string
+	}{
+		{"no destination", service.NewTransaction{Type: "transfer", Amount: 100, AccountID: &checking.ID}, "transfer_needs_account_and_to_account"},
+		{"same account", service.NewTransaction{Type: "transfer", Amount: 100, AccountID: &checking.ID, ToAccountID: &checking.ID}, "to_account_id:transfer_accounts_must_differ"},
+		{"between currencies without to_amount", service.NewTransaction{Type: "transfer", Amount: 100, AccountID: &checking.ID, ToAccountID: &euro.ID}, "to_amount:to_amount_required_between_currencies"},
+		{"other to_amount in one currency", service.NewTransaction{Type: "transfer", Amount: 100, ToAmount: ptr(money.Amount(90)), AccountID: &checking.ID, ToAccountID: &savings.ID}, "to_amount:to_amount_must_equal_amount"},
+		{"split", service.NewTransaction{Type: "transfer", Amount: 100, AccountID: &checking.ID, ToAccountID: &savings.ID, Splits: []service.Split{{Amount: 100}}}, "splits:transfer_cannot_be_split"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := f.svc.Transactions.CreateTransfer(ctx, f.owner, tt.in)
+			if got := code(err); got != tt.want {
+				t.Errorf("CreateTransfer = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTransfersUpdate(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	checking, savings := f.account(t, "owner", "USD"), f.account(t, "owner", "USD")
+	euro := f.account(t, "owner", "EUR")
+	food := f.category(t, "Food")
+
+	tr, err := f.svc.Transactions.CreateTransfer(ctx, f.owner, service.NewTransaction{
+		Type: "transfer", Amount: 1000, AccountID: &checking.ID, ToAccountID: &savings.ID,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	legs := func(t *testing.T) (from, to *models.Transaction) {
+		t.Helper()
+		from, err := f.svc.Transactions.Get(ctx, f.owner, tr.From.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		to, err = f.svc.Transactions.Get(ctx, f.owner, tr.To.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return from, to
+	}
+
+	// either leg can be edited; each keeps its direction
+	if _, err := f.svc.Transactions.Update(ctx, f.owner, tr.From.ID, service.TransactionPatch{
+		Amount: ptr(money.Amount(1500)), Payee: ptr("Rainy day"), Date: ptr("2024-03-05"),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	from, to := legs(t)
+	if from.Amount != -1500 || to.Amount != 1500 {
+		t.Errorf("after editing the source leg: %d and %d, want -1500 and 1500", from.Amount, to.Amount)
+	}
+	if to.Payee == nil || *to.Payee != "Rainy day" || !to.Date.Equal(from.Date) {
+		t.Errorf("peer payee %v on %v, want %q on %v", to.Payee, to.Date, "Rainy day", from.Date)
+	}
+	if _, err := f.svc.Transactions.Update(ctx, f.owner, tr.To.ID, service.TransactionPatch{Amount: ptr(money.Amount(700))}); err != nil {
+		t.Fatal(err)
+	}
+	if from, to = legs(t); from.Amount != -700 || to.Amount != 700 {
+		t.Errorf("after editing the destination leg: %d and %d, want -700 and 700", from.Amount, to.Amount)
+	}
+
+	tests := []struct {
+		name string
+		id   string
+		in   service.TransactionPatch
+		want string
+	}{
+		{"category", tr.From.ID, service.TransactionPatch{CategoryID: &food.ID}, "category_id:transfer_has_no_category"},
+		{"onto the peer's account", tr.From.ID, service.TransactionPatch{AccountID: &savings.ID}, "account_id:transfer_accounts_must_differ"},
+		{"off its account", tr.From.ID, service.TransactionPatch{AccountID: ptr("")}, "account_id:transfer_needs_account_and_to_account"},
+		{"onto an account in another currency", tr.To.ID, service.TransactionPatch{AccountID: &euro.ID}, "transfer_account_currency_must_not_change"},
+		{"type", tr.To.ID, service.TransactionPatch{Type: ptr("expense")}, "type:transfer_type_is_fixed"},
+		{"splits", tr.To.ID, service.TransactionPatch{Splits: &[]service.Split{}}, "splits:transfer_cannot_be_split"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := f.svc.Transactions.Update(ctx, f.owner, tt.id, tt.in)
+			if got := code(err); got != tt.want {
+				t.Errorf("Update = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTransfersUpdateBetweenCurrencies(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	checking, euro := f.account(t, "owner", "USD"), f.account(t, "owner", "EUR")
+	tr, err := f.svc.Transactions.CreateTransfer(ctx, f.owner, service.NewTransaction{
+		Type: "transfer", Amount: 1000, ToAmount: ptr(money.Amount(900)), AccountID: &checking.ID, ToAccountID: &euro.ID,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the legs' amounts are independent, the other fields still mirrored
+	from, err := f.svc.Transactions.Update(ctx, f.owner, tr.From.ID, service.TransactionPatch{
+		Amount: ptr(money.Amount(1200)), Memo: ptr("fees included"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	to, err := f.svc.Transactions.Get(ctx, f.owner, tr.To.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if from.Amount != -1200 || to.Amount != 900 {
+		t.Errorf("legs = %d and %d, want -1200 and 900", from.Amount, to.Amount)
+	}
+	if to.Memo == nil || *to.Memo != "fees included" {
+		t.Errorf("peer memo = %v, want %q", to.Memo, "fees included")
+	}
+	if to, err = f.svc.Transactions.Update(ctx, f.owner, tr.To.ID, service.TransactionPatch{Amount: ptr(money.Amount(950))}); err != nil {
+		t.Fatal(err)
+	}
+	if from, err = f.svc.Transactions.Get(ctx, f.owner, tr.From.ID); err != nil {
+		t.Fatal(err)
+	}
+	if from.Amount != -1200 || to.Amount != 950 {
+		t.Errorf("legs = %d and %d, want -1200 and 950", from.Amount, to.Amount)
+	}
+}
+
+func TestTransfersDeleteAndRestore(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	checking, savings := f.account(t, "owner", "USD"), f.account(t, "owner", "USD")
+	tr, err := f.svc.Transactions.CreateTransfer(ctx, f.owner, service.NewTransaction{
+		Type: "transfer", Amount: 1000, AccountID: &checking.ID, ToAccountID: &savings.ID,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := f.svc.Transactions.Delete(ctx, f.owner, tr.To.ID); err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{tr.From.ID, tr.To.ID} {
+		if _, err := f.svc.Transactions.Get(ctx, f.owner, id); code(err) != "not_found" {
+			t.Errorf("Get(%s) after deleting a leg = %q, want not_found", id, code(err))
+		}
+	}
+	if _, err := f.svc.Transactions.Restore(ctx, f.owner, tr.From.ID); err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{tr.From.ID, tr.To.ID} {
+		if _, err := f.svc.Transactions.Get(ctx, f.owner, id); err != nil {
+			t.Errorf("Get(%s) after restoring the other leg: %v", id, err)
+		}
+	}
+}
+
+func TestTransfersLeaveTotalsAlone(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	checking, savings := f.account(t, "owner", "USD"), f.account(t, "owner", "USD")
+	for _, in := range []service.NewTransaction{
+		{Type: "income", Amount: 300000, Date: ptr("2024-03-01"), AccountID: &checking.ID},
+		{Type: "expense", Amount: 4500, Date: ptr("2024-03-02"), AccountID: &checking.ID},
+	} {
+		if _, err := f.svc.Transactions.Create(ctx, f.owner, in); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := f.svc.Transactions.CreateTransfer(ctx, f.owner, service.NewTransaction{
+		Type: "transfer", Amount: 50000, Date: ptr("2024-03-03"), AccountID: &checking.ID, ToAccountID: &savings.ID,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	sum, err := f.svc.Analytics.SpendSummary(ctx, f.owner, "2024-03", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sum.TotalIncome != 300000 || sum.TotalExpense != 4500 {
+		t.Errorf("totals = %d income, %d expense; want 300000 and 4500", sum.TotalIncome, sum.TotalExpense)
+	}
+	if len(sum.ByCategory) != 1 || sum.ByCategory[0].Total != 4500 {
+		t.Errorf("by category = %+v, want only the expense", sum.ByCategory)
+	}
+}
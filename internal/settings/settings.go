@@ -0,0 +1,133 @@
+// Package settings reads per-user preferences and turns them into the
+// Calendar that decides where days and budget months begin for that user.
+package settings
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"budgex_backend/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Defaults for users who have not saved settings.
+const (
+	DefaultCurrency      = "USD"
+	DefaultTimezone      = "UTC"
+	DefaultWeekStart     = int(time.Monday)
+	DefaultMonthStartDay = 1
+)
+
+// MaxMonthStartDay is the latest day a budget month may start on; every
+// month has it.
+const MaxMonthStartDay = 28
+
+// Defaults returns the settings of a user who has not saved any.
+func Defaults(uid string) models.UserSettings {
+	return models.UserSettings{
+		UserID:        uid,
+		BaseCurrency:  DefaultCurrency,
+		Timezone:      DefaultTimezone,
+		WeekStart:     DefaultWeekStart,
+		MonthStartDay: DefaultMonthStartDay,
+	}
+}
+
+// Load returns the user's settings, or the defaults if none are saved.
+func Load(ctx context.Context, db *gorm.DB, uid string) (models.UserSettings, error) {
+	s := Defaults(uid)
+	err := db.WithContext(ctx).Where("user_id = ?", uid).First(&s).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return Defaults(uid), nil
+	}
+	return s, err
+}
+
+// Save writes all of the user's settings.
+func Save(ctx context.Context, db *gorm.DB, s *models.UserSettings) error {
+	return db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"base_currency", "timezone", "week_start", "month_start_day", "updated_at",
+		}),
+	}).Create(s).Error
+}
+
+// CalendarFor loads the user's settings and returns their Calendar.
+func CalendarFor(ctx context.Context, db *gorm.DB, uid string) (Calendar, error) {
+	s, err := Load(ctx, db, uid)
+	if err != nil {
+		return Calendar{}, err
+	}
+	return CalendarOf(s), nil
+}
+
+// Calendar places instants on a user's days and budget months. A budget month
+// is labelled by the month it starts in: with MonthStartDay 25, "2024-03" runs
+// from 25 March to 24 April, midnight to midnight in Loc.
+type Calendar struct {
+	Loc           *time.Location
+	MonthStartDay int
+}
+
+// UTC is the calendar of a user with default settings.
+var UTC = Calendar{Loc: time.UTC, MonthStartDay: DefaultMonthStartDay}
+
+// CalendarOf builds the Calendar for saved settings. An unknown timezone or
+// start day falls back to the default.
+func CalendarOf(s models.UserSettings) Calendar {
+	c := UTC
+	if loc, err := time.LoadLocation(s.Timezone); err == nil {
+		c.Loc = loc
+	}
+	if s.MonthStartDay >= 1 && s.MonthStartDay <= MaxMonthStartDay {
+		c.MonthStartDay = s.MonthStartDay
+	}
+	return c
+}
+
+// TZ is the timezone name, for AT TIME ZONE in SQL.
+func (c Calendar) TZ() string { return c.Loc.String() }
+
+// Day is the local date of t, as midnight UTC so it compares like a SQL date.
+func (c Calendar) Day(t time.Time) time.Time {
+	y, m, d := t.In(c.Loc).Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// Midnight is the instant the local day of date (a calendar date, any zone)
+// starts.
+func (c Calendar) Midnight(date time.Time) time.Time {
+	y, m, d := date.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, c.Loc)
+}
+
+// ParseDay reads YYYY-MM-DD as the start of that local day.
+func (c Calendar) ParseDay(s string) (time.Time, error) {
+	return time.ParseInLocation("2006-01-02", s, c.Loc)
+}
+
+// MonthOfDay is the budget month (YYYY-MM) a local date belongs to.
+func (c Calendar) MonthOfDay(day time.Time) string {
+	if day.Day() < c.MonthStartDay {
+		day = day.AddDate(0, 0, -day.Day()) // last day of the previous month
+	}
+	return day.Format("2006-01")
+}
+
+// Month is the budget month (YYYY-MM) the instant t falls in.
+func (c Calendar) Month(t time.Time) string { return c.MonthOfDay(c.Day(t)) }
+
+// MonthRange returns the instants [from, to) that a budget month spans.
+func (c Calendar) MonthRange(month string) (time.Time, time.Time, error) {
+	m, err := time.Parse("2006-01", month)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	from := time.Date(m.Year(), m.Month(), c.MonthStartDay, 0, 0, 0, 0, c.Loc)
+	to := time.Date(m.Year(), m.Month()+1, c.MonthStartDay, 0, 0, 0, 0, c.Loc)
+	return from, to, nil
+}
@@ -0,0 +1,121 @@
+package settings_test
+
+import (
+	"testing"
+	"time"
+
+	"budgex_backend/internal/models"
+	"budgex_backend/internal/settings"
+)
+
+func zone(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Skipf("no tz data for %s: %v", name, err)
+	}
+	return loc
+}
+
+func TestMonthRange(t *testing.T) {
+	colombo := zone(t, "Asia/Colombo")
+	tests := []struct {
+		name     string
+		cal      settings.Calendar
+		month    string
+		from, to string // RFC3339
+	}{
+		{"utc", settings.UTC, "2024-03", "2024-03-01T00:00:00Z", "2024-04-01T00:00:00Z"},
+		{"december", settings.UTC, "2024-12", "2024-12-01T00:00:00Z", "2025-01-01T00:00:00Z"},
+		{"payday", settings.Calendar{Loc: time.UTC, MonthStartDay: 25}, "2024-03", "2024-03-25T00:00:00Z", "2024-04-25T00:00:00Z"},
+		{"payday over the new year", settings.Calendar{Loc: time.UTC, MonthStartDay: 25}, "2024-12", "2024-12-25T00:00:00Z", "2025-01-25T00:00:00Z"},
+		{"timezone", settings.Calendar{Loc: colombo, MonthStartDay: 1}, "2024-03", "2024-02-29T18:30:00Z", "2024-03-31T18:30:00Z"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			from, to, err := tt.cal.MonthRange(tt.month)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := from.UTC().Format(time.RFC3339); got != tt.from {
+				t.Errorf("from = %s, want %s", got, tt.from)
+			}
+			if got := to.UTC().Format(time.RFC3339); got != tt.to {
+				t.Errorf("to = %s, want %s", got, tt.to)
+			}
+		})
+	}
+	for _, bad := range []string{"", "2024-13", "2024-3", "March"} {
+		if _, _, err := settings.UTC.MonthRange(bad); err == nil {
+			t.Errorf("MonthRange(%q) = nil error", bad)
+		}
+	}
+}
+
+func TestMonthOfDay(t *testing.T) {
+	payday := settings.Calendar{Loc: time.UTC, MonthStartDay: 25}
+	tests := []struct {
+		cal  settings.Calendar
+		day  string
+		want string
+	}{
+		{settings.UTC, "2024-03-01", "2024-03"},
+		{settings.UTC, "2024-03-31", "2024-03"},
+		{payday, "2024-03-24", "2024-02"},
+		{payday, "2024-03-25", "2024-03"},
+		{payday, "2024-04-24", "2024-03"},
+		{payday, "2024-01-10", "2023-12"},
+		{payday, "2024-12-31", "2024-12"},
+	}
+	for _, tt := range tests {
+		d, _ := time.Parse(time.DateOnly, tt.day)
+		if got := tt.cal.MonthOfDay(d); got != tt.want {
+			t.Errorf("MonthOfDay(%s) with start day %d = %s, want %s", tt.day, tt.cal.MonthStartDay, got, tt.want)
+		}
+	}
+}
+
+func TestMonthInTimezone(t *testing.T) {
+	// 1am on 1 March in Colombo is still February in UTC
+	colombo := settings.Calendar{Loc: zone(t, "Asia/Colombo"), MonthStartDay: 1}
+	at := time.Date(2024, 2, 29, 19, 30, 0, 0, time.UTC)
+	if got := colombo.Month(at); got != "2024-03" {
+		t.Errorf("Colombo month = %s, want 2024-03", got)
+	}
+	if got := settings.UTC.Month(at); got != "2024-02" {
+		t.Errorf("UTC month = %s, want 2024-02", got)
+	}
+	if got := colombo.Day(at).Format(time.DateOnly); got != "2024-03-01" {
+		t.Errorf("Colombo day = %s, want 2024-03-01", got)
+	}
+	if got := colombo.Midnight(colombo.Day(at)); !got.Equal(time.Date(2024, 2, 29, 18, 30, 0, 0, time.UTC)) {
+		t.Errorf("Colombo midnight = %s", got.UTC())
+	}
+	d, err := colombo.ParseDay("2024-03-01")
+	if err != nil || !d.Equal(time.Date(2024, 2, 29, 18, 30, 0, 0, time.UTC)) {
+		t.Errorf("ParseDay = %s, %v", d.UTC(), err)
+	}
+}
+
+func TestCalendarOf(t *testing.T) {
+	tests := []struct {
+		name  string
+		s     models.UserSettings
+		tz    string
+		start int
+	}{
+		{"defaults", settings.Defaults("u"), "UTC", 1},
+		{"saved", models.UserSettings{Timezone: "Asia/Colombo", MonthStartDay: 25}, "Asia/Colombo", 25},
+		{"unknown timezone", models.UserSettings{Timezone: "Mars/Olympus", MonthStartDay: 25}, "UTC", 25},
+		{"start day past 28", models.UserSettings{Timezone: "UTC", MonthStartDay: 31}, "UTC", 1},
+		{"no start day", models.UserSettings{Timezone: "UTC"}, "UTC", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := settings.CalendarOf(tt.s)
+			if c.TZ() != tt.tz || c.MonthStartDay != tt.start {
+				t.Errorf("calendar %s day %d, want %s day %d", c.TZ(), c.MonthStartDay, tt.tz, tt.start)
+			}
+		})
+	}
+}
@@ -0,0 +1,166 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	"budgex_backend/internal/models"
+	"budgex_backend/internal/money"
+	"budgex_backend/internal/store"
+)
+
+type accounts struct{ m *DB }
+
+// signed is a transaction's effect on its account balance. Transfer legs
+// already carry their sign.
+func signed(t models.Transaction) money.Amount {
+	if t.Type == models.TxExpense {
+		return -t.Amount
+	}
+	return t.Amount
+}
+
+// booked returns the live transactions on an account, in any household,
+// oldest first.
+func (d *data) booked(id string) []models.Transaction {
+	var out []models.Transaction
+	for _, t := range d.transactions {
+		if same(t.AccountID, id) && t.DeletedAt == nil {
+			out = append(out, t)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return before(out[i], store.TxCursor{Date: out[j].Date, CreatedAt: out[j].CreatedAt, ID: out[j].ID})
+	})
+	return out
+}
+
+func (s accounts) List(ctx context.Context, uid string, includeArchived bool) ([]store.AccountBalance, error) {
+	defer s.m.lock(ctx)()
+	out := []store.AccountBalance{}
+	for _, a := range s.m.d.accounts {
+		if a.UserID != uid || a.DeletedAt != nil || (a.Archived && !includeArchived) {
+			continue
+		}
+		bal := a.OpeningBalance
+		for _, t := range s.m.d.booked(a.ID) {
+			bal += signed(t)
+		}
+		out = append(out, store.AccountBalance{Account: a, Balance: bal})
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out, nil
+}
+
+func (s accounts) Get(ctx context.Context, uid, id string) (*models.Account, error) {
+	defer s.m.lock(ctx)()
+	for _, a := range s.m.d.accounts {
+		if a.ID == id && a.UserID == uid && a.DeletedAt == nil {
+			return &a, nil
+		}
+	}
+	return nil, store.ErrNotFound
+}
+
+func (s accounts) Create(ctx context.Context, a *models.Account) error {
+	defer s.m.lock(ctx)()
+	stamp(&a.Base)
+	s.m.d.accounts = append(s.m.d.accounts, *a)
+	return nil
+}
+
+func (s accounts) Update(ctx context.Context, uid, id string, changes map[string]any) error {
+	defer s.m.lock(ctx)()
+	for i, a := range s.m.d.accounts {
+		if a.ID != id || a.UserID != uid || a.DeletedAt != nil {
+			continue
+		}
+		for col, v := range changes {
+			if err := setAccountColumn(&a, col, v); err != nil {
+				return err
+			}
+		}
+		a.UpdatedAt = time.Now().UTC()
+		s.m.d.accounts[i] = a
+		return nil
+	}
+	return store.ErrNotFound
+}
+
+func setAccountColumn(a *models.Account, col string, v any) error {
+	var err error
+	switch col {
+	case "name":
+		a.Name, err = value[string](col, v)
+	case "kind":
+		a.Kind, err = value[string](col, v)
+	case "currency":
+		a.Currency, err = value[string](col, v)
+	case "opening_balance":
+		a.OpeningBalance, err = value[money.Amount](col, v)
+	case "archived":
+		a.Archived, err = value[bool](col, v)
+	case "deleted_at":
+		var at time.Time
+		at, err = value[time.Time](col, v)
+		a.DeletedAt = &at
+	default:
+		err = fmt.Errorf("memory: unknown accounts column %q", col)
+	}
+	return err
+}
+
+func (s accounts) SetCurrency(ctx context.Context, uid, id, currency string) error {
+	defer s.m.lock(ctx)()
+	ctx = context.WithValue(ctx, heldKey{}, s.m)
+	if err := s.Update(ctx, uid, id, map[string]any{"currency": currency}); err != nil {
+		return err
+	}
+	for i, t := range s.m.d.transactions {
+		if same(t.AccountID, id) {
+			s.m.d.transactions[i].Currency = currency
+		}
+	}
+	return nil
+}
+
+func (s accounts) Delete(ctx context.Context, uid, id string) error {
+	return s.Update(ctx, uid, id, map[string]any{"deleted_at": time.Now().UTC()})
+}
+
+func (s accounts) CountTransactions(ctx context.Context, uid, id string) (int64, error) {
+	defer s.m.lock(ctx)()
+	return int64(len(s.m.d.booked(id))), nil
+}
+
+func (s accounts) Balance(ctx context.Context, acc *models.Account, before time.Time) (money.Amount, error) {
+	defer s.m.lock(ctx)()
+	bal := acc.OpeningBalance
+	for _, t := range s.m.d.booked(acc.ID) {
+		if t.Date.Before(before) {
+			bal += signed(t)
+		}
+	}
+	return bal, nil
+}
+
+func (s accounts) Ledger(ctx context.Context, acc *models.Account, from, to *time.Time, limit int) ([]store.LedgerRow, error) {
+	defer s.m.lock(ctx)()
+	out := []store.LedgerRow{}
+	bal := acc.OpeningBalance
+	for _, t := range s.m.d.booked(acc.ID) {
+		bal += signed(t)
+		if (from != nil && t.Date.Before(*from)) || (to != nil && !t.Date.Before(*to)) {
+			continue
+		}
+		out = append(out, store.LedgerRow{Transaction: s.m.d.withSplits(t), RunningBalance: bal})
+	}
+	slices.Reverse(out)
+	if len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
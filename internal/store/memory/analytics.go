@@ -0,0 +1,132 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"budgex_backend/internal/models"
+	"budgex_backend/internal/money"
+	"budgex_backend/internal/settings"
+	"budgex_backend/internal/store"
+)
+
+type analytics struct{ m *DB }
+
+// sums groups amounts by key, currency and local day.
+type sums struct {
+	cal settings.Calendar
+	idx map[sumKey]int
+	out []store.DaySum
+}
+
+type sumKey struct {
+	key      string
+	null     bool
+	currency string
+	day      time.Time
+}
+
+func newSums(cal settings.Calendar) *sums {
+	return &sums{cal: cal, idx: map[sumKey]int{}, out: []store.DaySum{}}
+}
+
+func (s *sums) add(key *string, currency string, at time.Time, amount money.Amount) {
+	k := sumKey{key: deref(key), null: key == nil, currency: currency, day: s.cal.Day(at)}
+	i, ok := s.idx[k]
+	if !ok {
+		i = len(s.out)
+		s.idx[k] = i
+		s.out = append(s.out, store.DaySum{Key: key, Currency: currency, Day: k.day})
+	}
+	s.out[i].Total += amount
+}
+
+// counted reports whether t is live income or expense of hid.
+func counted(t models.Transaction, hid string) bool {
+	return t.HouseholdID == hid && t.DeletedAt == nil && (t.Type == models.TxIncome || t.Type == models.TxExpense)
+}
+
+func within(t, from, to time.Time) bool { return !t.Before(from) && t.Before(to) }
+
+func (s analytics) Totals(ctx context.Context, hid string, cal settings.Calendar, from, to time.Time) ([]store.DaySum, error) {
+	defer s.m.lock(ctx)()
+	out := newSums(cal)
+	for _, t := range s.m.d.transactions {
+		if counted(t, hid) && within(t.Date, from, to) {
+			typ := t.Type
+			out.add(&typ, t.Currency, t.Date, t.Amount)
+		}
+	}
+	return out.out, nil
+}
+
+func (s analytics) ExpenseByCategory(ctx context.Context, hid string, cal settings.Calendar, from, to time.Time) ([]store.DaySum, error) {
+	defer s.m.lock(ctx)()
+	d := &s.m.d
+	out := newSums(cal)
+	for _, t := range d.transactions {
+		if !counted(t, hid) || t.Type != models.TxExpense || !within(t.Date, from, to) {
+			continue
+		}
+		t = d.withSplits(t)
+		if len(t.Splits) == 0 {
+			out.add(t.CategoryID, t.Currency, t.Date, t.Amount)
+		}
+		for _, sp := range t.Splits {
+			out.add(sp.CategoryID, t.Currency, t.Date, sp.Amount)
+		}
+	}
+	return out.out, nil
+}
+
+func (s analytics) IncomeBefore(ctx context.Context, hid string, cal settings.Calendar, to time.Time) ([]store.DaySum, error) {
+	defer s.m.lock(ctx)()
+	out := newSums(cal)
+	for _, t := range s.m.d.transactions {
+		if counted(t, hid) && t.Type == models.TxIncome && t.Date.Before(to) {
+			out.add(nil, t.Currency, t.Date, t.Amount)
+		}
+	}
+	return out.out, nil
+}
+
+func (s analytics) RecentMonths(ctx context.Context, hid string, cal settings.Calendar, n int) ([]store.DaySum, error) {
+	defer s.m.lock(ctx)()
+	seen := map[string]bool{}
+	var months []string
+	for _, t := range s.m.d.transactions {
+		if m := cal.Month(t.Date); counted(t, hid) && !seen[m] {
+			seen[m] = true
+			months = append(months, m)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(months)))
+	if len(months) > n {
+		months = months[:n]
+	}
+	keep := map[string]bool{}
+	for _, m := range months {
+		keep[m] = true
+	}
+	out := newSums(cal)
+	for _, t := range s.m.d.transactions {
+		if counted(t, hid) && keep[cal.Month(t.Date)] {
+			typ := t.Type
+			out.add(&typ, t.Currency, t.Date, t.Amount)
+		}
+	}
+	return out.out, nil
+}
+
+func (s analytics) Rates(ctx context.Context, uid string, from, to time.Time) ([]models.FxRate, error) {
+	defer s.m.lock(ctx)()
+	out := []models.FxRate{}
+	for _, r := range s.m.d.rates {
+		if r.UserID == uid && !r.Date.Before(from) && !r.Date.After(to) {
+			out = append(out, r)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Date.Before(out[j].Date) })
+	return out, nil
+}
@@ -0,0 +1,77 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"time"
+
+	"budgex_backend/internal/models"
+	"budgex_backend/internal/money"
+	"budgex_backend/internal/store"
+)
+
+type budgets struct{ m *DB }
+
+func (s budgets) List(ctx context.Context, hid, month string) ([]models.Budget, error) {
+	defer s.m.lock(ctx)()
+	out := []models.Budget{}
+	for _, b := range s.m.d.budgets {
+		if b.HouseholdID == hid && b.Month == month && b.DeletedAt == nil {
+			out = append(out, b)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].CategoryID < out[j].CategoryID })
+	return out, nil
+}
+
+// Upsert leaves deleted_at alone on an existing row, like the ON CONFLICT
+// clause of the Postgres store.
+func (s budgets) Upsert(ctx context.Context, b *models.Budget) error {
+	defer s.m.lock(ctx)()
+	d := &s.m.d
+	for i, t := range d.budgets {
+		if t.HouseholdID == b.HouseholdID && t.Month == b.Month && t.CategoryID == b.CategoryID {
+			t.Amount, t.UpdatedAt = b.Amount, time.Now().UTC()
+			d.budgets[i] = t
+			*b = t
+			return nil
+		}
+	}
+	stampHousehold(&b.HouseholdBase)
+	d.budgets = append(d.budgets, *b)
+	return nil
+}
+
+func (s budgets) Before(ctx context.Context, hid, month string, categoryIDs []string) ([]store.BudgetHistory, error) {
+	defer s.m.lock(ctx)()
+	idx := map[string]int{}
+	out := []store.BudgetHistory{}
+	for _, b := range s.m.d.budgets {
+		if b.HouseholdID != hid || b.DeletedAt != nil || b.Month >= month || !slices.Contains(categoryIDs, b.CategoryID) {
+			continue
+		}
+		i, ok := idx[b.CategoryID]
+		if !ok {
+			i = len(out)
+			idx[b.CategoryID] = i
+			out = append(out, store.BudgetHistory{CategoryID: b.CategoryID, First: b.Month})
+		}
+		out[i].Total += b.Amount
+		if b.Month < out[i].First {
+			out[i].First = b.Month
+		}
+	}
+	return out, nil
+}
+
+func (s budgets) Assigned(ctx context.Context, hid, month string) (money.Amount, error) {
+	defer s.m.lock(ctx)()
+	var out money.Amount
+	for _, b := range s.m.d.budgets {
+		if b.HouseholdID == hid && b.DeletedAt == nil && b.Month <= month {
+			out += b.Amount
+		}
+	}
+	return out, nil
+}
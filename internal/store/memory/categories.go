@@ -0,0 +1,249 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"budgex_backend/internal/models"
+	"budgex_backend/internal/money"
+	"budgex_backend/internal/seed"
+	"budgex_backend/internal/store"
+)
+
+type categories struct{ m *DB }
+
+func (s categories) List(ctx context.Context, hid string, includeArchived bool) ([]models.Category, error) {
+	defer s.m.lock(ctx)()
+	out := []models.Category{}
+	for _, c := range s.m.d.categories {
+		if c.HouseholdID == hid && c.DeletedAt == nil && (includeArchived || !c.Archived) {
+			out = append(out, c)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out, nil
+}
+
+func (s categories) Get(ctx context.Context, hid, id string) (*models.Category, error) {
+	defer s.m.lock(ctx)()
+	for _, c := range s.m.d.categories {
+		if c.ID == id && c.HouseholdID == hid && c.DeletedAt == nil {
+			return &c, nil
+		}
+	}
+	return nil, store.ErrNotFound
+}
+
+func (s categories) Create(ctx context.Context, cat *models.Category) error {
+	defer s.m.lock(ctx)()
+	stampHousehold(&cat.HouseholdBase)
+	s.m.d.categories = append(s.m.d.categories, *cat)
+	return nil
+}
+
+func (s categories) Update(ctx context.Context, hid, id string, changes map[string]any) error {
+	defer s.m.lock(ctx)()
+	for i, c := range s.m.d.categories {
+		if c.ID != id || c.HouseholdID != hid || c.DeletedAt != nil {
+			continue
+		}
+		for col, v := range changes {
+			var err error
+			switch col {
+			case "name":
+				c.Name, err = value[string](col, v)
+			case "parent_id":
+				c.ParentID, err = nullable(col, v)
+			case "archived":
+				c.Archived, err = value[bool](col, v)
+			case "budget_rollover":
+				c.BudgetRollover, err = value[bool](col, v)
+			default:
+				err = fmt.Errorf("memory: unknown categories column %q", col)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		c.UpdatedAt = time.Now().UTC()
+		s.m.d.categories[i] = c
+		return nil
+	}
+	return store.ErrNotFound
+}
+
+func (s categories) InTree(ctx context.Context, hid, rootID, id string) (bool, error) {
+	defer s.m.lock(ctx)()
+	return s.m.d.tree(hid, rootID)[id], nil
+}
+
+func (s categories) CountTransactions(ctx context.Context, hid, id string) (int64, error) {
+	defer s.m.lock(ctx)()
+	var n int64
+	for _, t := range s.m.d.transactions {
+		if t.HouseholdID == hid && t.DeletedAt == nil && s.m.d.filedUnder(t, map[string]bool{id: true}) {
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (s categories) Merge(ctx context.Context, hid string, src, target *models.Category) error {
+	defer s.m.lock(ctx)()
+	d := &s.m.d
+	now := time.Now().UTC()
+	to := target.ID
+	for i, t := range d.transactions {
+		if t.HouseholdID == hid && same(t.CategoryID, src.ID) {
+			d.transactions[i].CategoryID = &to
+		}
+	}
+	for i, sp := range d.splits {
+		if sp.HouseholdID == hid && same(sp.CategoryID, src.ID) {
+			d.splits[i].CategoryID = &to
+		}
+	}
+	for i, r := range d.recurring {
+		if r.HouseholdID == hid && same(r.CategoryID, src.ID) {
+			d.recurring[i].CategoryID = &to
+		}
+	}
+	d.retargetRules(src.ID, &to)
+
+	// fold src's live budgets into the target's row of the same month and
+	// drop src's rows
+	var kept, moved []models.Budget
+	for _, b := range d.budgets {
+		switch {
+		case b.HouseholdID != hid || b.CategoryID != src.ID:
+			kept = append(kept, b)
+		case b.DeletedAt == nil:
+			moved = append(moved, b)
+		}
+	}
+	d.budgets = kept
+	for _, b := range moved {
+		d.addBudget(hid, b.CreatedBy, b.Month, to, b.Amount, now)
+	}
+
+	if d.tree(hid, src.ID)[target.ID] {
+		for i, c := range d.categories {
+			if c.ID == target.ID && c.HouseholdID == hid {
+				d.categories[i].ParentID = src.ParentID
+			}
+		}
+	}
+	for i, c := range d.categories {
+		if c.HouseholdID == hid && same(c.ParentID, src.ID) && c.ID != target.ID {
+			d.categories[i].ParentID = &to
+		}
+	}
+	d.removeCategory(hid, src, now)
+	return nil
+}
+
+// addBudget adds amount to the budget of month and category, reviving a
+// deleted row with just amount, or creates it.
+func (d *data) addBudget(hid, uid, month, categoryID string, amount money.Amount, now time.Time) {
+	for i, t := range d.budgets {
+		if t.HouseholdID == hid && t.Month == month && t.CategoryID == categoryID {
+			if t.DeletedAt == nil {
+				d.budgets[i].Amount += amount
+			} else {
+				d.budgets[i].Amount = amount
+			}
+			d.budgets[i].DeletedAt, d.budgets[i].UpdatedAt = nil, now
+			return
+		}
+	}
+	row := models.Budget{
+		HouseholdBase: models.HouseholdBase{HouseholdID: hid, CreatedBy: uid},
+		Month:         month, CategoryID: categoryID, Amount: amount,
+	}
+	stampHousehold(&row.HouseholdBase)
+	d.budgets = append(d.budgets, row)
+}
+
+func (s categories) Delete(ctx context.Context, hid string, cat *models.Category) error {
+	defer s.m.lock(ctx)()
+	d := &s.m.d
+	now := time.Now().UTC()
+	for i, b := range d.budgets {
+		if b.HouseholdID == hid && b.CategoryID == cat.ID && b.DeletedAt == nil {
+			d.budgets[i].DeletedAt = &now
+		}
+	}
+	for i, r := range d.recurring {
+		if r.HouseholdID == hid && same(r.CategoryID, cat.ID) {
+			d.recurring[i].CategoryID = nil
+		}
+	}
+	d.retargetRules(cat.ID, nil)
+	d.removeCategory(hid, cat, now)
+	return nil
+}
+
+// retargetRules points every user's rules that set category from at to, or
+// clears their category when to is nil.
+func (d *data) retargetRules(from string, to *string) {
+	for i, r := range d.rules {
+		if same(r.SetCategoryID, from) {
+			d.rules[i].SetCategoryID = to
+		}
+	}
+}
+
+// removeCategory moves src's subcategories up one level and soft-deletes it.
+func (d *data) removeCategory(hid string, src *models.Category, now time.Time) {
+	for i, c := range d.categories {
+		if c.HouseholdID != hid {
+			continue
+		}
+		if same(c.ParentID, src.ID) {
+			d.categories[i].ParentID = src.ParentID
+		}
+		if c.ID == src.ID {
+			d.categories[i].DeletedAt = &now
+		}
+	}
+}
+
+// Seed mirrors seed.Categories: names already used anywhere in the household's
+// tree are reused as parents instead of being created again.
+func (s categories) Seed(ctx context.Context, hid, uid, locale string) (seed.Result, error) {
+	defer s.m.lock(ctx)()
+	d := &s.m.d
+	res := seed.Result{Version: seed.Version, Locale: seed.Resolve(locale)}
+	key := func(name string) string { return strings.ToLower(strings.TrimSpace(name)) }
+	byName := map[string]string{}
+	for _, c := range d.categories {
+		if c.HouseholdID == hid && c.DeletedAt == nil {
+			byName[key(c.Name)] = c.ID
+		}
+	}
+	var walk func(nodes []seed.Node, parent *string)
+	walk = func(nodes []seed.Node, parent *string) {
+		for _, node := range nodes {
+			id, ok := byName[key(node.Name)]
+			if ok {
+				res.Skipped++
+			} else {
+				cat := models.Category{
+					HouseholdBase: models.HouseholdBase{HouseholdID: hid, CreatedBy: uid},
+					Name:          node.Name, ParentID: parent,
+				}
+				stampHousehold(&cat.HouseholdBase)
+				d.categories = append(d.categories, cat)
+				id = cat.ID
+				byName[key(node.Name)] = id
+				res.Created++
+			}
+			walk(node.Children, &id)
+		}
+	}
+	walk(seed.Tree(locale), nil)
+	return res, nil
+}
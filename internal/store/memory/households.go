@@ -0,0 +1,218 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	"budgex_backend/internal/models"
+	"budgex_backend/internal/store"
+
+	"github.com/google/uuid"
+)
+
+type households struct{ m *DB }
+
+// memberOf returns the live households of uid, with uid's role in each, in
+// the order uid joined them.
+func (d *data) memberOf(uid string) []models.Household {
+	var ms []models.HouseholdMember
+	for _, mb := range d.members {
+		if mb.UserID == uid {
+			ms = append(ms, mb)
+		}
+	}
+	sort.SliceStable(ms, func(i, j int) bool { return ms[i].CreatedAt.Before(ms[j].CreatedAt) })
+	out := []models.Household{}
+	for _, mb := range ms {
+		for _, h := range d.households {
+			if h.ID == mb.HouseholdID && h.DeletedAt == nil {
+				h.Role = mb.Role
+				out = append(out, h)
+			}
+		}
+	}
+	return out
+}
+
+func (s households) List(ctx context.Context, uid string) ([]models.Household, error) {
+	defer s.m.lock(ctx)()
+	return s.m.d.memberOf(uid), nil
+}
+
+func (s households) Get(ctx context.Context, uid, hid string) (*models.Household, error) {
+	defer s.m.lock(ctx)()
+	for _, h := range s.m.d.memberOf(uid) {
+		if h.ID == hid {
+			return &h, nil
+		}
+	}
+	return nil, store.ErrNotFound
+}
+
+func (s households) Create(ctx context.Context, h *models.Household) error {
+	defer s.m.lock(ctx)()
+	s.m.d.createHousehold(h)
+	return nil
+}
+
+// createHousehold stores h and makes its creator the owner.
+func (d *data) createHousehold(h *models.Household) {
+	fill(&h.ID, &h.CreatedAt, &h.UpdatedAt)
+	h.Role = models.RoleOwner
+	row := *h
+	row.Role = ""
+	d.households = append(d.households, row)
+	d.members = append(d.members, models.HouseholdMember{
+		HouseholdID: h.ID, UserID: h.CreatedBy, Role: models.RoleOwner,
+		CreatedAt: h.CreatedAt, UpdatedAt: h.CreatedAt,
+	})
+}
+
+func (s households) EnsureFirst(ctx context.Context, uid, name string) (*models.Household, bool, error) {
+	defer s.m.lock(ctx)()
+	if hs := s.m.d.memberOf(uid); len(hs) > 0 {
+		return &hs[0], false, nil
+	}
+	h := models.Household{Name: name, CreatedBy: uid}
+	s.m.d.createHousehold(&h)
+	return &h, true, nil
+}
+
+func (s households) Update(ctx context.Context, hid string, changes map[string]any) error {
+	defer s.m.lock(ctx)()
+	for i, h := range s.m.d.households {
+		if h.ID != hid || h.DeletedAt != nil {
+			continue
+		}
+		for col, v := range changes {
+			var err error
+			switch col {
+			case "name":
+				h.Name, err = value[string](col, v)
+			default:
+				err = fmt.Errorf("memory: unknown households column %q", col)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		h.UpdatedAt = time.Now().UTC()
+		s.m.d.households[i] = h
+		return nil
+	}
+	return store.ErrNotFound
+}
+
+func (s households) Delete(ctx context.Context, hid string) error {
+	defer s.m.lock(ctx)()
+	d := &s.m.d
+	i := slices.IndexFunc(d.households, func(h models.Household) bool {
+		return h.ID == hid && h.DeletedAt == nil
+	})
+	if i < 0 {
+		return store.ErrNotFound
+	}
+	now := time.Now().UTC()
+	d.households[i].DeletedAt = &now
+	d.invites = slices.DeleteFunc(d.invites, func(inv models.HouseholdInvite) bool { return inv.HouseholdID == hid })
+	d.members = slices.DeleteFunc(d.members, func(mb models.HouseholdMember) bool { return mb.HouseholdID == hid })
+	return nil
+}
+
+func (s households) Members(ctx context.Context, hid string) ([]models.HouseholdMember, error) {
+	defer s.m.lock(ctx)()
+	out := []models.HouseholdMember{}
+	for _, mb := range s.m.d.members {
+		if mb.HouseholdID == hid {
+			out = append(out, mb)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].CreatedAt.Before(out[j].CreatedAt) })
+	return out, nil
+}
+
+func (s households) SetRole(ctx context.Context, hid, uid, role string) error {
+	defer s.m.lock(ctx)()
+	for i, mb := range s.m.d.members {
+		if mb.HouseholdID == hid && mb.UserID == uid {
+			s.m.d.members[i].Role, s.m.d.members[i].UpdatedAt = role, time.Now().UTC()
+			return nil
+		}
+	}
+	return store.ErrNotFound
+}
+
+func (s households) RemoveMember(ctx context.Context, hid, uid string) error {
+	defer s.m.lock(ctx)()
+	n := len(s.m.d.members)
+	s.m.d.members = slices.DeleteFunc(s.m.d.members, func(mb models.HouseholdMember) bool {
+		return mb.HouseholdID == hid && mb.UserID == uid
+	})
+	if len(s.m.d.members) == n {
+		return store.ErrNotFound
+	}
+	return nil
+}
+
+func (s households) CreateInvite(ctx context.Context, inv *models.HouseholdInvite) error {
+	defer s.m.lock(ctx)()
+	if inv.ID == "" {
+		inv.ID = uuid.NewString()
+	}
+	inv.CreatedAt = time.Now().UTC()
+	s.m.d.invites = append(s.m.d.invites, *inv)
+	return nil
+}
+
+func (s households) Invites(ctx context.Context, hid string) ([]models.HouseholdInvite, error) {
+	defer s.m.lock(ctx)()
+	out := []models.HouseholdInvite{}
+	for _, inv := range s.m.d.invites {
+		if inv.HouseholdID == hid && inv.AcceptedBy == nil {
+			out = append(out, inv)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].CreatedAt.After(out[j].CreatedAt) })
+	return out, nil
+}
+
+func (s households) DeleteInvite(ctx context.Context, hid, id string) error {
+	defer s.m.lock(ctx)()
+	n := len(s.m.d.invites)
+	s.m.d.invites = slices.DeleteFunc(s.m.d.invites, func(inv models.HouseholdInvite) bool {
+		return inv.ID == id && inv.HouseholdID == hid && inv.AcceptedBy == nil
+	})
+	if len(s.m.d.invites) == n {
+		return store.ErrNotFound
+	}
+	return nil
+}
+
+func (s households) InviteByCode(ctx context.Context, code string) (*models.HouseholdInvite, error) {
+	defer s.m.lock(ctx)()
+	for _, inv := range s.m.d.invites {
+		if inv.Code == code {
+			return &inv, nil
+		}
+	}
+	return nil, store.ErrNotFound
+}
+
+func (s households) Accept(ctx context.Context, inv *models.HouseholdInvite, uid string, at time.Time) error {
+	defer s.m.lock(ctx)()
+	d := &s.m.d
+	i := slices.IndexFunc(d.invites, func(x models.HouseholdInvite) bool {
+		return x.ID == inv.ID && x.AcceptedBy == nil
+	})
+	if i < 0 {
+		return store.ErrNotFound
+	}
+	d.invites[i].AcceptedBy, d.invites[i].AcceptedAt = &uid, &at
+	d.members = append(d.members, models.HouseholdMember{
+		HouseholdID: inv.HouseholdID, UserID: uid, Role: inv.Role, CreatedAt: at, UpdatedAt: at,
+	})
+	return nil
+}
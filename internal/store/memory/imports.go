@@ -0,0 +1,83 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"budgex_backend/internal/models"
+	"budgex_backend/internal/store"
+)
+
+type imports struct{ m *DB }
+
+func (s imports) List(ctx context.Context, uid string) ([]models.ImportBatch, error) {
+	defer s.m.lock(ctx)()
+	out := []models.ImportBatch{}
+	for _, b := range s.m.d.batches {
+		if b.UserID == uid && b.DeletedAt == nil {
+			out = append(out, b)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].CreatedAt.After(out[j].CreatedAt) })
+	return out, nil
+}
+
+// Get ignores lock: calls are serialized already.
+func (s imports) Get(ctx context.Context, uid, id string, lock bool) (*models.ImportBatch, error) {
+	defer s.m.lock(ctx)()
+	for _, b := range s.m.d.batches {
+		if b.ID == id && b.UserID == uid && b.DeletedAt == nil {
+			return &b, nil
+		}
+	}
+	return nil, store.ErrNotFound
+}
+
+func (s imports) Create(ctx context.Context, b *models.ImportBatch) error {
+	defer s.m.lock(ctx)()
+	stamp(&b.Base)
+	s.m.d.batches = append(s.m.d.batches, *b)
+	return nil
+}
+
+func (s imports) Update(ctx context.Context, uid, id string, changes map[string]any) error {
+	defer s.m.lock(ctx)()
+	for i, b := range s.m.d.batches {
+		if b.ID != id || b.UserID != uid || b.DeletedAt != nil {
+			continue
+		}
+		for col, v := range changes {
+			if err := setBatchColumn(&b, col, v); err != nil {
+				return err
+			}
+		}
+		b.UpdatedAt = time.Now().UTC()
+		s.m.d.batches[i] = b
+		return nil
+	}
+	return store.ErrNotFound
+}
+
+func setBatchColumn(b *models.ImportBatch, col string, v any) error {
+	var err error
+	switch col {
+	case "status":
+		b.Status, err = value[string](col, v)
+	case "imported":
+		b.Imported, err = value[int](col, v)
+	case "committed_at", "rolled_back_at":
+		var at time.Time
+		if at, err = value[time.Time](col, v); err == nil {
+			if col == "committed_at" {
+				b.CommittedAt = &at
+			} else {
+				b.RolledBackAt = &at
+			}
+		}
+	default:
+		err = fmt.Errorf("memory: unknown import_batches column %q", col)
+	}
+	return err
+}
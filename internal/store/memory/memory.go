@@ -0,0 +1,197 @@
+// Package memory implements the stores in process memory, for tests and for
+// tools that run without a database. It keeps the rules of the Postgres
+// stores (live rows, household and user scoping, listing order) but no constraints.
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"budgex_backend/internal/models"
+	"budgex_backend/internal/store"
+
+	"github.com/google/uuid"
+)
+
+// DB holds every row. Calls are serialized; InTx holds the lock for its
+// whole run and puts the data back when fn fails.
+type DB struct {
+	mu sync.Mutex
+	d  data
+}
+
+type data struct {
+	transactions []models.Transaction // Splits are kept in splits
+	splits       []models.TransactionSplit
+	categories   []models.Category
+	budgets      []models.Budget
+	accounts     []models.Account
+	settings     map[string]models.UserSettings
+	rules        []models.Rule
+	recurring    []models.RecurringRule
+	rates        []models.FxRate
+	batches      []models.ImportBatch
+	households   []models.Household
+	members      []models.HouseholdMember
+	invites      []models.HouseholdInvite
+}
+
+// Rows are replaced, never changed through a pointer another copy shares, so
+// copying the slices is a full snapshot.
+func (d data) clone() data {
+	out := data{
+		transactions: slices.Clone(d.transactions),
+		splits:       slices.Clone(d.splits),
+		categories:   slices.Clone(d.categories),
+		budgets:      slices.Clone(d.budgets),
+		accounts:     slices.Clone(d.accounts),
+		settings:     make(map[string]models.UserSettings, len(d.settings)),
+		rules:        slices.Clone(d.rules),
+		recurring:    slices.Clone(d.recurring),
+		rates:        slices.Clone(d.rates),
+		batches:      slices.Clone(d.batches),
+		households:   slices.Clone(d.households),
+		members:      slices.Clone(d.members),
+		invites:      slices.Clone(d.invites),
+	}
+	for k, v := range d.settings {
+		out.settings[k] = v
+	}
+	return out
+}
+
+// New returns an empty DB.
+func New() *DB {
+	return &DB{d: data{settings: map[string]models.UserSettings{}}}
+}
+
+// Stores returns the stores backed by m.
+func (m *DB) Stores() store.Stores {
+	return store.Stores{
+		Tx:           m,
+		Transactions: transactions{m},
+		Categories:   categories{m},
+		Budgets:      budgets{m},
+		Analytics:    analytics{m},
+		Accounts:     accounts{m},
+		Settings:     userSettings{m},
+		Rules:        rules{m},
+		Recurring:    recurring{m},
+		Imports:      imports{m},
+		Rates:        rates{m},
+		Households:   households{m},
+		Health:       m,
+	}
+}
+
+type heldKey struct{}
+
+// lock takes the lock unless ctx comes from InTx, which already holds it.
+func (m *DB) lock(ctx context.Context) func() {
+	if ctx.Value(heldKey{}) == m {
+		return func() {}
+	}
+	m.mu.Lock()
+	return m.mu.Unlock
+}
+
+// InTx runs fn holding the lock and restores the data if fn fails.
+func (m *DB) InTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	defer m.lock(ctx)()
+	snap := m.d.clone()
+	if err := fn(context.WithValue(ctx, heldKey{}, m)); err != nil {
+		m.d = snap
+		return err
+	}
+	return nil
+}
+
+// AsUser is InTx: the memory stores have no row-level security.
+func (m *DB) AsUser(ctx context.Context, uid string, fn func(ctx context.Context) error) error {
+	return m.InTx(ctx, fn)
+}
+
+// Ping always succeeds.
+func (m *DB) Ping(ctx context.Context) error { return nil }
+
+// Insert adds rows the stores only read or that tests need up front:
+// accounts, settings, rules, recurring rules, rates, import batches,
+// households, their members and invites, and also categories, transactions and budgets. Rows are passed by
+// pointer; missing ids and timestamps are filled in on them.
+func (m *DB) Insert(rows ...any) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, r := range rows {
+		switch r := r.(type) {
+		case *models.Account:
+			stamp(&r.Base)
+			m.d.accounts = append(m.d.accounts, *r)
+		case *models.UserSettings:
+			m.d.settings[r.UserID] = *r
+		case *models.Rule:
+			stamp(&r.Base)
+			m.d.rules = append(m.d.rules, *r)
+		case *models.RecurringRule:
+			stampHousehold(&r.HouseholdBase)
+			m.d.recurring = append(m.d.recurring, *r)
+		case *models.FxRate:
+			m.d.rates = append(m.d.rates, *r)
+		case *models.ImportBatch:
+			stamp(&r.Base)
+			m.d.batches = append(m.d.batches, *r)
+		case *models.Category:
+			stampHousehold(&r.HouseholdBase)
+			m.d.categories = append(m.d.categories, *r)
+		case *models.Transaction:
+			m.d.insertTx(r)
+		case *models.Budget:
+			stampHousehold(&r.HouseholdBase)
+			m.d.budgets = append(m.d.budgets, *r)
+		case *models.Household:
+			fill(&r.ID, &r.CreatedAt, &r.UpdatedAt)
+			m.d.households = append(m.d.households, *r)
+		case *models.HouseholdMember:
+			fill(nil, &r.CreatedAt, &r.UpdatedAt)
+			m.d.members = append(m.d.members, *r)
+		case *models.HouseholdInvite:
+			if r.ID == "" {
+				r.ID = uuid.NewString()
+			}
+			m.d.invites = append(m.d.invites, *r)
+		default:
+			return fmt.Errorf("memory: cannot insert %T", r)
+		}
+	}
+	return nil
+}
+
+// stamp fills in what the database would default.
+func stamp(b *models.Base) { fill(&b.ID, &b.CreatedAt, &b.UpdatedAt) }
+
+func stampHousehold(b *models.HouseholdBase) { fill(&b.ID, &b.CreatedAt, &b.UpdatedAt) }
+
+// fill sets an empty id, when there is one, and zero timestamps.
+func fill(id *string, createdAt, updatedAt *time.Time) {
+	now := time.Now().UTC()
+	if id != nil && *id == "" {
+		*id = uuid.NewString()
+	}
+	if createdAt.IsZero() {
+		*createdAt = now
+	}
+	if updatedAt.IsZero() {
+		*updatedAt = now
+	}
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func same(p *string, v string) bool { return p != nil && *p == v }
@@ -0,0 +1,66 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"budgex_backend/internal/models"
+	"budgex_backend/internal/store"
+)
+
+type rates struct{ m *DB }
+
+func (s rates) List(ctx context.Context, uid string, f store.RateFilter) ([]models.FxRate, error) {
+	defer s.m.lock(ctx)()
+	out := []models.FxRate{}
+	for _, r := range s.m.d.rates {
+		switch {
+		case r.UserID != uid,
+			f.Base != "" && r.Base != f.Base,
+			f.Quote != "" && r.Quote != f.Quote,
+			f.From != nil && r.Date.Before(*f.From),
+			f.To != nil && r.Date.After(*f.To):
+			continue
+		}
+		out = append(out, r)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		a, b := out[i], out[j]
+		if !a.Date.Equal(b.Date) {
+			return a.Date.After(b.Date)
+		}
+		if a.Base != b.Base {
+			return a.Base < b.Base
+		}
+		return a.Quote < b.Quote
+	})
+	if f.Limit > 0 && len(out) > f.Limit {
+		out = out[:f.Limit]
+	}
+	return out, nil
+}
+
+func (s rates) Save(ctx context.Context, uid, source string, rs []models.FxRate) (int, error) {
+	defer s.m.lock(ctx)()
+	now := time.Now().UTC()
+	written := map[string]bool{}
+	for _, r := range rs {
+		r.UserID, r.Source = uid, source
+		r.CreatedAt, r.UpdatedAt = now, now
+		k := r.Date.Format("2006-01-02") + r.Base + r.Quote
+		written[k] = true
+		replaced := false
+		for i, old := range s.m.d.rates {
+			if old.UserID == uid && old.Date.Equal(r.Date) && old.Base == r.Base && old.Quote == r.Quote {
+				r.CreatedAt = old.CreatedAt
+				s.m.d.rates[i] = r
+				replaced = true
+			}
+		}
+		if !replaced {
+			s.m.d.rates = append(s.m.d.rates, r)
+		}
+	}
+	return len(written), nil
+}
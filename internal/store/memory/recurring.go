@@ -0,0 +1,78 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"budgex_backend/internal/models"
+	"budgex_backend/internal/store"
+)
+
+type recurring struct{ m *DB }
+
+func (s recurring) List(ctx context.Context, hid string) ([]models.RecurringRule, error) {
+	defer s.m.lock(ctx)()
+	out := []models.RecurringRule{}
+	for _, r := range s.m.d.recurring {
+		if r.HouseholdID == hid && r.DeletedAt == nil {
+			out = append(out, r)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out, nil
+}
+
+func (s recurring) Active(ctx context.Context, hid string) ([]models.RecurringRule, error) {
+	defer s.m.lock(ctx)()
+	var out []models.RecurringRule
+	for _, r := range s.m.d.recurring {
+		if r.HouseholdID == hid && r.Active && r.DeletedAt == nil {
+			out = append(out, r)
+		}
+	}
+	return out, nil
+}
+
+func (s recurring) Get(ctx context.Context, hid, id string) (*models.RecurringRule, error) {
+	defer s.m.lock(ctx)()
+	for _, r := range s.m.d.recurring {
+		if r.ID == id && r.HouseholdID == hid && r.DeletedAt == nil {
+			return &r, nil
+		}
+	}
+	return nil, store.ErrNotFound
+}
+
+func (s recurring) Create(ctx context.Context, r *models.RecurringRule) error {
+	defer s.m.lock(ctx)()
+	stampHousehold(&r.HouseholdBase)
+	s.m.d.recurring = append(s.m.d.recurring, *r)
+	return nil
+}
+
+func (s recurring) Save(ctx context.Context, r *models.RecurringRule) error {
+	defer s.m.lock(ctx)()
+	for i, old := range s.m.d.recurring {
+		if old.ID != r.ID || old.HouseholdID != r.HouseholdID || old.DeletedAt != nil {
+			continue
+		}
+		r.CreatedBy, r.CreatedAt, r.UpdatedAt = old.CreatedBy, old.CreatedAt, time.Now().UTC()
+		r.MaterializedThrough = old.MaterializedThrough
+		s.m.d.recurring[i] = *r
+		return nil
+	}
+	return store.ErrNotFound
+}
+
+func (s recurring) Delete(ctx context.Context, hid, id string) error {
+	defer s.m.lock(ctx)()
+	for i, r := range s.m.d.recurring {
+		if r.ID == id && r.HouseholdID == hid && r.DeletedAt == nil {
+			now := time.Now().UTC()
+			s.m.d.recurring[i].DeletedAt = &now
+			return nil
+		}
+	}
+	return store.ErrNotFound
+}
@@ -0,0 +1,88 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"budgex_backend/internal/models"
+	"budgex_backend/internal/store"
+)
+
+type rules struct{ m *DB }
+
+// byPriority sorts rules in the order they run.
+func byPriority(rs []models.Rule) {
+	sort.SliceStable(rs, func(i, j int) bool {
+		if rs[i].Priority != rs[j].Priority {
+			return rs[i].Priority < rs[j].Priority
+		}
+		return rs[i].CreatedAt.Before(rs[j].CreatedAt)
+	})
+}
+
+func (s rules) List(ctx context.Context, uid string) ([]models.Rule, error) {
+	defer s.m.lock(ctx)()
+	out := []models.Rule{}
+	for _, r := range s.m.d.rules {
+		if r.UserID == uid && r.DeletedAt == nil {
+			out = append(out, r)
+		}
+	}
+	byPriority(out)
+	return out, nil
+}
+
+func (s rules) Active(ctx context.Context, uid string) ([]models.Rule, error) {
+	defer s.m.lock(ctx)()
+	out := []models.Rule{}
+	for _, r := range s.m.d.rules {
+		if r.UserID == uid && r.Active && r.DeletedAt == nil {
+			out = append(out, r)
+		}
+	}
+	byPriority(out)
+	return out, nil
+}
+
+func (s rules) Get(ctx context.Context, uid, id string) (*models.Rule, error) {
+	defer s.m.lock(ctx)()
+	for _, r := range s.m.d.rules {
+		if r.ID == id && r.UserID == uid && r.DeletedAt == nil {
+			return &r, nil
+		}
+	}
+	return nil, store.ErrNotFound
+}
+
+func (s rules) Create(ctx context.Context, r *models.Rule) error {
+	defer s.m.lock(ctx)()
+	stamp(&r.Base)
+	s.m.d.rules = append(s.m.d.rules, *r)
+	return nil
+}
+
+func (s rules) Save(ctx context.Context, r *models.Rule) error {
+	defer s.m.lock(ctx)()
+	for i, old := range s.m.d.rules {
+		if old.ID != r.ID || old.UserID != r.UserID || old.DeletedAt != nil {
+			continue
+		}
+		r.CreatedAt, r.UpdatedAt = old.CreatedAt, time.Now().UTC()
+		s.m.d.rules[i] = *r
+		return nil
+	}
+	return store.ErrNotFound
+}
+
+func (s rules) Delete(ctx context.Context, uid, id string) error {
+	defer s.m.lock(ctx)()
+	for i, r := range s.m.d.rules {
+		if r.ID == id && r.UserID == uid && r.DeletedAt == nil {
+			now := time.Now().UTC()
+			s.m.d.rules[i].DeletedAt = &now
+			return nil
+		}
+	}
+	return store.ErrNotFound
+}
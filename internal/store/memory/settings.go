@@ -0,0 +1,32 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"budgex_backend/internal/models"
+	"budgex_backend/internal/settings"
+)
+
+type userSettings struct{ m *DB }
+
+func (s userSettings) Get(ctx context.Context, uid string) (models.UserSettings, error) {
+	defer s.m.lock(ctx)()
+	if us, ok := s.m.d.settings[uid]; ok {
+		return us, nil
+	}
+	return settings.Defaults(uid), nil
+}
+
+func (s userSettings) Save(ctx context.Context, us *models.UserSettings) error {
+	defer s.m.lock(ctx)()
+	now := time.Now().UTC()
+	if old, ok := s.m.d.settings[us.UserID]; ok {
+		us.CreatedAt = old.CreatedAt
+	} else if us.CreatedAt.IsZero() {
+		us.CreatedAt = now
+	}
+	us.UpdatedAt = now
+	s.m.d.settings[us.UserID] = *us
+	return nil
+}
@@ -0,0 +1,381 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"budgex_backend/internal/models"
+	"budgex_backend/internal/money"
+	"budgex_backend/internal/store"
+)
+
+type transactions struct{ m *DB }
+
+// insertTx stores tx, filling in ids and defaults on it and its splits.
+func (d *data) insertTx(tx *models.Transaction) {
+	stampHousehold(&tx.HouseholdBase)
+	if tx.Source == "" {
+		tx.Source = "manual"
+	}
+	for i := range tx.Splits {
+		stampHousehold(&tx.Splits[i].HouseholdBase)
+		tx.Splits[i].TransactionID = tx.ID
+		d.splits = append(d.splits, tx.Splits[i])
+	}
+	row := *tx
+	row.Splits = nil
+	d.transactions = append(d.transactions, row)
+}
+
+// withSplits returns t with its live split lines.
+func (d *data) withSplits(t models.Transaction) models.Transaction {
+	t.Splits = nil
+	for _, s := range d.splits {
+		if s.TransactionID == t.ID && s.DeletedAt == nil {
+			t.Splits = append(t.Splits, s)
+		}
+	}
+	return t
+}
+
+// tree returns rootID and its live descendants.
+func (d *data) tree(hid, rootID string) map[string]bool {
+	out := map[string]bool{}
+	for _, c := range d.categories {
+		if c.ID == rootID && c.HouseholdID == hid && c.DeletedAt == nil {
+			out[rootID] = true
+		}
+	}
+	for grew := len(out) > 0; grew; {
+		grew = false
+		for _, c := range d.categories {
+			if c.HouseholdID == hid && c.DeletedAt == nil && !out[c.ID] && c.ParentID != nil && out[*c.ParentID] {
+				out[c.ID] = true
+				grew = true
+			}
+		}
+	}
+	return out
+}
+
+// filedUnder reports whether t, or one of its live split lines, is in cats.
+func (d *data) filedUnder(t models.Transaction, cats map[string]bool) bool {
+	if t.CategoryID != nil && cats[*t.CategoryID] {
+		return true
+	}
+	for _, s := range d.splits {
+		if s.TransactionID == t.ID && s.DeletedAt == nil && s.CategoryID != nil && cats[*s.CategoryID] {
+			return true
+		}
+	}
+	return false
+}
+
+func hasTag(tags *string, tag string) bool {
+	if tags == nil {
+		return false
+	}
+	for _, t := range strings.Split(*tags, ",") {
+		if strings.TrimSpace(t) == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// before reports whether t sorts after k in the newest-first listing.
+func before(t models.Transaction, k store.TxCursor) bool {
+	if !t.Date.Equal(k.Date) {
+		return t.Date.Before(k.Date)
+	}
+	if !t.CreatedAt.Equal(k.CreatedAt) {
+		return t.CreatedAt.Before(k.CreatedAt)
+	}
+	return t.ID < k.ID
+}
+
+func (s transactions) List(ctx context.Context, hid string, f store.TxFilter) ([]models.Transaction, error) {
+	defer s.m.lock(ctx)()
+	d := &s.m.d
+	var cats map[string]bool
+	if f.CategoryID != "" {
+		cats = map[string]bool{f.CategoryID: true}
+		if f.IncludeDescendants {
+			cats = d.tree(hid, f.CategoryID)
+		}
+	}
+	out := []models.Transaction{}
+	for _, t := range d.transactions {
+		switch {
+		case t.HouseholdID != hid || t.DeletedAt != nil,
+			f.From != nil && t.Date.Before(*f.From),
+			f.To != nil && !t.Date.Before(*f.To),
+			f.Type != "" && t.Type != f.Type,
+			cats != nil && !d.filedUnder(t, cats),
+			f.AccountID != "" && !same(t.AccountID, f.AccountID),
+			f.Payee != "" && (t.Payee == nil || !strings.Contains(strings.ToLower(*t.Payee), strings.ToLower(f.Payee))),
+			f.Memo != "" && (t.Memo == nil || !strings.Contains(strings.ToLower(*t.Memo), strings.ToLower(f.Memo))),
+			f.Tag != "" && !hasTag(t.Tags, f.Tag),
+			f.MinAmount != nil && t.Amount < *f.MinAmount,
+			f.MaxAmount != nil && t.Amount > *f.MaxAmount,
+			f.Source != "" && t.Source != f.Source,
+			f.After != nil && !before(t, *f.After):
+			continue
+		}
+		out = append(out, d.withSplits(t))
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return before(out[j], store.TxCursor{Date: out[i].Date, CreatedAt: out[i].CreatedAt, ID: out[i].ID})
+	})
+	if f.Limit > 0 && len(out) > f.Limit {
+		out = out[:f.Limit]
+	}
+	return out, nil
+}
+
+func (s transactions) Get(ctx context.Context, hid, id string) (*models.Transaction, error) {
+	defer s.m.lock(ctx)()
+	for _, t := range s.m.d.transactions {
+		if t.ID == id && t.HouseholdID == hid && t.DeletedAt == nil {
+			out := s.m.d.withSplits(t)
+			return &out, nil
+		}
+	}
+	return nil, store.ErrNotFound
+}
+
+func (s transactions) GetMany(ctx context.Context, hid string, ids []string) ([]models.Transaction, error) {
+	defer s.m.lock(ctx)()
+	want := map[string]bool{}
+	for _, id := range ids {
+		want[id] = true
+	}
+	out := []models.Transaction{}
+	for _, t := range s.m.d.transactions {
+		if t.HouseholdID == hid && want[t.ID] {
+			out = append(out, s.m.d.withSplits(t))
+		}
+	}
+	return out, nil
+}
+
+func (s transactions) Create(ctx context.Context, tx *models.Transaction) error {
+	defer s.m.lock(ctx)()
+	s.m.d.insertTx(tx)
+	return nil
+}
+
+func (s transactions) Update(ctx context.Context, hid, id string, changes map[string]any) error {
+	defer s.m.lock(ctx)()
+	for i, t := range s.m.d.transactions {
+		if t.ID != id || t.HouseholdID != hid || t.DeletedAt != nil {
+			continue
+		}
+		for col, v := range changes {
+			if err := setTxColumn(&t, col, v); err != nil {
+				return err
+			}
+		}
+		t.UpdatedAt = time.Now().UTC()
+		s.m.d.transactions[i] = t
+		return nil
+	}
+	return store.ErrNotFound
+}
+
+func setTxColumn(t *models.Transaction, col string, v any) error {
+	var err error
+	switch col {
+	case "type":
+		t.Type, err = value[string](col, v)
+	case "date":
+		t.Date, err = value[time.Time](col, v)
+	case "amount":
+		t.Amount, err = value[money.Amount](col, v)
+	case "currency":
+		t.Currency, err = value[string](col, v)
+	case "payee":
+		t.Payee, err = nullable(col, v)
+	case "memo":
+		t.Memo, err = nullable(col, v)
+	case "category_id":
+		t.CategoryID, err = nullable(col, v)
+	case "tags":
+		t.Tags, err = nullable(col, v)
+	case "account_id":
+		t.AccountID, err = nullable(col, v)
+	default:
+		err = fmt.Errorf("memory: unknown transactions column %q", col)
+	}
+	return err
+}
+
+func value[T any](col string, v any) (T, error) {
+	out, ok := v.(T)
+	if !ok {
+		return out, fmt.Errorf("memory: column %q cannot hold %T", col, v)
+	}
+	return out, nil
+}
+
+// nullable reads a text value that may be NULL.
+func nullable(col string, v any) (*string, error) {
+	switch v := v.(type) {
+	case nil:
+		return nil, nil
+	case *string:
+		return v, nil
+	case string:
+		return &v, nil
+	}
+	return nil, fmt.Errorf("memory: column %q cannot hold %T", col, v)
+}
+
+func (s transactions) ReplaceSplits(ctx context.Context, tx *models.Transaction, splits []models.TransactionSplit) error {
+	defer s.m.lock(ctx)()
+	d := &s.m.d
+	kept := d.splits[:0:0]
+	for _, sp := range d.splits {
+		if sp.TransactionID != tx.ID || sp.HouseholdID != tx.HouseholdID {
+			kept = append(kept, sp)
+		}
+	}
+	for i := range splits {
+		stampHousehold(&splits[i].HouseholdBase)
+		splits[i].TransactionID = tx.ID
+		kept = append(kept, splits[i])
+	}
+	d.splits = kept
+	return nil
+}
+
+func (s transactions) TransferPeer(ctx context.Context, tx *models.Transaction) (*models.Transaction, error) {
+	defer s.m.lock(ctx)()
+	if tx.TransferID == nil {
+		return nil, store.ErrNotFound
+	}
+	for _, t := range s.m.d.transactions {
+		if t.HouseholdID == tx.HouseholdID && same(t.TransferID, *tx.TransferID) && t.ID != tx.ID && t.DeletedAt == nil {
+			return &t, nil
+		}
+	}
+	return nil, store.ErrNotFound
+}
+
+func (s transactions) SetDeleted(ctx context.Context, hid, id string, deleted bool) error {
+	defer s.m.lock(ctx)()
+	d := &s.m.d
+	var transferID *string
+	for _, t := range d.transactions {
+		if t.ID == id && t.HouseholdID == hid {
+			transferID = t.TransferID
+		}
+	}
+	var at *time.Time
+	if deleted {
+		now := time.Now().UTC()
+		at = &now
+	}
+	n := 0
+	for i, t := range d.transactions {
+		if t.HouseholdID != hid || (t.DeletedAt == nil) != deleted {
+			continue
+		}
+		if t.ID == id || (transferID != nil && same(t.TransferID, *transferID)) {
+			d.transactions[i].DeletedAt = at
+			n++
+		}
+	}
+	if n == 0 {
+		return store.ErrNotFound
+	}
+	return nil
+}
+
+func (s transactions) DeleteMany(ctx context.Context, hid string, ids []string) error {
+	defer s.m.lock(ctx)()
+	now := time.Now().UTC()
+	for i, t := range s.m.d.transactions {
+		if t.HouseholdID == hid && t.DeletedAt == nil && slices.Contains(ids, t.ID) {
+			s.m.d.transactions[i].DeletedAt = &now
+		}
+	}
+	return nil
+}
+
+func (s transactions) ExternalIDs(ctx context.Context, hid string, ids []string) ([]string, error) {
+	defer s.m.lock(ctx)()
+	out := []string{}
+	for _, t := range s.m.d.transactions {
+		if t.HouseholdID == hid && t.DeletedAt == nil && t.ExternalID != nil && slices.Contains(ids, *t.ExternalID) {
+			out = append(out, *t.ExternalID)
+		}
+	}
+	return out, nil
+}
+
+func (s transactions) Import(ctx context.Context, txs []models.Transaction) (int, error) {
+	defer s.m.lock(ctx)()
+	d := &s.m.d
+	n := 0
+	for i := range txs {
+		tx := &txs[i]
+		if tx.ExternalID != nil && slices.ContainsFunc(d.transactions, func(t models.Transaction) bool {
+			return t.HouseholdID == tx.HouseholdID && t.DeletedAt == nil && same(t.ExternalID, *tx.ExternalID)
+		}) {
+			continue
+		}
+		d.insertTx(tx)
+		n++
+	}
+	return n, nil
+}
+
+func (s transactions) DeleteImported(ctx context.Context, hid, batchID string) error {
+	defer s.m.lock(ctx)()
+	now := time.Now().UTC()
+	for i, t := range s.m.d.transactions {
+		if t.HouseholdID == hid && t.DeletedAt == nil && same(t.ImportBatchID, batchID) {
+			s.m.d.transactions[i].DeletedAt = &now
+		}
+	}
+	return nil
+}
+
+func (s transactions) DuplicatePairs(ctx context.Context, hid string, days int, from, to time.Time, limit int) ([]store.DuplicatePair, error) {
+	defer s.m.lock(ctx)()
+	window := time.Duration(days) * 24 * time.Hour
+	type pair struct {
+		p    store.DuplicatePair
+		date time.Time
+	}
+	var found []pair
+	live := s.m.d.transactions
+	for _, a := range live {
+		if a.HouseholdID != hid || a.DeletedAt != nil || (a.Type != models.TxIncome && a.Type != models.TxExpense) ||
+			a.Date.Before(from) || !a.Date.Before(to) {
+			continue
+		}
+		for _, b := range live {
+			if b.HouseholdID != hid || b.DeletedAt != nil || b.Type != a.Type || b.Amount != a.Amount ||
+				b.Currency != a.Currency || b.ID <= a.ID ||
+				b.Date.Before(a.Date.Add(-window)) || b.Date.After(a.Date.Add(window)) {
+				continue
+			}
+			found = append(found, pair{store.DuplicatePair{AID: a.ID, BID: b.ID, APayee: a.Payee, BPayee: b.Payee}, a.Date})
+		}
+	}
+	sort.SliceStable(found, func(i, j int) bool { return found[i].date.After(found[j].date) })
+	out := []store.DuplicatePair{}
+	for _, f := range found {
+		if len(out) == limit {
+			break
+		}
+		out = append(out, f.p)
+	}
+	return out, nil
+}
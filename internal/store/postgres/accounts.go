@@ -0,0 +1,153 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"budgex_backend/internal/db"
+	"budgex_backend/internal/models"
+	"budgex_backend/internal/money"
+	"budgex_backend/internal/store"
+	"budgex_backend/internal/validate"
+
+	"gorm.io/gorm"
+)
+
+type accounts struct{ db *gorm.DB }
+
+// An account belongs to its user, who may book it in any of their
+// households, including ones they have since left. Row-level security would
+// hide those transactions, so the reads of an account's transactions run past
+// it and are scoped by the account and its owner instead.
+
+// signedAmountSQL is a transaction's effect on its account balance.
+// Transfer legs already carry their sign.
+const signedAmountSQL = `CASE t.type WHEN 'income' THEN t.amount WHEN 'expense' THEN -t.amount WHEN 'transfer' THEN t.amount ELSE 0 END`
+
+// ownedAccountSQL keeps a transaction query to the accounts of a user; it
+// takes the user id.
+const ownedAccountSQL = `account_id IN (SELECT id FROM accounts WHERE user_id = ?)`
+
+func (s accounts) List(ctx context.Context, uid string, includeArchived bool) ([]store.AccountBalance, error) {
+	archived := ""
+	if !includeArchived {
+		archived = "AND NOT a.archived"
+	}
+	out := []store.AccountBalance{}
+	err := db.System(conn(ctx, s.db), func(tx *gorm.DB) error {
+		return tx.Raw(`
+			SELECT a.*,
+			       a.opening_balance + COALESCE(SUM(`+signedAmountSQL+`), 0) AS balance
+			FROM accounts a
+			LEFT JOIN transactions t
+			       ON t.account_id = a.id AND t.deleted_at IS NULL
+			WHERE a.user_id = ? AND a.deleted_at IS NULL `+archived+`
+			GROUP BY a.id
+			ORDER BY a.name ASC
+		`, uid).Scan(&out).Error
+	})
+	return out, err
+}
+
+func (s accounts) Get(ctx context.Context, uid, id string) (*models.Account, error) {
+	if !validate.ID(id) {
+		return nil, store.ErrNotFound
+	}
+	var a models.Account
+	if err := conn(ctx, s.db).Where("id = ? AND user_id = ? AND deleted_at IS NULL", id, uid).
+		First(&a).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &a, nil
+}
+
+func (s accounts) Create(ctx context.Context, a *models.Account) error {
+	return conn(ctx, s.db).Create(a).Error
+}
+
+func (s accounts) Update(ctx context.Context, uid, id string, changes map[string]any) error {
+	res := conn(ctx, s.db).Model(&models.Account{}).
+		Where("id = ? AND user_id = ? AND deleted_at IS NULL", id, uid).
+		Updates(changes)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return store.ErrNotFound
+	}
+	return nil
+}
+
+func (s accounts) SetCurrency(ctx context.Context, uid, id, currency string) error {
+	return conn(ctx, s.db).Transaction(func(tx *gorm.DB) error {
+		if err := s.Update(WithTx(ctx, tx), uid, id, map[string]any{"currency": currency}); err != nil {
+			return err
+		}
+		return db.System(tx, func(tx *gorm.DB) error {
+			return tx.Model(&models.Transaction{}).
+				Where("account_id = ? AND "+ownedAccountSQL, id, uid).
+				Update("currency", currency).Error
+		})
+	})
+}
+
+func (s accounts) Delete(ctx context.Context, uid, id string) error {
+	return s.Update(ctx, uid, id, map[string]any{"deleted_at": time.Now().UTC()})
+}
+
+func (s accounts) CountTransactions(ctx context.Context, uid, id string) (int64, error) {
+	var n int64
+	err := db.System(conn(ctx, s.db), func(tx *gorm.DB) error {
+		return tx.Model(&models.Transaction{}).
+			Where("account_id = ? AND deleted_at IS NULL AND "+ownedAccountSQL, id, uid).
+			Count(&n).Error
+	})
+	return n, err
+}
+
+func (s accounts) Balance(ctx context.Context, acc *models.Account, before time.Time) (money.Amount, error) {
+	var bal money.Amount
+	err := db.System(conn(ctx, s.db), func(tx *gorm.DB) error {
+		return tx.Raw(`
+			SELECT a.opening_balance + COALESCE(SUM(`+signedAmountSQL+`), 0)
+			FROM accounts a
+			LEFT JOIN transactions t
+			       ON t.account_id = a.id
+			      AND t.deleted_at IS NULL AND t.date < ?
+			WHERE a.id = ? AND a.user_id = ?
+			GROUP BY a.id
+		`, before, acc.ID, acc.UserID).Scan(&bal).Error
+	})
+	return bal, err
+}
+
+func (s accounts) Ledger(ctx context.Context, acc *models.Account, from, to *time.Time, limit int) ([]store.LedgerRow, error) {
+	where, args := "TRUE", []any{}
+	if from != nil {
+		where += " AND date >= ?"
+		args = append(args, *from)
+	}
+	if to != nil {
+		where += " AND date < ?"
+		args = append(args, *to)
+	}
+	// The window runs over the whole history so the balance is right even
+	// when only a slice of it is returned.
+	out := []store.LedgerRow{}
+	err := db.System(conn(ctx, s.db), func(tx *gorm.DB) error {
+		return tx.Raw(`
+			SELECT * FROM (
+				SELECT t.*,
+				       ? + SUM(`+signedAmountSQL+`)
+				           OVER (ORDER BY t.date, t.created_at, t.id) AS running_balance
+				FROM transactions t
+				WHERE t.account_id = ? AND t.deleted_at IS NULL AND `+ownedAccountSQL+`
+			) x
+			WHERE `+where+`
+			ORDER BY date DESC, created_at DESC, id DESC
+			LIMIT ?
+		`, append(append([]any{acc.OpeningBalance, acc.ID, acc.UserID}, args...), limit)...).
+			Scan(&out).Error
+	})
+	return out, err
+}
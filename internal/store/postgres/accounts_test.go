@@ -0,0 +1,112 @@
+package postgres_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"budgex_backend/internal/apperr"
+	"budgex_backend/internal/db"
+	"budgex_backend/internal/models"
+	"budgex_backend/internal/money"
+	"budgex_backend/internal/service"
+	"budgex_backend/internal/store/postgres"
+
+	"gorm.io/gorm"
+)
+
+// TestAccountsAcrossHouseholds books an account in two households and then
+// has its owner leave one of them: row-level security hides those
+// transactions from the owner, but they still count for the account.
+func TestAccountsAcrossHouseholds(t *testing.T) {
+	ctx := context.Background()
+	gdb := testDB(t)
+	st := postgres.New(gdb)
+	alice, home := testUser(t, gdb, "accounts_alice")
+	bob, shared := testUser(t, gdb, "accounts_bob")
+
+	acc := models.Account{Base: models.Base{UserID: alice}, Name: "Checking", Kind: "checking",
+		Currency: "USD", OpeningBalance: money.FromCents(10000)}
+	insert(t, gdb, &acc, &models.HouseholdMember{HouseholdID: shared, UserID: alice, Role: models.RoleEditor})
+	day := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	insert(t, gdb,
+		&models.Transaction{HouseholdBase: models.HouseholdBase{HouseholdID: home, CreatedBy: alice},
+			Type: models.TxIncome, Date: day, Amount: money.FromCents(5000), Currency: "USD", AccountID: &acc.ID},
+		&models.Transaction{HouseholdBase: models.HouseholdBase{HouseholdID: shared, CreatedBy: alice},
+			Type: models.TxExpense, Date: day.AddDate(0, 0, 1), Amount: money.FromCents(2000), Currency: "USD", AccountID: &acc.ID},
+		// bob's own account-less spending stays out of alice's totals
+		&models.Transaction{HouseholdBase: models.HouseholdBase{HouseholdID: shared, CreatedBy: bob},
+			Type: models.TxExpense, Date: day, Amount: money.FromCents(900), Currency: "USD"},
+	)
+	if err := db.AsSystem(ctx, gdb, func(tx *gorm.DB) error {
+		return tx.Where("household_id = ? AND user_id = ?", shared, alice).Delete(&models.HouseholdMember{}).Error
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	asUser(t, gdb, st, alice, func(ctx context.Context) error {
+		bal, err := st.Accounts.Balance(ctx, &acc, day.AddDate(0, 0, 7))
+		if err != nil {
+			return err
+		}
+		if bal != money.FromCents(13000) {
+			t.Errorf("Balance = %d, want 13000", bal)
+		}
+		list, err := st.Accounts.List(ctx, alice, false)
+		if err != nil {
+			return err
+		}
+		if len(list) != 1 || list[0].Balance != money.FromCents(13000) {
+			t.Errorf("List = %+v, want one account at 13000", list)
+		}
+		rows, err := st.Accounts.Ledger(ctx, &acc, nil, nil, 10)
+		if err != nil {
+			return err
+		}
+		if len(rows) != 2 || rows[0].RunningBalance != money.FromCents(13000) || rows[1].RunningBalance != money.FromCents(15000) {
+			t.Errorf("Ledger = %+v, want running balances 13000 and 15000", rows)
+		}
+		n, err := st.Accounts.CountTransactions(ctx, alice, acc.ID)
+		if err != nil {
+			return err
+		}
+		if n != 2 {
+			t.Errorf("CountTransactions = %d, want 2", n)
+		}
+		return nil
+	})
+
+	svc := service.New(st)
+	m := service.Member{UserID: alice, HouseholdID: home, Role: models.RoleOwner}
+	asUser(t, gdb, st, alice, func(ctx context.Context) error {
+		err := svc.Accounts.Delete(ctx, m, acc.ID)
+		if e := apperr.As(err); e == nil || e.Code != "account_has_transactions" {
+			t.Errorf("Delete = %v, want account_has_transactions", err)
+		}
+		return nil
+	})
+	asUser(t, gdb, st, alice, func(ctx context.Context) error {
+		return st.Accounts.SetCurrency(ctx, alice, acc.ID, "EUR")
+	})
+	var currencies []string
+	if err := db.AsSystem(ctx, gdb, func(tx *gorm.DB) error {
+		return tx.Model(&models.Transaction{}).Where("account_id = ?", acc.ID).Distinct().Pluck("currency", &currencies).Error
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if len(currencies) != 1 || currencies[0] != "EUR" {
+		t.Errorf("transaction currencies after SetCurrency = %v, want only EUR", currencies)
+	}
+
+	// bob cannot read alice's account through his household
+	asUser(t, gdb, st, bob, func(ctx context.Context) error {
+		n, err := st.Accounts.CountTransactions(ctx, bob, acc.ID)
+		if err != nil {
+			return err
+		}
+		if n != 0 {
+			t.Errorf("CountTransactions by bob = %d, want 0", n)
+		}
+		return nil
+	})
+}
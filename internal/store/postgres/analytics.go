@@ -0,0 +1,103 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"budgex_backend/internal/models"
+	"budgex_backend/internal/settings"
+	"budgex_backend/internal/store"
+
+	"gorm.io/gorm"
+)
+
+type analytics struct{ db *gorm.DB }
+
+// expenseLinesSQL yields one (category_id, amount, currency, date) row per
+// categorized piece of spending: the lines of split expenses, and every other
+// expense as a whole. Args: household id, from, to, household id, from, to.
+const expenseLinesSQL = `
+	SELECT s.category_id, s.amount, t.currency, t.date
+	FROM transaction_splits s
+	JOIN transactions t ON t.id = s.transaction_id
+	WHERE t.household_id = ? AND t.deleted_at IS NULL AND s.deleted_at IS NULL
+	  AND t.type = 'expense'
+	  AND t.date >= ? AND t.date < ?
+	UNION ALL
+	SELECT t.category_id, t.amount, t.currency, t.date
+	FROM transactions t
+	WHERE t.household_id = ? AND t.deleted_at IS NULL
+	  AND t.type = 'expense'
+	  AND t.date >= ? AND t.date < ?
+	  AND NOT EXISTS (
+	    SELECT 1 FROM transaction_splits s
+	    WHERE s.transaction_id = t.id AND s.deleted_at IS NULL
+	  )`
+
+// budgetMonthSQL is the budget month of a row's date, as the first of the
+// calendar month it is named after: shifting local time back by the start
+// day minus one puts each budget month on that month. Args: timezone, shift.
+const budgetMonthSQL = `date_trunc('month', (date AT TIME ZONE ?) - make_interval(days => ?))`
+
+func (s analytics) Totals(ctx context.Context, hid string, cal settings.Calendar, from, to time.Time) ([]store.DaySum, error) {
+	var out []store.DaySum
+	err := conn(ctx, s.db).Raw(`
+		SELECT type AS key, currency, (date AT TIME ZONE ?)::date AS day, SUM(amount) AS total
+		FROM transactions
+		WHERE household_id = ? AND deleted_at IS NULL
+		  AND type IN ('income', 'expense')
+		  AND date >= ? AND date < ?
+		GROUP BY 1, 2, 3
+	`, cal.TZ(), hid, from, to).Scan(&out).Error
+	return out, err
+}
+
+func (s analytics) ExpenseByCategory(ctx context.Context, hid string, cal settings.Calendar, from, to time.Time) ([]store.DaySum, error) {
+	var out []store.DaySum
+	err := conn(ctx, s.db).Raw(`
+		SELECT l.category_id::text AS key, l.currency, (l.date AT TIME ZONE ?)::date AS day, SUM(l.amount) AS total
+		FROM (`+expenseLinesSQL+`) l
+		GROUP BY 1, 2, 3
+	`, cal.TZ(), hid, from, to, hid, from, to).Scan(&out).Error
+	return out, err
+}
+
+func (s analytics) IncomeBefore(ctx context.Context, hid string, cal settings.Calendar, to time.Time) ([]store.DaySum, error) {
+	var out []store.DaySum
+	err := conn(ctx, s.db).Raw(`
+		SELECT currency, (date AT TIME ZONE ?)::date AS day, SUM(amount) AS total FROM transactions
+		WHERE household_id = ? AND deleted_at IS NULL AND type = 'income' AND date < ?
+		GROUP BY 1, 2
+	`, cal.TZ(), hid, to).Scan(&out).Error
+	return out, err
+}
+
+func (s analytics) RecentMonths(ctx context.Context, hid string, cal settings.Calendar, n int) ([]store.DaySum, error) {
+	shift := cal.MonthStartDay - 1
+	var out []store.DaySum
+	err := conn(ctx, s.db).Raw(`
+		WITH m AS (
+		  SELECT DISTINCT `+budgetMonthSQL+` AS month
+		  FROM transactions
+		  WHERE household_id = ? AND deleted_at IS NULL
+		    AND type IN ('income', 'expense')
+		  ORDER BY 1 DESC
+		  LIMIT ?
+		)
+		SELECT type AS key, currency, (date AT TIME ZONE ?)::date AS day, SUM(amount) AS total
+		FROM transactions
+		WHERE household_id = ? AND deleted_at IS NULL
+		  AND type IN ('income', 'expense')
+		  AND `+budgetMonthSQL+` IN (SELECT month FROM m)
+		GROUP BY 1, 2, 3
+	`, cal.TZ(), shift, hid, n, cal.TZ(), hid, cal.TZ(), shift).Scan(&out).Error
+	return out, err
+}
+
+func (s analytics) Rates(ctx context.Context, uid string, from, to time.Time) ([]models.FxRate, error) {
+	var out []models.FxRate
+	err := conn(ctx, s.db).
+		Where("user_id = ? AND date >= ? AND date <= ?", uid, from, to).
+		Order("date").Find(&out).Error
+	return out, err
+}
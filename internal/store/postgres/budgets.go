@@ -0,0 +1,51 @@
+package postgres
+
+import (
+	"context"
+
+	"budgex_backend/internal/models"
+	"budgex_backend/internal/money"
+	"budgex_backend/internal/store"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type budgets struct{ db *gorm.DB }
+
+func (s budgets) List(ctx context.Context, hid, month string) ([]models.Budget, error) {
+	var out []models.Budget
+	err := conn(ctx, s.db).Where("household_id = ? AND month = ? AND deleted_at IS NULL", hid, month).
+		Order("category_id").Find(&out).Error
+	return out, err
+}
+
+func (s budgets) Upsert(ctx context.Context, b *models.Budget) error {
+	return conn(ctx, s.db).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "household_id"}, {Name: "month"}, {Name: "category_id"}},
+		DoUpdates: clause.Assignments(map[string]any{"amount": clause.Column{Name: "excluded.amount"}}),
+	}).Create(b).Error
+}
+
+func (s budgets) Before(ctx context.Context, hid, month string, categoryIDs []string) ([]store.BudgetHistory, error) {
+	var out []store.BudgetHistory
+	if len(categoryIDs) == 0 {
+		return out, nil
+	}
+	err := conn(ctx, s.db).Raw(`
+		SELECT category_id, MIN(month) AS first, COALESCE(SUM(amount),0) AS total
+		FROM budgets
+		WHERE household_id = ? AND deleted_at IS NULL AND month < ? AND category_id IN ?
+		GROUP BY category_id
+	`, hid, month, categoryIDs).Scan(&out).Error
+	return out, err
+}
+
+func (s budgets) Assigned(ctx context.Context, hid, month string) (money.Amount, error) {
+	var out money.Amount
+	err := conn(ctx, s.db).Raw(`
+		SELECT COALESCE(SUM(amount),0) FROM budgets
+		WHERE household_id = ? AND deleted_at IS NULL AND month <= ?
+	`, hid, month).Scan(&out).Error
+	return out, err
+}
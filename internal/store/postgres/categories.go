@@ -0,0 +1,191 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"budgex_backend/internal/db"
+	"budgex_backend/internal/models"
+	"budgex_backend/internal/seed"
+	"budgex_backend/internal/store"
+	"budgex_backend/internal/validate"
+
+	"gorm.io/gorm"
+)
+
+type categories struct{ db *gorm.DB }
+
+func (s categories) List(ctx context.Context, hid string, includeArchived bool) ([]models.Category, error) {
+	q := conn(ctx, s.db).Where("household_id = ? AND deleted_at IS NULL", hid)
+	if !includeArchived {
+		q = q.Where("NOT archived")
+	}
+	var out []models.Category
+	err := q.Order("name ASC").Find(&out).Error
+	return out, err
+}
+
+func (s categories) Get(ctx context.Context, hid, id string) (*models.Category, error) {
+	if !validate.ID(id) {
+		return nil, store.ErrNotFound
+	}
+	var cat models.Category
+	if err := conn(ctx, s.db).Where("id = ? AND household_id = ? AND deleted_at IS NULL", id, hid).
+		First(&cat).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &cat, nil
+}
+
+func (s categories) Create(ctx context.Context, cat *models.Category) error {
+	return conn(ctx, s.db).Create(cat).Error
+}
+
+func (s categories) Update(ctx context.Context, hid, id string, changes map[string]any) error {
+	if !validate.ID(id) {
+		return store.ErrNotFound
+	}
+	res := conn(ctx, s.db).Model(&models.Category{}).
+		Where("id = ? AND household_id = ? AND deleted_at IS NULL", id, hid).
+		Updates(changes)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return store.ErrNotFound
+	}
+	return nil
+}
+
+func (s categories) InTree(ctx context.Context, hid, rootID, id string) (bool, error) {
+	var n int64
+	err := conn(ctx, s.db).Raw(`SELECT COUNT(*) FROM (`+categoryTreeSQL+`) t WHERE t.id = ?`,
+		rootID, hid, hid, id).Scan(&n).Error
+	return n > 0, err
+}
+
+func (s categories) CountTransactions(ctx context.Context, hid, id string) (int64, error) {
+	var n int64
+	err := conn(ctx, s.db).Raw(`
+		SELECT COUNT(*) FROM transactions t
+		WHERE t.household_id = ? AND t.deleted_at IS NULL
+		  AND (t.category_id = ? OR EXISTS (
+		    SELECT 1 FROM transaction_splits s
+		    WHERE s.transaction_id = t.id AND s.deleted_at IS NULL AND s.category_id = ?))
+	`, hid, id, id).Scan(&n).Error
+	return n, err
+}
+
+func (s categories) Merge(ctx context.Context, hid string, src, target *models.Category) error {
+	db := conn(ctx, s.db)
+	now := time.Now().UTC()
+	if err := db.Model(&models.Transaction{}).
+		Where("household_id = ? AND category_id = ?", hid, src.ID).
+		Update("category_id", target.ID).Error; err != nil {
+		return err
+	}
+	if err := db.Model(&models.TransactionSplit{}).
+		Where("household_id = ? AND category_id = ?", hid, src.ID).
+		Update("category_id", target.ID).Error; err != nil {
+		return err
+	}
+	if err := db.Model(&models.RecurringRule{}).
+		Where("household_id = ? AND category_id = ?", hid, src.ID).
+		Update("category_id", target.ID).Error; err != nil {
+		return err
+	}
+	if err := retargetRules(db, src.ID, &target.ID); err != nil {
+		return err
+	}
+
+	// (household_id, month, category_id) is unique, so fold src's budgets into the
+	// target's rows and drop them afterwards.
+	if err := db.Exec(`
+		INSERT INTO budgets (household_id, created_by, month, category_id, amount, created_at, updated_at)
+		SELECT household_id, created_by, month, ?::uuid, amount, ?, ?
+		FROM budgets
+		WHERE household_id = ? AND category_id = ? AND deleted_at IS NULL
+		ON CONFLICT (household_id, month, category_id) DO UPDATE
+		SET amount = CASE WHEN budgets.deleted_at IS NULL
+		                  THEN budgets.amount + excluded.amount
+		                  ELSE excluded.amount END,
+		    deleted_at = NULL,
+		    updated_at = excluded.updated_at
+	`, target.ID, now, now, hid, src.ID).Error; err != nil {
+		return err
+	}
+	if err := db.Where("household_id = ? AND category_id = ?", hid, src.ID).
+		Delete(&models.Budget{}).Error; err != nil {
+		return err
+	}
+
+	// merging a category into one of its descendants: the target takes its
+	// place, so re-parenting src's children cannot create a cycle
+	below, err := s.InTree(ctx, hid, src.ID, target.ID)
+	if err != nil {
+		return err
+	}
+	if below {
+		if err := db.Model(&models.Category{}).
+			Where("id = ? AND household_id = ?", target.ID, hid).
+			Update("parent_id", src.ParentID).Error; err != nil {
+			return err
+		}
+	}
+	if err := db.Model(&models.Category{}).
+		Where("household_id = ? AND parent_id = ? AND id <> ?", hid, src.ID, target.ID).
+		Update("parent_id", target.ID).Error; err != nil {
+		return err
+	}
+	return remove(db, hid, src, now)
+}
+
+func (s categories) Delete(ctx context.Context, hid string, cat *models.Category) error {
+	db := conn(ctx, s.db)
+	now := time.Now().UTC()
+	if err := db.Model(&models.Budget{}).
+		Where("household_id = ? AND category_id = ? AND deleted_at IS NULL", hid, cat.ID).
+		Update("deleted_at", now).Error; err != nil {
+		return err
+	}
+	if err := db.Model(&models.RecurringRule{}).
+		Where("household_id = ? AND category_id = ?", hid, cat.ID).
+		Update("category_id", nil).Error; err != nil {
+		return err
+	}
+	if err := retargetRules(db, cat.ID, nil); err != nil {
+		return err
+	}
+	return remove(db, hid, cat, now)
+}
+
+// retargetRules points the categorization rules that set category from at to
+// instead, or clears their category when to is nil. Rules belong to a user
+// and any member's rules may name the household's categories, so they are
+// updated past row-level security.
+func retargetRules(q *gorm.DB, from string, to *string) error {
+	return db.System(q, func(tx *gorm.DB) error {
+		return tx.Model(&models.Rule{}).
+			Where("set_category_id = ?", from).
+			Update("set_category_id", to).Error
+	})
+}
+
+// remove moves src's subcategories up one level and soft-deletes it.
+// Soft-deleting fires none of the ON DELETE actions of the category keys,
+// which only keep rows from naming another household's category, so Delete
+// and Merge move or clear every reference before calling it.
+func remove(db *gorm.DB, hid string, src *models.Category, now time.Time) error {
+	if err := db.Model(&models.Category{}).
+		Where("household_id = ? AND parent_id = ?", hid, src.ID).
+		Update("parent_id", src.ParentID).Error; err != nil {
+		return err
+	}
+	return db.Model(&models.Category{}).
+		Where("id = ? AND household_id = ?", src.ID, hid).
+		Update("deleted_at", now).Error
+}
+
+func (s categories) Seed(ctx context.Context, hid, uid, locale string) (seed.Result, error) {
+	return seed.Categories(ctx, conn(ctx, s.db), hid, uid, locale)
+}
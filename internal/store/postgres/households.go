@@ -0,0 +1,212 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"budgex_backend/internal/db"
+	"budgex_backend/internal/models"
+	"budgex_backend/internal/store"
+	"budgex_backend/internal/validate"
+
+	"gorm.io/gorm"
+)
+
+type households struct{ db *gorm.DB }
+
+// memberOf selects the live households of uid, with uid's role in each, in
+// the order uid joined them.
+func memberOf(q *gorm.DB, uid string) *gorm.DB {
+	return q.Table("households h").
+		Select("h.*, m.role").
+		Joins("JOIN household_members m ON m.household_id = h.id AND m.user_id = ?", uid).
+		Where("h.deleted_at IS NULL").
+		Order("m.created_at, h.id")
+}
+
+func (s households) List(ctx context.Context, uid string) ([]models.Household, error) {
+	var out []models.Household
+	err := memberOf(conn(ctx, s.db), uid).Find(&out).Error
+	return out, err
+}
+
+func (s households) Get(ctx context.Context, uid, hid string) (*models.Household, error) {
+	if !validate.ID(hid) {
+		return nil, store.ErrNotFound
+	}
+	var h models.Household
+	if err := memberOf(conn(ctx, s.db), uid).Where("h.id = ?", hid).Take(&h).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &h, nil
+}
+
+func (s households) Create(ctx context.Context, h *models.Household) error {
+	return create(conn(ctx, s.db), h)
+}
+
+// create inserts h and its owner. Row-level security only shows households
+// to their members, so both rows are written past it.
+func create(q *gorm.DB, h *models.Household) error {
+	return db.System(q, func(tx *gorm.DB) error {
+		if err := tx.Create(h).Error; err != nil {
+			return err
+		}
+		h.Role = models.RoleOwner
+		return tx.Create(&models.HouseholdMember{
+			HouseholdID: h.ID, UserID: h.CreatedBy, Role: models.RoleOwner,
+		}).Error
+	})
+}
+
+func (s households) EnsureFirst(ctx context.Context, uid, name string) (*models.Household, bool, error) {
+	var h models.Household
+	err := memberOf(conn(ctx, s.db), uid).Take(&h).Error
+	if err == nil {
+		return &h, false, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, false, err
+	}
+	created := false
+	err = conn(ctx, s.db).Transaction(func(tx *gorm.DB) error {
+		// the user's concurrent first requests must not each create one
+		if err := tx.Exec(`SELECT pg_advisory_xact_lock(hashtext(?))`, "household:"+uid).Error; err != nil {
+			return err
+		}
+		err := memberOf(tx, uid).Take(&h).Error
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		h = models.Household{Name: name, CreatedBy: uid}
+		created = true
+		return create(tx, &h)
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	return &h, created, nil
+}
+
+func (s households) Update(ctx context.Context, hid string, changes map[string]any) error {
+	res := conn(ctx, s.db).Model(&models.Household{}).
+		Where("id = ? AND deleted_at IS NULL", hid).
+		Updates(changes)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return store.ErrNotFound
+	}
+	return nil
+}
+
+func (s households) Delete(ctx context.Context, hid string) error {
+	q := conn(ctx, s.db)
+	// the household goes first: its rows stay writable only while the
+	// caller is still an owner
+	res := q.Model(&models.Household{}).
+		Where("id = ? AND deleted_at IS NULL", hid).
+		Update("deleted_at", time.Now().UTC())
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return store.ErrNotFound
+	}
+	if err := q.Where("household_id = ?", hid).Delete(&models.HouseholdInvite{}).Error; err != nil {
+		return err
+	}
+	return q.Where("household_id = ?", hid).Delete(&models.HouseholdMember{}).Error
+}
+
+func (s households) Members(ctx context.Context, hid string) ([]models.HouseholdMember, error) {
+	var out []models.HouseholdMember
+	err := conn(ctx, s.db).Where("household_id = ?", hid).
+		Order("created_at, user_id").Find(&out).Error
+	return out, err
+}
+
+func (s households) SetRole(ctx context.Context, hid, uid, role string) error {
+	res := conn(ctx, s.db).Model(&models.HouseholdMember{}).
+		Where("household_id = ? AND user_id = ?", hid, uid).
+		Update("role", role)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return store.ErrNotFound
+	}
+	return nil
+}
+
+func (s households) RemoveMember(ctx context.Context, hid, uid string) error {
+	res := conn(ctx, s.db).Where("household_id = ? AND user_id = ?", hid, uid).
+		Delete(&models.HouseholdMember{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return store.ErrNotFound
+	}
+	return nil
+}
+
+func (s households) CreateInvite(ctx context.Context, inv *models.HouseholdInvite) error {
+	return conn(ctx, s.db).Create(inv).Error
+}
+
+func (s households) Invites(ctx context.Context, hid string) ([]models.HouseholdInvite, error) {
+	var out []models.HouseholdInvite
+	err := conn(ctx, s.db).Where("household_id = ? AND accepted_by IS NULL", hid).
+		Order("created_at DESC").Find(&out).Error
+	return out, err
+}
+
+func (s households) DeleteInvite(ctx context.Context, hid, id string) error {
+	if !validate.ID(id) {
+		return store.ErrNotFound
+	}
+	res := conn(ctx, s.db).Where("id = ? AND household_id = ? AND accepted_by IS NULL", id, hid).
+		Delete(&models.HouseholdInvite{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return store.ErrNotFound
+	}
+	return nil
+}
+
+// Invites are only visible to owners of their household; whoever holds a
+// code is not one yet, so the lookup and the redemption run past row-level
+// security.
+
+func (s households) InviteByCode(ctx context.Context, code string) (*models.HouseholdInvite, error) {
+	var inv models.HouseholdInvite
+	err := db.System(conn(ctx, s.db), func(tx *gorm.DB) error {
+		return tx.Where("code = ?", code).Take(&inv).Error
+	})
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return &inv, nil
+}
+
+func (s households) Accept(ctx context.Context, inv *models.HouseholdInvite, uid string, at time.Time) error {
+	return db.System(conn(ctx, s.db), func(tx *gorm.DB) error {
+		res := tx.Model(&models.HouseholdInvite{}).
+			Where("id = ? AND accepted_by IS NULL", inv.ID).
+			Updates(map[string]any{"accepted_by": uid, "accepted_at": at})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return store.ErrNotFound // redeemed in the meantime
+		}
+		return tx.Create(&models.HouseholdMember{
+			HouseholdID: inv.HouseholdID, UserID: uid, Role: inv.Role,
+		}).Error
+	})
+}
@@ -0,0 +1,53 @@
+package postgres
+
+import (
+	"context"
+
+	"budgex_backend/internal/models"
+	"budgex_backend/internal/store"
+	"budgex_backend/internal/validate"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type imports struct{ db *gorm.DB }
+
+func (s imports) List(ctx context.Context, uid string) ([]models.ImportBatch, error) {
+	out := []models.ImportBatch{}
+	err := conn(ctx, s.db).Where("user_id = ? AND deleted_at IS NULL", uid).
+		Order("created_at DESC").Find(&out).Error
+	return out, err
+}
+
+func (s imports) Get(ctx context.Context, uid, id string, lock bool) (*models.ImportBatch, error) {
+	if !validate.ID(id) {
+		return nil, store.ErrNotFound
+	}
+	q := conn(ctx, s.db).Where("id = ? AND user_id = ? AND deleted_at IS NULL", id, uid)
+	if lock {
+		q = q.Clauses(clause.Locking{Strength: "UPDATE"})
+	}
+	var b models.ImportBatch
+	if err := q.First(&b).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &b, nil
+}
+
+func (s imports) Create(ctx context.Context, b *models.ImportBatch) error {
+	return conn(ctx, s.db).Create(b).Error
+}
+
+func (s imports) Update(ctx context.Context, uid, id string, changes map[string]any) error {
+	res := conn(ctx, s.db).Model(&models.ImportBatch{}).
+		Where("id = ? AND user_id = ? AND deleted_at IS NULL", id, uid).
+		Updates(changes)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return store.ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+// Package postgres implements the stores on PostgreSQL through gorm.
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"budgex_backend/internal/db"
+	"budgex_backend/internal/store"
+
+	"gorm.io/gorm"
+)
+
+// New returns the stores backed by db.
+func New(db *gorm.DB) store.Stores {
+	return store.Stores{
+		Tx:           transactor{db},
+		Transactions: transactions{db},
+		Categories:   categories{db},
+		Budgets:      budgets{db},
+		Analytics:    analytics{db},
+		Accounts:     accounts{db},
+		Settings:     userSettings{db},
+		Rules:        rules{db},
+		Recurring:    recurring{db},
+		Imports:      imports{db},
+		Rates:        rates{db},
+		Households:   households{db},
+		Health:       health{db},
+	}
+}
+
+type txKey struct{}
+
+// WithTx returns a ctx whose store calls run in tx.
+func WithTx(ctx context.Context, tx *gorm.DB) context.Context {
+	return context.WithValue(ctx, txKey{}, tx)
+}
+
+// conn is the transaction carried by ctx, else db.
+func conn(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if tx, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		return tx.WithContext(ctx)
+	}
+	return db.WithContext(ctx)
+}
+
+// Conn is the transaction carried by ctx, else db. Code outside the stores
+// that queries the database itself uses it to join the request transaction.
+func Conn(ctx context.Context, db *gorm.DB) *gorm.DB { return conn(ctx, db) }
+
+type transactor struct{ db *gorm.DB }
+
+// InTx opens a transaction, or a savepoint when ctx already carries one.
+func (t transactor) InTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return conn(ctx, t.db).Transaction(func(tx *gorm.DB) error {
+		return fn(WithTx(ctx, tx))
+	})
+}
+
+// AsUser opens a transaction that row-level security scopes to uid.
+func (t transactor) AsUser(ctx context.Context, uid string, fn func(ctx context.Context) error) error {
+	return conn(ctx, t.db).Transaction(func(tx *gorm.DB) error {
+		if err := db.SetUser(tx, uid); err != nil {
+			return err
+		}
+		return fn(WithTx(ctx, tx))
+	})
+}
+
+type health struct{ db *gorm.DB }
+
+func (h health) Ping(ctx context.Context) error {
+	return conn(ctx, h.db).Exec("SELECT 1").Error
+}
+
+// notFound maps gorm's not-found error to store.ErrNotFound.
+func notFound(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return store.ErrNotFound
+	}
+	return err
+}
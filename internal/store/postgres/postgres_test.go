@@ -0,0 +1,108 @@
+package postgres_test
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"budgex_backend/internal/db"
+	"budgex_backend/internal/models"
+	"budgex_backend/internal/store"
+	"budgex_backend/internal/store/postgres"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// testRole is an ordinary role the tests switch to, since the test database
+// is usually reached as a superuser, which row-level security ignores.
+const testRole = "budgex_rls_test"
+
+// testDB connects to TEST_DATABASE_URL, a scratch Postgres database, and
+// migrates it up. The connecting role must be able to create and switch to
+// testRole.
+func testDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+	gdb, err := db.Connect(dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Migrate(context.Background(), gdb); err != nil {
+		t.Fatal(err)
+	}
+	if err := gdb.Exec(`
+		DO $$ BEGIN
+		  IF NOT EXISTS (SELECT FROM pg_roles WHERE rolname = '` + testRole + `') THEN
+		    CREATE ROLE ` + testRole + ` NOLOGIN;
+		  END IF;
+		END $$;
+		GRANT USAGE ON SCHEMA public TO ` + testRole + `;
+		GRANT SELECT, INSERT, UPDATE, DELETE ON ALL TABLES IN SCHEMA public TO ` + testRole + `;`).Error; err != nil {
+		t.Fatal(err)
+	}
+	return gdb
+}
+
+// asUser runs fn the way a request of uid does: in one transaction scoped to
+// uid, as a role row-level security applies to.
+func asUser(t *testing.T, gdb *gorm.DB, st store.Stores, uid string, fn func(ctx context.Context) error) {
+	t.Helper()
+	err := st.Tx.AsUser(context.Background(), uid, func(ctx context.Context) error {
+		if err := postgres.Conn(ctx, gdb).Exec("SET LOCAL ROLE " + testRole).Error; err != nil {
+			return err
+		}
+		return fn(ctx)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// testUser returns a fresh user id with a household of their own, and
+// removes everything the user created when the test ends.
+func testUser(t *testing.T, gdb *gorm.DB, name string) (uid, hid string) {
+	t.Helper()
+	uid = name + "_" + uuid.NewString()
+	h := models.Household{Name: name, CreatedBy: uid}
+	err := db.AsSystem(context.Background(), gdb, func(tx *gorm.DB) error {
+		if err := tx.Create(&h).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.HouseholdMember{HouseholdID: h.ID, UserID: uid, Role: models.RoleOwner}).Error
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = db.AsSystem(context.Background(), gdb, func(tx *gorm.DB) error {
+			tx.Where("household_id = ?", h.ID).Delete(&models.TransactionSplit{})
+			tx.Where("created_by = ? OR household_id = ?", uid, h.ID).Delete(&models.Transaction{})
+			tx.Where("household_id = ?", h.ID).Delete(&models.Category{})
+			tx.Where("user_id = ?", uid).Delete(&models.Account{})
+			tx.Where("user_id = ? OR household_id = ?", uid, h.ID).Delete(&models.HouseholdMember{})
+			tx.Where("created_by = ?", uid).Delete(&models.Household{})
+			return nil
+		})
+	})
+	return uid, h.ID
+}
+
+// insert creates rows past row-level security, the way jobs do.
+func insert(t *testing.T, gdb *gorm.DB, rows ...any) {
+	t.Helper()
+	err := db.AsSystem(context.Background(), gdb, func(tx *gorm.DB) error {
+		for _, r := range rows {
+			if err := tx.Create(r).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
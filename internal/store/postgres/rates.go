@@ -0,0 +1,39 @@
+package postgres
+
+import (
+	"context"
+
+	"budgex_backend/internal/fx"
+	"budgex_backend/internal/models"
+	"budgex_backend/internal/store"
+
+	"gorm.io/gorm"
+)
+
+type rates struct{ db *gorm.DB }
+
+func (s rates) List(ctx context.Context, uid string, f store.RateFilter) ([]models.FxRate, error) {
+	q := conn(ctx, s.db).Where("user_id = ?", uid)
+	if f.Base != "" {
+		q = q.Where("base = ?", f.Base)
+	}
+	if f.Quote != "" {
+		q = q.Where("quote = ?", f.Quote)
+	}
+	if f.From != nil {
+		q = q.Where("date >= ?", *f.From)
+	}
+	if f.To != nil {
+		q = q.Where("date <= ?", *f.To)
+	}
+	if f.Limit > 0 {
+		q = q.Limit(f.Limit)
+	}
+	out := []models.FxRate{}
+	err := q.Order("date DESC, base, quote").Find(&out).Error
+	return out, err
+}
+
+func (s rates) Save(ctx context.Context, uid, source string, rs []models.FxRate) (int, error) {
+	return fx.Save(ctx, conn(ctx, s.db), uid, source, rs)
+}
@@ -0,0 +1,74 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"budgex_backend/internal/models"
+	"budgex_backend/internal/store"
+	"budgex_backend/internal/validate"
+
+	"gorm.io/gorm"
+)
+
+type recurring struct{ db *gorm.DB }
+
+func (s recurring) List(ctx context.Context, hid string) ([]models.RecurringRule, error) {
+	out := []models.RecurringRule{}
+	err := conn(ctx, s.db).Where("household_id = ? AND deleted_at IS NULL", hid).
+		Order("name ASC").Find(&out).Error
+	return out, err
+}
+
+func (s recurring) Active(ctx context.Context, hid string) ([]models.RecurringRule, error) {
+	var out []models.RecurringRule
+	err := conn(ctx, s.db).Where("household_id = ? AND active AND deleted_at IS NULL", hid).
+		Find(&out).Error
+	return out, err
+}
+
+func (s recurring) Get(ctx context.Context, hid, id string) (*models.RecurringRule, error) {
+	if !validate.ID(id) {
+		return nil, store.ErrNotFound
+	}
+	var r models.RecurringRule
+	if err := conn(ctx, s.db).Where("id = ? AND household_id = ? AND deleted_at IS NULL", id, hid).
+		First(&r).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &r, nil
+}
+
+func (s recurring) Create(ctx context.Context, r *models.RecurringRule) error {
+	return conn(ctx, s.db).Create(r).Error
+}
+
+func (s recurring) Save(ctx context.Context, r *models.RecurringRule) error {
+	res := conn(ctx, s.db).Select("*").
+		Omit("id", "household_id", "created_by", "created_at", "deleted_at", "materialized_through").
+		Where("household_id = ? AND deleted_at IS NULL", r.HouseholdID).
+		Save(r)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return store.ErrNotFound
+	}
+	return nil
+}
+
+func (s recurring) Delete(ctx context.Context, hid, id string) error {
+	if !validate.ID(id) {
+		return store.ErrNotFound
+	}
+	res := conn(ctx, s.db).Model(&models.RecurringRule{}).
+		Where("id = ? AND household_id = ? AND deleted_at IS NULL", id, hid).
+		Update("deleted_at", time.Now().UTC())
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return store.ErrNotFound
+	}
+	return nil
+}
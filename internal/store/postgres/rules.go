@@ -0,0 +1,75 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"budgex_backend/internal/models"
+	"budgex_backend/internal/store"
+	"budgex_backend/internal/validate"
+
+	"gorm.io/gorm"
+)
+
+type rules struct{ db *gorm.DB }
+
+func (s rules) List(ctx context.Context, uid string) ([]models.Rule, error) {
+	out := []models.Rule{}
+	err := conn(ctx, s.db).
+		Where("user_id = ? AND deleted_at IS NULL", uid).
+		Order("priority ASC, created_at ASC").Find(&out).Error
+	return out, err
+}
+
+func (s rules) Active(ctx context.Context, uid string) ([]models.Rule, error) {
+	var out []models.Rule
+	err := conn(ctx, s.db).
+		Where("user_id = ? AND active AND deleted_at IS NULL", uid).
+		Order("priority ASC, created_at ASC").Find(&out).Error
+	return out, err
+}
+
+func (s rules) Get(ctx context.Context, uid, id string) (*models.Rule, error) {
+	if !validate.ID(id) {
+		return nil, store.ErrNotFound
+	}
+	var r models.Rule
+	if err := conn(ctx, s.db).Where("id = ? AND user_id = ? AND deleted_at IS NULL", id, uid).
+		First(&r).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &r, nil
+}
+
+func (s rules) Create(ctx context.Context, r *models.Rule) error {
+	return conn(ctx, s.db).Create(r).Error
+}
+
+func (s rules) Save(ctx context.Context, r *models.Rule) error {
+	res := conn(ctx, s.db).Select("*").Omit("id", "user_id", "created_at", "deleted_at").
+		Where("user_id = ? AND deleted_at IS NULL", r.UserID).
+		Save(r)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return store.ErrNotFound
+	}
+	return nil
+}
+
+func (s rules) Delete(ctx context.Context, uid, id string) error {
+	if !validate.ID(id) {
+		return store.ErrNotFound
+	}
+	res := conn(ctx, s.db).Model(&models.Rule{}).
+		Where("id = ? AND user_id = ? AND deleted_at IS NULL", id, uid).
+		Update("deleted_at", time.Now().UTC())
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return store.ErrNotFound
+	}
+	return nil
+}
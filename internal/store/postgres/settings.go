@@ -0,0 +1,20 @@
+package postgres
+
+import (
+	"context"
+
+	"budgex_backend/internal/models"
+	"budgex_backend/internal/settings"
+
+	"gorm.io/gorm"
+)
+
+type userSettings struct{ db *gorm.DB }
+
+func (s userSettings) Get(ctx context.Context, uid string) (models.UserSettings, error) {
+	return settings.Load(ctx, conn(ctx, s.db), uid)
+}
+
+func (s userSettings) Save(ctx context.Context, us *models.UserSettings) error {
+	return settings.Save(ctx, conn(ctx, s.db), us)
+}
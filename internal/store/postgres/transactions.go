@@ -0,0 +1,257 @@
+package postgres
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"budgex_backend/internal/models"
+	"budgex_backend/internal/store"
+	"budgex_backend/internal/validate"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type transactions struct{ db *gorm.DB }
+
+// LiveSplits is the Preload condition for split lines.
+const LiveSplits = "deleted_at IS NULL"
+
+// transferOfSQL selects the transfer id of a transaction, NULL for ordinary
+// rows. Args: transaction id, household id.
+const transferOfSQL = `SELECT transfer_id FROM transactions WHERE id = ? AND household_id = ?`
+
+// categoryTreeSQL selects a category and all of its descendants.
+// Args: root category id, household id, household id. UNION (not UNION ALL)
+// stops on parent cycles left by older data.
+const categoryTreeSQL = `
+	WITH RECURSIVE tree AS (
+		SELECT id FROM categories
+		WHERE id = ? AND household_id = ? AND deleted_at IS NULL
+		UNION
+		SELECT c.id FROM categories c
+		JOIN tree ON c.parent_id = tree.id
+		WHERE c.household_id = ? AND c.deleted_at IS NULL
+	)
+	SELECT id FROM tree`
+
+// duplicatePairsSQL pairs live income/expense rows of one household with the
+// same type, amount and currency whose dates are at most N days apart.
+// Args: days, days, household id, from, to, limit.
+const duplicatePairsSQL = `
+	SELECT a.id AS a_id, b.id AS b_id, a.payee AS a_payee, b.payee AS b_payee
+	FROM transactions a
+	JOIN transactions b
+	  ON b.household_id = a.household_id AND b.type = a.type AND b.amount = a.amount AND b.currency = a.currency
+	 AND b.id > a.id AND b.deleted_at IS NULL
+	 AND b.date BETWEEN a.date - make_interval(days => ?) AND a.date + make_interval(days => ?)
+	WHERE a.household_id = ? AND a.deleted_at IS NULL
+	  AND a.type IN ('income','expense')
+	  AND a.date >= ? AND a.date < ?
+	ORDER BY a.date DESC
+	LIMIT ?`
+
+// EscapeLike escapes the LIKE wildcards in s so it matches literally.
+func EscapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+func (s transactions) List(ctx context.Context, hid string, f store.TxFilter) ([]models.Transaction, error) {
+	q := conn(ctx, s.db).Where("household_id = ? AND deleted_at IS NULL", hid)
+	if f.From != nil {
+		q = q.Where("date >= ?", *f.From)
+	}
+	if f.To != nil {
+		q = q.Where("date < ?", *f.To)
+	}
+	if f.Type != "" {
+		q = q.Where("type = ?", f.Type)
+	}
+	if f.CategoryID != "" {
+		if f.IncludeDescendants {
+			q = q.Where(`category_id IN (`+categoryTreeSQL+`) OR EXISTS (
+				SELECT 1 FROM transaction_splits s
+				WHERE s.transaction_id = transactions.id AND s.deleted_at IS NULL
+				  AND s.category_id IN (`+categoryTreeSQL+`))`,
+				f.CategoryID, hid, hid, f.CategoryID, hid, hid)
+		} else {
+			q = q.Where(`category_id = ? OR EXISTS (
+				SELECT 1 FROM transaction_splits s
+				WHERE s.transaction_id = transactions.id AND s.deleted_at IS NULL
+				  AND s.category_id = ?)`, f.CategoryID, f.CategoryID)
+		}
+	}
+	if f.AccountID != "" {
+		q = q.Where("account_id = ?", f.AccountID)
+	}
+	if f.Payee != "" {
+		q = q.Where("payee ILIKE ?", "%"+EscapeLike(f.Payee)+"%")
+	}
+	if f.Memo != "" {
+		q = q.Where("memo ILIKE ?", "%"+EscapeLike(f.Memo)+"%")
+	}
+	if f.Tag != "" {
+		q = q.Where(`? = ANY(regexp_split_to_array(tags, '\s*,\s*'))`, f.Tag)
+	}
+	if f.MinAmount != nil {
+		q = q.Where("amount >= ?", *f.MinAmount)
+	}
+	if f.MaxAmount != nil {
+		q = q.Where("amount <= ?", *f.MaxAmount)
+	}
+	if f.Source != "" {
+		q = q.Where("source = ?", f.Source)
+	}
+	if k := f.After; k != nil {
+		q = q.Where("(date, created_at, id) < (?, ?, ?)", k.Date, k.CreatedAt, k.ID)
+	}
+	if f.Limit > 0 {
+		q = q.Limit(f.Limit)
+	}
+	var out []models.Transaction
+	err := q.Order("date DESC, created_at DESC, id DESC").
+		Preload("Splits", LiveSplits).
+		Find(&out).Error
+	return out, err
+}
+
+func (s transactions) Get(ctx context.Context, hid, id string) (*models.Transaction, error) {
+	if !validate.ID(id) {
+		return nil, store.ErrNotFound
+	}
+	var tx models.Transaction
+	err := conn(ctx, s.db).Where("id = ? AND household_id = ? AND deleted_at IS NULL", id, hid).
+		Preload("Splits", LiveSplits).
+		First(&tx).Error
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return &tx, nil
+}
+
+func (s transactions) GetMany(ctx context.Context, hid string, ids []string) ([]models.Transaction, error) {
+	var out []models.Transaction
+	err := conn(ctx, s.db).Where("household_id = ? AND id IN ?", hid, ids).
+		Preload("Splits", LiveSplits).Find(&out).Error
+	return out, err
+}
+
+func (s transactions) Create(ctx context.Context, tx *models.Transaction) error {
+	return conn(ctx, s.db).Create(tx).Error
+}
+
+func (s transactions) Update(ctx context.Context, hid, id string, changes map[string]any) error {
+	res := conn(ctx, s.db).Model(&models.Transaction{}).
+		Where("id = ? AND household_id = ? AND deleted_at IS NULL", id, hid).
+		Updates(changes)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return store.ErrNotFound
+	}
+	return nil
+}
+
+func (s transactions) ReplaceSplits(ctx context.Context, tx *models.Transaction, splits []models.TransactionSplit) error {
+	db := conn(ctx, s.db)
+	if err := db.Where("transaction_id = ? AND household_id = ?", tx.ID, tx.HouseholdID).
+		Delete(&models.TransactionSplit{}).Error; err != nil {
+		return err
+	}
+	if len(splits) == 0 {
+		return nil
+	}
+	for i := range splits {
+		splits[i].TransactionID = tx.ID
+	}
+	return db.Create(&splits).Error
+}
+
+func (s transactions) TransferPeer(ctx context.Context, tx *models.Transaction) (*models.Transaction, error) {
+	if tx.TransferID == nil {
+		return nil, store.ErrNotFound
+	}
+	var peer models.Transaction
+	if err := conn(ctx, s.db).Where("household_id = ? AND transfer_id = ? AND id <> ? AND deleted_at IS NULL",
+		tx.HouseholdID, *tx.TransferID, tx.ID).First(&peer).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &peer, nil
+}
+
+func (s transactions) SetDeleted(ctx context.Context, hid, id string, deleted bool) error {
+	if !validate.ID(id) {
+		return store.ErrNotFound
+	}
+	q := conn(ctx, s.db).Model(&models.Transaction{}).Where("household_id = ?", hid)
+	var at any // NULL restores
+	if deleted {
+		at = time.Now().UTC()
+		q = q.Where("deleted_at IS NULL")
+	} else {
+		q = q.Where("deleted_at IS NOT NULL")
+	}
+	res := q.Where("id = ? OR transfer_id = ("+transferOfSQL+")", id, id, hid).
+		Update("deleted_at", at)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return store.ErrNotFound
+	}
+	return nil
+}
+
+func (s transactions) DeleteMany(ctx context.Context, hid string, ids []string) error {
+	return conn(ctx, s.db).Model(&models.Transaction{}).
+		Where("id IN ? AND household_id = ? AND deleted_at IS NULL", ids, hid).
+		Update("deleted_at", time.Now().UTC()).Error
+}
+
+func (s transactions) ExternalIDs(ctx context.Context, hid string, ids []string) ([]string, error) {
+	out := []string{}
+	if len(ids) == 0 {
+		return out, nil
+	}
+	err := conn(ctx, s.db).Model(&models.Transaction{}).
+		Where("household_id = ? AND deleted_at IS NULL AND external_id IN ?", hid, ids).
+		Pluck("external_id", &out).Error
+	return out, err
+}
+
+func (s transactions) Import(ctx context.Context, txs []models.Transaction) (int, error) {
+	if len(txs) == 0 {
+		return 0, nil
+	}
+	// the unique index on (household_id, external_id) turns away rows
+	// imported since they were checked
+	res := conn(ctx, s.db).Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(&txs, 500)
+	return int(res.RowsAffected), res.Error
+}
+
+func (s transactions) DeleteImported(ctx context.Context, hid, batchID string) error {
+	return conn(ctx, s.db).Model(&models.Transaction{}).
+		Where("import_batch_id = ? AND household_id = ? AND deleted_at IS NULL", batchID, hid).
+		Update("deleted_at", time.Now().UTC()).Error
+}
+
+func (s transactions) DuplicatePairs(ctx context.Context, hid string, days int, from, to time.Time, limit int) ([]store.DuplicatePair, error) {
+	type pairRow struct {
+		AID    string  `gorm:"column:a_id"`
+		BID    string  `gorm:"column:b_id"`
+		APayee *string `gorm:"column:a_payee"`
+		BPayee *string `gorm:"column:b_payee"`
+	}
+	var rows []pairRow
+	if err := conn(ctx, s.db).Raw(duplicatePairsSQL, days, days, hid, from, to, limit).
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	out := make([]store.DuplicatePair, len(rows))
+	for i, r := range rows {
+		out[i] = store.DuplicatePair{AID: r.AID, BID: r.BID, APayee: r.APayee, BPayee: r.BPayee}
+	}
+	return out, nil
+}
@@ -0,0 +1,172 @@
+package postgres_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"budgex_backend/internal/models"
+	"budgex_backend/internal/money"
+	"budgex_backend/internal/store"
+	"budgex_backend/internal/store/postgres"
+)
+
+func TestTransactionsListPages(t *testing.T) {
+	gdb := testDB(t)
+	st := postgres.New(gdb)
+	uid, hid := testUser(t, gdb, "tx_pages")
+
+	// seven rows on two days; five share a date and three of those also
+	// share created_at, so only the id breaks the tie
+	day := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	at := time.Date(2024, 3, 2, 9, 0, 0, 0, time.UTC)
+	own := models.HouseholdBase{HouseholdID: hid, CreatedBy: uid}
+	var rows []*models.Transaction
+	for i, k := range []struct {
+		date    time.Time
+		created time.Time
+	}{
+		{day, at}, {day, at}, {day, at}, {day, at.Add(time.Second)}, {day, at.Add(-time.Second)},
+		{day.AddDate(0, 0, 1), at}, {day.AddDate(0, 0, -1), at},
+	} {
+		b := own
+		b.CreatedAt = k.created
+		rows = append(rows, &models.Transaction{HouseholdBase: b, Type: models.TxExpense,
+			Date: k.date, Amount: money.FromCents(int64(100 + i)), Currency: "USD"})
+	}
+	for _, r := range rows {
+		insert(t, gdb, r)
+	}
+
+	var got []string
+	asUser(t, gdb, st, uid, func(ctx context.Context) error {
+		f := store.TxFilter{Limit: 2}
+		for range len(rows) {
+			page, err := st.Transactions.List(ctx, hid, f)
+			if err != nil {
+				return err
+			}
+			for _, tx := range page {
+				got = append(got, tx.ID)
+			}
+			if len(page) < f.Limit {
+				return nil
+			}
+			last := page[len(page)-1]
+			f.After = &store.TxCursor{Date: last.Date, CreatedAt: last.CreatedAt, ID: last.ID}
+		}
+		t.Error("paging did not end")
+		return nil
+	})
+
+	want := make([]models.Transaction, len(rows))
+	for i, r := range rows {
+		want[i] = *r
+	}
+	slices.SortFunc(want, func(a, b models.Transaction) int {
+		if c := b.Date.Compare(a.Date); c != 0 {
+			return c
+		}
+		if c := b.CreatedAt.Compare(a.CreatedAt); c != 0 {
+			return c
+		}
+		switch {
+		case a.ID > b.ID:
+			return -1
+		case a.ID < b.ID:
+			return 1
+		}
+		return 0
+	})
+	var wantIDs []string
+	for _, w := range want {
+		wantIDs = append(wantIDs, w.ID)
+	}
+	if !slices.Equal(got, wantIDs) {
+		t.Errorf("paged ids = %v, want %v", got, wantIDs)
+	}
+}
+
+func TestTransactionsListFilters(t *testing.T) {
+	gdb := testDB(t)
+	st := postgres.New(gdb)
+	uid, hid := testUser(t, gdb, "tx_filters")
+	own := models.HouseholdBase{HouseholdID: hid, CreatedBy: uid}
+
+	food := models.Category{HouseholdBase: own, Name: "Food"}
+	insert(t, gdb, &food)
+	fruit := models.Category{HouseholdBase: own, Name: "Fruit", ParentID: &food.ID}
+	acc := models.Account{Base: models.Base{UserID: uid}, Name: "Checking", Kind: "checking", Currency: "USD"}
+	insert(t, gdb, &fruit, &acc)
+
+	day := func(d int) time.Time { return time.Date(2024, 3, d, 0, 0, 0, 0, time.UTC) }
+	str := func(s string) *string { return &s }
+	tx := func(payee string, d int, amount int64) *models.Transaction {
+		return &models.Transaction{HouseholdBase: own, Type: models.TxExpense, Date: day(d),
+			Amount: money.FromCents(amount), Currency: "USD", Payee: str(payee)}
+	}
+	grocer := tx("Corner Grocer", 1, 1500)
+	grocer.CategoryID = &food.ID
+	grocer.Tags = str("weekly, home")
+	market := tx("Market 100%", 5, 800)
+	market.CategoryID = &fruit.ID
+	market.AccountID = &acc.ID
+	market.Memo = str("Apples and PEARS")
+	salary := tx("Employer", 10, 300000)
+	salary.Type = models.TxIncome
+	salary.AccountID = &acc.ID
+	split := tx("Superstore", 12, 4000)
+	insert(t, gdb, grocer, market, salary, split)
+	insert(t, gdb, &models.TransactionSplit{HouseholdBase: own, TransactionID: split.ID, CategoryID: &fruit.ID, Amount: money.FromCents(1000)},
+		&models.TransactionSplit{HouseholdBase: own, TransactionID: split.ID, Amount: money.FromCents(3000)})
+	deleted := tx("Corner Grocer", 2, 1500)
+	now := time.Now()
+	deleted.DeletedAt = &now
+	insert(t, gdb, deleted)
+
+	amount := func(a int64) *money.Amount { m := money.FromCents(a); return &m }
+	tests := []struct {
+		name string
+		f    store.TxFilter
+		want []*models.Transaction
+	}{
+		{"all", store.TxFilter{}, []*models.Transaction{split, salary, market, grocer}},
+		{"date range", store.TxFilter{From: ptrTime(day(5)), To: ptrTime(day(12))}, []*models.Transaction{salary, market}},
+		{"category", store.TxFilter{CategoryID: food.ID}, []*models.Transaction{grocer}},
+		{"category and subcategories", store.TxFilter{CategoryID: food.ID, IncludeDescendants: true}, []*models.Transaction{split, market, grocer}},
+		{"split line", store.TxFilter{CategoryID: fruit.ID}, []*models.Transaction{split, market}},
+		{"account", store.TxFilter{AccountID: acc.ID}, []*models.Transaction{salary, market}},
+		{"type", store.TxFilter{Type: models.TxIncome}, []*models.Transaction{salary}},
+		{"tag", store.TxFilter{Tag: "home"}, []*models.Transaction{grocer}},
+		{"tag is exact", store.TxFilter{Tag: "hom"}, nil},
+		{"min amount", store.TxFilter{MinAmount: amount(1500)}, []*models.Transaction{split, salary, grocer}},
+		{"amount range", store.TxFilter{MinAmount: amount(800), MaxAmount: amount(1500)}, []*models.Transaction{market, grocer}},
+		{"payee", store.TxFilter{Payee: "grocer"}, []*models.Transaction{grocer}},
+		{"payee wildcard is literal", store.TxFilter{Payee: "100%"}, []*models.Transaction{market}},
+		{"payee underscore is literal", store.TxFilter{Payee: "_"}, nil},
+		{"memo", store.TxFilter{Memo: "pears"}, []*models.Transaction{market}},
+		{"combined", store.TxFilter{AccountID: acc.ID, Type: models.TxExpense, Payee: "market"}, []*models.Transaction{market}},
+	}
+	asUser(t, gdb, st, uid, func(ctx context.Context) error {
+		for _, tt := range tests {
+			got, err := st.Transactions.List(ctx, hid, tt.f)
+			if err != nil {
+				return err
+			}
+			var gotIDs, wantIDs []string
+			for _, g := range got {
+				gotIDs = append(gotIDs, g.ID)
+			}
+			for _, w := range tt.want {
+				wantIDs = append(wantIDs, w.ID)
+			}
+			if !slices.Equal(gotIDs, wantIDs) {
+				t.Errorf("%s: ids = %v, want %v", tt.name, gotIDs, wantIDs)
+			}
+		}
+		return nil
+	})
+}
+
+func ptrTime(t time.Time) *time.Time { return &t }
@@ -0,0 +1,352 @@
+// Package store defines how the services read and write data. Transactions,
+// categories, budgets, recurring rules and reports are scoped to one
+// household (hid); accounts, settings, rules, import batches and exchange
+// rates to one user (uid). Package
+// postgres implements the stores on the database; package memory keeps
+// everything in process, for tests and tools.
+package store
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"budgex_backend/internal/models"
+	"budgex_backend/internal/money"
+	"budgex_backend/internal/seed"
+	"budgex_backend/internal/settings"
+)
+
+// ErrNotFound is returned when a row does not exist, is deleted or belongs to
+// another user or household.
+var ErrNotFound = errors.New("not found")
+
+// Stores bundles one implementation of every store.
+type Stores struct {
+	Tx           Transactor
+	Transactions TransactionStore
+	Categories   CategoryStore
+	Budgets      BudgetStore
+	Analytics    AnalyticsStore
+	Accounts     AccountStore
+	Settings     SettingsStore
+	Rules        RuleStore
+	Recurring    RecurringStore
+	Imports      ImportStore
+	Rates        RateStore
+	Households   HouseholdStore
+	Health       HealthStore
+}
+
+// Transactor runs several store calls atomically.
+type Transactor interface {
+	// InTx runs fn in a transaction that commits when fn returns nil. Stores
+	// called with the ctx passed to fn take part in it; nested calls roll back
+	// on their own when they fail.
+	InTx(ctx context.Context, fn func(ctx context.Context) error) error
+	// AsUser is InTx for a request made by uid: rows of households uid is not
+	// a member of stay out of reach even of a query that forgets its filter.
+	AsUser(ctx context.Context, uid string, fn func(ctx context.Context) error) error
+}
+
+// HealthStore reports whether the stores can be reached.
+type HealthStore interface {
+	Ping(ctx context.Context) error
+}
+
+// TxCursor is the keyset position of a transaction in the listing, which is
+// ordered by (date, created_at, id) descending.
+type TxCursor struct {
+	Date      time.Time
+	CreatedAt time.Time
+	ID        string
+}
+
+// TxFilter selects live transactions. Zero fields match everything.
+type TxFilter struct {
+	From, To           *time.Time // [From, To)
+	Type               string
+	CategoryID         string // split transactions match on any of their lines
+	IncludeDescendants bool   // also match subcategories of CategoryID
+	AccountID          string
+	Payee              string // case-insensitive substring
+	Memo               string // case-insensitive substring
+	Tag                string // exact tag
+	MinAmount          *money.Amount
+	MaxAmount          *money.Amount
+	Source             string
+	After              *TxCursor // rows after this one in listing order
+	Limit              int
+}
+
+// DuplicatePair is two transactions with the same type, amount and currency
+// dated close together.
+type DuplicatePair struct {
+	AID, BID       string
+	APayee, BPayee *string
+}
+
+// TransactionStore holds transactions and their split lines.
+type TransactionStore interface {
+	// List returns the live transactions matching f in listing order, with
+	// their live split lines.
+	List(ctx context.Context, hid string, f TxFilter) ([]models.Transaction, error)
+	// Get returns a live transaction with its live split lines.
+	Get(ctx context.Context, hid, id string) (*models.Transaction, error)
+	// GetMany returns the transactions among ids, deleted or not, with their
+	// live split lines. Unknown ids are left out.
+	GetMany(ctx context.Context, hid string, ids []string) ([]models.Transaction, error)
+	// Create inserts tx and its split lines and fills in their ids.
+	Create(ctx context.Context, tx *models.Transaction) error
+	// Update sets columns of a live transaction; changes maps column names to
+	// values.
+	Update(ctx context.Context, hid, id string, changes map[string]any) error
+	// ReplaceSplits swaps the split lines of tx for splits.
+	ReplaceSplits(ctx context.Context, tx *models.Transaction, splits []models.TransactionSplit) error
+	// TransferPeer returns the other live leg of a transfer.
+	TransferPeer(ctx context.Context, tx *models.Transaction) (*models.Transaction, error)
+	// SetDeleted soft-deletes (or restores) a transaction together with the
+	// other leg when it is a transfer. Restoring needs a deleted row.
+	SetDeleted(ctx context.Context, hid, id string, deleted bool) error
+	// DeleteMany soft-deletes the live transactions among ids.
+	DeleteMany(ctx context.Context, hid string, ids []string) error
+	// ExternalIDs returns those of ids that live transactions carry as
+	// external_id.
+	ExternalIDs(ctx context.Context, hid string, ids []string) ([]string, error)
+	// Import inserts txs, skipping rows whose external_id a live
+	// transaction of their household already has, and returns how many it
+	// inserted.
+	Import(ctx context.Context, txs []models.Transaction) (int, error)
+	// DeleteImported soft-deletes the live transactions an import batch
+	// created.
+	DeleteImported(ctx context.Context, hid, batchID string) error
+	// DuplicatePairs pairs live income and expense rows dated in [from, to)
+	// with another row of the same type, amount and currency at most days
+	// apart, newest first.
+	DuplicatePairs(ctx context.Context, hid string, days int, from, to time.Time, limit int) ([]DuplicatePair, error)
+}
+
+// CategoryStore holds the category tree.
+type CategoryStore interface {
+	// List returns live categories by name.
+	List(ctx context.Context, hid string, includeArchived bool) ([]models.Category, error)
+	// Get returns a live category.
+	Get(ctx context.Context, hid, id string) (*models.Category, error)
+	// Create inserts cat and fills in its id.
+	Create(ctx context.Context, cat *models.Category) error
+	// Update sets columns of a live category; changes maps column names to
+	// values.
+	Update(ctx context.Context, hid, id string, changes map[string]any) error
+	// InTree reports whether id is rootID or one of its descendants.
+	InTree(ctx context.Context, hid, rootID, id string) (bool, error)
+	// CountTransactions counts the live transactions filed under a category,
+	// directly or through a split line.
+	CountTransactions(ctx context.Context, hid, id string) (int64, error)
+	// Merge moves transactions, split lines, budgets, recurring rules,
+	// categorization rules and subcategories from src to target and deletes
+	// src. Budgets of the same month are added up.
+	Merge(ctx context.Context, hid string, src, target *models.Category) error
+	// Delete deletes cat and its budgets, detaches it from recurring and
+	// categorization rules and moves its subcategories up one level.
+	Delete(ctx context.Context, hid string, cat *models.Category) error
+	// Seed adds the default categories of locale the household does not
+	// have yet, created by uid.
+	Seed(ctx context.Context, hid, uid, locale string) (seed.Result, error)
+}
+
+// BudgetHistory sums a category's budgets before some month.
+type BudgetHistory struct {
+	CategoryID string
+	First      string // earliest budgeted month, YYYY-MM
+	Total      money.Amount
+}
+
+// BudgetStore holds monthly budget amounts per category.
+type BudgetStore interface {
+	// List returns the live budgets of a month by category.
+	List(ctx context.Context, hid, month string) ([]models.Budget, error)
+	// Upsert sets the amount of the budget for b's month and category.
+	Upsert(ctx context.Context, b *models.Budget) error
+	// Before sums the live budgets of each of categoryIDs before month.
+	// Categories without any are left out.
+	Before(ctx context.Context, hid, month string, categoryIDs []string) ([]BudgetHistory, error)
+	// Assigned sums every live budget of month and earlier months.
+	Assigned(ctx context.Context, hid, month string) (money.Amount, error)
+}
+
+// DaySum is an amount summed per key, currency and local day, before any
+// conversion. What the key holds depends on the query.
+type DaySum struct {
+	Key      *string
+	Currency string
+	Day      time.Time // local date at midnight UTC
+	Total    money.Amount
+}
+
+// AnalyticsStore aggregates transactions for reports. Days are the local
+// dates of cal.
+type AnalyticsStore interface {
+	// Totals sums income and expense keyed by type, for rows in [from, to).
+	Totals(ctx context.Context, hid string, cal settings.Calendar, from, to time.Time) ([]DaySum, error)
+	// ExpenseByCategory sums expense keyed by category id, for rows in
+	// [from, to). Split expenses count per line; the key is nil for
+	// uncategorized spend.
+	ExpenseByCategory(ctx context.Context, hid string, cal settings.Calendar, from, to time.Time) ([]DaySum, error)
+	// IncomeBefore sums income dated before to.
+	IncomeBefore(ctx context.Context, hid string, cal settings.Calendar, to time.Time) ([]DaySum, error)
+	// RecentMonths sums income and expense keyed by type over the latest n
+	// budget months of cal that have any.
+	RecentMonths(ctx context.Context, hid string, cal settings.Calendar, n int) ([]DaySum, error)
+	// Rates returns the user's exchange rates dated in [from, to], oldest
+	// first.
+	Rates(ctx context.Context, uid string, from, to time.Time) ([]models.FxRate, error)
+}
+
+// AccountBalance is an account with the sum of its live transactions.
+type AccountBalance struct {
+	models.Account
+	Balance money.Amount
+}
+
+// LedgerRow is a transaction with the balance of its account after it.
+type LedgerRow struct {
+	models.Transaction
+	RunningBalance money.Amount
+}
+
+// AccountStore holds accounts. An account belongs to one user, who may book
+// it in any of their households, so its balance counts transactions of all of
+// them, including households the user has since left.
+type AccountStore interface {
+	// List returns the user's live accounts by name with their balances.
+	List(ctx context.Context, uid string, includeArchived bool) ([]AccountBalance, error)
+	// Get returns a live account.
+	Get(ctx context.Context, uid, id string) (*models.Account, error)
+	// Create inserts a and fills in its id.
+	Create(ctx context.Context, a *models.Account) error
+	// Update sets columns of a live account; changes maps column names to
+	// values.
+	Update(ctx context.Context, uid, id string, changes map[string]any) error
+	// SetCurrency changes a live account's currency and relabels its
+	// transactions, whose amounts are not converted.
+	SetCurrency(ctx context.Context, uid, id, currency string) error
+	// Delete soft-deletes a live account.
+	Delete(ctx context.Context, uid, id string) error
+	// CountTransactions counts the live transactions booked on an account.
+	CountTransactions(ctx context.Context, uid, id string) (int64, error)
+	// Balance is the opening balance plus the live transactions dated
+	// before before.
+	Balance(ctx context.Context, acc *models.Account, before time.Time) (money.Amount, error)
+	// Ledger returns at most limit of the account's live transactions dated
+	// in [from, to), newest first, each with the balance after it counted
+	// over the whole history.
+	Ledger(ctx context.Context, acc *models.Account, from, to *time.Time, limit int) ([]LedgerRow, error)
+}
+
+// SettingsStore holds user settings.
+type SettingsStore interface {
+	// Get returns the user's settings, or the defaults if none are saved.
+	Get(ctx context.Context, uid string) (models.UserSettings, error)
+	// Save writes all of the user's settings.
+	Save(ctx context.Context, s *models.UserSettings) error
+}
+
+// RuleStore holds categorization rules.
+type RuleStore interface {
+	// List returns the user's live rules in the order they run.
+	List(ctx context.Context, uid string) ([]models.Rule, error)
+	// Active returns the user's active rules in the order they run.
+	Active(ctx context.Context, uid string) ([]models.Rule, error)
+	// Get returns a live rule.
+	Get(ctx context.Context, uid, id string) (*models.Rule, error)
+	// Create inserts r and fills in its id.
+	Create(ctx context.Context, r *models.Rule) error
+	// Save writes every column of a live rule but its id, owner and
+	// creation time.
+	Save(ctx context.Context, r *models.Rule) error
+	// Delete soft-deletes a live rule.
+	Delete(ctx context.Context, uid, id string) error
+}
+
+// RecurringStore holds recurring transaction rules.
+type RecurringStore interface {
+	// List returns the household's live recurring rules by name.
+	List(ctx context.Context, hid string) ([]models.RecurringRule, error)
+	// Active returns the household's live, active recurring rules.
+	Active(ctx context.Context, hid string) ([]models.RecurringRule, error)
+	// Get returns a live recurring rule.
+	Get(ctx context.Context, hid, id string) (*models.RecurringRule, error)
+	// Create inserts r and fills in its id.
+	Create(ctx context.Context, r *models.RecurringRule) error
+	// Save writes the schedule and template of a live recurring rule; its
+	// household, creator and how far it was materialized stay.
+	Save(ctx context.Context, r *models.RecurringRule) error
+	// Delete soft-deletes a live recurring rule.
+	Delete(ctx context.Context, hid, id string) error
+}
+
+// ImportStore holds statement import batches.
+type ImportStore interface {
+	// List returns the user's live batches, newest first.
+	List(ctx context.Context, uid string) ([]models.ImportBatch, error)
+	// Get returns a live batch. With lock it stays locked until the
+	// transaction ctx carries ends.
+	Get(ctx context.Context, uid, id string, lock bool) (*models.ImportBatch, error)
+	// Create inserts b and fills in its id.
+	Create(ctx context.Context, b *models.ImportBatch) error
+	// Update sets columns of a live batch.
+	Update(ctx context.Context, uid, id string, changes map[string]any) error
+}
+
+// RateFilter selects exchange rates. Zero fields match everything.
+type RateFilter struct {
+	Base, Quote string
+	From, To    *time.Time // [From, To]
+	Limit       int
+}
+
+// RateStore holds the exchange rates users upload.
+type RateStore interface {
+	// List returns the user's rates matching f, newest first.
+	List(ctx context.Context, uid string, f RateFilter) ([]models.FxRate, error)
+	// Save upserts rates of source for the user; a rate of a day and pair
+	// replaces the one saved before. It returns how many distinct rates it
+	// wrote.
+	Save(ctx context.Context, uid, source string, rates []models.FxRate) (int, error)
+}
+
+// HouseholdStore holds households, their members and invites.
+type HouseholdStore interface {
+	// List returns the live households uid belongs to, with uid's role, in
+	// the order uid joined them.
+	List(ctx context.Context, uid string) ([]models.Household, error)
+	// Get returns a live household uid belongs to, with uid's role.
+	Get(ctx context.Context, uid, hid string) (*models.Household, error)
+	// Create inserts h, fills in its id and makes h.CreatedBy its owner.
+	Create(ctx context.Context, h *models.Household) error
+	// EnsureFirst returns the first household uid belongs to. A user in no
+	// household gets a new one named name, reported by created.
+	EnsureFirst(ctx context.Context, uid, name string) (h *models.Household, created bool, err error)
+	// Update sets columns of a live household.
+	Update(ctx context.Context, hid string, changes map[string]any) error
+	// Delete soft-deletes a household and ends every membership of it.
+	Delete(ctx context.Context, hid string) error
+	// Members returns the members of a household in the order they joined.
+	Members(ctx context.Context, hid string) ([]models.HouseholdMember, error)
+	// SetRole changes a member's role.
+	SetRole(ctx context.Context, hid, uid, role string) error
+	// RemoveMember ends a membership.
+	RemoveMember(ctx context.Context, hid, uid string) error
+	// CreateInvite inserts inv and fills in its id.
+	CreateInvite(ctx context.Context, inv *models.HouseholdInvite) error
+	// Invites returns a household's invites that were not accepted yet,
+	// newest first.
+	Invites(ctx context.Context, hid string) ([]models.HouseholdInvite, error)
+	// DeleteInvite removes an invite that was not accepted yet.
+	DeleteInvite(ctx context.Context, hid, id string) error
+	// InviteByCode finds an invite by its code, in any household.
+	InviteByCode(ctx context.Context, code string) (*models.HouseholdInvite, error)
+	// Accept adds uid to the invite's household with its role and marks the
+	// invite accepted.
+	Accept(ctx context.Context, inv *models.HouseholdInvite, uid string, at time.Time) error
+}
@@ -0,0 +1,251 @@
+package validate
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"budgex_backend/internal/apperr"
+	"budgex_backend/internal/fx"
+
+	"github.com/google/uuid"
+)
+
+// Limits of the tags rule.
+const (
+	MaxTags   = 20
+	MaxTagLen = 32
+)
+
+// checkFunc tests v, which is already dereferenced and invalid only for a
+// nil pointer under required. name is the field's JSON name, used to build
+// the code.
+type checkFunc func(v reflect.Value, name, param string) (apperr.FieldError, bool)
+
+var checks = map[string]checkFunc{
+	"required": required,
+	"notempty": required,
+	"gt":       compare("gt"),
+	"gte":      compare("gte"),
+	"lt":       compare("lt"),
+	"lte":      compare("lte"),
+	"min":      length("min"),
+	"max":      length("max"),
+	"oneof":    oneOf,
+	"uuid":     format(ID, "must_be_uuid", "must be a UUID"),
+	"month":    format(layout("2006-01"), "must_be_YYYY-MM", "must be a month as YYYY-MM"),
+	"date":     format(layout("2006-01-02"), "must_be_YYYY-MM-DD", "must be a date as YYYY-MM-DD"),
+	"datetime": format(isDateTime, "must_be_rfc3339_or_YYYY-MM-DD", "must be an RFC 3339 time or a YYYY-MM-DD date"),
+	"currency": format(isCurrency, "must_be_iso_4217", "must be a three-letter ISO 4217 code"),
+	"timezone": format(isTimezone, "must_be_iana", "must be an IANA time zone such as Europe/London"),
+	"regex":    format(isRegex, "must_be_regex", "must be a valid regular expression"),
+	"tags":     tags,
+}
+
+func fail(name, suffix, detail string) (apperr.FieldError, bool) {
+	return apperr.FieldError{Code: name + "_" + suffix, Detail: detail}, false
+}
+
+var pass = apperr.FieldError{}
+
+func required(v reflect.Value, name, _ string) (apperr.FieldError, bool) {
+	ok := v.IsValid()
+	if ok {
+		switch v.Kind() {
+		case reflect.String:
+			ok = strings.TrimSpace(v.String()) != ""
+		case reflect.Slice, reflect.Map:
+			ok = v.Len() > 0
+		}
+	}
+	if !ok {
+		return fail(name, "required", "is required")
+	}
+	return pass, true
+}
+
+// compare checks a number against param.
+func compare(op string) checkFunc {
+	return func(v reflect.Value, name, param string) (apperr.FieldError, bool) {
+		n, limit := number(v), mustFloat(param)
+		switch op {
+		case "gt":
+			if n > limit {
+				return pass, true
+			}
+			if limit == 0 {
+				return fail(name, "must_be_positive", "must be greater than 0")
+			}
+			return fail(name, "must_be_greater_than_"+param, "must be greater than "+param)
+		case "gte":
+			if n >= limit {
+				return pass, true
+			}
+			if limit == 0 {
+				return fail(name, "must_not_be_negative", "must not be negative")
+			}
+			return fail(name, "must_be_at_least_"+param, "must be at least "+param)
+		case "lt":
+			if n < limit {
+				return pass, true
+			}
+			return fail(name, "must_be_less_than_"+param, "must be less than "+param)
+		default:
+			if n <= limit {
+				return pass, true
+			}
+			return fail(name, "must_be_at_most_"+param, "must be at most "+param)
+		}
+	}
+}
+
+// length bounds the characters of a string, the items of a slice or the
+// value of a number.
+func length(op string) checkFunc {
+	return func(v reflect.Value, name, param string) (apperr.FieldError, bool) {
+		limit := mustFloat(param)
+		switch v.Kind() {
+		case reflect.String:
+			n := float64(utf8.RuneCountInString(v.String()))
+			if op == "min" && n < limit {
+				return fail(name, "too_short", fmt.Sprintf("must be at least %s characters", param))
+			}
+			if op == "max" && n > limit {
+				return fail(name, "too_long", fmt.Sprintf("must be at most %s characters", param))
+			}
+		case reflect.Slice, reflect.Array, reflect.Map:
+			n := float64(v.Len())
+			if op == "min" && n < limit {
+				if limit == 1 {
+					return fail(name, "required", "must not be empty")
+				}
+				return apperr.FieldError{Code: "too_few_" + name, Detail: "must have at least " + param + " items"}, false
+			}
+			if op == "max" && n > limit {
+				return apperr.FieldError{Code: "too_many_" + name, Detail: "must have at most " + param + " items"}, false
+			}
+		default:
+			if op == "min" {
+				return compare("gte")(v, name, param)
+			}
+			return compare("lte")(v, name, param)
+		}
+		return pass, true
+	}
+}
+
+// oneOf takes a space-separated list of allowed values.
+func oneOf(v reflect.Value, name, param string) (apperr.FieldError, bool) {
+	allowed := strings.Fields(param)
+	s := fmt.Sprint(v.Interface())
+	for _, a := range allowed {
+		if s == a {
+			return pass, true
+		}
+	}
+	suffix := "must_be_one_of_allowed"
+	if len(allowed) <= 3 {
+		suffix = "must_be_" + strings.Join(allowed[:len(allowed)-1], "_") + "_or_" + allowed[len(allowed)-1]
+		if len(allowed) == 1 {
+			suffix = "must_be_" + allowed[0]
+		}
+	}
+	return fail(name, suffix, "must be one of: "+strings.Join(allowed, ", "))
+}
+
+// format checks a string with ok.
+func format(ok func(string) bool, suffix, detail string) checkFunc {
+	return func(v reflect.Value, name, _ string) (apperr.FieldError, bool) {
+		if v.Kind() != reflect.String || !ok(v.String()) {
+			return fail(name, suffix, detail)
+		}
+		return pass, true
+	}
+}
+
+// ID reports whether s is a UUID in the canonical 36 character form ids are
+// handed out in. Ids go straight into uuid columns, where anything else would
+// make Postgres error out, so callers treat other strings as not found.
+func ID(s string) bool {
+	_, err := uuid.Parse(s)
+	return err == nil && len(s) == 36
+}
+
+func layout(l string) func(string) bool {
+	return func(s string) bool {
+		_, err := time.Parse(l, s)
+		return err == nil
+	}
+}
+
+func isDateTime(s string) bool {
+	return layout("2006-01-02")(s) || layout(time.RFC3339)(s)
+}
+
+func isCurrency(s string) bool {
+	_, ok := fx.NormalizeCode(s)
+	return ok
+}
+
+func isTimezone(s string) bool {
+	if s == "Local" {
+		return false
+	}
+	_, err := time.LoadLocation(s)
+	return err == nil
+}
+
+func isRegex(s string) bool {
+	_, err := regexp.Compile(s)
+	return err == nil
+}
+
+// tags checks a comma-separated tag list: at most MaxTags tags of up to
+// MaxTagLen letters, digits, spaces and - _ . : /
+func tags(v reflect.Value, name, _ string) (apperr.FieldError, bool) {
+	list := strings.Split(v.String(), ",")
+	if len(list) > MaxTags {
+		return apperr.FieldError{Code: "too_many_" + name,
+			Detail: fmt.Sprintf("must have at most %d tags", MaxTags)}, false
+	}
+	for _, t := range list {
+		t = strings.TrimSpace(t)
+		if t == "" {
+			continue
+		}
+		if utf8.RuneCountInString(t) > MaxTagLen {
+			return fail(name, "tag_too_long", fmt.Sprintf("tag %q is longer than %d characters", t, MaxTagLen))
+		}
+		for _, r := range t {
+			if !unicode.IsLetter(r) && !unicode.IsDigit(r) && !strings.ContainsRune(" -_.:/", r) {
+				return fail(name, "bad_syntax", fmt.Sprintf("tag %q may only hold letters, digits, spaces and - _ . : /", t))
+			}
+		}
+	}
+	return pass, true
+}
+
+func number(v reflect.Value) float64 {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		return v.Float()
+	}
+	panic(fmt.Sprintf("validate: %s is not a number", v.Type()))
+}
+
+func mustFloat(s string) float64 {
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		panic(fmt.Sprintf("validate: bad rule parameter %q", s))
+	}
+	return f
+}
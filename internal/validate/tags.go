@@ -0,0 +1,159 @@
+package validate
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// registered holds the request types recorded by Register.
+var registered []any
+
+// Register records request types so CheckRegistered can check their tags
+// on start. Packages call it from init for the types they validate.
+func Register(vs ...any) {
+	registered = append(registered, vs...)
+}
+
+// CheckRegistered checks the tags of every registered type with Tags.
+func CheckRegistered() error {
+	errs := make([]error, 0, len(registered))
+	for _, v := range registered {
+		errs = append(errs, Tags(v))
+	}
+	return errors.Join(errs...)
+}
+
+// Tags reports every rule in the tags of v's type, nested structs and slice
+// elements included, that Check would panic on: unknown rules, parameters
+// that are not numbers, and rules that do not fit the field's type.
+func Tags(v any) error {
+	t := reflect.TypeOf(v)
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return fmt.Errorf("validate: %T is not a struct", v)
+	}
+	var errs []error
+	tagsOf(t, t.Name()+".", map[reflect.Type]bool{}, &errs)
+	return errors.Join(errs...)
+}
+
+func tagsOf(t reflect.Type, prefix string, seen map[reflect.Type]bool, errs *[]error) {
+	if seen[t] {
+		return
+	}
+	seen[t] = true
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		name := jsonName(sf)
+		if name == "-" {
+			continue
+		}
+		ft := derefType(sf.Type)
+		if sf.Anonymous && ft.Kind() == reflect.Struct {
+			tagsOf(ft, prefix, seen, errs)
+			continue
+		}
+		path := prefix + name
+		rules, each := splitTag(sf.Tag.Get("validate"))
+		for _, r := range rules {
+			if err := fits(ft, r); err != nil {
+				*errs = append(*errs, fmt.Errorf("validate: %s: %w", path, err))
+			}
+		}
+		elem := ft
+		if ft.Kind() == reflect.Slice || ft.Kind() == reflect.Array {
+			elem = derefType(ft.Elem())
+			for _, r := range parseRules(each) {
+				if err := fits(elem, r); err != nil {
+					*errs = append(*errs, fmt.Errorf("validate: %s.*: %w", path, err))
+				}
+			}
+			path += ".*"
+		} else if each != "" {
+			*errs = append(*errs, fmt.Errorf("validate: %s: dive on %s, not a slice", path, ft))
+		}
+		if elem.Kind() == reflect.Struct && !isLeaf(elem) {
+			tagsOf(elem, path+".", seen, errs)
+		}
+	}
+}
+
+// fits reports whether rule r can run on values of type t.
+func fits(t reflect.Type, r rule) error {
+	switch r.name {
+	case "required", "notempty":
+		return nil
+	case "ref":
+		if r.param == "" {
+			return errors.New("ref needs a kind, e.g. ref=category")
+		}
+		return kindIs(t, r, reflect.String)
+	case "gt", "gte", "lt", "lte":
+		if err := numberParam(r); err != nil {
+			return err
+		}
+		if !isNumber(t) {
+			return fmt.Errorf("%s needs a number, not %s", r.name, t)
+		}
+		return nil
+	case "min", "max":
+		if err := numberParam(r); err != nil {
+			return err
+		}
+		if !isNumber(t) {
+			return kindIs(t, r, reflect.String, reflect.Slice, reflect.Array, reflect.Map)
+		}
+		return nil
+	case "oneof":
+		if len(strings.Fields(r.param)) == 0 {
+			return errors.New("oneof needs values, e.g. oneof=a b")
+		}
+		return nil
+	}
+	if _, ok := checks[r.name]; !ok {
+		return fmt.Errorf("unknown rule %q", r.name)
+	}
+	// the rest check the format of a string
+	return kindIs(t, r, reflect.String)
+}
+
+func numberParam(r rule) error {
+	if _, err := strconv.ParseFloat(r.param, 64); err != nil {
+		return fmt.Errorf("%s needs a number, not %q", r.name, r.param)
+	}
+	return nil
+}
+
+func kindIs(t reflect.Type, r rule, kinds ...reflect.Kind) error {
+	for _, k := range kinds {
+		if t.Kind() == k {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s does not apply to %s", r.name, t)
+}
+
+func isNumber(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+func derefType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t
+}
@@ -0,0 +1,225 @@
+// Package validate checks request structs against their `validate` tags and
+// reports every failing field at once.
+//
+//	type NewThing struct {
+//		Name       string       `json:"name" validate:"required,max=100"`
+//		Month      string       `json:"month" validate:"required,month"`
+//		Amount     money.Amount `json:"amount" validate:"gt=0"`
+//		CategoryID *string      `json:"category_id" validate:"uuid,ref=category"`
+//		IDs        []string     `json:"ids" validate:"min=1,max=50,dive,uuid"`
+//	}
+//
+// Rules other than required pass on nil pointers and empty strings, so
+// optional and patch fields are only checked when they are set. notempty is
+// required for patch fields: it passes on a nil pointer but not on a pointer
+// to a blank string. A field stops at its first failing rule. Nested structs
+// and slices of structs are walked; their fields are reported as
+// "splits.0.amount". Rules after dive apply to each element of a slice.
+// Numbers compare as stored, so a money.Amount compares in cents: gte=100
+// means at least 1.00.
+//
+// A rule that does not exist or does not fit its field panics in Check.
+// Request types are therefore passed to Register, and CheckRegistered finds
+// such mistakes when the server starts.
+package validate
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"budgex_backend/internal/apperr"
+)
+
+// Refs reports whether the row id of a kind ("account", "category") exists
+// and belongs to the caller. It backs the ref rule.
+type Refs func(ctx context.Context, kind, id string) (bool, error)
+
+// Struct checks v, a struct or a pointer to one, without ref rules.
+func Struct(v any) error {
+	return Check(context.Background(), v, nil)
+}
+
+// Check checks v, a struct or a pointer to one. Failures come back together
+// as one *apperr.Error; an error from refs is returned as is. Ref rules are
+// skipped when refs is nil.
+func Check(ctx context.Context, v any, refs Refs) error {
+	w := walker{ctx: ctx, refs: refs}
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("validate: %T is not a struct", v))
+	}
+	w.walkStruct(rv, "")
+	if w.err != nil {
+		return w.err
+	}
+	if len(w.fields) == 0 {
+		return nil
+	}
+	return apperr.Fields(w.fields...)
+}
+
+type walker struct {
+	ctx    context.Context
+	refs   Refs
+	fields []apperr.FieldError
+	err    error // from refs
+}
+
+func (w *walker) walkStruct(v reflect.Value, prefix string) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		name := jsonName(sf)
+		if name == "-" {
+			continue
+		}
+		fv := v.Field(i)
+		if sf.Anonymous && deref(fv).Kind() == reflect.Struct {
+			if fv = deref(fv); fv.IsValid() {
+				w.walkStruct(fv, prefix)
+			}
+			continue
+		}
+		w.walkField(fv, prefix+name, name, sf.Tag.Get("validate"))
+	}
+}
+
+// walkField applies the rules in tag to v and then descends into it.
+func (w *walker) walkField(v reflect.Value, path, name, tag string) {
+	rules, each := splitTag(tag)
+	if !w.apply(v, path, name, rules) {
+		return
+	}
+	v = deref(v)
+	if !v.IsValid() {
+		return
+	}
+	switch v.Kind() {
+	case reflect.Struct:
+		if !isLeaf(v.Type()) {
+			w.walkStruct(v, path+".")
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			elem := v.Index(i)
+			p := fmt.Sprintf("%s.%d", path, i)
+			if each != "" {
+				if !w.apply(elem, p, name, parseRules(each)) {
+					continue
+				}
+			}
+			if e := deref(elem); e.IsValid() && e.Kind() == reflect.Struct && !isLeaf(e.Type()) {
+				w.walkStruct(e, p+".")
+			}
+		}
+	}
+}
+
+// apply runs rules on v and records the first failure. It reports whether
+// every rule passed.
+func (w *walker) apply(v reflect.Value, path, name string, rules []rule) bool {
+	for _, r := range rules {
+		if w.err != nil {
+			return false
+		}
+		skip := isUnset(v)
+		switch r.name {
+		case "required":
+			skip = false
+		case "notempty":
+			skip = !deref(v).IsValid()
+		}
+		if skip {
+			continue
+		}
+		fe, ok := w.check(v, name, r)
+		if !ok {
+			fe.Field = path
+			w.fields = append(w.fields, fe)
+			return false
+		}
+	}
+	return true
+}
+
+func (w *walker) check(v reflect.Value, name string, r rule) (apperr.FieldError, bool) {
+	if r.name == "ref" {
+		if w.refs == nil {
+			return apperr.FieldError{}, true
+		}
+		ok, err := w.refs(w.ctx, r.param, deref(v).String())
+		if err != nil {
+			w.err = err
+			return apperr.FieldError{}, true
+		}
+		return apperr.FieldError{Code: r.param + "_not_found", Detail: "does not exist"}, ok
+	}
+	fn, found := checks[r.name]
+	if !found {
+		panic(fmt.Sprintf("validate: unknown rule %q on %s", r.name, name))
+	}
+	return fn(deref(v), name, r.param)
+}
+
+type rule struct{ name, param string }
+
+// splitTag separates the field's own rules from those after dive.
+func splitTag(tag string) ([]rule, string) {
+	parts := strings.Split(tag, ",")
+	for i, p := range parts {
+		if strings.TrimSpace(p) == "dive" {
+			return parseRules(strings.Join(parts[:i], ",")), strings.Join(parts[i+1:], ",")
+		}
+	}
+	return parseRules(tag), ""
+}
+
+func parseRules(s string) []rule {
+	if s == "" {
+		return nil
+	}
+	var out []rule
+	for _, part := range strings.Split(s, ",") {
+		name, param, _ := strings.Cut(strings.TrimSpace(part), "=")
+		out = append(out, rule{name, param})
+	}
+	return out
+}
+
+func jsonName(sf reflect.StructField) string {
+	name, _, _ := strings.Cut(sf.Tag.Get("json"), ",")
+	if name == "" {
+		return sf.Name
+	}
+	return name
+}
+
+// deref follows pointers; it returns the zero Value for a nil pointer.
+func deref(v reflect.Value) reflect.Value {
+	for v.IsValid() && (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
+// isUnset reports a nil pointer or an empty string.
+func isUnset(v reflect.Value) bool {
+	v = deref(v)
+	return !v.IsValid() || (v.Kind() == reflect.String && v.Len() == 0)
+}
+
+// isLeaf reports struct types validated as a whole, such as time.Time.
+func isLeaf(t reflect.Type) bool {
+	return t.PkgPath() == "time"
+}
@@ -0,0 +1,199 @@
+package validate_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"budgex_backend/internal/apperr"
+	"budgex_backend/internal/money"
+	"budgex_backend/internal/validate"
+)
+
+type line struct {
+	CategoryID *string      `json:"category_id" validate:"uuid,ref=category"`
+	Amount     money.Amount `json:"amount" validate:"gt=0"`
+}
+
+type input struct {
+	Name       string        `json:"name" validate:"required,max=5"`
+	Kind       *string       `json:"kind" validate:"notempty,oneof=cash checking"`
+	Amount     money.Amount  `json:"amount" validate:"gt=0"`
+	Budget     *money.Amount `json:"budget" validate:"gte=0,lte=100"` // cents: at most 1.00
+	Fee        money.Amount  `json:"fee" validate:"gte=150"`          // cents: at least 1.50
+	CategoryID string        `json:"category_id" validate:"uuid,ref=category"`
+	AccountID  *string       `json:"account_id" validate:"uuid,ref=account"`
+	IDs        []string      `json:"ids" validate:"max=2,dive,uuid"`
+	Lines      []line        `json:"lines" validate:"max=3"`
+}
+
+const (
+	known   = "6f1c2f43-9a4e-4d4b-8f61-1d2b4b7a0c11"
+	unknown = "0b6f4b52-3c1e-4a8e-9a55-7d0c2f1e9b20"
+)
+
+// refs knows one category and one account.
+func refs(_ context.Context, kind, id string) (bool, error) {
+	return id == known, nil
+}
+
+func ptr[T any](v T) *T { return &v }
+
+// valid returns an input that passes; tests break one field of it.
+func valid() input {
+	return input{Name: "Food", Amount: 1, Fee: 150}
+}
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name  string
+		edit  func(*input)
+		field string // "" when the input passes
+		code  string
+	}{
+		{"valid", func(*input) {}, "", ""},
+
+		{"required missing", func(in *input) { in.Name = "" }, "name", "name_required"},
+		{"required blank", func(in *input) { in.Name = "   " }, "name", "name_required"},
+		{"max length", func(in *input) { in.Name = "Groceries" }, "name", "name_too_long"},
+		{"max counts runes", func(in *input) { in.Name = "Café!" }, "", ""},
+
+		{"oneof", func(in *input) { in.Kind = ptr("cash") }, "", ""},
+		{"oneof other", func(in *input) { in.Kind = ptr("gold") }, "kind", "kind_must_be_cash_or_checking"},
+		{"notempty nil", func(in *input) { in.Kind = nil }, "", ""},
+		{"notempty blank", func(in *input) { in.Kind = ptr("") }, "kind", "kind_required"},
+
+		{"gt 0 cents", func(in *input) { in.Amount = 0 }, "amount", "amount_must_be_positive"},
+		{"gt one cent", func(in *input) { in.Amount = money.FromCents(1) }, "", ""},
+		{"gt negative", func(in *input) { in.Amount = -500 }, "amount", "amount_must_be_positive"},
+		{"gte 0", func(in *input) { in.Budget = ptr(money.Amount(0)) }, "", ""},
+		{"gte negative", func(in *input) { in.Budget = ptr(money.Amount(-1)) }, "budget", "budget_must_not_be_negative"},
+		{"lte in cents", func(in *input) { in.Budget = ptr(money.Amount(100)) }, "", ""},
+		{"lte over", func(in *input) { in.Budget = ptr(money.Amount(101)) }, "budget", "budget_must_be_at_most_100"},
+		{"gte in cents", func(in *input) { in.Fee = 149 }, "fee", "fee_must_be_at_least_150"},
+
+		{"uuid", func(in *input) { in.CategoryID = known }, "", ""},
+		{"uuid bad", func(in *input) { in.CategoryID = "food" }, "category_id", "category_id_must_be_uuid"},
+		{"uuid without dashes", func(in *input) { in.CategoryID = strings.ReplaceAll(known, "-", "") }, "category_id", "category_id_must_be_uuid"},
+		{"ref unknown", func(in *input) { in.CategoryID = unknown }, "category_id", "category_not_found"},
+		{"ref account", func(in *input) { in.AccountID = ptr(unknown) }, "account_id", "account_not_found"},
+		{"ref skipped when empty", func(in *input) { in.AccountID = ptr("") }, "", ""},
+
+		{"dive", func(in *input) { in.IDs = []string{known, unknown} }, "", ""},
+		{"dive bad element", func(in *input) { in.IDs = []string{known, "x"} }, "ids.1", "ids_must_be_uuid"},
+		{"slice max", func(in *input) { in.IDs = []string{known, known, known} }, "ids", "too_many_ids"},
+
+		{"nested", func(in *input) { in.Lines = []line{{Amount: 100, CategoryID: ptr(known)}} }, "", ""},
+		{"nested amount", func(in *input) { in.Lines = []line{{Amount: 100}, {Amount: 0}} }, "lines.1.amount", "amount_must_be_positive"},
+		{"nested ref", func(in *input) { in.Lines = []line{{Amount: 100, CategoryID: ptr(unknown)}} }, "lines.0.category_id", "category_not_found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in := valid()
+			tt.edit(&in)
+			err := validate.Check(context.Background(), &in, refs)
+			if tt.field == "" {
+				if err != nil {
+					t.Fatalf("Check = %v, want it to pass", err)
+				}
+				return
+			}
+			e := apperr.As(err)
+			if e == nil || e.Kind != apperr.KindInvalid || len(e.Fields) != 1 {
+				t.Fatalf("Check = %#v, want one invalid field", err)
+			}
+			if f := e.Fields[0]; f.Field != tt.field || f.Code != tt.code {
+				t.Errorf("Check = %s:%s, want %s:%s", f.Field, f.Code, tt.field, tt.code)
+			}
+		})
+	}
+}
+
+func TestCheckReportsEveryField(t *testing.T) {
+	in := input{Amount: 0, CategoryID: "x", Fee: 150}
+	e := apperr.As(validate.Check(context.Background(), in, refs))
+	if e == nil || e.Code != "invalid_input" {
+		t.Fatalf("Check = %v, want invalid_input", e)
+	}
+	var got []string
+	for _, f := range e.Fields {
+		got = append(got, f.Field)
+	}
+	if want := []string{"name", "amount", "category_id"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("fields = %v, want %v", got, want)
+	}
+}
+
+func TestCheckRefError(t *testing.T) {
+	boom := errors.New("db down")
+	in := valid()
+	in.CategoryID = known
+	err := validate.Check(context.Background(), in, func(context.Context, string, string) (bool, error) {
+		return false, boom
+	})
+	if !errors.Is(err, boom) {
+		t.Errorf("Check = %v, want the lookup error", err)
+	}
+	// Struct skips ref rules
+	if err := validate.Struct(in); err != nil {
+		t.Errorf("Struct = %v", err)
+	}
+}
+
+func TestTags(t *testing.T) {
+	type nested struct {
+		Count int `json:"count" validate:"gte=x"`
+	}
+	tests := []struct {
+		name string
+		v    any
+		want string // "" for no error
+	}{
+		{"valid", input{}, ""},
+		{"pointer", &input{}, ""},
+		{"not a struct", 3, "is not a struct"},
+		{"unknown rule", struct {
+			A string `json:"a" validate:"required,email"`
+		}{}, `a: unknown rule "email"`},
+		{"typo", struct {
+			A string `json:"a" validate:"requried"`
+		}{}, `unknown rule "requried"`},
+		{"bad number", struct {
+			A int `json:"a" validate:"max=ten"`
+		}{}, `max needs a number, not "ten"`},
+		{"compare on string", struct {
+			A string `json:"a" validate:"gt=0"`
+		}{}, "gt needs a number, not string"},
+		{"format on number", struct {
+			A *int `json:"a" validate:"uuid"`
+		}{}, "uuid does not apply to int"},
+		{"ref without kind", struct {
+			A string `json:"a" validate:"ref"`
+		}{}, "ref needs a kind"},
+		{"empty oneof", struct {
+			A string `json:"a" validate:"oneof="`
+		}{}, "oneof needs values"},
+		{"dive on string", struct {
+			A string `json:"a" validate:"dive,uuid"`
+		}{}, "dive on string, not a slice"},
+		{"after dive", struct {
+			A []int `json:"a" validate:"dive,uuid"`
+		}{}, "a.*: uuid does not apply to int"},
+		{"nested", struct {
+			Lines []nested `json:"lines"`
+		}{}, `lines.*.count: gte needs a number, not "x"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validate.Tags(tt.v)
+			switch {
+			case tt.want == "" && err != nil:
+				t.Errorf("Tags = %v, want nil", err)
+			case tt.want != "" && (err == nil || !strings.Contains(err.Error(), tt.want)):
+				t.Errorf("Tags = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}